@@ -2,13 +2,19 @@ package main
 
 import (
 	"context"
+	"errors"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/samber/do"
 	"github.com/serroba/web-demo-go/internal/container"
+	"github.com/serroba/web-demo-go/internal/logging"
 	"github.com/serroba/web-demo-go/internal/messaging"
+	"github.com/serroba/web-demo-go/internal/metrics"
 	"go.uber.org/zap"
 )
 
@@ -20,11 +26,24 @@ func main() {
 		TopicURLCreated:  getEnv("TOPIC_URL_CREATED", "url.created"),
 		TopicURLAccessed: getEnv("TOPIC_URL_ACCESSED", "url.accessed"),
 		ConsumerGroup:    getEnv("CONSUMER_GROUP", "analytics"),
+
+		TopicURLCreatedToken: getEnv("TOPIC_URL_CREATED_TOKEN", ""),
+		TopicURLCreatedHash:  getEnv("TOPIC_URL_CREATED_HASH", ""),
+
+		ConsumerAckTimeout:     getDurationEnv("CONSUMER_ACK_TIMEOUT", 30*time.Second),
+		PendingMetricsInterval: getDurationEnv("PENDING_METRICS_INTERVAL", 15*time.Second),
+
+		AnalyticsRetention:     getDurationEnv("ANALYTICS_RETENTION", 0),
+		AnalyticsPruneInterval: getDurationEnv("ANALYTICS_PRUNE_INTERVAL", time.Hour),
+		AnalyticsIPHashKey:     getEnv("ANALYTICS_IP_HASH_KEY", ""),
+
+		AccessCountFlushInterval: getDurationEnv("ACCESS_COUNT_FLUSH_INTERVAL", 10*time.Second),
 	}
 
 	injector := do.New()
 	do.ProvideValue(injector, opts)
 	container.LoggerPackage(injector)
+	container.MetricsPackage(injector)
 	container.RedisPackage(injector)
 	container.PostgresPackage(injector)
 	container.AnalyticsStorePackage(injector)
@@ -32,6 +51,7 @@ func main() {
 
 	logger := do.MustInvoke[*zap.Logger](injector)
 	group := do.MustInvoke[*messaging.ConsumerGroup](injector)
+	registry := do.MustInvoke[*prometheus.Registry](injector)
 
 	ctx, cancel := context.WithCancel(context.Background())
 
@@ -39,6 +59,19 @@ func main() {
 		logger.Fatal("failed to start consumer group", zap.Error(err))
 	}
 
+	// Expose Prometheus metrics
+	metricsServer := &http.Server{
+		Addr:              getEnv("METRICS_ADDR", ":9090"),
+		Handler:           metrics.Handler(registry),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	go func() {
+		if err := metricsServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("metrics server error", zap.Error(err))
+		}
+	}()
+
 	// Wait for shutdown signal
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
@@ -47,6 +80,10 @@ func main() {
 	logger.Info("shutting down")
 	cancel()
 
+	_ = logging.TimeShutdown(logger, "metrics server", func() error {
+		return metricsServer.Shutdown(context.Background())
+	})
+
 	if err := injector.Shutdown(); err != nil {
 		logger.Error("shutdown error", zap.Error(err))
 	}
@@ -61,3 +98,13 @@ func getEnv(key, defaultValue string) string {
 
 	return defaultValue
 }
+
+func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
+	if v := os.Getenv(key); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			return d
+		}
+	}
+
+	return defaultValue
+}
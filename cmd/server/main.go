@@ -12,17 +12,22 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/samber/do"
 	"github.com/serroba/web-demo-go/internal/container"
+	"github.com/serroba/web-demo-go/internal/logging"
+	"github.com/serroba/web-demo-go/internal/tlsconfig"
 	"go.uber.org/zap"
 )
 
 func registerPackages(injector *do.Injector, options *container.Options) {
 	do.ProvideValue(injector, options)
 	container.LoggerPackage(injector)
+	container.MetricsPackage(injector)
 	container.RedisPackage(injector)
 	container.PostgresPackage(injector)
 	container.RepositoryPackage(injector)
+	container.CodeGeneratorPackage(injector)
 	container.RateLimitPackage(injector)
 	container.PublisherGroupPackage(injector)
+	container.AnalyticsStorePackage(injector)
 	container.HTTPPackage(injector)
 }
 
@@ -35,35 +40,58 @@ func main() {
 
 		var server *http.Server
 
+		readinessCtx, stopReadiness := context.WithCancel(context.Background())
+
 		hooks.OnStart(func() {
 			router := do.MustInvoke[*chi.Mux](injector)
 
 			// Invoke API to trigger route registration
 			_ = do.MustInvoke[huma.API](injector)
 
+			// /readyz answers 503 until dependencies answer and the warm-up delay passes
+			go container.AwaitReadiness(readinessCtx, injector)
+
 			server = &http.Server{
 				Addr:              fmt.Sprintf(":%d", options.Port),
 				Handler:           router,
 				ReadHeaderTimeout: 10 * time.Second,
 			}
 
-			logger.Info("server starting", zap.Int("port", options.Port))
+			serve := server.ListenAndServe
 
-			if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			if options.TLSCertFile != "" || options.TLSKeyFile != "" {
+				tlsConfig, err := tlsconfig.New(options.MinTLSVersion)
+				if err != nil {
+					logger.Fatal("invalid TLS configuration", zap.Error(err))
+				}
+
+				server.TLSConfig = tlsConfig
+				serve = func() error {
+					return server.ListenAndServeTLS(options.TLSCertFile, options.TLSKeyFile)
+				}
+			}
+
+			logger.Info("server starting",
+				zap.Int("port", options.Port),
+				zap.Bool("tls", server.TLSConfig != nil),
+			)
+
+			if err := serve(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 				logger.Fatal("server failed", zap.Error(err))
 			}
 		})
 
 		hooks.OnStop(func() {
 			logger.Info("shutting down")
+			stopReadiness()
 
 			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 			defer cancel()
 
 			if server != nil {
-				if err := server.Shutdown(ctx); err != nil {
-					logger.Error("server shutdown error", zap.Error(err))
-				}
+				_ = logging.TimeShutdown(logger, "http server", func() error {
+					return server.Shutdown(ctx)
+				})
 			}
 
 			if err := injector.Shutdown(); err != nil {
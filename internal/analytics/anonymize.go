@@ -0,0 +1,19 @@
+package analytics
+
+import "net"
+
+// AnonymizeIP truncates a client IP so it no longer identifies a single host:
+// the last octet of an IPv4 address and the last 80 bits of an IPv6 address are
+// zeroed. Values that are not IP addresses are dropped.
+func AnonymizeIP(ip string) string {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return ""
+	}
+
+	if v4 := parsed.To4(); v4 != nil {
+		return v4.Mask(net.CIDRMask(24, 32)).String()
+	}
+
+	return parsed.Mask(net.CIDRMask(48, 128)).String()
+}
@@ -0,0 +1,28 @@
+package analytics_test
+
+import (
+	"testing"
+
+	"github.com/serroba/web-demo-go/internal/analytics"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnonymizeIP(t *testing.T) {
+	tests := []struct {
+		name string
+		ip   string
+		want string
+	}{
+		{name: "ipv4 zeroes last octet", ip: "203.0.113.42", want: "203.0.113.0"},
+		{name: "ipv4-mapped ipv6", ip: "::ffff:203.0.113.42", want: "203.0.113.0"},
+		{name: "ipv6 keeps the /48 prefix", ip: "2001:db8:abcd:12::1", want: "2001:db8:abcd::"},
+		{name: "empty", ip: "", want: ""},
+		{name: "not an ip", ip: "unknown", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, analytics.AnonymizeIP(tt.ip))
+		})
+	}
+}
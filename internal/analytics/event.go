@@ -1,23 +1,92 @@
 package analytics
 
-import "time"
+import (
+	"cmp"
+	"encoding/json"
+	"time"
+)
 
 // URLCreatedEvent represents an event emitted when a URL is shortened.
 type URLCreatedEvent struct {
 	Code        string    `json:"code"`
-	OriginalURL string    `json:"originalUrl"`
-	URLHash     string    `json:"urlHash,omitempty"`
+	OriginalURL string    `json:"original_url"`
+	URLHash     string    `json:"url_hash,omitempty"`
 	Strategy    string    `json:"strategy"`
-	CreatedAt   time.Time `json:"createdAt"`
-	ClientIP    string    `json:"clientIp"`
-	UserAgent   string    `json:"userAgent"`
+	CreatedAt   time.Time `json:"created_at"`
+	ClientIP    string    `json:"client_ip,omitempty"`
+	UserAgent   string    `json:"user_agent,omitempty"`
 }
 
 // URLAccessedEvent represents an event emitted when a short URL is accessed.
 type URLAccessedEvent struct {
 	Code       string    `json:"code"`
-	AccessedAt time.Time `json:"accessedAt"`
-	ClientIP   string    `json:"clientIp"`
-	UserAgent  string    `json:"userAgent"`
+	AccessedAt time.Time `json:"accessed_at"`
+	ClientIP   string    `json:"client_ip,omitempty"`
+	UserAgent  string    `json:"user_agent,omitempty"`
 	Referrer   string    `json:"referrer,omitempty"`
 }
+
+// legacyEventFields holds the camelCase names events used before switching to
+// snake_case, so payloads still queued from older publishers keep decoding.
+type legacyEventFields struct {
+	OriginalURL string    `json:"originalUrl"`
+	URLHash     string    `json:"urlHash"`
+	CreatedAt   time.Time `json:"createdAt"`
+	AccessedAt  time.Time `json:"accessedAt"`
+	ClientIP    string    `json:"clientIp"`
+	UserAgent   string    `json:"userAgent"`
+}
+
+// UnmarshalJSON decodes snake_case payloads and falls back to legacy camelCase fields.
+func (e *URLCreatedEvent) UnmarshalJSON(data []byte) error {
+	// Fields are matched by JSON name, so the embedded structs do not clash.
+	type event URLCreatedEvent
+
+	var payload struct {
+		event
+		legacyEventFields
+	}
+
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return err
+	}
+
+	legacy := payload.legacyEventFields
+	*e = URLCreatedEvent(payload.event)
+	e.OriginalURL = cmp.Or(e.OriginalURL, legacy.OriginalURL)
+	e.URLHash = cmp.Or(e.URLHash, legacy.URLHash)
+	e.ClientIP = cmp.Or(e.ClientIP, legacy.ClientIP)
+	e.UserAgent = cmp.Or(e.UserAgent, legacy.UserAgent)
+
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = legacy.CreatedAt
+	}
+
+	return nil
+}
+
+// UnmarshalJSON decodes snake_case payloads and falls back to legacy camelCase fields.
+func (e *URLAccessedEvent) UnmarshalJSON(data []byte) error {
+	// Fields are matched by JSON name, so the embedded structs do not clash.
+	type event URLAccessedEvent
+
+	var payload struct {
+		event
+		legacyEventFields
+	}
+
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return err
+	}
+
+	legacy := payload.legacyEventFields
+	*e = URLAccessedEvent(payload.event)
+	e.ClientIP = cmp.Or(e.ClientIP, legacy.ClientIP)
+	e.UserAgent = cmp.Or(e.UserAgent, legacy.UserAgent)
+
+	if e.AccessedAt.IsZero() {
+		e.AccessedAt = legacy.AccessedAt
+	}
+
+	return nil
+}
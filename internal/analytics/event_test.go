@@ -0,0 +1,143 @@
+package analytics_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/serroba/web-demo-go/internal/analytics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestURLCreatedEvent_JSON(t *testing.T) {
+	createdAt := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+
+	t.Run("marshals snake_case field names", func(t *testing.T) {
+		payload, err := json.Marshal(&analytics.URLCreatedEvent{
+			Code:        "abc123",
+			OriginalURL: "https://example.com",
+			URLHash:     "h1",
+			Strategy:    "hash",
+			CreatedAt:   createdAt,
+			ClientIP:    "10.0.0.1",
+			UserAgent:   "curl/8",
+		})
+		require.NoError(t, err)
+
+		assert.JSONEq(t, `{
+			"code": "abc123",
+			"original_url": "https://example.com",
+			"url_hash": "h1",
+			"strategy": "hash",
+			"created_at": "2026-10-16T09:00:00Z",
+			"client_ip": "10.0.0.1",
+			"user_agent": "curl/8"
+		}`, string(payload))
+	})
+
+	t.Run("omits empty optional fields", func(t *testing.T) {
+		payload, err := json.Marshal(&analytics.URLCreatedEvent{
+			Code:        "abc123",
+			OriginalURL: "https://example.com",
+			Strategy:    "token",
+			CreatedAt:   createdAt,
+		})
+		require.NoError(t, err)
+
+		assert.JSONEq(t, `{
+			"code": "abc123",
+			"original_url": "https://example.com",
+			"strategy": "token",
+			"created_at": "2026-10-16T09:00:00Z"
+		}`, string(payload))
+	})
+
+	t.Run("decodes legacy camelCase payloads", func(t *testing.T) {
+		var event analytics.URLCreatedEvent
+
+		err := json.Unmarshal([]byte(`{
+			"code": "abc123",
+			"originalUrl": "https://example.com",
+			"urlHash": "h1",
+			"strategy": "hash",
+			"createdAt": "2026-10-16T09:00:00Z",
+			"clientIp": "10.0.0.1",
+			"userAgent": "curl/8"
+		}`), &event)
+		require.NoError(t, err)
+
+		assert.Equal(t, analytics.URLCreatedEvent{
+			Code:        "abc123",
+			OriginalURL: "https://example.com",
+			URLHash:     "h1",
+			Strategy:    "hash",
+			CreatedAt:   createdAt,
+			ClientIP:    "10.0.0.1",
+			UserAgent:   "curl/8",
+		}, event)
+	})
+
+	t.Run("round trips", func(t *testing.T) {
+		want := analytics.URLCreatedEvent{Code: "abc123", OriginalURL: "https://example.com", CreatedAt: createdAt}
+
+		payload, err := json.Marshal(&want)
+		require.NoError(t, err)
+
+		var got analytics.URLCreatedEvent
+		require.NoError(t, json.Unmarshal(payload, &got))
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("returns error on invalid json", func(t *testing.T) {
+		var event analytics.URLCreatedEvent
+
+		assert.Error(t, json.Unmarshal([]byte(`{"code":`), &event))
+	})
+}
+
+func TestURLAccessedEvent_JSON(t *testing.T) {
+	accessedAt := time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC)
+
+	t.Run("marshals snake_case names and omits empty optionals", func(t *testing.T) {
+		payload, err := json.Marshal(&analytics.URLAccessedEvent{
+			Code:       "abc123",
+			AccessedAt: accessedAt,
+			ClientIP:   "10.0.0.1",
+		})
+		require.NoError(t, err)
+
+		assert.JSONEq(t, `{
+			"code": "abc123",
+			"accessed_at": "2026-10-16T09:30:00Z",
+			"client_ip": "10.0.0.1"
+		}`, string(payload))
+	})
+
+	t.Run("decodes legacy camelCase payloads", func(t *testing.T) {
+		var event analytics.URLAccessedEvent
+
+		err := json.Unmarshal([]byte(`{
+			"code": "abc123",
+			"accessedAt": "2026-10-16T09:30:00Z",
+			"clientIp": "10.0.0.1",
+			"userAgent": "curl/8",
+			"referrer": "https://ref.example"
+		}`), &event)
+		require.NoError(t, err)
+
+		assert.Equal(t, analytics.URLAccessedEvent{
+			Code:       "abc123",
+			AccessedAt: accessedAt,
+			ClientIP:   "10.0.0.1",
+			UserAgent:  "curl/8",
+			Referrer:   "https://ref.example",
+		}, event)
+	})
+
+	t.Run("returns error on invalid json", func(t *testing.T) {
+		var event analytics.URLAccessedEvent
+
+		assert.Error(t, json.Unmarshal([]byte(`[`), &event))
+	})
+}
@@ -0,0 +1,128 @@
+package analytics
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// HitStore persists accumulated per-code access counts.
+type HitStore interface {
+	// IncrementAccessCounts adds each delta to the access count of its code in a single batch.
+	IncrementAccessCounts(ctx context.Context, deltas map[string]int64) error
+}
+
+// HitCounter accumulates access counts in memory and flushes them to a HitStore
+// periodically, so a click does not cost a database write.
+type HitCounter struct {
+	store    HitStore
+	interval time.Duration
+	logger   *zap.Logger
+	mu       sync.Mutex
+	counts   map[string]int64
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+// NewHitCounter creates a hit counter that flushes every interval.
+func NewHitCounter(store HitStore, interval time.Duration, logger *zap.Logger) *HitCounter {
+	return &HitCounter{
+		store:    store,
+		interval: interval,
+		logger:   logger,
+		counts:   make(map[string]int64),
+		done:     make(chan struct{}),
+	}
+}
+
+// Record counts one access for code.
+func (c *HitCounter) Record(code string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.counts[code]++
+}
+
+// Track wraps an accessed-event handler so every successfully handled event is counted.
+func (c *HitCounter) Track(
+	next func(ctx context.Context, event *URLAccessedEvent) error,
+) func(ctx context.Context, event *URLAccessedEvent) error {
+	return func(ctx context.Context, event *URLAccessedEvent) error {
+		if err := next(ctx, event); err != nil {
+			return err
+		}
+
+		c.Record(event.Code)
+
+		return nil
+	}
+}
+
+// Start flushes accumulated counts on every interval until shut down.
+func (c *HitCounter) Start(ctx context.Context) error {
+	ctx, c.cancel = context.WithCancel(ctx)
+
+	go c.loop(ctx)
+
+	return nil
+}
+
+func (c *HitCounter) loop(ctx context.Context) {
+	defer close(c.done)
+
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.flush(ctx)
+		}
+	}
+}
+
+// flush writes the accumulated counts in one batch. Counts that fail to
+// persist are merged back so they are retried on the next flush.
+func (c *HitCounter) flush(ctx context.Context) {
+	c.mu.Lock()
+	deltas := c.counts
+	c.counts = make(map[string]int64)
+	c.mu.Unlock()
+
+	if len(deltas) == 0 {
+		return
+	}
+
+	if err := c.store.IncrementAccessCounts(ctx, deltas); err != nil {
+		c.logger.Error("failed to flush access counts",
+			zap.Int("codes", len(deltas)),
+			zap.Error(err),
+		)
+
+		c.mu.Lock()
+		for code, delta := range deltas {
+			c.counts[code] += delta
+		}
+		c.mu.Unlock()
+
+		return
+	}
+
+	c.logger.Debug("flushed access counts", zap.Int("codes", len(deltas)))
+}
+
+// Shutdown stops the periodic flush and writes any remaining counts.
+func (c *HitCounter) Shutdown() error {
+	if c.cancel != nil {
+		c.cancel()
+		<-c.done
+	}
+
+	c.flush(context.Background())
+
+	return nil
+}
@@ -0,0 +1,106 @@
+package analytics_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/serroba/web-demo-go/internal/analytics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type mockHitStore struct {
+	mu      sync.Mutex
+	batches []map[string]int64
+	err     error
+}
+
+func (m *mockHitStore) IncrementAccessCounts(_ context.Context, deltas map[string]int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.err != nil {
+		return m.err
+	}
+
+	m.batches = append(m.batches, deltas)
+
+	return nil
+}
+
+func (m *mockHitStore) Batches() []map[string]int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.batches
+}
+
+func TestHitCounter(t *testing.T) {
+	saveAccessed := func(_ context.Context, _ *analytics.URLAccessedEvent) error { return nil }
+
+	t.Run("flushes accumulated events as a single batched increment", func(t *testing.T) {
+		store := &mockHitStore{}
+		counter := analytics.NewHitCounter(store, time.Hour, zap.NewNop())
+		handle := counter.Track(saveAccessed)
+
+		require.NoError(t, counter.Start(context.Background()))
+
+		for range 3 {
+			require.NoError(t, handle(context.Background(), &analytics.URLAccessedEvent{Code: "abc123"}))
+		}
+
+		require.NoError(t, handle(context.Background(), &analytics.URLAccessedEvent{Code: "def456"}))
+		require.NoError(t, counter.Shutdown())
+
+		assert.Equal(t, []map[string]int64{{"abc123": 3, "def456": 1}}, store.Batches())
+	})
+
+	t.Run("flushes on interval", func(t *testing.T) {
+		store := &mockHitStore{}
+		counter := analytics.NewHitCounter(store, 10*time.Millisecond, zap.NewNop())
+
+		require.NoError(t, counter.Start(context.Background()))
+
+		counter.Record("abc123")
+		counter.Record("abc123")
+
+		assert.Eventually(t, func() bool { return len(store.Batches()) == 1 }, time.Second, 5*time.Millisecond)
+		require.NoError(t, counter.Shutdown())
+
+		assert.Equal(t, []map[string]int64{{"abc123": 2}}, store.Batches())
+	})
+
+	t.Run("does not count events the handler failed", func(t *testing.T) {
+		store := &mockHitStore{}
+		counter := analytics.NewHitCounter(store, time.Hour, zap.NewNop())
+		handle := counter.Track(func(_ context.Context, _ *analytics.URLAccessedEvent) error {
+			return errors.New("save failed")
+		})
+
+		require.Error(t, handle(context.Background(), &analytics.URLAccessedEvent{Code: "abc123"}))
+		require.NoError(t, counter.Shutdown())
+
+		assert.Empty(t, store.Batches())
+	})
+
+	t.Run("keeps counts when a flush fails", func(t *testing.T) {
+		store := &mockHitStore{err: errors.New("db down")}
+		counter := analytics.NewHitCounter(store, time.Hour, zap.NewNop())
+
+		counter.Record("abc123")
+		require.NoError(t, counter.Shutdown())
+
+		store.mu.Lock()
+		store.err = nil
+		store.mu.Unlock()
+
+		counter.Record("abc123")
+		require.NoError(t, counter.Shutdown())
+
+		assert.Equal(t, []map[string]int64{{"abc123": 2}}, store.Batches())
+	})
+}
@@ -0,0 +1,85 @@
+package analytics
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Pruner periodically deletes access events older than the retention period.
+type Pruner struct {
+	store     Store
+	retention time.Duration
+	interval  time.Duration
+	logger    *zap.Logger
+	cancel    context.CancelFunc
+	done      chan struct{}
+}
+
+// NewPruner creates a pruner that runs every interval and keeps events newer than retention.
+func NewPruner(store Store, retention, interval time.Duration, logger *zap.Logger) *Pruner {
+	return &Pruner{
+		store:     store,
+		retention: retention,
+		interval:  interval,
+		logger:    logger,
+		done:      make(chan struct{}),
+	}
+}
+
+// Start prunes once immediately and then on every interval until shut down.
+func (p *Pruner) Start(ctx context.Context) error {
+	ctx, p.cancel = context.WithCancel(ctx)
+
+	go p.loop(ctx)
+
+	return nil
+}
+
+func (p *Pruner) loop(ctx context.Context) {
+	defer close(p.done)
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		p.prune(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *Pruner) prune(ctx context.Context) {
+	cutoff := time.Now().Add(-p.retention)
+
+	deleted, err := p.store.PruneAccessedBefore(ctx, cutoff)
+	if err != nil {
+		p.logger.Error("failed to prune accessed events",
+			zap.Time("before", cutoff),
+			zap.Error(err),
+		)
+
+		return
+	}
+
+	p.logger.Info("pruned accessed events",
+		zap.Time("before", cutoff),
+		zap.Int64("deleted", deleted),
+	)
+}
+
+// Shutdown stops the pruner and waits for an in-flight prune to finish.
+func (p *Pruner) Shutdown() error {
+	if p.cancel != nil {
+		p.cancel()
+	}
+
+	<-p.done
+
+	return nil
+}
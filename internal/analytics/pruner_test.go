@@ -0,0 +1,129 @@
+package analytics_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/serroba/web-demo-go/internal/analytics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type mockStore struct {
+	pruneFunc func(ctx context.Context, t time.Time) (int64, error)
+}
+
+func (m *mockStore) SaveURLCreated(_ context.Context, _ *analytics.URLCreatedEvent) error {
+	return nil
+}
+
+func (m *mockStore) SaveURLAccessed(_ context.Context, _ *analytics.URLAccessedEvent) error {
+	return nil
+}
+
+func (m *mockStore) AccessCounts(_ context.Context, _ []string) (map[string]int64, error) {
+	return map[string]int64{}, nil
+}
+
+func (m *mockStore) UniqueVisitors(_ context.Context, _ string, _ time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (m *mockStore) TopCodes(_ context.Context, _ time.Time, _ int) ([]analytics.CodeCount, error) {
+	return nil, nil
+}
+
+func (m *mockStore) ReferrerBreakdown(_ context.Context, _ string, _ time.Time) ([]analytics.ReferrerCount, error) {
+	return nil, nil
+}
+
+func (m *mockStore) PruneAccessedBefore(ctx context.Context, t time.Time) (int64, error) {
+	return m.pruneFunc(ctx, t)
+}
+
+func TestPruner(t *testing.T) {
+	t.Run("prunes events older than retention on start", func(t *testing.T) {
+		cutoffs := make(chan time.Time, 1)
+		store := &mockStore{
+			pruneFunc: func(_ context.Context, t time.Time) (int64, error) {
+				select {
+				case cutoffs <- t:
+				default:
+				}
+
+				return 3, nil
+			},
+		}
+
+		pruner := analytics.NewPruner(store, 24*time.Hour, time.Hour, zap.NewNop())
+		before := time.Now()
+
+		require.NoError(t, pruner.Start(context.Background()))
+
+		select {
+		case cutoff := <-cutoffs:
+			assert.WithinDuration(t, before.Add(-24*time.Hour), cutoff, time.Second)
+		case <-time.After(time.Second):
+			t.Fatal("expected prune on start")
+		}
+
+		require.NoError(t, pruner.Shutdown())
+	})
+
+	t.Run("prunes again on every interval", func(t *testing.T) {
+		calls := make(chan struct{}, 10)
+		store := &mockStore{
+			pruneFunc: func(_ context.Context, _ time.Time) (int64, error) {
+				select {
+				case calls <- struct{}{}:
+				default:
+				}
+
+				return 0, nil
+			},
+		}
+
+		pruner := analytics.NewPruner(store, time.Hour, 5*time.Millisecond, zap.NewNop())
+		require.NoError(t, pruner.Start(context.Background()))
+
+		for range 2 {
+			select {
+			case <-calls:
+			case <-time.After(time.Second):
+				t.Fatal("expected repeated prune")
+			}
+		}
+
+		require.NoError(t, pruner.Shutdown())
+	})
+
+	t.Run("keeps running when prune fails", func(t *testing.T) {
+		calls := make(chan struct{}, 10)
+		store := &mockStore{
+			pruneFunc: func(_ context.Context, _ time.Time) (int64, error) {
+				select {
+				case calls <- struct{}{}:
+				default:
+				}
+
+				return 0, errors.New("delete failed")
+			},
+		}
+
+		pruner := analytics.NewPruner(store, time.Hour, 5*time.Millisecond, zap.NewNop())
+		require.NoError(t, pruner.Start(context.Background()))
+
+		for range 2 {
+			select {
+			case <-calls:
+			case <-time.After(time.Second):
+				t.Fatal("expected prune after failure")
+			}
+		}
+
+		require.NoError(t, pruner.Shutdown())
+	})
+}
@@ -0,0 +1,48 @@
+package analytics
+
+import (
+	"cmp"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// ReferrerCount is the number of recorded accesses from a referrer host.
+// An empty Host counts accesses without a usable referrer.
+type ReferrerCount struct {
+	Host  string
+	Count int64
+}
+
+// ReferrerHost reduces a Referer header to its lowercased host, without port
+// or a leading "www.". Values that are not absolute URLs yield "".
+func ReferrerHost(referrer string) string {
+	u, err := url.Parse(strings.TrimSpace(referrer))
+	if err != nil || u.Host == "" {
+		return ""
+	}
+
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+
+	return strings.TrimPrefix(host, "www.")
+}
+
+// GroupReferrers folds per-referrer counts into per-host counts, most accesses
+// first and ties broken by host.
+func GroupReferrers(counts map[string]int64) []ReferrerCount {
+	byHost := make(map[string]int64, len(counts))
+	for referrer, n := range counts {
+		byHost[ReferrerHost(referrer)] += n
+	}
+
+	grouped := make([]ReferrerCount, 0, len(byHost))
+	for host, n := range byHost {
+		grouped = append(grouped, ReferrerCount{Host: host, Count: n})
+	}
+
+	slices.SortFunc(grouped, func(a, b ReferrerCount) int {
+		return cmp.Or(cmp.Compare(b.Count, a.Count), cmp.Compare(a.Host, b.Host))
+	})
+
+	return grouped
+}
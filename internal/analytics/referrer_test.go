@@ -0,0 +1,46 @@
+package analytics_test
+
+import (
+	"testing"
+
+	"github.com/serroba/web-demo-go/internal/analytics"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReferrerHost(t *testing.T) {
+	tests := []struct {
+		name     string
+		referrer string
+		want     string
+	}{
+		{name: "drops path and query", referrer: "https://news.example.com/item?id=1", want: "news.example.com"},
+		{name: "lowercases the host", referrer: "https://News.Example.COM/", want: "news.example.com"},
+		{name: "drops port and www", referrer: "http://www.example.org:8080/post", want: "example.org"},
+		{name: "drops trailing dot", referrer: "https://example.org./", want: "example.org"},
+		{name: "empty", referrer: "", want: ""},
+		{name: "not absolute", referrer: "example.org/page", want: ""},
+		{name: "unparseable", referrer: "http://[::1", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, analytics.ReferrerHost(tt.referrer))
+		})
+	}
+}
+
+func TestGroupReferrers(t *testing.T) {
+	grouped := analytics.GroupReferrers(map[string]int64{
+		"https://b.example/one": 2,
+		"https://B.example/two": 1,
+		"https://a.example/":    3,
+		"":                      1,
+		"garbage":               2,
+	})
+
+	assert.Equal(t, []analytics.ReferrerCount{
+		{Host: "", Count: 3},
+		{Host: "a.example", Count: 3},
+		{Host: "b.example", Count: 3},
+	}, grouped)
+}
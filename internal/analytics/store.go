@@ -1,9 +1,32 @@
 package analytics
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
-// Store defines the interface for persisting analytics events.
+// CodeCount is the number of recorded accesses of a code.
+type CodeCount struct {
+	Code  string
+	Count int64
+}
+
+// Store defines the interface for persisting and querying analytics events.
 type Store interface {
 	SaveURLCreated(ctx context.Context, event *URLCreatedEvent) error
 	SaveURLAccessed(ctx context.Context, event *URLAccessedEvent) error
+	// AccessCounts returns the number of recorded accesses for each code.
+	// Codes without any recorded access are reported with a count of 0.
+	AccessCounts(ctx context.Context, codes []string) (map[string]int64, error)
+	// UniqueVisitors returns the number of distinct client IPs that accessed code since t.
+	// Accesses without a recorded client IP are not counted.
+	UniqueVisitors(ctx context.Context, code string, since time.Time) (int64, error)
+	// TopCodes returns up to limit codes with the most recorded accesses since t,
+	// most accessed first. A zero t counts every retained access.
+	TopCodes(ctx context.Context, since time.Time, limit int) ([]CodeCount, error)
+	// ReferrerBreakdown returns the accesses of code since t grouped by referrer
+	// host, most accesses first. A zero t counts every retained access.
+	ReferrerBreakdown(ctx context.Context, code string, since time.Time) ([]ReferrerCount, error)
+	// PruneAccessedBefore deletes access events recorded before t and returns how many were removed.
+	PruneAccessedBefore(ctx context.Context, t time.Time) (int64, error)
 }
@@ -2,6 +2,7 @@ package store
 
 import (
 	"context"
+	"time"
 
 	"github.com/serroba/web-demo-go/internal/analytics"
 	"go.uber.org/zap"
@@ -37,3 +38,35 @@ func (n *Noop) SaveURLAccessed(_ context.Context, event *analytics.URLAccessedEv
 
 	return nil
 }
+
+// AccessCounts reports a zero count for every code since no events are persisted.
+func (n *Noop) AccessCounts(_ context.Context, codes []string) (map[string]int64, error) {
+	counts := make(map[string]int64, len(codes))
+	for _, code := range codes {
+		counts[code] = 0
+	}
+
+	return counts, nil
+}
+
+// UniqueVisitors reports zero since no events are persisted.
+func (n *Noop) UniqueVisitors(_ context.Context, _ string, _ time.Time) (int64, error) {
+	return 0, nil
+}
+
+// TopCodes reports no codes since no events are persisted.
+func (n *Noop) TopCodes(_ context.Context, _ time.Time, _ int) ([]analytics.CodeCount, error) {
+	return []analytics.CodeCount{}, nil
+}
+
+// ReferrerBreakdown reports no referrers since no events are persisted.
+func (n *Noop) ReferrerBreakdown(_ context.Context, _ string, _ time.Time) ([]analytics.ReferrerCount, error) {
+	return []analytics.ReferrerCount{}, nil
+}
+
+// PruneAccessedBefore is a no-op since no events are persisted.
+func (n *Noop) PruneAccessedBefore(_ context.Context, t time.Time) (int64, error) {
+	n.logger.Info("prune accessed events requested", zap.Time("before", t))
+
+	return 0, nil
+}
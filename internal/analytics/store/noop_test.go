@@ -51,3 +51,53 @@ func TestNoop_SaveURLAccessed(t *testing.T) {
 
 	require.NoError(t, err)
 }
+
+func TestNoop_AccessCounts(t *testing.T) {
+	logger := zap.NewNop()
+	noop := store.NewNoop(logger)
+
+	counts, err := noop.AccessCounts(context.Background(), []string{"abc123", "def456"})
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{"abc123": 0, "def456": 0}, counts)
+}
+
+func TestNoop_UniqueVisitors(t *testing.T) {
+	logger := zap.NewNop()
+	noop := store.NewNoop(logger)
+
+	count, err := noop.UniqueVisitors(context.Background(), "abc123", time.Time{})
+
+	require.NoError(t, err)
+	assert.Zero(t, count)
+}
+
+func TestNoop_TopCodes(t *testing.T) {
+	logger := zap.NewNop()
+	noop := store.NewNoop(logger)
+
+	top, err := noop.TopCodes(context.Background(), time.Time{}, 10)
+
+	require.NoError(t, err)
+	assert.Empty(t, top)
+}
+
+func TestNoop_ReferrerBreakdown(t *testing.T) {
+	logger := zap.NewNop()
+	noop := store.NewNoop(logger)
+
+	referrers, err := noop.ReferrerBreakdown(context.Background(), "abc123", time.Time{})
+
+	require.NoError(t, err)
+	assert.Empty(t, referrers)
+}
+
+func TestNoop_PruneAccessedBefore(t *testing.T) {
+	logger := zap.NewNop()
+	noop := store.NewNoop(logger)
+
+	deleted, err := noop.PruneAccessedBefore(context.Background(), time.Now())
+
+	require.NoError(t, err)
+	assert.Zero(t, deleted)
+}
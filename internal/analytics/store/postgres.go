@@ -2,7 +2,11 @@ package store
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"net"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/serroba/web-demo-go/internal/analytics"
@@ -10,27 +14,47 @@ import (
 
 // Postgres persists analytics events to TimescaleDB hypertables.
 type Postgres struct {
-	pool *pgxpool.Pool
+	pool      *pgxpool.Pool
+	ipHashKey []byte
+}
+
+// PostgresOption configures optional Postgres behavior.
+type PostgresOption func(*Postgres)
+
+// WithIPHashKey stores an HMAC-SHA256 of each client IP keyed with key instead of
+// the IP itself, so visitors stay countable without retaining their addresses.
+func WithIPHashKey(key []byte) PostgresOption {
+	return func(p *Postgres) {
+		p.ipHashKey = key
+	}
 }
 
 // NewPostgres creates a new PostgreSQL analytics store.
-func NewPostgres(pool *pgxpool.Pool) *Postgres {
-	return &Postgres{pool: pool}
+func NewPostgres(pool *pgxpool.Pool, opts ...PostgresOption) *Postgres {
+	p := &Postgres{pool: pool}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
 }
 
 func (p *Postgres) SaveURLCreated(ctx context.Context, event *analytics.URLCreatedEvent) error {
 	query := `
-		INSERT INTO url_created_events (code, original_url, url_hash, strategy, created_at, client_ip, user_agent)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO url_created_events (code, original_url, url_hash, strategy, created_at, client_ip, client_ip_hash, user_agent)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 	`
 
+	clientIP, clientIPHash := p.clientIP(event.ClientIP)
+
 	_, err := p.pool.Exec(ctx, query,
 		event.Code,
 		event.OriginalURL,
 		nullableString(event.URLHash),
 		event.Strategy,
 		event.CreatedAt,
-		parseIP(event.ClientIP),
+		clientIP,
+		clientIPHash,
 		nullableString(event.UserAgent),
 	)
 
@@ -39,14 +63,17 @@ func (p *Postgres) SaveURLCreated(ctx context.Context, event *analytics.URLCreat
 
 func (p *Postgres) SaveURLAccessed(ctx context.Context, event *analytics.URLAccessedEvent) error {
 	query := `
-		INSERT INTO url_accessed_events (code, accessed_at, client_ip, user_agent, referrer)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO url_accessed_events (code, accessed_at, client_ip, client_ip_hash, user_agent, referrer)
+		VALUES ($1, $2, $3, $4, $5, $6)
 	`
 
+	clientIP, clientIPHash := p.clientIP(event.ClientIP)
+
 	_, err := p.pool.Exec(ctx, query,
 		event.Code,
 		event.AccessedAt,
-		parseIP(event.ClientIP),
+		clientIP,
+		clientIPHash,
 		nullableString(event.UserAgent),
 		nullableString(event.Referrer),
 	)
@@ -54,6 +81,180 @@ func (p *Postgres) SaveURLAccessed(ctx context.Context, event *analytics.URLAcce
 	return err
 }
 
+func (p *Postgres) AccessCounts(ctx context.Context, codes []string) (map[string]int64, error) {
+	counts := make(map[string]int64, len(codes))
+	for _, code := range codes {
+		counts[code] = 0
+	}
+
+	if len(codes) == 0 {
+		return counts, nil
+	}
+
+	query := `
+		SELECT code, COUNT(*)
+		FROM url_accessed_events
+		WHERE code = ANY($1)
+		GROUP BY code
+	`
+
+	rows, err := p.pool.Query(ctx, query, codes)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			code  string
+			count int64
+		)
+
+		if err = rows.Scan(&code, &count); err != nil {
+			return nil, err
+		}
+
+		counts[code] = count
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
+
+// UniqueVisitors counts distinct client IPs, or their hashes when IP hashing is
+// enabled, among the accesses of code since the given time.
+func (p *Postgres) UniqueVisitors(ctx context.Context, code string, since time.Time) (int64, error) {
+	query := `
+		SELECT COUNT(DISTINCT COALESCE(client_ip_hash, host(client_ip)))
+		FROM url_accessed_events
+		WHERE code = $1 AND accessed_at >= $2
+	`
+
+	var count int64
+	if err := p.pool.QueryRow(ctx, query, code, since).Scan(&count); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// TopCodes ranks codes by their access events since the given time. Ties are
+// broken by code so results are stable.
+func (p *Postgres) TopCodes(ctx context.Context, since time.Time, limit int) ([]analytics.CodeCount, error) {
+	query := `
+		SELECT code, COUNT(*) AS accesses
+		FROM url_accessed_events
+		WHERE accessed_at >= $1
+		GROUP BY code
+		ORDER BY accesses DESC, code
+		LIMIT $2
+	`
+
+	rows, err := p.pool.Query(ctx, query, since, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	top := make([]analytics.CodeCount, 0, limit)
+
+	for rows.Next() {
+		var count analytics.CodeCount
+
+		if err = rows.Scan(&count.Code, &count.Count); err != nil {
+			return nil, err
+		}
+
+		top = append(top, count)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return top, nil
+}
+
+// ReferrerBreakdown counts the accesses of code since the given time per stored
+// referrer and folds them into referrer hosts.
+func (p *Postgres) ReferrerBreakdown(
+	ctx context.Context, code string, since time.Time,
+) ([]analytics.ReferrerCount, error) {
+	query := `
+		SELECT COALESCE(referrer, ''), COUNT(*)
+		FROM url_accessed_events
+		WHERE code = $1 AND accessed_at >= $2
+		GROUP BY referrer
+	`
+
+	rows, err := p.pool.Query(ctx, query, code, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := map[string]int64{}
+
+	for rows.Next() {
+		var (
+			referrer string
+			count    int64
+		)
+
+		if err = rows.Scan(&referrer, &count); err != nil {
+			return nil, err
+		}
+
+		counts[referrer] += count
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return analytics.GroupReferrers(counts), nil
+}
+
+func (p *Postgres) PruneAccessedBefore(ctx context.Context, t time.Time) (int64, error) {
+	query := `DELETE FROM url_accessed_events WHERE accessed_at < $1`
+
+	tag, err := p.pool.Exec(ctx, query, t)
+	if err != nil {
+		return 0, err
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// IncrementAccessCounts adds the deltas to short_urls.access_count in a single statement.
+func (p *Postgres) IncrementAccessCounts(ctx context.Context, deltas map[string]int64) error {
+	if len(deltas) == 0 {
+		return nil
+	}
+
+	codes := make([]string, 0, len(deltas))
+	counts := make([]int64, 0, len(deltas))
+
+	for code, delta := range deltas {
+		codes = append(codes, code)
+		counts = append(counts, delta)
+	}
+
+	query := `
+		UPDATE short_urls AS s
+		SET access_count = s.access_count + d.delta
+		FROM unnest($1::text[], $2::bigint[]) AS d(code, delta)
+		WHERE s.code = d.code
+	`
+
+	_, err := p.pool.Exec(ctx, query, codes, counts)
+
+	return err
+}
+
 func nullableString(s string) *string {
 	if s == "" {
 		return nil
@@ -62,6 +263,20 @@ func nullableString(s string) *string {
 	return &s
 }
 
+// clientIP returns the values stored in the client_ip and client_ip_hash columns.
+// With a hash key only the keyed hash of the IP is kept.
+func (p *Postgres) clientIP(ip string) (net.IP, *string) {
+	if len(p.ipHashKey) == 0 || ip == "" {
+		return parseIP(ip), nil
+	}
+
+	mac := hmac.New(sha256.New, p.ipHashKey)
+	mac.Write([]byte(ip))
+	hash := hex.EncodeToString(mac.Sum(nil))
+
+	return nil, &hash
+}
+
 func parseIP(s string) net.IP {
 	if s == "" {
 		return nil
@@ -70,5 +285,8 @@ func parseIP(s string) net.IP {
 	return net.ParseIP(s)
 }
 
-// Compile-time check.
-var _ analytics.Store = (*Postgres)(nil)
+// Compile-time checks.
+var (
+	_ analytics.Store    = (*Postgres)(nil)
+	_ analytics.HitStore = (*Postgres)(nil)
+)
@@ -0,0 +1,72 @@
+package audit_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/serroba/web-demo-go/internal/audit"
+	"github.com/serroba/web-demo-go/internal/clock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestLogger_Record(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	t.Run("emits the entry on the audit logger", func(t *testing.T) {
+		core, logs := observer.New(zapcore.InfoLevel)
+		recorder := audit.NewLogger(zap.New(core), audit.WithClock(clock.NewFake(now)))
+
+		ctx := audit.ContextWithActor(context.Background(), "admin:1234abcd")
+		recorder.Record(ctx, audit.Entry{Action: "update_target", Target: "abc123", ClientIP: "10.0.0.1"})
+
+		require.Equal(t, 1, logs.Len())
+
+		entry := logs.All()[0]
+		assert.Equal(t, "audit", entry.LoggerName)
+		assert.Equal(t, map[string]any{
+			"actor":     "admin:1234abcd",
+			"action":    "update_target",
+			"target":    "abc123",
+			"timestamp": now,
+			"client_ip": "10.0.0.1",
+		}, entry.ContextMap())
+	})
+
+	t.Run("keeps an explicit actor and timestamp", func(t *testing.T) {
+		core, logs := observer.New(zapcore.InfoLevel)
+		recorder := audit.NewLogger(zap.New(core))
+		at := now.Add(-time.Hour)
+
+		recorder.Record(context.Background(), audit.Entry{
+			Actor:     "ops",
+			Action:    "import_urls",
+			Target:    "urls.csv",
+			Detail:    "imported=2 skipped=0",
+			Timestamp: at,
+		})
+
+		fields := logs.All()[0].ContextMap()
+		assert.Equal(t, "ops", fields["actor"])
+		assert.Equal(t, at, fields["timestamp"])
+		assert.Equal(t, "imported=2 skipped=0", fields["detail"])
+	})
+}
+
+func TestActorFromContext(t *testing.T) {
+	assert.Equal(t, audit.UnknownActor, audit.ActorFromContext(context.Background()))
+	assert.Equal(t, "ops", audit.ActorFromContext(audit.ContextWithActor(context.Background(), "ops")))
+}
+
+func TestTokenActor(t *testing.T) {
+	actor := audit.TokenActor("secret")
+
+	assert.Regexp(t, `^admin:[0-9a-f]{8}$`, actor)
+	assert.Equal(t, actor, audit.TokenActor("secret"))
+	assert.NotEqual(t, actor, audit.TokenActor("rotated"))
+	assert.NotContains(t, actor, "secret")
+}
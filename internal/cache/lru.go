@@ -2,16 +2,19 @@ package cache
 
 import (
 	"sync"
+	"time"
 
+	"github.com/serroba/web-demo-go/internal/clock"
 	"github.com/serroba/web-demo-go/internal/shortener"
 )
 
 // node represents a doubly linked list node.
 type node struct {
-	key   string
-	value *shortener.ShortURL
-	prev  *node
-	next  *node
+	key       string
+	value     *shortener.ShortURL
+	expiresAt time.Time // zero never expires
+	prev      *node
+	next      *node
 }
 
 // LRU implements a Least Recently Used cache.
@@ -21,48 +24,86 @@ type LRU struct {
 	items    map[string]*node
 	head     *node // sentinel - head.next is most recently used
 	tail     *node // sentinel - tail.prev is least recently used
+	clock    clock.Clock
 	mu       sync.RWMutex
 }
 
+// Option configures optional LRU behavior.
+type Option func(*LRU)
+
+// WithClock sets the clock used to expire entries added with SetWithTTL.
+func WithClock(c clock.Clock) Option {
+	return func(l *LRU) {
+		l.clock = c
+	}
+}
+
 // New creates a new LRU cache with the given capacity.
-func New(capacity int) *LRU {
+func New(capacity int, opts ...Option) *LRU {
 	head := &node{}
 	tail := &node{}
 	head.next = tail
 	tail.prev = head
 
-	return &LRU{
+	c := &LRU{
 		capacity: capacity,
 		items:    make(map[string]*node),
 		head:     head,
 		tail:     tail,
+		clock:    clock.Real{},
+	}
+
+	for _, opt := range opts {
+		opt(c)
 	}
+
+	return c
 }
 
 // Get retrieves a value from the cache.
 // Returns the value and true if found, nil and false otherwise.
 // Accessing an item moves it to the front (most recently used).
+// A nil value with true is a cached miss stored with SetWithTTL.
 func (c *LRU) Get(key string) (*shortener.ShortURL, bool) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	if n, ok := c.items[key]; ok {
-		c.moveToFront(n)
+	n, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	if !n.expiresAt.IsZero() && !c.clock.Now().Before(n.expiresAt) {
+		c.detach(n)
+		delete(c.items, key)
 
-		return n.value, true
+		return nil, false
 	}
 
-	return nil, false
+	c.moveToFront(n)
+
+	return n.value, true
 }
 
 // Set adds or updates a value in the cache.
 // If the cache is at capacity, the least recently used item is evicted.
 func (c *LRU) Set(key string, value *shortener.ShortURL) {
+	c.set(key, value, time.Time{})
+}
+
+// SetWithTTL is like Set, but the entry expires after ttl. A nil value caches
+// a miss, so repeated lookups of a missing key can skip the backing store.
+func (c *LRU) SetWithTTL(key string, value *shortener.ShortURL, ttl time.Duration) {
+	c.set(key, value, c.clock.Now().Add(ttl))
+}
+
+func (c *LRU) set(key string, value *shortener.ShortURL, expiresAt time.Time) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
 	if n, ok := c.items[key]; ok {
 		n.value = value
+		n.expiresAt = expiresAt
 		c.moveToFront(n)
 
 		return
@@ -74,11 +115,22 @@ func (c *LRU) Set(key string, value *shortener.ShortURL) {
 	}
 
 	// Add new node at front
-	n := &node{key: key, value: value}
+	n := &node{key: key, value: value, expiresAt: expiresAt}
 	c.items[key] = n
 	c.addToFront(n)
 }
 
+// Delete removes key from the cache if present.
+func (c *LRU) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if n, ok := c.items[key]; ok {
+		c.detach(n)
+		delete(c.items, key)
+	}
+}
+
 // Len returns the current number of items in the cache.
 func (c *LRU) Len() int {
 	c.mu.RLock()
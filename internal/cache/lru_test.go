@@ -3,8 +3,10 @@ package cache_test
 import (
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/serroba/web-demo-go/internal/cache"
+	"github.com/serroba/web-demo-go/internal/clock"
 	"github.com/serroba/web-demo-go/internal/shortener"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -64,6 +66,75 @@ func TestLRU_BasicOperations(t *testing.T) {
 	})
 }
 
+func TestLRU_Delete(t *testing.T) {
+	t.Run("removes an existing key", func(t *testing.T) {
+		c := cache.New(10)
+		c.Set("a", newShortURL("a", "https://a.com"))
+		c.Set("b", newShortURL("b", "https://b.com"))
+
+		c.Delete("a")
+
+		_, ok := c.Get("a")
+		assert.False(t, ok)
+		assert.Equal(t, 1, c.Len())
+
+		val, ok := c.Get("b")
+		require.True(t, ok)
+		assert.Equal(t, "https://b.com", val.OriginalURL)
+	})
+
+	t.Run("ignores a missing key", func(t *testing.T) {
+		c := cache.New(10)
+		c.Set("a", newShortURL("a", "https://a.com"))
+
+		c.Delete("missing")
+
+		assert.Equal(t, 1, c.Len())
+	})
+}
+
+func TestLRU_SetWithTTL(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	t.Run("entry expires after its ttl", func(t *testing.T) {
+		clk := clock.NewFake(now)
+		c := cache.New(10, cache.WithClock(clk))
+		c.SetWithTTL("a", newShortURL("a", "https://a.com"), time.Minute)
+
+		_, ok := c.Get("a")
+		assert.True(t, ok)
+
+		clk.Advance(time.Minute)
+
+		_, ok = c.Get("a")
+		assert.False(t, ok)
+		assert.Equal(t, 0, c.Len())
+	})
+
+	t.Run("nil value caches a miss", func(t *testing.T) {
+		c := cache.New(10)
+		c.SetWithTTL("missing", nil, time.Minute)
+
+		val, ok := c.Get("missing")
+
+		assert.True(t, ok)
+		assert.Nil(t, val)
+	})
+
+	t.Run("set clears the expiry", func(t *testing.T) {
+		clk := clock.NewFake(now)
+		c := cache.New(10, cache.WithClock(clk))
+		c.SetWithTTL("a", nil, time.Minute)
+		c.Set("a", newShortURL("a", "https://a.com"))
+
+		clk.Advance(time.Hour)
+
+		val, ok := c.Get("a")
+		require.True(t, ok)
+		assert.Equal(t, "https://a.com", val.OriginalURL)
+	})
+}
+
 func TestLRU_Eviction(t *testing.T) {
 	t.Run("evicts when capacity exceeded", func(t *testing.T) {
 		c := cache.New(2)
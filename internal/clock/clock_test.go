@@ -0,0 +1,29 @@
+package clock_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/serroba/web-demo-go/internal/clock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReal(t *testing.T) {
+	before := time.Now()
+	now := clock.Real{}.Now()
+
+	assert.False(t, now.Before(before))
+}
+
+func TestFake(t *testing.T) {
+	start := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	c := clock.NewFake(start)
+
+	assert.Equal(t, start, c.Now())
+
+	c.Advance(time.Minute)
+	assert.Equal(t, start.Add(time.Minute), c.Now())
+
+	c.Set(start)
+	assert.Equal(t, start, c.Now())
+}
@@ -3,6 +3,7 @@ package container
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/ThreeDotsLabs/watermill"
@@ -12,20 +13,24 @@ import (
 	_ "github.com/danielgtaylor/huma/v2/formats/cbor" // CBOR format support for huma
 	"github.com/go-chi/chi/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
-	"github.com/jaevor/go-nanoid"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/redis/go-redis/v9"
 	"github.com/samber/do"
 	"github.com/serroba/web-demo-go/internal/analytics"
 	analyticsstore "github.com/serroba/web-demo-go/internal/analytics/store"
+	"github.com/serroba/web-demo-go/internal/audit"
 	"github.com/serroba/web-demo-go/internal/cache"
 	"github.com/serroba/web-demo-go/internal/handlers"
 	"github.com/serroba/web-demo-go/internal/health"
+	"github.com/serroba/web-demo-go/internal/logging"
 	"github.com/serroba/web-demo-go/internal/messaging"
+	"github.com/serroba/web-demo-go/internal/metrics"
 	"github.com/serroba/web-demo-go/internal/middleware"
 	"github.com/serroba/web-demo-go/internal/ratelimit"
 	ratelimitstore "github.com/serroba/web-demo-go/internal/ratelimit/store"
 	"github.com/serroba/web-demo-go/internal/shortener"
 	"github.com/serroba/web-demo-go/internal/store"
+	"github.com/serroba/web-demo-go/internal/version"
 	"go.uber.org/zap"
 )
 
@@ -34,7 +39,7 @@ type Options struct {
 	CodeLength       int           `default:"8"              help:"Short code length" short:"c"`
 	RedisAddr        string        `default:"localhost:6379" help:"Redis address"     short:"r"`
 	DatabaseURL      string        `env:"DATABASE_URL"       help:"PostgreSQL URL"    required:""`
-	RateLimitStore   string        `default:"memory"         env:"RATE_LIMIT_STORE"   help:"memory or redis"`
+	RateLimitStore   string        `default:"memory"         env:"RATE_LIMIT_STORE"   help:"memory, redis or postgres"`
 	CacheSize        int           `default:"1000"           env:"CACHE_SIZE"         help:"LRU cache size (0=off)"`
 	CacheTTL         time.Duration `default:"1h"             env:"CACHE_TTL"          help:"Redis cache TTL"`
 	LogFormat        string        `default:"console"        env:"LOG_FORMAT"         help:"console or json"`
@@ -42,12 +47,243 @@ type Options struct {
 	TopicURLAccessed string        `default:"url.accessed"   env:"TOPIC_URL_ACCESSED" help:"URL accessed topic"`
 	ConsumerGroup    string        `default:"analytics"      env:"CONSUMER_GROUP"     help:"Consumer group name"`
 
+	// Cache unknown codes briefly so scanners probing random codes do not reach the database
+	NegativeCacheTTL time.Duration `default:"0" env:"NEGATIVE_CACHE_TTL" help:"How long to cache unknown codes (0=off)"`
+
+	// Per-strategy Redis cache TTLs; hash codes never change target, so they can be cached longer
+	TokenCacheTTL time.Duration `default:"0" env:"TOKEN_CACHE_TTL" help:"Redis cache TTL for token-strategy URLs (0=CACHE_TTL)"`
+	HashCacheTTL  time.Duration `default:"0" env:"HASH_CACHE_TTL" help:"Redis cache TTL for hash-strategy URLs (0=CACHE_TTL)"`
+
+	// Populate the Redis cache before Save returns (through) or from a bounded background queue (behind)
+	CacheWriteMode      string `default:"through" env:"CACHE_WRITE_MODE"       help:"When new short URLs are cached (through or behind)"`
+	CacheWriteQueueSize int    `default:"1000"    env:"CACHE_WRITE_QUEUE_SIZE" help:"Short URLs waiting to be cached in write-behind mode"`
+
+	// Split batch saves (CSV imports) into chunks and bound how many run at once, so imports cannot drain the DB pool
+	BatchChunkSize   int `default:"500" env:"BATCH_CHUNK_SIZE"  help:"Short URLs per database batch during imports (0=one batch)"`
+	BatchConcurrency int `default:"2"   env:"BATCH_CONCURRENCY" help:"Import chunks saved concurrently"`
+
+	// Skip Redis cache population for a while after a cache error
+	CacheErrorCooldown time.Duration `default:"5s" env:"CACHE_ERROR_COOLDOWN" help:"Skip cache population after a cache error (0=off)"`
+
+	// Broker health configuration
+	BrokerMaxLag int64 `default:"0" env:"BROKER_MAX_LAG" help:"Consumer lag that marks the broker unhealthy (0=off)"`
+
+	// Consecutive failed Redis pings before /health reports Redis unhealthy; a successful ping resets the count
+	RedisHealthFailureThreshold int `default:"3" env:"REDIS_HEALTH_FAILURE_THRESHOLD" help:"Consecutive failed Redis pings before Redis is reported unhealthy"`
+
+	// /readyz answers 503 until Redis and PostgreSQL answer and this warm-up period has passed
+	ReadinessDelay time.Duration `default:"0s" env:"READINESS_DELAY" help:"Extra time /readyz reports not ready after dependencies answer"`
+
+	// Per-strategy created topics (empty falls back to TopicURLCreated)
+	TopicURLCreatedToken string `env:"TOPIC_URL_CREATED_TOKEN" help:"URL created topic for the token strategy"`
+	TopicURLCreatedHash  string `env:"TOPIC_URL_CREATED_HASH"  help:"URL created topic for the hash strategy"`
+
+	// Mirror every published event to the streams of a second Redis (empty=off)
+	PublishMirrorRedisAddr string `env:"PUBLISH_MIRROR_REDIS_ADDR" help:"Redis address that also receives every published event"`
+
+	// Publish retries: total attempts per event (1=no retry) and the initial, doubling backoff
+	PublishRetryAttempts int           `default:"1"    env:"PUBLISH_RETRY_ATTEMPTS" help:"Attempts per event publish before giving up (1=no retry)"`
+	PublishRetryBackoff  time.Duration `default:"50ms" env:"PUBLISH_RETRY_BACKOFF"  help:"Delay before the first publish retry, doubled after each retry"`
+
+	// Wrap published events in the CloudEvents JSON envelope; consumers accept both formats
+	CloudEvents       bool   `default:"false"      env:"CLOUD_EVENTS"        help:"Publish events in the CloudEvents JSON format"`
+	CloudEventsSource string `default:"/shortener" env:"CLOUD_EVENTS_SOURCE" help:"CloudEvents source attribute of published events"`
+
+	// Publish access events from a bounded background buffer instead of inside the redirect (0=publish inline)
+	AnalyticsBufferSize int `default:"0" env:"ANALYTICS_BUFFER_SIZE" help:"Maximum access events buffered for background publishing (0=publish inline)"`
+
+	// What create does when the created event cannot be published: ignore (fail-open) or fail (500)
+	PublishFailurePolicy string `default:"ignore" env:"PUBLISH_FAILURE_POLICY" help:"Publish failure policy for creates (ignore or fail)"`
+
+	// Per-message processing limit before the consumer nacks (0=no limit)
+	ConsumerAckTimeout time.Duration `default:"30s" env:"CONSUMER_ACK_TIMEOUT" help:"Nack messages whose handler takes longer than this (0=no limit)"`
+
+	// How often the consumer exports pending message counts from XPENDING (0=disabled)
+	PendingMetricsInterval time.Duration `default:"15s" env:"PENDING_METRICS_INTERVAL" help:"How often to export consumer group pending message counts (0=disabled)"`
+
+	// Analytics retention configuration
+	AnalyticsRetention     time.Duration `default:"0"  env:"ANALYTICS_RETENTION"      help:"Delete access events older than this (0=keep forever)"`
+	AnalyticsPruneInterval time.Duration `default:"1h" env:"ANALYTICS_PRUNE_INTERVAL" help:"How often to prune access events"`
+
+	// GDPR: zero the last IPv4 octet / last 80 IPv6 bits before client IPs enter analytics events
+	AnonymizeIP bool `default:"false" env:"ANONYMIZE_IP" help:"Truncate client IPs before recording analytics events"`
+
+	// Store a keyed hash of client IPs instead of the IPs themselves (empty stores raw IPs)
+	AnalyticsIPHashKey string `env:"ANALYTICS_IP_HASH_KEY" help:"Secret for hashing client IPs in analytics events"`
+
+	// How often accumulated access counts are written to short_urls (0=disabled)
+	AccessCountFlushInterval time.Duration `default:"10s" env:"ACCESS_COUNT_FLUSH_INTERVAL" help:"How often to flush per-URL access counts (0=disabled)"`
+
+	// Per-operation repository latency histogram (shortener_store_operation_duration_seconds)
+	StoreMetrics bool `default:"false" env:"STORE_METRICS" help:"Record PostgreSQL repository operation latency in Prometheus"`
+
+	// Export the share of code lookups that miss per window and warn above the threshold (0=disabled)
+	NotFoundRateWindow    time.Duration `default:"0"   env:"NOT_FOUND_RATE_WINDOW"    help:"Window for the code lookup not-found ratio (0=disabled)"`
+	NotFoundRateThreshold float64       `default:"0.5" env:"NOT_FOUND_RATE_THRESHOLD" help:"Not-found ratio above which a warning is logged"`
+
+	// Direct TLS serving: setting both files serves HTTPS on Port (empty serves plain HTTP)
+	TLSCertFile string `env:"TLS_CERT_FILE" help:"PEM certificate file for serving TLS directly"`
+	TLSKeyFile  string `env:"TLS_KEY_FILE"  help:"PEM private key file for serving TLS directly"`
+
+	// Handshakes below this version are refused; 1.0 and 1.1 are rejected as insecure
+	MinTLSVersion string `default:"1.2" env:"MIN_TLS_VERSION" help:"Minimum TLS version when serving TLS (1.2 or 1.3)"`
+
+	// Response compression: gzip/deflate bodies of at least this many bytes (0=off)
+	CompressionMinSize int `default:"1024" env:"COMPRESSION_MIN_SIZE" help:"Minimum response size in bytes to gzip or deflate (0=off)"`
+
+	// Load shedding: requests beyond this many in flight get 503 (0=unlimited)
+	MaxConcurrentRequests int `default:"0" env:"MAX_CONCURRENT_REQUESTS" help:"Maximum in-flight API requests before answering 503 (0=unlimited)"`
+
+	// Global redirect token bucket shielding the store from herds on one link (0=off)
+	RedirectRateLimit float64 `default:"0"   env:"REDIRECT_RATE_LIMIT" help:"Redirects per second across all clients before answering 503 (0=off)"`
+	RedirectBurst     int     `default:"100" env:"REDIRECT_BURST"      help:"Redirects allowed in a burst above the global redirect rate"`
+
+	// Per-code redirect cap against a leaked code being hammered by many clients (0=off)
+	CodeRedirectLimit  int64         `default:"0"  env:"CODE_REDIRECT_LIMIT"  help:"Redirects per code per window before answering 429 (0=off)"`
+	CodeRedirectWindow time.Duration `default:"1m" env:"CODE_REDIRECT_WINDOW" help:"Window for the per-code redirect limit"`
+
+	// Comma-separated Host allowlist; requests for other hosts get 400 (empty=allow all)
+	AllowedHosts string `env:"ALLOWED_HOSTS" help:"Comma-separated list of allowed Host headers"`
+
+	// Requests without a User-Agent: allow, reject with 400, or rate limit by peer address ignoring forwarding headers
+	MissingUserAgent string `default:"allow" env:"MISSING_USER_AGENT" help:"Handling of requests without a User-Agent (allow, reject or peer)"`
+
+	// Trusted proxies in front of the server: the client IP is this many X-Forwarded-For hops from the right (0=leftmost)
+	// Above 0, X-Forwarded-Proto and X-Forwarded-Host are honored as well
+	XFFTrustDepth int `default:"0" env:"XFF_TRUST_DEPTH" help:"Trusted proxies appending to X-Forwarded-For (0=use the leftmost entry)"`
+
+	// Requests for /{code}/: serve like /{code}, redirect to it, or leave unmatched (off)
+	TrailingSlash string `default:"strip" env:"TRAILING_SLASH" help:"Handling of /{code}/ requests (strip, redirect or off)"`
+
+	// Hide /docs, /openapi.json and /schemas in locked-down deployments
+	DisableDocs bool `default:"false" env:"DISABLE_DOCS" help:"Do not serve the API docs and OpenAPI spec"`
+
+	// Translate error messages using Accept-Language (English when unsupported)
+	LocalizeErrors bool `default:"false" env:"LOCALIZE_ERRORS" help:"Localize error messages from the Accept-Language header"`
+
+	// Wrap response bodies in {"data": ..., "meta": ...}; errors keep the problem details format
+	EnvelopeResponses bool `default:"false" env:"ENVELOPE_RESPONSES" help:"Wrap response bodies in a data/meta envelope"`
+
+	// Encode stats counts beyond 2^53-1 as JSON strings so JavaScript clients keep their precision
+	LargeCountsAsStrings bool `default:"false" env:"STATS_LARGE_COUNTS_AS_STRINGS" help:"Encode stats counts beyond 2^53-1 as JSON strings"`
+
+	// Upper bound for client deadlines sent in X-Request-Timeout-Ms (0 ignores the header)
+	RequestTimeoutMax time.Duration `default:"30s" env:"REQUEST_TIMEOUT_MAX" help:"Maximum deadline a client may request with X-Request-Timeout-Ms"`
+
+	// HMAC signatures required on requests with a body, for server-to-server deployments (empty disables)
+	RequestSigningSecret string        `env:"REQUEST_SIGNING_SECRET" help:"Shared secret for X-Signature request signatures"`
+	RequestSigningWindow time.Duration `default:"5m"                 env:"REQUEST_SIGNING_WINDOW" help:"Maximum age of a signed request's timestamp"`
+
+	// Bearer token required by /admin endpoints (empty disables them)
+	AdminToken string `env:"ADMIN_TOKEN" help:"Bearer token for admin endpoints"`
+
+	// Client API keys accepted in X-API-Key; short URLs record the key that created them
+	APIKeys string `env:"API_KEYS" help:"Client API keys as name:secret[:tier], comma-separated"`
+
+	// Content negotiation: default response format and whether unsupported Accept headers get 406
+	DefaultContentType string `default:"application/json" env:"DEFAULT_CONTENT_TYPE" help:"application/json or application/cbor"`
+	StrictAccept       bool   `default:"false"            env:"STRICT_ACCEPT"        help:"Return 406 for Accept headers matching no supported media type"`
+
+	// Short code configuration
+	TokenCodeLength       int    `default:"0"     env:"TOKEN_CODE_LENGTH"        help:"Code length for the token strategy (0=use code length)"`
+	HashCodeLength        int    `default:"0"     env:"HASH_CODE_LENGTH"         help:"Code length for the hash strategy (0=use code length)"`
+	HashMinURLLength      int    `default:"0"     env:"HASH_MIN_URL_LENGTH"      help:"Minimum URL length for the hash strategy (0=off)"`
+	HashRequireDottedHost bool   `default:"false" env:"HASH_REQUIRE_DOTTED_HOST" help:"Require a host with a dot for the hash strategy"`
+	CaseInsensitiveCodes  bool   `default:"false" env:"CASE_INSENSITIVE_CODES"   help:"Generate lowercase codes and match codes case-insensitively"`
+	MaxAliasLength        int    `default:"16"    env:"MAX_ALIAS_LENGTH"         help:"Maximum length of a vanity alias"`
+	ReservedAliasPrefixes string `default:"_,-"   env:"RESERVED_ALIAS_PREFIXES"  help:"Comma-separated prefixes aliases may not start with"`
+	AliasCharset          string `default:""      env:"ALIAS_CHARSET"            help:"Characters vanity aliases may use (empty=letters, digits, '-' and '_')"`
+	RedirectStatus        int    `default:"301"   env:"REDIRECT_STATUS"          help:"Redirect status code (301, 302, 307 or 308)"`
+	IncludeQRUrl          bool   `default:"false" env:"INCLUDE_QR_URL"           help:"Include the QR image URL in create responses"`
+	RelativeShortURL      bool   `default:"false" env:"RELATIVE_SHORT_URL"       help:"Return short URLs as root-relative paths (/abc123)"`
+	DefaultStrategy       string `default:"token" env:"DEFAULT_STRATEGY"         help:"Strategy used when a request does not specify one (token or hash)"`
+
+	// Bound URL paths before they are normalized and hashed
+	MaxPathSegments int `default:"0" env:"MAX_PATH_SEGMENTS" help:"Reject URLs whose path has more segments (0=off)"`
+	MaxPathLength   int `default:"0" env:"MAX_PATH_LENGTH"   help:"Reject URLs whose path is longer (0=off)"`
+
+	// Store the normalized target next to the original so stats can group equivalent URLs
+	StoreNormalizedURL bool `default:"false" env:"STORE_NORMALIZED_URL" help:"Store the normalized URL for the token and hash strategies"`
+
+	// Answer repeated token creates of one URL by one client with the earlier code, tracked in Redis (0=off)
+	DuplicateSubmissionWindow time.Duration `default:"0" env:"DUPLICATE_SUBMISSION_WINDOW" help:"Return the earlier code when a client resubmits a URL within this window (0=off)"`
+
+	// Store the final target when shortening one of our own short URLs
+	CollapseSelfRedirects bool `default:"false" env:"COLLAPSE_SELF_REDIRECTS" help:"Shorten our own short URLs to the URL they point to"`
+
+	// Short code generator: random (nanoid), sequential (base62 counter) or uuid
+	GeneratorType string `default:"random" env:"CODE_GENERATOR" help:"Code generator for the token and hash strategies (random, sequential or uuid)"`
+
+	// Redirect caching headers
+	RedirectCacheMaxAge time.Duration `default:"0"     env:"REDIRECT_CACHE_MAX_AGE" help:"Cache-Control max-age for redirects (0=omit header)"`
+	RedirectLinkHeader  bool          `default:"false" env:"REDIRECT_LINK_HEADER"   help:"Add a canonical Link header to redirects"`
+
+	// Fallback for unknown codes (empty returns 404)
+	NotFoundRedirectURL string `env:"NOT_FOUND_REDIRECT_URL" help:"Redirect unknown codes here with a 302 instead of returning 404"`
+
 	// Rate limit configuration per scope
-	RateLimitGlobalPerDay   int64 `default:"1000000" env:"RATE_LIMIT_GLOBAL_DAY"   help:"Global requests per day"`
-	RateLimitReadPerMinute  int64 `default:"100000"  env:"RATE_LIMIT_READ_MINUTE"  help:"Read requests per minute"`
-	RateLimitWritePerMinute int64 `default:"10"      env:"RATE_LIMIT_WRITE_MINUTE" help:"Write requests per minute"`
-	RateLimitWritePerHour   int64 `default:"100"     env:"RATE_LIMIT_WRITE_HOUR"   help:"Write requests per hour"`
-	RateLimitWritePerDay    int64 `default:"500"     env:"RATE_LIMIT_WRITE_DAY"    help:"Write requests per day"`
+	RateLimitGlobalPerDay    int64 `default:"1000000" env:"RATE_LIMIT_GLOBAL_DAY"        help:"Global requests per day"`
+	RateLimitReadPerMinute   int64 `default:"100000"  env:"RATE_LIMIT_READ_MINUTE"       help:"Read requests per minute"`
+	RateLimitWritePerMinute  int64 `default:"10"      env:"RATE_LIMIT_WRITE_MINUTE"      help:"Write requests per minute"`
+	RateLimitWritePerHour    int64 `default:"100"     env:"RATE_LIMIT_WRITE_HOUR"        help:"Write requests per hour"`
+	RateLimitWritePerDay     int64 `default:"500"     env:"RATE_LIMIT_WRITE_DAY"         help:"Write requests per day"`
+	RateLimitMaxCustomLimits int   `default:"5"       env:"RATE_LIMIT_MAX_CUSTOM_LIMITS" help:"Maximum custom rate limits per endpoint (0=unlimited)"`
+	RateLimitMonitorOnly     bool  `default:"false"   env:"RATE_LIMIT_MONITOR_ONLY"      help:"Log would-be-denied requests without blocking"`
+
+	// Alert (error log) when one client is denied more than this many times per window (0=off)
+	RateLimitBreachThreshold int           `default:"0"  env:"RATE_LIMIT_BREACH_THRESHOLD" help:"Denials per client per window before alerting (0=off)"`
+	RateLimitBreachWindow    time.Duration `default:"5m" env:"RATE_LIMIT_BREACH_WINDOW"    help:"Window for the rate limit breach threshold"`
+
+	// Rate limits for API keys of a tier, replacing the base limits of the scopes they name
+	RateLimitTierLimits string `env:"RATE_LIMIT_TIER_LIMITS" help:"Tier limits as tier:scope=max/window, comma-separated"`
+}
+
+// strategyCreatedTopics returns the configured created-event topic overrides by strategy.
+func (o *Options) strategyCreatedTopics() map[handlers.Strategy]string {
+	topics := map[handlers.Strategy]string{}
+
+	if o.TopicURLCreatedToken != "" {
+		topics[handlers.StrategyToken] = o.TopicURLCreatedToken
+	}
+
+	if o.TopicURLCreatedHash != "" {
+		topics[handlers.StrategyHash] = o.TopicURLCreatedHash
+	}
+
+	return topics
+}
+
+// streamTopics returns every topic the publisher group writes to: the created
+// and accessed topics plus the distinct per-strategy created topics.
+func (o *Options) streamTopics() []string {
+	return append([]string{o.TopicURLCreated, o.TopicURLAccessed}, o.extraCreatedTopics()...)
+}
+
+// publishOptions returns the options shared by every typed publish function.
+func (o *Options) publishOptions() []messaging.PublishOption {
+	opts := []messaging.PublishOption{messaging.WithRetry(o.PublishRetryAttempts, o.PublishRetryBackoff)}
+
+	if o.CloudEvents {
+		opts = append(opts, messaging.WithCloudEvents(o.CloudEventsSource))
+	}
+
+	return opts
+}
+
+// extraCreatedTopics returns the distinct per-strategy topics that differ from TopicURLCreated.
+func (o *Options) extraCreatedTopics() []string {
+	seen := map[string]bool{o.TopicURLCreated: true}
+
+	var topics []string
+
+	for _, topic := range []string{o.TopicURLCreatedToken, o.TopicURLCreatedHash} {
+		if topic != "" && !seen[topic] {
+			seen[topic] = true
+			topics = append(topics, topic)
+		}
+	}
+
+	return topics
 }
 
 // LoggerPackage provides the zap logger.
@@ -63,29 +299,39 @@ func LoggerPackage(i *do.Injector) {
 	})
 }
 
+// MetricsPackage provides the Prometheus registry shared by all instrumented components.
+func MetricsPackage(i *do.Injector) {
+	do.Provide(i, func(_ *do.Injector) (*prometheus.Registry, error) {
+		return metrics.NewRegistry(), nil
+	})
+}
+
 // RedisClient wraps redis.Client to implement Shutdownable for do.Injector.
 type RedisClient struct {
 	*redis.Client
+	logger *zap.Logger
 }
 
 // Shutdown implements do.Shutdownable.
 func (r *RedisClient) Shutdown() error {
-	if r.Client != nil {
-		return r.Close()
+	if r.Client == nil {
+		return nil
 	}
 
-	return nil
+	return logging.TimeShutdown(r.logger, "redis", r.Close)
 }
 
 // RedisPackage provides the Redis client.
 func RedisPackage(i *do.Injector) {
 	do.Provide(i, func(i *do.Injector) (*RedisClient, error) {
 		opts := do.MustInvoke[*Options](i)
+		logger := do.MustInvoke[*zap.Logger](i)
 
 		return &RedisClient{
 			Client: redis.NewClient(&redis.Options{
 				Addr: opts.RedisAddr,
 			}),
+			logger: logger,
 		}, nil
 	})
 }
@@ -93,15 +339,20 @@ func RedisPackage(i *do.Injector) {
 // PostgresPool wraps pgxpool.Pool to implement Shutdownable for do.Injector.
 type PostgresPool struct {
 	*pgxpool.Pool
+	logger *zap.Logger
 }
 
 // Shutdown implements do.Shutdownable.
 func (p *PostgresPool) Shutdown() error {
-	if p.Pool != nil {
-		p.Close()
+	if p.Pool == nil {
+		return nil
 	}
 
-	return nil
+	return logging.TimeShutdown(p.logger, "postgres", func() error {
+		p.Close()
+
+		return nil
+	})
 }
 
 // PostgresPackage provides the PostgreSQL connection pool.
@@ -123,47 +374,128 @@ func PostgresPackage(i *do.Injector) {
 			return nil, err
 		}
 
-		return &PostgresPool{Pool: pool}, nil
+		return &PostgresPool{Pool: pool, logger: do.MustInvoke[*zap.Logger](i)}, nil
 	})
 }
 
 // RepositoryPackage provides the URL repository with Redis caching over PostgreSQL.
+// The Redis cache layer is also provided on its own, so the injector shuts it
+// down and drains its write-behind queue before closing the Redis client.
 func RepositoryPackage(i *do.Injector) {
-	do.Provide(i, func(i *do.Injector) (shortener.Repository, error) {
+	do.Provide(i, func(i *do.Injector) (*store.RedisCacheRepository, error) {
 		opts := do.MustInvoke[*Options](i)
 		pool := do.MustInvoke[*PostgresPool](i)
 		redisClient := do.MustInvoke[*RedisClient](i)
 
 		// PostgreSQL as source of truth
-		postgresStore := store.NewPostgresStore(pool.Pool)
+		var postgresStore shortener.Repository = store.NewPostgresStore(pool.Pool)
+
+		// Optional latency histogram around the database calls only
+		if opts.StoreMetrics {
+			registry := do.MustInvoke[*prometheus.Registry](i)
+			postgresStore = store.NewInstrumentedRepository(postgresStore, metrics.NewRepositoryRecorder(registry))
+		}
+
+		// Throttle batch imports; each chunk holds a pool connection while it runs
+		if opts.BatchChunkSize > 0 {
+			postgresStore = store.NewChunkedRepository(postgresStore, opts.BatchChunkSize, opts.BatchConcurrency)
+		}
 
 		// Redis cache layer with configurable TTL
-		var repo shortener.Repository = store.NewRedisCacheRepository(postgresStore, redisClient.Client, opts.CacheTTL)
+		cacheOpts := []store.RedisCacheOption{
+			store.WithCacheErrorCooldown(opts.CacheErrorCooldown),
+			store.WithRedisNegativeTTL(opts.NegativeCacheTTL),
+			store.WithCacheLogger(do.MustInvoke[*zap.Logger](i)),
+			store.WithCacheRecorder(metrics.NewCacheRecorder(do.MustInvoke[*prometheus.Registry](i))),
+		}
+
+		if opts.TokenCacheTTL > 0 {
+			cacheOpts = append(cacheOpts, store.WithStrategyTTL(shortener.StrategyToken, opts.TokenCacheTTL))
+		}
+
+		if opts.HashCacheTTL > 0 {
+			cacheOpts = append(cacheOpts, store.WithStrategyTTL(shortener.StrategyHash, opts.HashCacheTTL))
+		}
+
+		writeMode := store.CacheWriteMode(opts.CacheWriteMode)
+		if !store.IsValidCacheWriteMode(writeMode) {
+			return nil, fmt.Errorf("invalid cache write mode %q: must be 'through' or 'behind'", opts.CacheWriteMode)
+		}
+
+		if writeMode == store.CacheWriteBehind {
+			cacheOpts = append(cacheOpts, store.WithWriteBehind(opts.CacheWriteQueueSize))
+		}
+
+		return store.NewRedisCacheRepository(postgresStore, redisClient.Client, opts.CacheTTL, cacheOpts...), nil
+	})
+
+	do.Provide(i, func(i *do.Injector) (shortener.Repository, error) {
+		opts := do.MustInvoke[*Options](i)
+
+		redisCache, err := do.Invoke[*store.RedisCacheRepository](i)
+		if err != nil {
+			return nil, err
+		}
+
+		var repo shortener.Repository = redisCache
 
 		// Optional in-memory LRU cache on top
 		if opts.CacheSize > 0 {
-			repo = store.NewCachedRepository(repo, cache.New(opts.CacheSize))
+			repo = store.NewCachedRepository(repo, cache.New(opts.CacheSize),
+				store.WithLRUNegativeTTL(opts.NegativeCacheTTL))
+		}
+
+		// Optional not-found ratio around the whole stack, so misses served from cache count too
+		if opts.NotFoundRateWindow > 0 {
+			registry := do.MustInvoke[*prometheus.Registry](i)
+			logger := do.MustInvoke[*zap.Logger](i)
+			repo = store.NewInstrumentedRepository(repo,
+				metrics.NewNotFoundRate(registry, opts.NotFoundRateWindow, opts.NotFoundRateThreshold, logger))
 		}
 
 		return repo, nil
 	})
 }
 
-// RateLimitPackage provides the rate limit store.
+// CodeGeneratorPackage provides the factory for short code generators, selected
+// by Options.GeneratorType. Override shortener.GeneratorFactory in the injector
+// to plug in a custom generator.
+func CodeGeneratorPackage(i *do.Injector) {
+	do.Provide(i, func(i *do.Injector) (shortener.GeneratorFactory, error) {
+		opts := do.MustInvoke[*Options](i)
+
+		// Seeding sequential counters with the clock keeps them ahead of codes
+		// handed out before a restart unless codes are created faster than 1000/s.
+		return shortener.NewGeneratorFactory(shortener.GeneratorType(opts.GeneratorType), uint64(time.Now().UnixMilli()))
+	})
+}
+
+// RateLimitPackage provides the rate limit store. The memory and redis stores
+// count sliding windows; the postgres store counts fixed windows.
 func RateLimitPackage(i *do.Injector) {
 	do.Provide(i, func(i *do.Injector) (ratelimit.Store, error) {
 		opts := do.MustInvoke[*Options](i)
-		redisClient := do.MustInvoke[*RedisClient](i)
 
 		switch opts.RateLimitStore {
 		case "redis":
-			return ratelimitstore.NewRedis(redisClient.Client), nil
+			var s ratelimit.SlidingWindowStore = ratelimitstore.NewRedis(do.MustInvoke[*RedisClient](i).Client)
+
+			return s, nil
+		case "postgres":
+			var s ratelimit.FixedWindowStore = ratelimitstore.NewPostgres(do.MustInvoke[*PostgresPool](i).Pool)
+
+			return s, nil
 		default:
-			return ratelimitstore.NewMemory(), nil
+			var s ratelimit.SlidingWindowStore = ratelimitstore.NewMemory()
+
+			return s, nil
 		}
 	})
 }
 
+// mirrorRedisClient names the Redis client events are mirrored to.
+const mirrorRedisClient = "mirror-redis"
+
 // PublisherGroupPackage provides the publisher group for event publishing.
 func PublisherGroupPackage(i *do.Injector) {
 	do.Provide(i, func(i *do.Injector) (*messaging.PublisherGroup, error) {
@@ -179,16 +511,66 @@ func PublisherGroupPackage(i *do.Injector) {
 			return nil, err
 		}
 
-		return messaging.NewPublisherGroup(publisher), nil
+		opts := do.MustInvoke[*Options](i)
+		if opts.PublishMirrorRedisAddr == "" {
+			return messaging.NewPublisherGroup(publisher), nil
+		}
+
+		mirror, err := redisstream.NewPublisher(
+			redisstream.PublisherConfig{
+				Client: do.MustInvokeNamed[*RedisClient](i, mirrorRedisClient).Client,
+			},
+			watermill.NopLogger{},
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		return messaging.NewPublisherGroup(messaging.NewFanOutPublisher(publisher, mirror)), nil
+	})
+
+	// Client for the mirror Redis; invoked only when PublishMirrorRedisAddr is set
+	do.ProvideNamed(i, mirrorRedisClient, func(i *do.Injector) (*RedisClient, error) {
+		opts := do.MustInvoke[*Options](i)
+		logger := do.MustInvoke[*zap.Logger](i)
+
+		return &RedisClient{
+			Client: redis.NewClient(&redis.Options{
+				Addr: opts.PublishMirrorRedisAddr,
+			}),
+			logger: logger,
+		}, nil
+	})
+
+	// Access events published from a bounded buffer; invoked only when AnalyticsBufferSize is set
+	do.Provide(i, func(i *do.Injector) (*messaging.AsyncPublisher[analytics.URLAccessedEvent], error) {
+		opts := do.MustInvoke[*Options](i)
+		publisherGroup := do.MustInvoke[*messaging.PublisherGroup](i)
+		registry := do.MustInvoke[*prometheus.Registry](i)
+
+		publish := messaging.NewPublishFunc[analytics.URLAccessedEvent](
+			publisherGroup.Publisher(),
+			opts.TopicURLAccessed,
+			opts.publishOptions()...,
+		)
+
+		return messaging.NewAsyncPublisher(publish, opts.AnalyticsBufferSize, do.MustInvoke[*zap.Logger](i),
+			messaging.WithBufferRecorder(metrics.NewPublishBufferRecorder(registry))), nil
 	})
 }
 
 // AnalyticsStorePackage provides the analytics store for persisting events.
 func AnalyticsStorePackage(i *do.Injector) {
 	do.Provide(i, func(i *do.Injector) (analytics.Store, error) {
+		opts := do.MustInvoke[*Options](i)
 		pool := do.MustInvoke[*PostgresPool](i)
 
-		return analyticsstore.NewPostgres(pool.Pool), nil
+		var storeOpts []analyticsstore.PostgresOption
+		if opts.AnalyticsIPHashKey != "" {
+			storeOpts = append(storeOpts, analyticsstore.WithIPHashKey([]byte(opts.AnalyticsIPHashKey)))
+		}
+
+		return analyticsstore.NewPostgres(pool.Pool, storeOpts...), nil
 	})
 }
 
@@ -199,6 +581,7 @@ func ConsumerGroupPackage(i *do.Injector) {
 		redisClient := do.MustInvoke[*RedisClient](i)
 		logger := do.MustInvoke[*zap.Logger](i)
 		store := do.MustInvoke[analytics.Store](i)
+		registry := do.MustInvoke[*prometheus.Registry](i)
 
 		subscriber, err := redisstream.NewSubscriber(
 			redisstream.SubscriberConfig{
@@ -212,7 +595,13 @@ func ConsumerGroupPackage(i *do.Injector) {
 			return nil, err
 		}
 
-		group := messaging.NewConsumerGroup(subscriber, logger)
+		group := messaging.NewConsumerGroup(subscriber, logger,
+			messaging.WithRecorder(metrics.NewConsumerRecorder(registry)))
+
+		consumerOpts := []messaging.ConsumerOption{
+			messaging.WithAckTimeout(opts.ConsumerAckTimeout),
+			messaging.WithHandlerRecorder(metrics.NewHandlerRecorder(registry)),
+		}
 
 		// Register analytics consumers
 		group.Add(messaging.NewConsumer(
@@ -220,15 +609,54 @@ func ConsumerGroupPackage(i *do.Injector) {
 			opts.TopicURLCreated,
 			store.SaveURLCreated,
 			logger,
+			consumerOpts...,
 		))
 
+		// Count accesses per code and flush them in batches when the store supports it
+		saveAccessed := store.SaveURLAccessed
+
+		var hits *analytics.HitCounter
+
+		if hitStore, ok := store.(analytics.HitStore); ok && opts.AccessCountFlushInterval > 0 {
+			hits = analytics.NewHitCounter(hitStore, opts.AccessCountFlushInterval, logger)
+			saveAccessed = hits.Track(saveAccessed)
+		}
+
 		group.Add(messaging.NewConsumer(
 			subscriber,
 			opts.TopicURLAccessed,
-			store.SaveURLAccessed,
+			saveAccessed,
 			logger,
+			consumerOpts...,
 		))
 
+		// Consume created events routed to per-strategy topics
+		for _, topic := range opts.extraCreatedTopics() {
+			group.Add(messaging.NewConsumer(
+				subscriber,
+				topic,
+				store.SaveURLCreated,
+				logger,
+				consumerOpts...,
+			))
+		}
+
+		// Added after the accessed consumer so the final flush sees every handled event
+		if hits != nil {
+			group.Add(hits)
+		}
+
+		// Periodically prune access events past the retention period
+		if opts.AnalyticsRetention > 0 {
+			group.Add(analytics.NewPruner(store, opts.AnalyticsRetention, opts.AnalyticsPruneInterval, logger))
+		}
+
+		// Export unacknowledged message counts so a backed-up consumer shows in metrics
+		if opts.PendingMetricsInterval > 0 {
+			group.Add(metrics.NewPendingMonitor(registry, redisClient.Client, opts.ConsumerGroup, opts.streamTopics(),
+				opts.PendingMetricsInterval, logger))
+		}
+
 		return group, nil
 	})
 }
@@ -239,6 +667,10 @@ func HTTPPackage(i *do.Injector) {
 		return chi.NewMux(), nil
 	})
 
+	do.Provide(i, func(_ *do.Injector) (*health.Gate, error) {
+		return health.NewGate(), nil
+	})
+
 	do.Provide(i, func(i *do.Injector) (huma.API, error) {
 		router := do.MustInvoke[*chi.Mux](i)
 		opts := do.MustInvoke[*Options](i)
@@ -247,49 +679,357 @@ func HTTPPackage(i *do.Injector) {
 		urlStore := do.MustInvoke[shortener.Repository](i)
 		rateLimitStore := do.MustInvoke[ratelimit.Store](i)
 		publisherGroup := do.MustInvoke[*messaging.PublisherGroup](i)
+		registry := do.MustInvoke[*prometheus.Registry](i)
+		analyticsStore := do.MustInvoke[analytics.Store](i)
+
+		apiConfig, err := handlers.NewAPIConfig(opts.DefaultContentType)
+		if err != nil {
+			return nil, err
+		}
+
+		if opts.DisableDocs {
+			apiConfig = handlers.WithoutDocs(apiConfig)
+		}
+
+		if opts.LocalizeErrors {
+			apiConfig = handlers.WithLocalizedErrors(apiConfig)
+		}
+
+		if opts.EnvelopeResponses {
+			apiConfig = handlers.WithEnvelopedResponses(apiConfig)
+		}
+
+		// Router middleware must be installed before any route, including Huma's docs
+		trailingSlash := middleware.TrailingSlashPolicy(opts.TrailingSlash)
+		if !middleware.IsValidTrailingSlashPolicy(trailingSlash) {
+			return nil, fmt.Errorf("invalid trailing slash policy %q: must be 'strip', 'redirect' or 'off'",
+				opts.TrailingSlash)
+		}
+
+		router.Use(middleware.TrailingSlash(trailingSlash))
+
+		if opts.CompressionMinSize > 0 {
+			router.Use(middleware.Compress(opts.CompressionMinSize))
+		}
+
+		if opts.RequestSigningSecret != "" {
+			router.Use(middleware.VerifySignature(opts.RequestSigningSecret, opts.RequestSigningWindow))
+		}
 
-		api := humachi.New(router, huma.DefaultConfig("URL Shortener", "1.0.0"))
+		router.Use(middleware.RequestTimeout(opts.RequestTimeoutMax))
+
+		api := humachi.New(router, apiConfig)
+
+		// Expose Prometheus metrics outside of the Huma API (no rate limiting or docs)
+		router.Handle("/metrics", metrics.Handler(registry))
 
 		// Set up middleware
-		api.UseMiddleware(middleware.RequestMeta(api))
+		api.UseMiddleware(middleware.RequestMeta(api, middleware.WithRequestMetaXFFTrustDepth(opts.XFFTrustDepth)))
+		api.UseMiddleware(middleware.RequestID(api, logger))
+		api.UseMiddleware(middleware.MaxConcurrentRequests(api, opts.MaxConcurrentRequests))
+		api.UseMiddleware(middleware.AllowedHosts(api, splitList(opts.AllowedHosts)))
+		api.UseMiddleware(middleware.AdminAuth(api, opts.AdminToken))
+
+		apiKeys, err := middleware.ParseAPIKeys(opts.APIKeys)
+		if err != nil {
+			return nil, err
+		}
+
+		api.UseMiddleware(middleware.APIKeyAuth(api, apiKeys))
+
+		missingUA := middleware.MissingUserAgentPolicy(opts.MissingUserAgent)
+		if !middleware.IsValidMissingUserAgentPolicy(missingUA) {
+			return nil, fmt.Errorf("invalid missing user agent policy %q: must be 'allow', 'reject' or 'peer'",
+				opts.MissingUserAgent)
+		}
+
+		if missingUA == middleware.MissingUserAgentReject {
+			api.UseMiddleware(middleware.RequireUserAgent(api))
+		}
+
+		if opts.StrictAccept {
+			api.UseMiddleware(middleware.NotAcceptable(api, handlers.SupportedMediaTypes(apiConfig)))
+		}
 
 		// Build rate limit policy from configuration
-		policy := ratelimit.NewPolicyBuilder().
+		builder := ratelimit.NewPolicyBuilder().
 			AddLimit(ratelimit.ScopeGlobal, opts.RateLimitGlobalPerDay, 24*time.Hour).
 			AddLimit(ratelimit.ScopeRead, opts.RateLimitReadPerMinute, time.Minute).
 			AddLimit(ratelimit.ScopeWrite, opts.RateLimitWritePerMinute, time.Minute).
 			AddLimit(ratelimit.ScopeWrite, opts.RateLimitWritePerHour, time.Hour).
-			AddLimit(ratelimit.ScopeWrite, opts.RateLimitWritePerDay, 24*time.Hour).
-			Build()
+			AddLimit(ratelimit.ScopeWrite, opts.RateLimitWritePerDay, 24*time.Hour)
+
+		tierLimits, err := ratelimit.ParseTierLimits(opts.RateLimitTierLimits)
+		if err != nil {
+			return nil, err
+		}
+
+		for scope, limits := range tierLimits {
+			for _, limit := range limits {
+				builder.AddLimit(scope, limit.Max, limit.Window)
+			}
+		}
+
+		policy := builder.Build()
+		if err := ratelimit.ValidatePolicy(policy); err != nil {
+			return nil, fmt.Errorf("rate limit policy: %w", err)
+		}
 
 		limiter := ratelimit.NewPolicyLimiter(rateLimitStore, policy)
-		resolver := ratelimit.NewOperationScopeResolver()
-		api.UseMiddleware(middleware.PolicyRateLimiter(api, limiter, resolver, logger))
+
+		resolver := ratelimit.NewTieredPolicyResolver(ratelimit.NewOperationScopeResolver(), limiter)
+
+		rateLimitOpts := []middleware.PolicyRateLimiterOption{
+			middleware.WithDecisionRecorder(metrics.NewRateLimitRecorder(registry)),
+			middleware.WithXFFTrustDepth(opts.XFFTrustDepth),
+		}
+		if opts.RateLimitMonitorOnly {
+			rateLimitOpts = append(rateLimitOpts, middleware.WithMonitorOnly())
+		}
+
+		if missingUA == middleware.MissingUserAgentPeer {
+			rateLimitOpts = append(rateLimitOpts, middleware.WithPeerKeyWithoutUserAgent())
+		}
+
+		if opts.RateLimitBreachThreshold > 0 {
+			tracker := ratelimit.NewBreachTracker(opts.RateLimitBreachThreshold, opts.RateLimitBreachWindow)
+			rateLimitOpts = append(rateLimitOpts,
+				middleware.WithBreachNotifier(tracker, middleware.NewLogBreachNotifier(logger)))
+		}
+
+		api.UseMiddleware(middleware.PolicyRateLimiter(api, limiter, resolver, logger, rateLimitOpts...))
+
+		// Global redirect budget, checked after per-client limits so abusive clients are refused first
+		if opts.RedirectRateLimit > 0 {
+			bucket := ratelimit.NewTokenBucket(opts.RedirectRateLimit, opts.RedirectBurst)
+			api.UseMiddleware(middleware.GlobalThrottle(api, bucket))
+		}
+
+		if !handlers.IsValidRedirectStatus(opts.RedirectStatus) {
+			return nil, fmt.Errorf("invalid redirect status %d: must be 301, 302, 307 or 308", opts.RedirectStatus)
+		}
 
 		// Set up handlers
 		baseURL := fmt.Sprintf("http://localhost:%d", opts.Port)
-		codeGenerator, _ := nanoid.Standard(opts.CodeLength)
+		auditLog := audit.NewLogger(logger)
+		handlerOpts := []handlers.URLHandlerOption{
+			handlers.WithRedirectStatus(opts.RedirectStatus),
+			handlers.WithAudit(auditLog),
+		}
+
+		if opts.CaseInsensitiveCodes {
+			handlerOpts = append(handlerOpts, handlers.WithCaseInsensitiveCodes())
+		}
+
+		if opts.NotFoundRedirectURL != "" {
+			handlerOpts = append(handlerOpts, handlers.WithNotFoundRedirect(opts.NotFoundRedirectURL))
+		}
+
+		if opts.RedirectCacheMaxAge > 0 {
+			handlerOpts = append(handlerOpts, handlers.WithRedirectCacheMaxAge(opts.RedirectCacheMaxAge))
+		}
+
+		if opts.RedirectLinkHeader {
+			handlerOpts = append(handlerOpts, handlers.WithRedirectLinkHeader())
+		}
+
+		if opts.IncludeQRUrl {
+			handlerOpts = append(handlerOpts, handlers.WithQRURL())
+		}
+
+		if opts.RelativeShortURL {
+			handlerOpts = append(handlerOpts, handlers.WithRelativeShortURLs())
+		}
+
+		if opts.AnonymizeIP {
+			handlerOpts = append(handlerOpts, handlers.WithAnonymizedIPs())
+		}
+
+		if opts.CollapseSelfRedirects {
+			handlerOpts = append(handlerOpts, handlers.WithCollapsedSelfRedirects())
+		}
+
+		if opts.CodeRedirectLimit > 0 {
+			handlerOpts = append(handlerOpts, handlers.WithCodeRedirectLimit(rateLimitStore, ratelimit.LimitConfig{
+				Window: opts.CodeRedirectWindow,
+				Max:    opts.CodeRedirectLimit,
+			}))
+		}
+
+		if opts.DuplicateSubmissionWindow > 0 {
+			submissions := store.NewRedisRecentSubmissions(redisClient.Client, opts.DuplicateSubmissionWindow)
+			handlerOpts = append(handlerOpts, handlers.WithRecentSubmissions(submissions))
+		}
+
+		newGenerator := do.MustInvoke[shortener.GeneratorFactory](i)
+
+		tokenGenerator, err := newGenerator(
+			codeLengthOrDefault(opts.TokenCodeLength, opts.CodeLength), opts.CaseInsensitiveCodes)
+		if err != nil {
+			return nil, fmt.Errorf("token strategy: %w", err)
+		}
+
+		hashGenerator, err := newGenerator(
+			codeLengthOrDefault(opts.HashCodeLength, opts.CodeLength), opts.CaseInsensitiveCodes)
+		if err != nil {
+			return nil, fmt.Errorf("hash strategy: %w", err)
+		}
+
+		if opts.AliasCharset != "" {
+			if err := shortener.ValidateAliasCharset(opts.AliasCharset); err != nil {
+				return nil, err
+			}
+		}
+
+		handlerOpts = append(handlerOpts, handlers.WithAliasPolicy(shortener.AliasPolicy{
+			MaxLength:        opts.MaxAliasLength,
+			ReservedPrefixes: splitList(opts.ReservedAliasPrefixes),
+			Charset:          opts.AliasCharset,
+		}))
+
+		hashRules := shortener.URLRules{
+			MinLength:         opts.HashMinURLLength,
+			RequireDottedHost: opts.HashRequireDottedHost,
+		}
+
+		tokenOpts := []shortener.TokenStrategyOption{}
+		hashOpts := []shortener.HashStrategyOption{shortener.WithURLRules(hashRules)}
+
+		if opts.StoreNormalizedURL {
+			tokenOpts = append(tokenOpts, shortener.WithTokenNormalizedURL())
+			hashOpts = append(hashOpts, shortener.WithHashNormalizedURL())
+		}
+
+		if opts.MaxPathSegments > 0 || opts.MaxPathLength > 0 {
+			normalizeOpts := []shortener.NormalizeOption{
+				shortener.WithMaxPathSegments(opts.MaxPathSegments),
+				shortener.WithMaxPathLength(opts.MaxPathLength),
+			}
+
+			tokenOpts = append(tokenOpts, shortener.WithTokenNormalizeOptions(normalizeOpts...))
+			hashOpts = append(hashOpts, shortener.WithHashNormalizeOptions(normalizeOpts...))
+			handlerOpts = append(handlerOpts, handlers.WithNormalizeOptions(normalizeOpts...))
+		}
 
 		strategies := map[handlers.Strategy]shortener.Strategy{
-			handlers.StrategyToken: shortener.NewTokenStrategy(urlStore, codeGenerator),
-			handlers.StrategyHash:  shortener.NewHashStrategy(urlStore, codeGenerator),
+			handlers.StrategyToken: shortener.NewTokenStrategy(urlStore, tokenGenerator, tokenOpts...),
+			handlers.StrategyHash:  shortener.NewHashStrategy(urlStore, hashGenerator, hashOpts...),
+		}
+
+		defaultStrategy := handlers.Strategy(opts.DefaultStrategy)
+		if _, ok := strategies[defaultStrategy]; !ok {
+			return nil, fmt.Errorf("default strategy %q: %w", opts.DefaultStrategy, handlers.InvalidStrategyError(strategies))
 		}
 
+		handlerOpts = append(handlerOpts, handlers.WithDefaultStrategy(defaultStrategy))
+
+		publishFailure := handlers.PublishFailurePolicy(opts.PublishFailurePolicy)
+		if !handlers.IsValidPublishFailurePolicy(publishFailure) {
+			return nil, fmt.Errorf("invalid publish failure policy %q: must be 'ignore' or 'fail'", opts.PublishFailurePolicy)
+		}
+
+		handlerOpts = append(handlerOpts, handlers.WithPublishFailurePolicy(publishFailure))
+
 		pub := publisherGroup.Publisher()
+		publishOpts := opts.publishOptions()
+
+		strategyPublishers := map[handlers.Strategy]messaging.Publish[analytics.URLCreatedEvent]{}
+		for strategy, topic := range opts.strategyCreatedTopics() {
+			strategyPublishers[strategy] = messaging.NewPublishFunc[analytics.URLCreatedEvent](pub, topic, publishOpts...)
+		}
+
+		handlerOpts = append(handlerOpts, handlers.WithStrategyPublishers(strategyPublishers))
+
+		publishAccessed := messaging.NewPublishFunc[analytics.URLAccessedEvent](pub, opts.TopicURLAccessed, publishOpts...)
+		if opts.AnalyticsBufferSize > 0 {
+			publishAccessed = do.MustInvoke[*messaging.AsyncPublisher[analytics.URLAccessedEvent]](i).Publish
+		}
+
 		urlHandler := handlers.NewURLHandler(
 			urlStore,
 			baseURL,
 			strategies,
-			messaging.NewPublishFunc[analytics.URLCreatedEvent](pub, opts.TopicURLCreated),
-			messaging.NewPublishFunc[analytics.URLAccessedEvent](pub, opts.TopicURLAccessed),
+			messaging.NewPublishFunc[analytics.URLCreatedEvent](pub, opts.TopicURLCreated, publishOpts...),
+			publishAccessed,
 			logger,
+			handlerOpts...,
+		)
+		var statsOpts []handlers.StatsHandlerOption
+		if opts.LargeCountsAsStrings {
+			statsOpts = append(statsOpts, handlers.WithLargeCountsAsStrings())
+		}
+
+		statsHandler := handlers.NewStatsHandler(analyticsStore, logger, statsOpts...)
+		adminOpts := []handlers.AdminHandlerOption{
+			handlers.WithAdminAudit(auditLog),
+			handlers.WithAdminAnalytics(analyticsStore),
+		}
+		if opts.CaseInsensitiveCodes {
+			adminOpts = append(adminOpts, handlers.WithAdminCaseInsensitiveCodes())
+		}
+
+		adminHandler := handlers.NewAdminHandler(urlStore, limiter, logger, adminOpts...)
+		brokerChecker := health.NewStreamChecker(
+			redisClient.Client,
+			opts.ConsumerGroup,
+			opts.streamTopics(),
+			opts.BrokerMaxLag,
+		)
+		healthHandler := health.NewHandler(
+			health.NewThresholdChecker(health.NewRedisChecker(redisClient.Client), opts.RedisHealthFailureThreshold),
+			health.WithChecker("postgres", do.MustInvoke[*PostgresPool](i).Pool),
+			health.WithChecker("broker", brokerChecker),
+			health.WithReadinessGate(do.MustInvoke[*health.Gate](i)),
 		)
-		healthHandler := health.NewHandler(health.NewRedisChecker(redisClient.Client))
 
 		// Register routes
 		handlers.RegisterRoutes(api, urlHandler)
+		handlers.RegisterStatsRoutes(api, statsHandler)
+		handlers.RegisterAdminRoutes(api, adminHandler)
 		health.RegisterRoutes(api, healthHandler)
+		version.RegisterRoutes(api)
+
+		if err := ratelimit.ValidateOperations(api, opts.RateLimitMaxCustomLimits); err != nil {
+			return nil, fmt.Errorf("invalid endpoint rate limits: %w", err)
+		}
 
 		return api, nil
 	})
 }
+
+// AwaitReadiness opens the readiness gate once Redis and PostgreSQL answer and
+// ReadinessDelay has passed, retrying unavailable dependencies every second.
+// It gives up when ctx is done.
+func AwaitReadiness(ctx context.Context, i *do.Injector) {
+	opts := do.MustInvoke[*Options](i)
+	gate := do.MustInvoke[*health.Gate](i)
+
+	if gate.OpenWhen(ctx, opts.ReadinessDelay, time.Second,
+		health.NewRedisChecker(do.MustInvoke[*RedisClient](i).Client),
+		do.MustInvoke[*PostgresPool](i).Pool,
+	) {
+		do.MustInvoke[*zap.Logger](i).Info("ready to accept traffic")
+	}
+}
+
+// splitList parses a comma-separated option value, dropping empty entries.
+func splitList(value string) []string {
+	var items []string
+
+	for item := range strings.SplitSeq(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+// codeLengthOrDefault returns length, or fallback when length is unset.
+func codeLengthOrDefault(length, fallback int) int {
+	if length > 0 {
+		return length
+	}
+
+	return fallback
+}
@@ -0,0 +1,45 @@
+package container_test
+
+import (
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/samber/do"
+	"github.com/serroba/web-demo-go/internal/container"
+	"github.com/serroba/web-demo-go/internal/ratelimit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimitPackage(t *testing.T) {
+	invoke := func(t *testing.T, kind string) ratelimit.Store {
+		t.Helper()
+
+		i := do.New()
+		do.ProvideValue(i, &container.Options{RateLimitStore: kind})
+		do.ProvideValue(i, &container.RedisClient{Client: redis.NewClient(&redis.Options{})})
+		do.ProvideValue(i, &container.PostgresPool{})
+		container.RateLimitPackage(i)
+
+		s, err := do.Invoke[ratelimit.Store](i)
+		require.NoError(t, err)
+
+		return s
+	}
+
+	for _, kind := range []string{"memory", "redis"} {
+		t.Run(kind+" store counts sliding windows", func(t *testing.T) {
+			s := invoke(t, kind)
+
+			assert.Implements(t, (*ratelimit.SlidingWindowStore)(nil), s)
+			assert.NotImplements(t, (*ratelimit.FixedWindowStore)(nil), s)
+		})
+	}
+
+	t.Run("postgres store counts fixed windows", func(t *testing.T) {
+		s := invoke(t, "postgres")
+
+		assert.Implements(t, (*ratelimit.FixedWindowStore)(nil), s)
+		assert.NotImplements(t, (*ratelimit.SlidingWindowStore)(nil), s)
+	})
+}
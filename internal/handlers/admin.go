@@ -0,0 +1,265 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/serroba/web-demo-go/internal/analytics"
+	"github.com/serroba/web-demo-go/internal/audit"
+	"github.com/serroba/web-demo-go/internal/clock"
+	"github.com/serroba/web-demo-go/internal/logging"
+	"github.com/serroba/web-demo-go/internal/ratelimit"
+	"github.com/serroba/web-demo-go/internal/shortener"
+	"go.uber.org/zap"
+)
+
+// AdminMetadataKey marks operations that require the admin token.
+const AdminMetadataKey = "admin"
+
+// Audited admin actions.
+const (
+	AuditActionImportURLs            = "import_urls"
+	AuditActionUpdateTarget          = "update_target"
+	AuditActionFlagURL               = "flag_url"
+	AuditActionUpdateRateLimitPolicy = "update_ratelimit_policy"
+)
+
+// AdminHandler serves operational endpoints for administrators.
+type AdminHandler struct {
+	store     shortener.Repository
+	limiter   *ratelimit.PolicyLimiter
+	logger    *zap.Logger
+	audit     audit.Recorder
+	analytics analytics.Store
+	clock     clock.Clock
+
+	caseInsensitive bool
+}
+
+// AdminHandlerOption configures optional AdminHandler behavior.
+type AdminHandlerOption func(*AdminHandler)
+
+// WithAdminAudit records an audit entry for every change made through the handler.
+func WithAdminAudit(recorder audit.Recorder) AdminHandlerOption {
+	return func(h *AdminHandler) {
+		h.audit = recorder
+	}
+}
+
+// WithAdminAnalytics sets the analytics store used to find the most accessed
+// codes when warming the cache. Without it, cache warming is unavailable.
+func WithAdminAnalytics(store analytics.Store) AdminHandlerOption {
+	return func(h *AdminHandler) {
+		h.analytics = store
+	}
+}
+
+// WithAdminClock sets the clock used to stamp imported short URLs that have
+// no created_at.
+func WithAdminClock(c clock.Clock) AdminHandlerOption {
+	return func(h *AdminHandler) {
+		h.clock = c
+	}
+}
+
+// WithAdminCaseInsensitiveCodes lowercases imported codes before they are
+// saved, matching the URL handler's WithCaseInsensitiveCodes.
+func WithAdminCaseInsensitiveCodes() AdminHandlerOption {
+	return func(h *AdminHandler) {
+		h.caseInsensitive = true
+	}
+}
+
+// NewAdminHandler creates a new admin handler.
+func NewAdminHandler(
+	store shortener.Repository,
+	limiter *ratelimit.PolicyLimiter,
+	logger *zap.Logger,
+	opts ...AdminHandlerOption,
+) *AdminHandler {
+	h := &AdminHandler{
+		store:   store,
+		limiter: limiter,
+		logger:  logger,
+		audit:   audit.Nop{},
+		clock:   clock.Real{},
+	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h
+}
+
+// normalizeCode applies the handler's code casing rules to an imported code.
+func (h *AdminHandler) normalizeCode(code string) shortener.Code {
+	if h.caseInsensitive {
+		code = strings.ToLower(code)
+	}
+
+	return shortener.Code(code)
+}
+
+// auditEntry builds an audit entry for the actor and client of ctx.
+func auditEntry(ctx context.Context, action, target, detail string) audit.Entry {
+	return audit.Entry{
+		Actor:    audit.ActorFromContext(ctx),
+		Action:   action,
+		Target:   target,
+		Detail:   detail,
+		ClientIP: RequestMetaFromContext(ctx).ClientIP,
+	}
+}
+
+// CountURLs returns the total number of stored short URLs.
+func (h *AdminHandler) CountURLs(ctx context.Context, _ *struct{}) (*CountURLsResponse, error) {
+	count, err := h.store.Count(ctx)
+	if err != nil {
+		logging.FromContext(ctx, h.logger).Error("failed to count urls", zap.Error(err))
+
+		return nil, huma.Error500InternalServerError("failed to count urls")
+	}
+
+	resp := &CountURLsResponse{}
+	resp.Body.Count = count
+
+	return resp, nil
+}
+
+// InspectRateLimit reports the current count of every rate limit bucket for a client key.
+func (h *AdminHandler) InspectRateLimit(
+	ctx context.Context,
+	req *InspectRateLimitRequest,
+) (*InspectRateLimitResponse, error) {
+	buckets, err := h.limiter.Inspect(ctx, req.Key)
+	if err != nil {
+		logging.FromContext(ctx, h.logger).Error("failed to inspect rate limit", zap.Error(err))
+
+		return nil, huma.Error500InternalServerError("failed to inspect rate limit")
+	}
+
+	resp := &InspectRateLimitResponse{}
+	resp.Body.Key = req.Key
+	resp.Body.Buckets = make([]RateLimitBucket, 0, len(buckets))
+
+	for _, b := range buckets {
+		resp.Body.Buckets = append(resp.Body.Buckets, RateLimitBucket{
+			Scope:  string(b.Scope),
+			Window: b.Config.Window.String(),
+			Max:    b.Config.Max,
+			Count:  b.Count,
+			Key:    b.Key,
+		})
+	}
+
+	return resp, nil
+}
+
+// UpdateRateLimitPolicy validates a new rate limit policy and swaps it into the
+// limiter, so later requests are checked against it without a restart.
+func (h *AdminHandler) UpdateRateLimitPolicy(
+	ctx context.Context,
+	req *UpdateRateLimitPolicyRequest,
+) (*RateLimitPolicyResponse, error) {
+	builder := ratelimit.NewPolicyBuilder()
+
+	for _, limit := range req.Body.Limits {
+		window, err := time.ParseDuration(limit.Window)
+		if err != nil {
+			return nil, huma.Error400BadRequest(fmt.Sprintf("invalid %s window %q", limit.Scope, limit.Window))
+		}
+
+		builder.AddLimit(ratelimit.Scope(limit.Scope), limit.Max, window)
+	}
+
+	policy := builder.Build()
+	if err := ratelimit.ValidatePolicy(policy); err != nil {
+		return nil, huma.Error400BadRequest(err.Error())
+	}
+
+	h.limiter.SetPolicy(policy)
+
+	resp := &RateLimitPolicyResponse{}
+	resp.Body.Limits = policyLimits(policy)
+
+	h.audit.Record(ctx, auditEntry(ctx, AuditActionUpdateRateLimitPolicy, "ratelimit", describeLimits(resp.Body.Limits)))
+
+	return resp, nil
+}
+
+// policyLimits flattens policy ordered by scope, then policy order.
+func policyLimits(policy *ratelimit.Policy) []RateLimitPolicyLimit {
+	limits := []RateLimitPolicyLimit{}
+
+	for _, scope := range slices.Sorted(maps.Keys(policy.Limits)) {
+		for _, limit := range policy.Limits[scope] {
+			limits = append(limits, RateLimitPolicyLimit{
+				Scope:  string(scope),
+				Window: limit.Window.String(),
+				Max:    limit.Max,
+			})
+		}
+	}
+
+	return limits
+}
+
+// describeLimits renders limits as "scope max/window" pairs for the audit log.
+func describeLimits(limits []RateLimitPolicyLimit) string {
+	parts := make([]string, 0, len(limits))
+	for _, limit := range limits {
+		parts = append(parts, fmt.Sprintf("%s %d/%s", limit.Scope, limit.Max, limit.Window))
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// WarmCache looks up the most accessed codes through the repository, so every
+// cache layer that populates on a miss holds them again, e.g. after a flush.
+// Codes that no longer exist and lookups that fail are counted and skipped.
+// The lookups are marked as warm-ups so they stay out of client lookup metrics.
+func (h *AdminHandler) WarmCache(ctx context.Context, req *WarmCacheRequest) (*WarmCacheResponse, error) {
+	if h.analytics == nil {
+		return nil, huma.Error501NotImplemented("cache warming requires an analytics store")
+	}
+
+	top, err := h.analytics.TopCodes(ctx, req.Since, req.Count)
+	if err != nil {
+		logging.FromContext(ctx, h.logger).Error("failed to load top codes", zap.Error(err))
+
+		return nil, huma.Error500InternalServerError("failed to load top codes")
+	}
+
+	resp := &WarmCacheResponse{}
+	resp.Body.Codes = make([]string, 0, len(top))
+
+	warmCtx := shortener.WithWarmUp(ctx)
+
+	for _, entry := range top {
+		_, err = h.store.GetByCode(warmCtx, shortener.Code(entry.Code))
+
+		switch {
+		case err == nil:
+			resp.Body.Warmed++
+			resp.Body.Codes = append(resp.Body.Codes, entry.Code)
+		case errors.Is(err, shortener.ErrNotFound):
+			resp.Body.Missing++
+		default:
+			resp.Body.Failed++
+
+			logging.FromContext(ctx, h.logger).Warn("failed to warm cached url",
+				zap.String("code", entry.Code),
+				zap.Error(err),
+			)
+		}
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,392 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/adapters/humachi"
+	"github.com/go-chi/chi/v5"
+	"github.com/serroba/web-demo-go/internal/analytics"
+	"github.com/serroba/web-demo-go/internal/cache"
+	"github.com/serroba/web-demo-go/internal/handlers"
+	"github.com/serroba/web-demo-go/internal/middleware"
+	"github.com/serroba/web-demo-go/internal/ratelimit"
+	ratelimitstore "github.com/serroba/web-demo-go/internal/ratelimit/store"
+	"github.com/serroba/web-demo-go/internal/shortener"
+	"github.com/serroba/web-demo-go/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestAdminHandler_CountURLs(t *testing.T) {
+	t.Run("returns total url count", func(t *testing.T) {
+		memStore := store.NewMemoryStore()
+		_ = memStore.Save(context.Background(), &shortener.ShortURL{Code: "abc123", OriginalURL: testURL})
+		_ = memStore.Save(context.Background(), &shortener.ShortURL{Code: "def456", OriginalURL: testURL})
+		handler := handlers.NewAdminHandler(memStore, nil, zap.NewNop())
+
+		resp, err := handler.CountURLs(context.Background(), nil)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), resp.Body.Count)
+	})
+
+	t.Run("returns 500 when count fails", func(t *testing.T) {
+		handler := handlers.NewAdminHandler(&mockStore{countErr: errMock}, nil, zap.NewNop())
+
+		resp, err := handler.CountURLs(context.Background(), nil)
+
+		assert.Nil(t, resp)
+
+		var statusErr huma.StatusError
+		require.ErrorAs(t, err, &statusErr)
+		assert.Equal(t, http.StatusInternalServerError, statusErr.GetStatus())
+	})
+}
+
+func TestAdminHandler_InspectRateLimit(t *testing.T) {
+	policy := ratelimit.NewPolicyBuilder().
+		AddLimit(ratelimit.ScopeWrite, 10, time.Minute).
+		Build()
+
+	t.Run("reports bucket counts for a client key", func(t *testing.T) {
+		rlStore := ratelimitstore.NewMemory()
+		_, _ = rlStore.Record(context.Background(), "client:write:60000", time.Minute)
+		_, _ = rlStore.Record(context.Background(), "client:write:60000", time.Minute)
+		limiter := ratelimit.NewPolicyLimiter(rlStore, policy)
+		handler := handlers.NewAdminHandler(store.NewMemoryStore(), limiter, zap.NewNop())
+
+		resp, err := handler.InspectRateLimit(context.Background(), &handlers.InspectRateLimitRequest{Key: "client"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "client", resp.Body.Key)
+		assert.Equal(t, []handlers.RateLimitBucket{
+			{Scope: "write", Window: "1m0s", Max: 10, Count: 2, Key: "client:write:60000"},
+		}, resp.Body.Buckets)
+	})
+
+	t.Run("returns 500 when the store fails", func(t *testing.T) {
+		limiter := ratelimit.NewPolicyLimiter(&failingRateLimitStore{}, policy)
+		handler := handlers.NewAdminHandler(store.NewMemoryStore(), limiter, zap.NewNop())
+
+		resp, err := handler.InspectRateLimit(context.Background(), &handlers.InspectRateLimitRequest{Key: "client"})
+
+		assert.Nil(t, resp)
+
+		var statusErr huma.StatusError
+		require.ErrorAs(t, err, &statusErr)
+		assert.Equal(t, http.StatusInternalServerError, statusErr.GetStatus())
+	})
+}
+
+func rateLimitPolicyRequest(limits ...handlers.RateLimitPolicyLimit) *handlers.UpdateRateLimitPolicyRequest {
+	req := &handlers.UpdateRateLimitPolicyRequest{}
+	req.Body.Limits = limits
+
+	return req
+}
+
+func TestAdminHandler_UpdateRateLimitPolicy(t *testing.T) {
+	initial := ratelimit.NewPolicyBuilder().AddLimit(ratelimit.ScopeWrite, 10, time.Minute).Build()
+
+	t.Run("swaps the limiter policy and reports it", func(t *testing.T) {
+		limiter := ratelimit.NewPolicyLimiter(ratelimitstore.NewMemory(), initial)
+		recorder := &auditRecorder{}
+		handler := handlers.NewAdminHandler(store.NewMemoryStore(), limiter, zap.NewNop(),
+			handlers.WithAdminAudit(recorder))
+
+		resp, err := handler.UpdateRateLimitPolicy(context.Background(), rateLimitPolicyRequest(
+			handlers.RateLimitPolicyLimit{Scope: "write", Window: "1m", Max: 5},
+			handlers.RateLimitPolicyLimit{Scope: "global", Window: "24h", Max: 1000},
+			handlers.RateLimitPolicyLimit{Scope: "write", Window: "1h", Max: 50},
+			handlers.RateLimitPolicyLimit{Scope: "pro:write", Window: "1m", Max: 50},
+		))
+
+		require.NoError(t, err)
+		assert.Equal(t, []handlers.RateLimitPolicyLimit{
+			{Scope: "global", Window: "24h0m0s", Max: 1000},
+			{Scope: "pro:write", Window: "1m0s", Max: 50},
+			{Scope: "write", Window: "1m0s", Max: 5},
+			{Scope: "write", Window: "1h0m0s", Max: 50},
+		}, resp.Body.Limits)
+		assert.Equal(t, map[ratelimit.Scope][]ratelimit.LimitConfig{
+			ratelimit.ScopeGlobal: {{Window: 24 * time.Hour, Max: 1000}},
+			ratelimit.ScopeWrite:  {{Window: time.Minute, Max: 5}, {Window: time.Hour, Max: 50}},
+			"pro:write":           {{Window: time.Minute, Max: 50}},
+		}, limiter.Policy().Limits)
+
+		require.Len(t, recorder.entries, 1)
+		assert.Equal(t, handlers.AuditActionUpdateRateLimitPolicy, recorder.entries[0].Action)
+		assert.Equal(t, "global 1000/24h0m0s, pro:write 50/1m0s, write 5/1m0s, write 50/1h0m0s",
+			recorder.entries[0].Detail)
+	})
+
+	t.Run("rejects invalid policies and keeps the current one", func(t *testing.T) {
+		tests := []struct {
+			name  string
+			limit handlers.RateLimitPolicyLimit
+		}{
+			{name: "unparseable window", limit: handlers.RateLimitPolicyLimit{Scope: "read", Window: "soon", Max: 5}},
+			{name: "zero window", limit: handlers.RateLimitPolicyLimit{Scope: "read", Window: "0s", Max: 5}},
+			{name: "unknown scope", limit: handlers.RateLimitPolicyLimit{Scope: "admin", Window: "1m", Max: 5}},
+			{name: "tier of an unknown scope", limit: handlers.RateLimitPolicyLimit{Scope: "pro:admin", Window: "1m", Max: 5}},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				limiter := ratelimit.NewPolicyLimiter(ratelimitstore.NewMemory(), initial)
+				recorder := &auditRecorder{}
+				handler := handlers.NewAdminHandler(store.NewMemoryStore(), limiter, zap.NewNop(),
+					handlers.WithAdminAudit(recorder))
+
+				resp, err := handler.UpdateRateLimitPolicy(context.Background(), rateLimitPolicyRequest(tt.limit))
+
+				assert.Nil(t, resp)
+
+				var statusErr huma.StatusError
+				require.ErrorAs(t, err, &statusErr)
+				assert.Equal(t, http.StatusBadRequest, statusErr.GetStatus())
+				assert.Same(t, initial, limiter.Policy())
+				assert.Empty(t, recorder.entries)
+			})
+		}
+	})
+}
+
+func TestRegisterAdminRoutes_RateLimitPolicyReload(t *testing.T) {
+	limiter := ratelimit.NewPolicyLimiter(ratelimitstore.NewMemory(), ratelimit.NewPolicyBuilder().
+		AddLimit(ratelimit.ScopeRead, 1, time.Minute).
+		AddLimit(ratelimit.ScopeWrite, 100, time.Minute).
+		Build())
+
+	router := chi.NewMux()
+	api := humachi.New(router, huma.DefaultConfig("Test", "1.0.0"))
+	api.UseMiddleware(middleware.PolicyRateLimiter(api, limiter, ratelimit.NewMethodScopeResolver(), zap.NewNop()))
+	handlers.RegisterAdminRoutes(api, handlers.NewAdminHandler(store.NewMemoryStore(), limiter, zap.NewNop()))
+
+	count := func() int {
+		req := httptest.NewRequest(http.MethodGet, "/admin/urls/count", nil)
+		req.Header.Set("User-Agent", "test")
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		return rec.Code
+	}
+
+	reload := func(body string) {
+		req := httptest.NewRequest(http.MethodPut, "/admin/ratelimit/policy", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", "test")
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	}
+
+	assert.Equal(t, http.StatusOK, count())
+	assert.Equal(t, http.StatusTooManyRequests, count())
+
+	reload(`{"limits":[{"scope":"read","window":"1m","max":4},{"scope":"write","window":"1m","max":100}]}`)
+
+	// Two reads are already counted in the shared minute bucket
+	assert.Equal(t, http.StatusOK, count())
+	assert.Equal(t, http.StatusOK, count())
+	assert.Equal(t, http.StatusTooManyRequests, count())
+
+	reload(`{"limits":[{"scope":"read","window":"1h","max":1},{"scope":"write","window":"1m","max":100}]}`)
+
+	// A new window starts a fresh bucket
+	assert.Equal(t, http.StatusOK, count())
+	assert.Equal(t, http.StatusTooManyRequests, count())
+
+	t.Run("schema rejects an empty policy", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPut, "/admin/ratelimit/policy", strings.NewReader(`{"limits":[]}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", "test")
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	})
+}
+
+func TestRegisterAdminRoutes_RateLimitPolicyReloadTiers(t *testing.T) {
+	limiter := ratelimit.NewPolicyLimiter(ratelimitstore.NewMemory(), ratelimit.NewPolicyBuilder().
+		AddLimit(ratelimit.ScopeRead, 1, time.Minute).
+		AddLimit(ratelimit.ScopeWrite, 100, time.Minute).
+		Build())
+	resolver := ratelimit.NewTieredPolicyResolver(ratelimit.NewMethodScopeResolver(), limiter)
+
+	router := chi.NewMux()
+	api := humachi.New(router, huma.DefaultConfig("Test", "1.0.0"))
+	api.UseMiddleware(middleware.APIKeyAuth(api, []middleware.APIKey{
+		{Name: "paid", Secret: "pro-secret", Tier: "pro"},
+		{Name: "trial", Secret: "free-secret", Tier: "free"},
+	}))
+	api.UseMiddleware(middleware.PolicyRateLimiter(api, limiter, resolver, zap.NewNop()))
+	handlers.RegisterAdminRoutes(api, handlers.NewAdminHandler(store.NewMemoryStore(), limiter, zap.NewNop()))
+
+	count := func(key string) int {
+		req := httptest.NewRequest(http.MethodGet, "/admin/urls/count", nil)
+		req.Header.Set("User-Agent", "test")
+		req.Header.Set(middleware.APIKeyHeader, key)
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		return rec.Code
+	}
+
+	req := httptest.NewRequest(http.MethodPut, "/admin/ratelimit/policy", strings.NewReader(
+		`{"limits":[{"scope":"read","window":"1m","max":1},{"scope":"pro:read","window":"1m","max":3},`+
+			`{"scope":"write","window":"1m","max":100}]}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "test")
+	req.Header.Set(middleware.APIKeyHeader, "pro-secret")
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Contains(t, rec.Body.String(), `"scope":"pro:read"`)
+
+	// The free tier has no read limits of its own and keeps the base limit
+	assert.Equal(t, http.StatusOK, count("free-secret"))
+	assert.Equal(t, http.StatusTooManyRequests, count("free-secret"))
+
+	for range 3 {
+		assert.Equal(t, http.StatusOK, count("pro-secret"))
+	}
+
+	assert.Equal(t, http.StatusTooManyRequests, count("pro-secret"))
+}
+
+func TestAdminHandler_WarmCache(t *testing.T) {
+	t.Run("loads the most accessed codes into the cache", func(t *testing.T) {
+		memStore := store.NewMemoryStore()
+		require.NoError(t, memStore.Save(context.Background(), &shortener.ShortURL{Code: "hot1", OriginalURL: testURL}))
+		require.NoError(t, memStore.Save(context.Background(), &shortener.ShortURL{Code: "hot2", OriginalURL: testURL}))
+		require.NoError(t, memStore.Save(context.Background(), &shortener.ShortURL{Code: "cold", OriginalURL: testURL}))
+
+		// Saved before wrapping, so the cache starts empty as after a flush
+		lru := cache.New(10)
+		cached := store.NewCachedRepository(memStore, lru)
+
+		var gotLimit int
+
+		analyticsStore := &mockAnalyticsStore{
+			topCodesFunc: func(_ context.Context, _ time.Time, limit int) ([]analytics.CodeCount, error) {
+				gotLimit = limit
+
+				return []analytics.CodeCount{{Code: "hot2", Count: 9}, {Code: "gone", Count: 5}, {Code: "hot1", Count: 3}}, nil
+			},
+		}
+		handler := handlers.NewAdminHandler(cached, nil, zap.NewNop(), handlers.WithAdminAnalytics(analyticsStore))
+
+		resp, err := handler.WarmCache(context.Background(), &handlers.WarmCacheRequest{Count: 3})
+
+		require.NoError(t, err)
+		assert.Equal(t, 3, gotLimit)
+		assert.Equal(t, 2, resp.Body.Warmed)
+		assert.Equal(t, 1, resp.Body.Missing)
+		assert.Zero(t, resp.Body.Failed)
+		assert.Equal(t, []string{"hot2", "hot1"}, resp.Body.Codes)
+
+		for _, code := range []string{"hot1", "hot2"} {
+			cachedURL, ok := lru.Get(code)
+			require.True(t, ok, code)
+			assert.Equal(t, testURL, cachedURL.OriginalURL)
+		}
+
+		_, ok := lru.Get("cold")
+		assert.False(t, ok)
+	})
+
+	t.Run("keeps warm-up lookups out of client lookup metrics", func(t *testing.T) {
+		recorder := &operationRecorder{}
+		repo := store.NewInstrumentedRepository(store.NewMemoryStore(), recorder)
+
+		analyticsStore := &mockAnalyticsStore{
+			topCodesFunc: func(context.Context, time.Time, int) ([]analytics.CodeCount, error) {
+				return []analytics.CodeCount{{Code: "gone", Count: 5}}, nil
+			},
+		}
+		handler := handlers.NewAdminHandler(repo, nil, zap.NewNop(), handlers.WithAdminAnalytics(analyticsStore))
+
+		resp, err := handler.WarmCache(context.Background(), &handlers.WarmCacheRequest{Count: 10})
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, resp.Body.Missing)
+		assert.Equal(t, []string{"warm_by_code"}, recorder.operations)
+	})
+
+	t.Run("counts failed lookups and keeps going", func(t *testing.T) {
+		analyticsStore := &mockAnalyticsStore{
+			topCodesFunc: func(context.Context, time.Time, int) ([]analytics.CodeCount, error) {
+				return []analytics.CodeCount{{Code: "abc123", Count: 1}}, nil
+			},
+		}
+		handler := handlers.NewAdminHandler(&mockStore{getByCodeErr: errMock}, nil, zap.NewNop(),
+			handlers.WithAdminAnalytics(analyticsStore))
+
+		resp, err := handler.WarmCache(context.Background(), &handlers.WarmCacheRequest{Count: 10})
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, resp.Body.Failed)
+		assert.Empty(t, resp.Body.Codes)
+	})
+
+	t.Run("returns 500 when top codes fail", func(t *testing.T) {
+		analyticsStore := &mockAnalyticsStore{
+			topCodesFunc: func(context.Context, time.Time, int) ([]analytics.CodeCount, error) {
+				return nil, errMock
+			},
+		}
+		handler := handlers.NewAdminHandler(store.NewMemoryStore(), nil, zap.NewNop(),
+			handlers.WithAdminAnalytics(analyticsStore))
+
+		resp, err := handler.WarmCache(context.Background(), &handlers.WarmCacheRequest{Count: 10})
+
+		assert.Nil(t, resp)
+
+		var statusErr huma.StatusError
+		require.ErrorAs(t, err, &statusErr)
+		assert.Equal(t, http.StatusInternalServerError, statusErr.GetStatus())
+	})
+
+	t.Run("returns 501 without an analytics store", func(t *testing.T) {
+		handler := handlers.NewAdminHandler(store.NewMemoryStore(), nil, zap.NewNop())
+
+		_, err := handler.WarmCache(context.Background(), &handlers.WarmCacheRequest{Count: 10})
+
+		var statusErr huma.StatusError
+		require.ErrorAs(t, err, &statusErr)
+		assert.Equal(t, http.StatusNotImplemented, statusErr.GetStatus())
+	})
+}
+
+// operationRecorder collects the repository operations it observes.
+type operationRecorder struct {
+	operations []string
+}
+
+func (r *operationRecorder) ObserveOperation(operation string, _ store.Outcome, _ time.Duration) {
+	r.operations = append(r.operations, operation)
+}
+
+type failingRateLimitStore struct{}
+
+func (failingRateLimitStore) Record(_ context.Context, _ string, _ time.Duration) (int64, error) {
+	return 0, errMock
+}
+
+func (failingRateLimitStore) Peek(_ context.Context, _ string, _ time.Duration) (int64, error) {
+	return 0, errMock
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"net/url"
+	"strings"
+)
+
+// resolveBaseURL returns the base URL for building short URLs, honoring the
+// X-Forwarded-Proto and X-Forwarded-Host values captured in the request metadata.
+// The configured base URL is returned unchanged when no forwarded values are present.
+func resolveBaseURL(baseURL string, meta RequestMeta) string {
+	proto := firstForwardedValue(meta.ForwardedProto)
+	host := firstForwardedValue(meta.ForwardedHost)
+
+	if proto == "" && host == "" {
+		return baseURL
+	}
+
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return baseURL
+	}
+
+	// Only accept known schemes to avoid reflecting arbitrary header values
+	switch strings.ToLower(proto) {
+	case "http", "https":
+		u.Scheme = strings.ToLower(proto)
+	}
+
+	if host != "" {
+		u.Host = host
+	}
+
+	return strings.TrimSuffix(u.String(), "/")
+}
+
+// firstForwardedValue returns the first entry of a comma-separated forwarded header.
+func firstForwardedValue(v string) string {
+	if idx := strings.Index(v, ","); idx != -1 {
+		v = v[:idx]
+	}
+
+	return strings.TrimSpace(v)
+}
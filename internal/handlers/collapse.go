@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/serroba/web-demo-go/internal/shortener"
+)
+
+// maxCollapseHops bounds how many of our own short URLs are followed when
+// collapsing, so chains stored before collapsing was enabled cannot loop forever.
+const maxCollapseHops = 5
+
+// collapseSelfRedirects follows target while it is a short URL of this service and
+// returns the URL it finally points to. Unknown codes and flagged short URLs are
+// kept as given, so the latter still go through the flagged-URL fallback.
+func (h *URLHandler) collapseSelfRedirects(ctx context.Context, target string) (string, error) {
+	hosts := h.ownHosts(ctx)
+
+	for range maxCollapseHops {
+		code, ok := selfShortCode(target, hosts)
+		if !ok {
+			return target, nil
+		}
+
+		shortURL, err := h.store.GetByCode(ctx, h.normalizeCode(code))
+		if errors.Is(err, shortener.ErrNotFound) {
+			return target, nil
+		}
+
+		if err != nil {
+			return "", huma.Error500InternalServerError("failed to resolve short url")
+		}
+
+		if shortURL.Flagged {
+			return target, nil
+		}
+
+		target = shortURL.OriginalURL
+	}
+
+	// The last hop followed may have left this service
+	if _, ok := selfShortCode(target, hosts); !ok {
+		return target, nil
+	}
+
+	return "", huma.Error400BadRequest("url redirects through too many short urls")
+}
+
+// ownHosts returns the hosts short URLs are issued under: the configured base
+// URL and the forwarded host of the current request, if any.
+func (h *URLHandler) ownHosts(ctx context.Context) []string {
+	var hosts []string
+
+	for _, base := range []string{h.baseURL, resolveBaseURL(h.baseURL, RequestMetaFromContext(ctx))} {
+		if u, err := url.Parse(base); err == nil && u.Host != "" {
+			hosts = append(hosts, strings.ToLower(u.Host))
+		}
+	}
+
+	return hosts
+}
+
+// selfShortCode returns the code of target when it is a short URL on one of hosts.
+func selfShortCode(target string, hosts []string) (string, bool) {
+	u, err := url.Parse(target)
+	if err != nil || u.RawQuery != "" || u.Fragment != "" {
+		return "", false
+	}
+
+	if !slices.Contains(hosts, strings.ToLower(u.Host)) {
+		return "", false
+	}
+
+	code := strings.TrimPrefix(u.Path, "/")
+	if code == "" || strings.Contains(code, "/") {
+		return "", false
+	}
+
+	return code, true
+}
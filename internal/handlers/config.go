@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/serroba/web-demo-go/internal/i18n"
+)
+
+// NewAPIConfig returns the Huma configuration for the shortener API.
+// defaultContentType selects the response format used when a request has no
+// Accept header; it must be one of the registered formats (e.g. application/cbor).
+func NewAPIConfig(defaultContentType string) (huma.Config, error) {
+	config := huma.DefaultConfig("URL Shortener", "1.0.0")
+
+	if defaultContentType == "" {
+		return config, nil
+	}
+
+	if _, ok := config.Formats[defaultContentType]; !ok {
+		return huma.Config{}, fmt.Errorf("unsupported default content type %q", defaultContentType)
+	}
+
+	config.DefaultFormat = defaultContentType
+
+	return config, nil
+}
+
+// SupportedMediaTypes returns the sorted media types the configuration can
+// encode, skipping Huma's short format aliases such as "json".
+func SupportedMediaTypes(config huma.Config) []string {
+	mediaTypes := make([]string, 0, len(config.Formats))
+
+	for name := range config.Formats {
+		if strings.Contains(name, "/") {
+			mediaTypes = append(mediaTypes, name)
+		}
+	}
+
+	slices.Sort(mediaTypes)
+
+	return mediaTypes
+}
+
+// WithoutDocs returns config with the interactive docs, OpenAPI spec and
+// schema routes disabled, for deployments that should not expose them.
+func WithoutDocs(config huma.Config) huma.Config {
+	config.DocsPath = ""
+	config.OpenAPIPath = ""
+	config.SchemasPath = ""
+
+	return config
+}
+
+// WithLocalizedErrors returns config with a transformer that translates error
+// titles and details into the language negotiated from Accept-Language. It runs
+// for every error response, including those written by middleware, and falls
+// back to English for unsupported languages.
+func WithLocalizedErrors(config huma.Config) huma.Config {
+	config.Transformers = append(config.Transformers, localizeError)
+
+	return config
+}
+
+// Envelope wraps response bodies when WithEnvelopedResponses is set.
+type Envelope struct {
+	Data any          `json:"data"`
+	Meta EnvelopeMeta `json:"meta"`
+}
+
+// EnvelopeMeta carries details about the response an Envelope wraps.
+type EnvelopeMeta struct {
+	RequestID string `json:"requestId,omitempty"`
+}
+
+// WithEnvelopedResponses returns config with a transformer that wraps response
+// bodies in an Envelope, {"data": ..., "meta": ...}, for clients that expect
+// every response in the same shape. Errors keep the problem details format and
+// raw bodies such as QR images are written as they are. The OpenAPI schemas
+// still describe the unwrapped bodies.
+func WithEnvelopedResponses(config huma.Config) huma.Config {
+	config.Transformers = append(config.Transformers, envelopeResponse)
+
+	return config
+}
+
+func envelopeResponse(ctx huma.Context, _ string, v any) (any, error) {
+	if _, isError := v.(error); isError || v == nil {
+		return v, nil
+	}
+
+	return &Envelope{
+		Data: v,
+		Meta: EnvelopeMeta{RequestID: RequestMetaFromContext(ctx.Context()).RequestID},
+	}, nil
+}
+
+func localizeError(ctx huma.Context, _ string, v any) (any, error) {
+	model, ok := v.(*huma.ErrorModel)
+	if !ok {
+		return v, nil
+	}
+
+	lang := i18n.Negotiate(ctx.Header("Accept-Language"))
+	ctx.SetHeader("Content-Language", lang)
+
+	if lang == i18n.DefaultLanguage {
+		return v, nil
+	}
+
+	localized := *model
+	localized.Title = i18n.Translate(lang, model.Title)
+	localized.Detail = i18n.Translate(lang, model.Detail)
+
+	return &localized, nil
+}
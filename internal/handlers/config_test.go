@@ -0,0 +1,226 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/adapters/humachi"
+	_ "github.com/danielgtaylor/huma/v2/formats/cbor" // CBOR format support for huma
+	"github.com/go-chi/chi/v5"
+	"github.com/serroba/web-demo-go/internal/handlers"
+	"github.com/serroba/web-demo-go/internal/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type pingOutput struct {
+	Body struct {
+		Message string `json:"message"`
+	}
+}
+
+func serveWithConfig(t *testing.T, config huma.Config, accept string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	router := chi.NewMux()
+	api := humachi.New(router, config)
+
+	huma.Get(api, "/ping", func(_ context.Context, _ *struct{}) (*pingOutput, error) {
+		out := &pingOutput{}
+		out.Body.Message = "pong"
+
+		return out, nil
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	return w
+}
+
+func TestNewAPIConfig(t *testing.T) {
+	t.Run("defaults to json without accept header", func(t *testing.T) {
+		config, err := handlers.NewAPIConfig("")
+		require.NoError(t, err)
+
+		w := serveWithConfig(t, config, "")
+
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	})
+
+	t.Run("uses configured cbor default without accept header", func(t *testing.T) {
+		config, err := handlers.NewAPIConfig("application/cbor")
+		require.NoError(t, err)
+
+		w := serveWithConfig(t, config, "")
+
+		assert.Equal(t, "application/cbor", w.Header().Get("Content-Type"))
+	})
+
+	t.Run("accept header still wins over default", func(t *testing.T) {
+		config, err := handlers.NewAPIConfig("application/cbor")
+		require.NoError(t, err)
+
+		w := serveWithConfig(t, config, "application/json")
+
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	})
+
+	t.Run("rejects unknown content type", func(t *testing.T) {
+		_, err := handlers.NewAPIConfig("application/xml")
+
+		require.Error(t, err)
+	})
+}
+
+func TestSupportedMediaTypes(t *testing.T) {
+	config, err := handlers.NewAPIConfig("")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"application/cbor", "application/json"}, handlers.SupportedMediaTypes(config))
+}
+
+func TestWithoutDocs(t *testing.T) {
+	get := func(t *testing.T, config huma.Config, path string) int {
+		t.Helper()
+
+		router := chi.NewMux()
+		humachi.New(router, config)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+
+		return w.Code
+	}
+
+	config, err := handlers.NewAPIConfig("")
+	require.NoError(t, err)
+
+	for _, path := range []string{"/docs", "/openapi.json"} {
+		t.Run(path+" reachable by default", func(t *testing.T) {
+			assert.Equal(t, http.StatusOK, get(t, config, path))
+		})
+
+		t.Run(path+" unreachable when disabled", func(t *testing.T) {
+			assert.Equal(t, http.StatusNotFound, get(t, handlers.WithoutDocs(config), path))
+		})
+	}
+}
+
+func TestWithLocalizedErrors(t *testing.T) {
+	config, err := handlers.NewAPIConfig("")
+	require.NoError(t, err)
+
+	router := chi.NewMux()
+	api := humachi.New(router, handlers.WithLocalizedErrors(config))
+	huma.Get(api, "/missing", func(_ context.Context, _ *struct{}) (*pingOutput, error) {
+		return nil, huma.Error404NotFound("short url not found")
+	})
+
+	get := func(acceptLanguage string) (*httptest.ResponseRecorder, huma.ErrorModel) {
+		req := httptest.NewRequest(http.MethodGet, "/missing", nil)
+		if acceptLanguage != "" {
+			req.Header.Set("Accept-Language", acceptLanguage)
+		}
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		var body huma.ErrorModel
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+
+		return w, body
+	}
+
+	t.Run("translates into a supported language", func(t *testing.T) {
+		w, body := get("es-ES,es;q=0.9,en;q=0.5")
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, "es", w.Header().Get("Content-Language"))
+		assert.Equal(t, "No encontrado", body.Title)
+		assert.Equal(t, "URL corta no encontrada", body.Detail)
+	})
+
+	for name, acceptLanguage := range map[string]string{
+		"falls back to English without a header":    "",
+		"falls back to English for other languages": "fr-FR, ja;q=0.8",
+	} {
+		t.Run(name, func(t *testing.T) {
+			w, body := get(acceptLanguage)
+
+			assert.Equal(t, http.StatusNotFound, w.Code)
+			assert.Equal(t, "en", w.Header().Get("Content-Language"))
+			assert.Equal(t, "Not Found", body.Title)
+			assert.Equal(t, "short url not found", body.Detail)
+		})
+	}
+}
+
+func TestWithEnvelopedResponses(t *testing.T) {
+	serve := func(t *testing.T, config huma.Config, path string) (*httptest.ResponseRecorder, map[string]any) {
+		t.Helper()
+
+		router := chi.NewMux()
+		api := humachi.New(router, config)
+		api.UseMiddleware(middleware.RequestID(api, zap.NewNop()))
+
+		huma.Get(api, "/ping", func(_ context.Context, _ *struct{}) (*pingOutput, error) {
+			out := &pingOutput{}
+			out.Body.Message = "pong"
+
+			return out, nil
+		})
+		huma.Get(api, "/missing", func(_ context.Context, _ *struct{}) (*pingOutput, error) {
+			return nil, huma.Error404NotFound("short url not found")
+		})
+
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set(middleware.RequestIDHeader, "req-123")
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		var body map[string]any
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+
+		return w, body
+	}
+
+	config, err := handlers.NewAPIConfig("")
+	require.NoError(t, err)
+
+	t.Run("bodies are flat by default", func(t *testing.T) {
+		_, body := serve(t, config, "/ping")
+
+		assert.Equal(t, "pong", body["message"])
+		assert.NotContains(t, body, "data")
+	})
+
+	t.Run("bodies are wrapped when enabled", func(t *testing.T) {
+		w, body := serve(t, handlers.WithEnvelopedResponses(config), "/ping")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		require.Contains(t, body, "data")
+		require.Contains(t, body, "meta")
+		assert.Equal(t, "pong", body["data"].(map[string]any)["message"])
+		assert.Equal(t, map[string]any{"requestId": "req-123"}, body["meta"])
+	})
+
+	t.Run("errors keep the problem details format", func(t *testing.T) {
+		w, body := serve(t, handlers.WithEnvelopedResponses(config), "/missing")
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, "short url not found", body["detail"])
+		assert.NotContains(t, body, "data")
+	})
+}
@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"cmp"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/serroba/web-demo-go/internal/logging"
+	"github.com/serroba/web-demo-go/internal/shortener"
+	"go.uber.org/zap"
+)
+
+// Import row statuses reported by ImportURLs.
+const (
+	ImportStatusImported = "imported"
+	ImportStatusSkipped  = "skipped"
+)
+
+// ImportURLs stores short URLs from an uploaded CSV with code,url[,created_at] rows.
+// Malformed rows and codes already in use are skipped and reported; the rest are
+// saved in a single batch.
+func (h *AdminHandler) ImportURLs(ctx context.Context, req *ImportURLsRequest) (*ImportURLsResponse, error) {
+	file := req.RawBody.Data().File
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	resp := &ImportURLsResponse{}
+	resp.Body.Rows = []ImportRowResult{}
+
+	var (
+		batch     []*shortener.ShortURL
+		batchRows []int
+		seen      = make(map[shortener.Code]bool)
+		now       = h.clock.Now()
+	)
+
+	for row := 1; ; row++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			resp.Body.Rows = append(resp.Body.Rows, skippedRow(row, "", parseErr.Err.Error()))
+
+			continue
+		}
+
+		if err != nil {
+			return nil, huma.Error400BadRequest("failed to read csv", err)
+		}
+
+		if row == 1 && isImportHeader(record) {
+			continue
+		}
+
+		shortURL, err := parseImportRecord(record, now)
+		if err != nil {
+			resp.Body.Rows = append(resp.Body.Rows, skippedRow(row, firstField(record), err.Error()))
+
+			continue
+		}
+
+		shortURL.Code = h.normalizeCode(string(shortURL.Code))
+
+		if seen[shortURL.Code] {
+			resp.Body.Rows = append(resp.Body.Rows, skippedRow(row, string(shortURL.Code), "duplicate code in file"))
+
+			continue
+		}
+
+		seen[shortURL.Code] = true
+		batch = append(batch, shortURL)
+		batchRows = append(batchRows, row)
+	}
+
+	inserted := make(map[shortener.Code]bool, len(batch))
+
+	if len(batch) > 0 {
+		codes, err := h.store.SaveBatch(ctx, batch)
+		if err != nil {
+			logging.FromContext(ctx, h.logger).Error("failed to save imported urls", zap.Error(err))
+
+			return nil, huma.Error500InternalServerError("failed to import urls")
+		}
+
+		for _, code := range codes {
+			inserted[code] = true
+		}
+	}
+
+	for i, shortURL := range batch {
+		if !inserted[shortURL.Code] {
+			resp.Body.Rows = append(resp.Body.Rows, skippedRow(batchRows[i], string(shortURL.Code), "code already in use"))
+
+			continue
+		}
+
+		resp.Body.Rows = append(resp.Body.Rows, ImportRowResult{
+			Row:    batchRows[i],
+			Code:   string(shortURL.Code),
+			Status: ImportStatusImported,
+		})
+	}
+
+	slices.SortFunc(resp.Body.Rows, func(a, b ImportRowResult) int {
+		return cmp.Compare(a.Row, b.Row)
+	})
+
+	resp.Body.Imported = len(inserted)
+	resp.Body.Skipped = len(resp.Body.Rows) - len(inserted)
+
+	h.audit.Record(ctx, auditEntry(ctx, AuditActionImportURLs, req.RawBody.Data().File.Filename,
+		fmt.Sprintf("imported=%d skipped=%d", resp.Body.Imported, resp.Body.Skipped)))
+
+	return resp, nil
+}
+
+// parseImportRecord validates a code,url[,created_at] record. Records without
+// a created_at are stamped with now.
+func parseImportRecord(record []string, now time.Time) (*shortener.ShortURL, error) {
+	if len(record) < 2 || len(record) > 3 {
+		return nil, fmt.Errorf("expected code,url[,created_at] but got %d fields", len(record))
+	}
+
+	code := strings.TrimSpace(record[0])
+	if err := shortener.ValidateCode(code); err != nil {
+		return nil, err
+	}
+
+	originalURL := strings.TrimSpace(record[1])
+
+	parsed, err := url.ParseRequestURI(originalURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, errors.New("url must be an absolute http or https URL")
+	}
+
+	createdAt := now
+
+	if len(record) == 3 && strings.TrimSpace(record[2]) != "" {
+		createdAt, err = time.Parse(time.RFC3339, strings.TrimSpace(record[2]))
+		if err != nil {
+			return nil, errors.New("created_at must be an RFC 3339 timestamp")
+		}
+	}
+
+	return &shortener.ShortURL{
+		Code:        shortener.Code(code),
+		OriginalURL: originalURL,
+		CreatedAt:   createdAt,
+	}, nil
+}
+
+// isImportHeader reports whether record is a code,url[,created_at] header row.
+func isImportHeader(record []string) bool {
+	return len(record) >= 2 &&
+		strings.EqualFold(strings.TrimSpace(record[0]), "code") &&
+		strings.EqualFold(strings.TrimSpace(record[1]), "url")
+}
+
+func firstField(record []string) string {
+	if len(record) == 0 {
+		return ""
+	}
+
+	return strings.TrimSpace(record[0])
+}
+
+func skippedRow(row int, code, reason string) ImportRowResult {
+	return ImportRowResult{
+		Row:    row,
+		Code:   code,
+		Status: ImportStatusSkipped,
+		Error:  reason,
+	}
+}
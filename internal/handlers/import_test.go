@@ -0,0 +1,206 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/adapters/humachi"
+	"github.com/go-chi/chi/v5"
+	"github.com/serroba/web-demo-go/internal/clock"
+	"github.com/serroba/web-demo-go/internal/handlers"
+	"github.com/serroba/web-demo-go/internal/shortener"
+	"github.com/serroba/web-demo-go/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func uploadCSV(
+	t *testing.T,
+	repo shortener.Repository,
+	content string,
+	opts ...handlers.AdminHandlerOption,
+) *httptest.ResponseRecorder {
+	t.Helper()
+
+	router := chi.NewMux()
+	api := humachi.New(router, huma.DefaultConfig("Test", "1.0.0"))
+	handlers.RegisterAdminRoutes(api, handlers.NewAdminHandler(repo, nil, zap.NewNop(), opts...))
+
+	var body bytes.Buffer
+
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", "urls.csv")
+	require.NoError(t, err)
+
+	_, err = part.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/import", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func TestAdminHandler_ImportURLs_Audit(t *testing.T) {
+	recorder := &auditRecorder{}
+
+	rec := uploadCSV(t, store.NewMemoryStore(), "abc123,https://example.com/a\nbad code,https://example.com/b\n",
+		handlers.WithAdminAudit(recorder))
+
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.Len(t, recorder.entries, 1)
+	assert.Equal(t, handlers.AuditActionImportURLs, recorder.entries[0].Action)
+	assert.Equal(t, "urls.csv", recorder.entries[0].Target)
+	assert.Equal(t, "imported=1 skipped=1", recorder.entries[0].Detail)
+}
+
+func TestAdminHandler_ImportURLs(t *testing.T) {
+	t.Run("stores valid rows and reports skipped ones", func(t *testing.T) {
+		memStore := store.NewMemoryStore()
+		_ = memStore.Save(context.Background(), &shortener.ShortURL{Code: "taken", OriginalURL: testURL})
+
+		rec := uploadCSV(t, memStore, "code,url,created_at\n"+
+			"abc123,https://example.com/a,2024-01-02T03:04:05Z\n"+
+			"def456,https://example.com/b\n"+
+			"bad code,https://example.com/c\n"+
+			"ghi789,not-a-url\n"+
+			"taken,https://example.com/d\n"+
+			"abc123,https://example.com/e\n"+
+			"onlycode\n"+
+			"a\"b,https://example.com/f\n")
+
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		var body struct {
+			Imported int                        `json:"imported"`
+			Skipped  int                        `json:"skipped"`
+			Rows     []handlers.ImportRowResult `json:"rows"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+
+		assert.Equal(t, 2, body.Imported)
+		assert.Equal(t, 6, body.Skipped)
+		require.Len(t, body.Rows, 8)
+
+		statuses := make(map[int]string, len(body.Rows))
+		for _, row := range body.Rows {
+			statuses[row.Row] = row.Status
+
+			if row.Status == handlers.ImportStatusSkipped {
+				assert.NotEmpty(t, row.Error, "row %d", row.Row)
+			}
+		}
+
+		assert.Equal(t, map[int]string{
+			2: handlers.ImportStatusImported,
+			3: handlers.ImportStatusImported,
+			4: handlers.ImportStatusSkipped,
+			5: handlers.ImportStatusSkipped,
+			6: handlers.ImportStatusSkipped,
+			7: handlers.ImportStatusSkipped,
+			8: handlers.ImportStatusSkipped,
+			9: handlers.ImportStatusSkipped,
+		}, statuses)
+
+		got, err := memStore.GetByCode(context.Background(), "abc123")
+		require.NoError(t, err)
+		assert.Equal(t, "https://example.com/a", got.OriginalURL)
+		assert.Equal(t, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), got.CreatedAt.UTC())
+
+		got, err = memStore.GetByCode(context.Background(), "def456")
+		require.NoError(t, err)
+		assert.Equal(t, "https://example.com/b", got.OriginalURL)
+
+		got, err = memStore.GetByCode(context.Background(), "taken")
+		require.NoError(t, err)
+		assert.Equal(t, testURL, got.OriginalURL, "existing code must not be overwritten")
+	})
+
+	t.Run("returns 500 when the batch save fails", func(t *testing.T) {
+		rec := uploadCSV(t, &mockStore{saveBatchErr: errMock}, "abc123,https://example.com/a\n")
+
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+
+	t.Run("reports codes the batch did not insert as conflicts", func(t *testing.T) {
+		repo := &partialBatchStore{mockStore: &mockStore{}, taken: map[shortener.Code]bool{"def456": true}}
+
+		rec := uploadCSV(t, repo, "abc123,https://example.com/a\ndef456,https://example.com/b\n")
+
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		var body handlers.ImportURLsResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body.Body))
+
+		assert.Equal(t, 1, body.Body.Imported)
+		assert.Equal(t, 1, body.Body.Skipped)
+		assert.Equal(t, []handlers.ImportRowResult{
+			{Row: 1, Code: "abc123", Status: handlers.ImportStatusImported},
+			{Row: 2, Code: "def456", Status: handlers.ImportStatusSkipped, Error: "code already in use"},
+		}, body.Body.Rows)
+	})
+
+	t.Run("stamps rows without created_at using the handler clock", func(t *testing.T) {
+		memStore := store.NewMemoryStore()
+		now := time.Date(2025, 6, 7, 8, 9, 10, 0, time.UTC)
+
+		rec := uploadCSV(t, memStore, "abc123,https://example.com/a\n",
+			handlers.WithAdminClock(clock.NewFake(now)))
+
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		got, err := memStore.GetByCode(context.Background(), "abc123")
+		require.NoError(t, err)
+		assert.Equal(t, now, got.CreatedAt)
+	})
+
+	t.Run("lowercases codes when codes are case-insensitive", func(t *testing.T) {
+		memStore := store.NewMemoryStore()
+
+		rec := uploadCSV(t, memStore, "AbC123,https://example.com/a\nabc123,https://example.com/b\n",
+			handlers.WithAdminCaseInsensitiveCodes())
+
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		got, err := memStore.GetByCode(context.Background(), "abc123")
+		require.NoError(t, err)
+		assert.Equal(t, "https://example.com/a", got.OriginalURL)
+
+		var body handlers.ImportURLsResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body.Body))
+		assert.Equal(t, 1, body.Body.Imported)
+		assert.Equal(t, "duplicate code in file", body.Body.Rows[1].Error)
+	})
+}
+
+// partialBatchStore inserts every code in a batch except the taken ones, as a
+// store does when another writer claimed a code after the import was uploaded.
+type partialBatchStore struct {
+	*mockStore
+
+	taken map[shortener.Code]bool
+}
+
+func (s *partialBatchStore) SaveBatch(_ context.Context, shortURLs []*shortener.ShortURL) ([]shortener.Code, error) {
+	var inserted []shortener.Code
+
+	for _, shortURL := range shortURLs {
+		if !s.taken[shortURL.Code] {
+			inserted = append(inserted, shortURL.Code)
+		}
+	}
+
+	return inserted, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 
+	"github.com/serroba/web-demo-go/internal/audit"
 	"github.com/serroba/web-demo-go/internal/shortener"
 )
 
@@ -16,8 +17,21 @@ type mockStore struct {
 	saveErr         error
 	getByCodeErr    error
 	getByHashErr    error
+	countErr        error
+	saveBatchErr    error
+	count           int64
 	saved           *shortener.ShortURL
 	getByHashResult *shortener.ShortURL
+	recent          []*shortener.ShortURL
+	recentErr       error
+	recentLimit     int
+	updateErr       error
+	flagErr         error
+	flagged         map[shortener.Code]bool
+	existsErr       error
+	existsCodes     []shortener.Code
+	statsErr        error
+	accessCount     int64
 }
 
 func (m *mockStore) Save(_ context.Context, shortURL *shortener.ShortURL) error {
@@ -26,6 +40,20 @@ func (m *mockStore) Save(_ context.Context, shortURL *shortener.ShortURL) error
 	return m.saveErr
 }
 
+// SaveBatch reports every short URL as inserted.
+func (m *mockStore) SaveBatch(_ context.Context, shortURLs []*shortener.ShortURL) ([]shortener.Code, error) {
+	if m.saveBatchErr != nil {
+		return nil, m.saveBatchErr
+	}
+
+	codes := make([]shortener.Code, 0, len(shortURLs))
+	for _, shortURL := range shortURLs {
+		codes = append(codes, shortURL.Code)
+	}
+
+	return codes, nil
+}
+
 func (m *mockStore) GetByCode(_ context.Context, _ shortener.Code) (*shortener.ShortURL, error) {
 	if m.getByCodeErr != nil {
 		return nil, m.getByCodeErr
@@ -44,3 +72,71 @@ func (m *mockStore) GetByHash(_ context.Context, _ shortener.URLHash) (*shortene
 
 	return m.getByHashResult, nil
 }
+
+func (m *mockStore) GetWithStats(_ context.Context, code shortener.Code) (*shortener.ShortURLStats, error) {
+	if m.statsErr != nil {
+		return nil, m.statsErr
+	}
+
+	return &shortener.ShortURLStats{
+		ShortURL:    &shortener.ShortURL{Code: code, OriginalURL: testURL},
+		AccessCount: m.accessCount,
+	}, nil
+}
+
+// Exists reports the saved code as taken.
+func (m *mockStore) Exists(_ context.Context, codes []shortener.Code) (map[shortener.Code]bool, error) {
+	m.existsCodes = codes
+
+	if m.existsErr != nil {
+		return nil, m.existsErr
+	}
+
+	exists := make(map[shortener.Code]bool, len(codes))
+	for _, code := range codes {
+		exists[code] = m.saved != nil && m.saved.Code == code
+	}
+
+	return exists, nil
+}
+
+func (m *mockStore) Count(_ context.Context) (int64, error) {
+	return m.count, m.countErr
+}
+
+func (m *mockStore) MostRecent(_ context.Context, limit int) ([]*shortener.ShortURL, error) {
+	m.recentLimit = limit
+
+	return m.recent, m.recentErr
+}
+
+func (m *mockStore) ListByCreator(_ context.Context, _ string, _ int) ([]*shortener.ShortURL, error) {
+	return nil, nil
+}
+
+func (m *mockStore) UpdateTarget(_ context.Context, _ shortener.Code, _, _ string) error {
+	return m.updateErr
+}
+
+func (m *mockStore) Flag(_ context.Context, code shortener.Code, flagged bool) error {
+	if m.flagErr != nil {
+		return m.flagErr
+	}
+
+	if m.flagged == nil {
+		m.flagged = map[shortener.Code]bool{}
+	}
+
+	m.flagged[code] = flagged
+
+	return nil
+}
+
+// auditRecorder collects audit entries.
+type auditRecorder struct {
+	entries []audit.Entry
+}
+
+func (r *auditRecorder) Record(_ context.Context, entry audit.Entry) {
+	r.entries = append(r.entries, entry)
+}
@@ -28,6 +28,7 @@ func RegisterRoutes(api huma.API, urlHandler *URLHandler) {
 			},
 		},
 	}, urlHandler.CreateShortURL)
+	applyStrategyEnum(api, urlHandler)
 
 	// GET /{code} - Redirect to original URL
 	// Uses relaxed rate limits for high-traffic read operations
@@ -43,6 +44,238 @@ func RegisterRoutes(api huma.API, urlHandler *URLHandler) {
 					{Window: time.Minute, Max: 1000}, // 1000 per minute
 				},
 			},
+			GlobalThrottleMetadataKey: true,
 		},
 	}, urlHandler.RedirectToURL)
+
+	// GET /api/resolve/{code} - Resolve a code to its URL as cacheable JSON
+	// Shares the redirect limits since CDNs absorb most of the traffic
+	huma.Register(api, huma.Operation{
+		Method:      http.MethodGet,
+		Path:        "/api/resolve/{code}",
+		Summary:     "Resolve short code",
+		Description: "Returns the URL associated with the short code as JSON with long-lived caching headers. Does not record an access.",
+		Tags:        []string{"URLs"},
+		Metadata: map[string]any{
+			ratelimit.MetadataKey: ratelimit.EndpointConfig{
+				Limits: []ratelimit.LimitConfig{
+					{Window: time.Minute, Max: 1000}, // 1000 per minute
+				},
+			},
+		},
+	}, urlHandler.ResolveURL)
+
+	// GET /api/urls/recent - Most recently created short URLs
+	huma.Register(api, huma.Operation{
+		Method:      http.MethodGet,
+		Path:        "/api/urls/recent",
+		Summary:     "List recent short URLs",
+		Description: "Returns the most recently created short URLs, newest first. The limit defaults to 20 and may not exceed 100.",
+		Tags:        []string{"URLs"},
+		Metadata: map[string]any{
+			ratelimit.MetadataKey: ratelimit.EndpointConfig{
+				Scope: ratelimit.ScopeRead,
+			},
+		},
+	}, urlHandler.RecentURLs)
+
+	// GET /api/urls/mine - Short URLs created with the caller's API key
+	huma.Register(api, huma.Operation{
+		Method:      http.MethodGet,
+		Path:        "/api/urls/mine",
+		Summary:     "List my short URLs",
+		Description: "Returns the short URLs created with the API key sent in X-API-Key, newest first. The limit defaults to 20 and may not exceed 100.",
+		Tags:        []string{"URLs"},
+		Metadata: map[string]any{
+			ratelimit.MetadataKey: ratelimit.EndpointConfig{
+				Scope: ratelimit.ScopeRead,
+			},
+		},
+	}, urlHandler.MyURLs)
+
+	// POST /api/available - Check several candidate vanity codes at once
+	// Read-only despite the POST body, so it shares the read scope limits
+	huma.Register(api, huma.Operation{
+		Method:      http.MethodPost,
+		Path:        "/api/available",
+		Summary:     "Check code availability",
+		Description: "Reports for each candidate code whether it can be requested as a vanity alias, or why not (taken or invalid).",
+		Tags:        []string{"URLs"},
+		Metadata: map[string]any{
+			ratelimit.MetadataKey: ratelimit.EndpointConfig{
+				Scope: ratelimit.ScopeRead,
+			},
+		},
+	}, urlHandler.CheckAvailability)
+
+	// GET /api/urls/{code} - Short URL metadata with its access count
+	huma.Register(api, huma.Operation{
+		Method:      http.MethodGet,
+		Path:        "/api/urls/{code}",
+		Summary:     "Get short URL metadata",
+		Description: "Returns the short URL, its target and creation details, and how many redirects it has served.",
+		Tags:        []string{"URLs"},
+		Metadata: map[string]any{
+			ratelimit.MetadataKey: ratelimit.EndpointConfig{
+				Scope: ratelimit.ScopeRead,
+			},
+		},
+	}, urlHandler.URLMetadata)
+
+	// PUT /api/urls/{code} - Point a code at a new URL
+	// Requires the admin token since codes have no owners
+	huma.Register(api, huma.Operation{
+		Method:      http.MethodPut,
+		Path:        "/api/urls/{code}",
+		Summary:     "Update short URL target",
+		Description: "Points an existing short code at a new URL. The URL is normalized and hash-strategy codes are rehashed.",
+		Tags:        []string{"URLs"},
+		Metadata:    map[string]any{AdminMetadataKey: true},
+	}, urlHandler.UpdateTarget)
+
+	// PUT /api/urls/{code}/flag - Flag a code's target as bad
+	// Requires the admin token like target updates
+	huma.Register(api, huma.Operation{
+		Method:      http.MethodPut,
+		Path:        "/api/urls/{code}/flag",
+		Summary:     "Flag short URL target",
+		Description: "Flags or unflags the target of a short code. Flagged codes redirect to their fallback URL.",
+		Tags:        []string{"URLs"},
+		Metadata:    map[string]any{AdminMetadataKey: true},
+	}, urlHandler.FlagURL)
+}
+
+// RegisterStatsRoutes registers analytics statistics routes.
+func RegisterStatsRoutes(api huma.API, statsHandler *StatsHandler) {
+	// POST /stats/batch - Access counts for several codes
+	// Read-only despite the POST body, so it shares the read scope limits
+	huma.Register(api, huma.Operation{
+		Method:      http.MethodPost,
+		Path:        "/stats/batch",
+		Summary:     "Get access counts for multiple codes",
+		Description: "Returns the access count for each requested short code. Unknown codes report 0.",
+		Tags:        []string{"Stats"},
+		Metadata: map[string]any{
+			ratelimit.MetadataKey: ratelimit.EndpointConfig{
+				Scope: ratelimit.ScopeRead,
+			},
+		},
+	}, statsHandler.BatchStats)
+
+	// GET /stats/{code}/unique - Distinct visitors of a code
+	huma.Register(api, huma.Operation{
+		Method:      http.MethodGet,
+		Path:        "/stats/{code}/unique",
+		Summary:     "Get unique visitors",
+		Description: "Returns the number of distinct client IPs that accessed the short code, optionally since a given time.",
+		Tags:        []string{"Stats"},
+		Metadata: map[string]any{
+			ratelimit.MetadataKey: ratelimit.EndpointConfig{
+				Scope: ratelimit.ScopeRead,
+			},
+		},
+	}, statsHandler.UniqueVisitors)
+
+	// GET /stats/{code}/referrers - Accesses of a code by referrer host
+	huma.Register(api, huma.Operation{
+		Method:      http.MethodGet,
+		Path:        "/stats/{code}/referrers",
+		Summary:     "Get referrer breakdown",
+		Description: "Returns the accesses of the short code grouped by referrer host, optionally since a given time.",
+		Tags:        []string{"Stats"},
+		Metadata: map[string]any{
+			ratelimit.MetadataKey: ratelimit.EndpointConfig{
+				Scope: ratelimit.ScopeRead,
+			},
+		},
+	}, statsHandler.Referrers)
+}
+
+// RegisterAdminRoutes registers administrative routes.
+func RegisterAdminRoutes(api huma.API, adminHandler *AdminHandler) {
+	// GET /admin/urls/count - Total number of short URLs
+	huma.Register(api, huma.Operation{
+		Method:      http.MethodGet,
+		Path:        "/admin/urls/count",
+		Summary:     "Count short URLs",
+		Description: "Returns the total number of stored short URLs.",
+		Tags:        []string{"Admin"},
+		Metadata:    map[string]any{AdminMetadataKey: true},
+	}, adminHandler.CountURLs)
+
+	// GET /admin/ratelimit - Inspect a client's rate limit buckets
+	huma.Register(api, huma.Operation{
+		Method:      http.MethodGet,
+		Path:        "/admin/ratelimit",
+		Summary:     "Inspect rate limit buckets",
+		Description: "Returns the current count of every rate limit bucket for a client key without recording a request.",
+		Tags:        []string{"Admin"},
+		Metadata:    map[string]any{AdminMetadataKey: true},
+	}, adminHandler.InspectRateLimit)
+
+	// PUT /admin/ratelimit/policy - Replace the rate limit policy without a restart
+	huma.Register(api, huma.Operation{
+		Method:      http.MethodPut,
+		Path:        "/admin/ratelimit/policy",
+		Summary:     "Update rate limit policy",
+		Description: "Validates and atomically replaces the global, read and write rate limits. Scopes left out are not limited. Counts in windows the old and new policies share carry over. The change is not persisted and only applies to this instance.",
+		Tags:        []string{"Admin"},
+		Metadata:    map[string]any{AdminMetadataKey: true},
+	}, adminHandler.UpdateRateLimitPolicy)
+
+	// POST /admin/cache/warm - Load the most accessed codes into the cache
+	huma.Register(api, huma.Operation{
+		Method:      http.MethodPost,
+		Path:        "/admin/cache/warm",
+		Summary:     "Warm the cache",
+		Description: "Looks up the most accessed codes from analytics so the cache holds them again, e.g. after a cache flush.",
+		Tags:        []string{"Admin"},
+		Metadata:    map[string]any{AdminMetadataKey: true},
+	}, adminHandler.WarmCache)
+
+	// POST /admin/import - Bulk import short URLs from CSV
+	huma.Register(api, huma.Operation{
+		Method:      http.MethodPost,
+		Path:        "/admin/import",
+		Summary:     "Import short URLs",
+		Description: "Imports short URLs from a multipart CSV upload with code,url[,created_at] rows and reports the result of each row.",
+		Tags:        []string{"Admin"},
+		Metadata:    map[string]any{AdminMetadataKey: true},
+	}, adminHandler.ImportURLs)
+}
+
+// applyStrategyEnum sets the strategy enum and default of the /shorten request
+// schema from the handler's registered strategies, so the OpenAPI spec and
+// request validation follow configuration. The strategy query parameter gets
+// the same enum but no default, which would otherwise override the default
+// strategy on every request.
+func applyStrategyEnum(api huma.API, urlHandler *URLHandler) {
+	op := api.OpenAPI().Paths["/shorten"].Post
+	strategies := urlHandler.Strategies()
+
+	enum := make([]any, 0, len(strategies))
+	for _, strategy := range strategies {
+		enum = append(enum, string(strategy))
+	}
+
+	for _, param := range op.Parameters {
+		if param.In == "query" && param.Name == "strategy" && param.Schema != nil {
+			param.Schema.Enum = enum
+			param.Schema.PrecomputeMessages()
+		}
+	}
+
+	schema := op.RequestBody.Content["application/json"].Schema
+	if schema.Ref != "" {
+		schema = api.OpenAPI().Components.Schemas.SchemaFromRef(schema.Ref)
+	}
+
+	prop, ok := schema.Properties["strategy"]
+	if !ok {
+		return
+	}
+
+	prop.Enum = enum
+	prop.Default = string(urlHandler.DefaultStrategy())
+	prop.PrecomputeMessages()
 }
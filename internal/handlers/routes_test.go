@@ -0,0 +1,484 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/adapters/humachi"
+	"github.com/go-chi/chi/v5"
+	"github.com/serroba/web-demo-go/internal/analytics"
+	"github.com/serroba/web-demo-go/internal/handlers"
+	"github.com/serroba/web-demo-go/internal/middleware"
+	"github.com/serroba/web-demo-go/internal/shortener"
+	"github.com/serroba/web-demo-go/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func setupURLRoutes(t *testing.T, strategies []handlers.Strategy, opts ...handlers.URLHandlerOption) *chi.Mux {
+	t.Helper()
+
+	memStore := store.NewMemoryStore()
+	gen, err := shortener.NewCodeGenerator(8, false)
+	require.NoError(t, err)
+
+	registered := make(map[handlers.Strategy]shortener.Strategy, len(strategies))
+	for _, strategy := range strategies {
+		registered[strategy] = shortener.NewTokenStrategy(memStore, gen)
+	}
+
+	handler := handlers.NewURLHandler(
+		memStore,
+		"http://localhost:8888",
+		registered,
+		noopPublish[analytics.URLCreatedEvent](),
+		noopPublish[analytics.URLAccessedEvent](),
+		zap.NewNop(),
+		opts...,
+	)
+
+	router := chi.NewMux()
+	api := humachi.New(router, huma.DefaultConfig("Test", "1.0.0"))
+	handlers.RegisterRoutes(api, handler)
+
+	return router
+}
+
+func strategySchema(t *testing.T, router *chi.Mux) map[string]any {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, "/openapi.json", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var spec struct {
+		Components struct {
+			Schemas map[string]struct {
+				Properties map[string]map[string]any `json:"properties"`
+			} `json:"schemas"`
+		} `json:"components"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &spec))
+
+	body, ok := spec.Components.Schemas["CreateShortURLRequestBody"]
+	require.True(t, ok, "request body schema should be registered")
+
+	return body.Properties["strategy"]
+}
+
+func TestRegisterRoutes_StrategyEnum(t *testing.T) {
+	t.Run("enum lists configured strategies", func(t *testing.T) {
+		router := setupURLRoutes(t, []handlers.Strategy{handlers.StrategyHash, handlers.StrategyToken})
+
+		schema := strategySchema(t, router)
+
+		assert.Equal(t, []any{"hash", "token"}, schema["enum"])
+		assert.Equal(t, "token", schema["default"])
+	})
+
+	t.Run("enum follows removed strategies and default", func(t *testing.T) {
+		router := setupURLRoutes(t, []handlers.Strategy{handlers.StrategyHash},
+			handlers.WithDefaultStrategy(handlers.StrategyHash))
+
+		schema := strategySchema(t, router)
+
+		assert.Equal(t, []any{"hash"}, schema["enum"])
+		assert.Equal(t, "hash", schema["default"])
+	})
+
+	t.Run("rejects strategies that are not configured", func(t *testing.T) {
+		router := setupURLRoutes(t, []handlers.Strategy{handlers.StrategyHash},
+			handlers.WithDefaultStrategy(handlers.StrategyHash))
+
+		req := httptest.NewRequest(http.MethodPost, "/shorten",
+			strings.NewReader(`{"url":"https://example.com","strategy":"token"}`))
+		req.Header.Set("Content-Type", "application/json")
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	})
+
+	t.Run("omitted strategy uses the configured default", func(t *testing.T) {
+		router := setupURLRoutes(t, []handlers.Strategy{handlers.StrategyHash},
+			handlers.WithDefaultStrategy(handlers.StrategyHash))
+
+		req := httptest.NewRequest(http.MethodPost, "/shorten", strings.NewReader(`{"url":"https://example.com"}`))
+		req.Header.Set("Content-Type", "application/json")
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	})
+}
+
+func TestRegisterRoutes_StrategyQuery(t *testing.T) {
+	post := func(t *testing.T, query, body string) *httptest.ResponseRecorder {
+		t.Helper()
+
+		router := setupURLRoutes(t, []handlers.Strategy{handlers.StrategyHash},
+			handlers.WithDefaultStrategy(handlers.StrategyHash))
+
+		req := httptest.NewRequest(http.MethodPost, "/shorten"+query, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	t.Run("accepts a configured strategy", func(t *testing.T) {
+		rec := post(t, "?strategy=hash", `{"url":"https://example.com"}`)
+
+		assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	})
+
+	t.Run("rejects strategies that are not configured", func(t *testing.T) {
+		rec := post(t, "?strategy=token", `{"url":"https://example.com"}`)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	})
+}
+
+func TestRegisterRoutes_URLValidation(t *testing.T) {
+	post := func(t *testing.T, body string) *httptest.ResponseRecorder {
+		t.Helper()
+
+		router := setupURLRoutes(t, []handlers.Strategy{handlers.StrategyToken})
+
+		req := httptest.NewRequest(http.MethodPost, "/shorten", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	tests := []struct {
+		name string
+		body string
+		want int
+	}{
+		{name: "valid url", body: `{"url":"https://example.com/path?q=1"}`, want: http.StatusOK},
+		{name: "not a url", body: `{"url":"not a url"}`, want: http.StatusUnprocessableEntity},
+		{name: "missing scheme", body: `{"url":"example.com/path"}`, want: http.StatusUnprocessableEntity},
+		{name: "unsupported scheme", body: `{"url":"ftp://example.com/file"}`, want: http.StatusUnprocessableEntity},
+		{name: "missing host", body: `{"url":"https:///path"}`, want: http.StatusUnprocessableEntity},
+		{
+			name: "too long",
+			body: `{"url":"https://example.com/` + strings.Repeat("a", 2048) + `"}`,
+			want: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "malformed fallback url",
+			body: `{"url":"https://example.com","fallbackUrl":"javascript:alert(1)"}`,
+			want: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "content type hint",
+			body: `{"url":"https://example.com/report.pdf","contentTypeHint":"application/pdf"}`,
+			want: http.StatusOK,
+		},
+		{
+			name: "malformed content type hint",
+			body: `{"url":"https://example.com/report.pdf","contentTypeHint":"pdf"}`,
+			want: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := post(t, tt.body)
+
+			assert.Equal(t, tt.want, rec.Code, rec.Body.String())
+
+			if tt.want == http.StatusUnprocessableEntity {
+				assert.Contains(t, rec.Body.String(), "validation failed")
+			}
+		})
+	}
+}
+
+func TestRegisterRoutes_URLMetadata(t *testing.T) {
+	router := setupURLRoutes(t, []handlers.Strategy{handlers.StrategyToken})
+
+	serve := func(req *http.Request) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	create := httptest.NewRequest(http.MethodPost, "/shorten", strings.NewReader(`{"url":"https://example.com/docs"}`))
+	create.Header.Set("Content-Type", "application/json")
+
+	created := serve(create)
+	require.Equal(t, http.StatusOK, created.Code, created.Body.String())
+
+	var createResp struct {
+		Code string `json:"code"`
+	}
+	require.NoError(t, json.Unmarshal(created.Body.Bytes(), &createResp))
+
+	t.Run("returns metadata with the access count", func(t *testing.T) {
+		rec := serve(httptest.NewRequest(http.MethodGet, "/api/urls/"+createResp.Code, nil))
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		var body map[string]any
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		assert.Equal(t, createResp.Code, body["code"])
+		assert.Equal(t, "https://example.com/docs", body["originalUrl"])
+		assert.InDelta(t, 0, body["accessCount"], 0)
+	})
+
+	t.Run("recent urls keep their own route", func(t *testing.T) {
+		rec := serve(httptest.NewRequest(http.MethodGet, "/api/urls/recent", nil))
+
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.True(t, strings.HasPrefix(strings.TrimSpace(rec.Body.String()), "["))
+	})
+}
+
+func TestRegisterRoutes_Resolve(t *testing.T) {
+	router := setupURLRoutes(t, []handlers.Strategy{handlers.StrategyToken})
+
+	serve := func(req *http.Request) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	create := httptest.NewRequest(http.MethodPost, "/shorten", strings.NewReader(`{"url":"https://example.com/docs"}`))
+	create.Header.Set("Content-Type", "application/json")
+
+	created := serve(create)
+	require.Equal(t, http.StatusOK, created.Code, created.Body.String())
+
+	var createResp struct {
+		Code string `json:"code"`
+	}
+	require.NoError(t, json.Unmarshal(created.Body.Bytes(), &createResp))
+
+	t.Run("returns the mapping with caching headers", func(t *testing.T) {
+		rec := serve(httptest.NewRequest(http.MethodGet, "/api/resolve/"+createResp.Code, nil))
+
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.Equal(t, "public, max-age=3600, must-revalidate", rec.Header().Get("Cache-Control"))
+		assert.NotEmpty(t, rec.Header().Get("ETag"))
+		assert.JSONEq(t, `{"code":"`+createResp.Code+`","url":"https://example.com/docs"}`,
+			stripSchema(t, rec.Body.Bytes()))
+	})
+
+	t.Run("matching If-None-Match returns not modified", func(t *testing.T) {
+		first := serve(httptest.NewRequest(http.MethodGet, "/api/resolve/"+createResp.Code, nil))
+
+		req := httptest.NewRequest(http.MethodGet, "/api/resolve/"+createResp.Code, nil)
+		req.Header.Set("If-None-Match", first.Header().Get("ETag"))
+
+		assert.Equal(t, http.StatusNotModified, serve(req).Code)
+	})
+
+	t.Run("unknown code returns not found", func(t *testing.T) {
+		rec := serve(httptest.NewRequest(http.MethodGet, "/api/resolve/missing", nil))
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
+
+func TestRegisterRoutes_UpdateTarget(t *testing.T) {
+	router := setupURLRoutes(t, []handlers.Strategy{handlers.StrategyToken})
+
+	serve := func(req *http.Request) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	update := func(code, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, "/api/urls/"+code, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+
+		return serve(req)
+	}
+
+	create := httptest.NewRequest(http.MethodPost, "/shorten", strings.NewReader(`{"url":"https://example.com/docs"}`))
+	create.Header.Set("Content-Type", "application/json")
+
+	created := serve(create)
+	require.Equal(t, http.StatusOK, created.Code, created.Body.String())
+
+	var createResp struct {
+		Code string `json:"code"`
+	}
+	require.NoError(t, json.Unmarshal(created.Body.Bytes(), &createResp))
+
+	t.Run("repoints the code", func(t *testing.T) {
+		rec := update(createResp.Code, `{"url":"https://example.com/guide"}`)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		resolved := serve(httptest.NewRequest(http.MethodGet, "/api/resolve/"+createResp.Code, nil))
+		assert.JSONEq(t, `{"code":"`+createResp.Code+`","url":"https://example.com/guide"}`,
+			stripSchema(t, resolved.Body.Bytes()))
+	})
+
+	t.Run("rejects an invalid url", func(t *testing.T) {
+		rec := update(createResp.Code, `{"url":"not a url"}`)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	})
+
+	t.Run("unknown code returns not found", func(t *testing.T) {
+		rec := update("missing", `{"url":"https://example.com"}`)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
+
+func TestRegisterRoutes_FlagURL(t *testing.T) {
+	router := setupURLRoutes(t, []handlers.Strategy{handlers.StrategyToken})
+
+	serve := func(req *http.Request) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	create := httptest.NewRequest(http.MethodPost, "/shorten", strings.NewReader(`{"url":"https://example.com/docs"}`))
+	create.Header.Set("Content-Type", "application/json")
+
+	created := serve(create)
+	require.Equal(t, http.StatusOK, created.Code, created.Body.String())
+
+	var createResp struct {
+		Code string `json:"code"`
+	}
+	require.NoError(t, json.Unmarshal(created.Body.Bytes(), &createResp))
+
+	flag := httptest.NewRequest(http.MethodPut, "/api/urls/"+createResp.Code+"/flag",
+		strings.NewReader(`{"flagged":true}`))
+	flag.Header.Set("Content-Type", "application/json")
+
+	rec := serve(flag)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.JSONEq(t, `{"code":"`+createResp.Code+`","flagged":true}`, stripSchema(t, rec.Body.Bytes()))
+
+	redirect := serve(httptest.NewRequest(http.MethodGet, "/"+createResp.Code, nil))
+	assert.Equal(t, http.StatusGone, redirect.Code, "flagged codes without a fallback are gone")
+}
+
+// stripSchema removes the $schema link Huma adds to JSON response bodies.
+func stripSchema(t *testing.T, body []byte) string {
+	t.Helper()
+
+	var fields map[string]any
+	require.NoError(t, json.Unmarshal(body, &fields))
+	delete(fields, "$schema")
+
+	out, err := json.Marshal(fields)
+	require.NoError(t, err)
+
+	return string(out)
+}
+
+func TestRegisterRoutes_RecentURLs(t *testing.T) {
+	router := setupURLRoutes(t, []handlers.Strategy{handlers.StrategyToken})
+
+	get := func(target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+
+		return rec
+	}
+
+	t.Run("returns a json array", func(t *testing.T) {
+		rec := get("/api/urls/recent")
+
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.JSONEq(t, `[]`, rec.Body.String())
+	})
+
+	for _, limit := range []string{"0", "101", "abc"} {
+		t.Run("rejects limit "+limit, func(t *testing.T) {
+			rec := get("/api/urls/recent?limit=" + limit)
+
+			assert.Equal(t, http.StatusUnprocessableEntity, rec.Code, rec.Body.String())
+		})
+	}
+}
+
+func TestRegisterRoutes_MyURLs(t *testing.T) {
+	router := chi.NewMux()
+	api := humachi.New(router, huma.DefaultConfig("Test", "1.0.0"))
+	api.UseMiddleware(middleware.APIKeyAuth(api, []middleware.APIKey{
+		{Name: "team-a", Secret: "secret-a"},
+		{Name: "team-b", Secret: "secret-b"},
+	}))
+	handlers.RegisterRoutes(api, newTestHandler(store.NewMemoryStore()))
+
+	serve := func(method, target, key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+
+		if key != "" {
+			req.Header.Set(middleware.APIKeyHeader, key)
+		}
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	for _, create := range []struct{ key, url string }{
+		{key: "secret-a", url: "https://example.com/a1"},
+		{key: "secret-b", url: "https://example.com/b1"},
+		{key: "", url: "https://example.com/anonymous"},
+		{key: "secret-a", url: "https://example.com/a2"},
+	} {
+		rec := serve(http.MethodPost, "/shorten", create.key, `{"url":"`+create.url+`"}`)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	}
+
+	t.Run("lists only the key's urls", func(t *testing.T) {
+		rec := serve(http.MethodGet, "/api/urls/mine", "secret-a", "")
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		var body []handlers.RecentURL
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+
+		var urls []string
+
+		for _, shortURL := range body {
+			assert.Equal(t, "key:team-a", shortURL.CreatedBy)
+
+			urls = append(urls, shortURL.OriginalURL)
+		}
+
+		assert.ElementsMatch(t, []string{"https://example.com/a1", "https://example.com/a2"}, urls)
+	})
+
+	t.Run("requires an api key", func(t *testing.T) {
+		rec := serve(http.MethodGet, "/api/urls/mine", "", "")
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code, rec.Body.String())
+	})
+
+	t.Run("rejects an unknown api key", func(t *testing.T) {
+		rec := serve(http.MethodGet, "/api/urls/mine", "nope", "")
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code, rec.Body.String())
+	})
+}
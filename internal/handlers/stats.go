@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/fxamacker/cbor/v2"
+	"github.com/serroba/web-demo-go/internal/analytics"
+	"github.com/serroba/web-demo-go/internal/logging"
+	"go.uber.org/zap"
+)
+
+// MaxSafeInteger is the largest integer JavaScript numbers represent exactly (2^53-1).
+const MaxSafeInteger = 1<<53 - 1
+
+// StatCount is a count in a stats response. Counts beyond MaxSafeInteger are
+// encoded as JSON strings when the handler was built with WithLargeCountsAsStrings,
+// so JavaScript clients do not silently round them. CBOR always carries the integer.
+type StatCount struct {
+	value         int64
+	largeAsString bool
+}
+
+// Int64 returns the count.
+func (c StatCount) Int64() int64 {
+	return c.value
+}
+
+// MarshalJSON implements json.Marshaler.
+func (c StatCount) MarshalJSON() ([]byte, error) {
+	n := strconv.FormatInt(c.value, 10)
+	if c.largeAsString && (c.value > MaxSafeInteger || c.value < -MaxSafeInteger) {
+		return []byte(`"` + n + `"`), nil
+	}
+
+	return []byte(n), nil
+}
+
+// MarshalCBOR implements cbor.Marshaler.
+func (c StatCount) MarshalCBOR() ([]byte, error) {
+	return cbor.Marshal(c.value)
+}
+
+// Schema documents a count as an integer, or a string of digits when large.
+func (StatCount) Schema(huma.Registry) *huma.Schema {
+	return &huma.Schema{
+		OneOf: []*huma.Schema{
+			{Type: huma.TypeInteger, Format: "int64"},
+			{Type: huma.TypeString, Pattern: "^-?[0-9]+$"},
+		},
+	}
+}
+
+// StatsHandler serves access statistics for short URLs.
+type StatsHandler struct {
+	store         analytics.Store
+	logger        *zap.Logger
+	largeAsString bool
+}
+
+// StatsHandlerOption configures optional StatsHandler behavior.
+type StatsHandlerOption func(*StatsHandler)
+
+// WithLargeCountsAsStrings encodes counts beyond MaxSafeInteger as JSON strings.
+func WithLargeCountsAsStrings() StatsHandlerOption {
+	return func(h *StatsHandler) {
+		h.largeAsString = true
+	}
+}
+
+// NewStatsHandler creates a new stats handler backed by the analytics store.
+func NewStatsHandler(store analytics.Store, logger *zap.Logger, opts ...StatsHandlerOption) *StatsHandler {
+	h := &StatsHandler{
+		store:  store,
+		logger: logger,
+	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h
+}
+
+func (h *StatsHandler) count(n int64) StatCount {
+	return StatCount{value: n, largeAsString: h.largeAsString}
+}
+
+// BatchStats returns the access count for each requested code in a single query.
+func (h *StatsHandler) BatchStats(ctx context.Context, req *BatchStatsRequest) (*BatchStatsResponse, error) {
+	counts, err := h.store.AccessCounts(ctx, req.Body.Codes)
+	if err != nil {
+		logging.FromContext(ctx, h.logger).Error("failed to load access counts",
+			zap.Int("codes", len(req.Body.Codes)),
+			zap.Error(err),
+		)
+
+		return nil, huma.Error500InternalServerError("failed to load stats")
+	}
+
+	resp := &BatchStatsResponse{}
+	resp.Body.Counts = make(map[string]StatCount, len(counts))
+
+	for code, n := range counts {
+		resp.Body.Counts[code] = h.count(n)
+	}
+
+	return resp, nil
+}
+
+// UniqueVisitors returns the number of distinct client IPs that accessed a code.
+func (h *StatsHandler) UniqueVisitors(ctx context.Context, req *UniqueVisitorsRequest) (*UniqueVisitorsResponse, error) {
+	count, err := h.store.UniqueVisitors(ctx, req.Code, req.Since)
+	if err != nil {
+		logging.FromContext(ctx, h.logger).Error("failed to load unique visitors",
+			zap.String("code", req.Code),
+			zap.Error(err),
+		)
+
+		return nil, huma.Error500InternalServerError("failed to load stats")
+	}
+
+	resp := &UniqueVisitorsResponse{}
+	resp.Body.Code = req.Code
+	resp.Body.UniqueVisitors = h.count(count)
+
+	return resp, nil
+}
+
+// Referrers returns the accesses of a code grouped by referrer host.
+func (h *StatsHandler) Referrers(ctx context.Context, req *ReferrersRequest) (*ReferrersResponse, error) {
+	referrers, err := h.store.ReferrerBreakdown(ctx, req.Code, req.Since)
+	if err != nil {
+		logging.FromContext(ctx, h.logger).Error("failed to load referrer breakdown",
+			zap.String("code", req.Code),
+			zap.Error(err),
+		)
+
+		return nil, huma.Error500InternalServerError("failed to load stats")
+	}
+
+	resp := &ReferrersResponse{}
+	resp.Body.Code = req.Code
+	resp.Body.Referrers = make([]ReferrerCount, len(referrers))
+
+	for i, referrer := range referrers {
+		resp.Body.Referrers[i] = ReferrerCount{Host: referrer.Host, Count: h.count(referrer.Count)}
+	}
+
+	return resp, nil
+}
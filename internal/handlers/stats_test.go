@@ -0,0 +1,269 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/fxamacker/cbor/v2"
+	"github.com/serroba/web-demo-go/internal/analytics"
+	"github.com/serroba/web-demo-go/internal/handlers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// mockAnalyticsStore is a test double for analytics.Store.
+type mockAnalyticsStore struct {
+	accessCountsFunc   func(ctx context.Context, codes []string) (map[string]int64, error)
+	uniqueVisitorsFunc func(ctx context.Context, code string, since time.Time) (int64, error)
+	topCodesFunc       func(ctx context.Context, since time.Time, limit int) ([]analytics.CodeCount, error)
+	referrersFunc      func(ctx context.Context, code string, since time.Time) ([]analytics.ReferrerCount, error)
+}
+
+func (m *mockAnalyticsStore) SaveURLCreated(_ context.Context, _ *analytics.URLCreatedEvent) error {
+	return nil
+}
+
+func (m *mockAnalyticsStore) SaveURLAccessed(_ context.Context, _ *analytics.URLAccessedEvent) error {
+	return nil
+}
+
+func (m *mockAnalyticsStore) AccessCounts(ctx context.Context, codes []string) (map[string]int64, error) {
+	return m.accessCountsFunc(ctx, codes)
+}
+
+func (m *mockAnalyticsStore) UniqueVisitors(ctx context.Context, code string, since time.Time) (int64, error) {
+	return m.uniqueVisitorsFunc(ctx, code, since)
+}
+
+func (m *mockAnalyticsStore) TopCodes(
+	ctx context.Context,
+	since time.Time,
+	limit int,
+) ([]analytics.CodeCount, error) {
+	return m.topCodesFunc(ctx, since, limit)
+}
+
+func (m *mockAnalyticsStore) ReferrerBreakdown(
+	ctx context.Context,
+	code string,
+	since time.Time,
+) ([]analytics.ReferrerCount, error) {
+	return m.referrersFunc(ctx, code, since)
+}
+
+func (m *mockAnalyticsStore) PruneAccessedBefore(_ context.Context, _ time.Time) (int64, error) {
+	return 0, nil
+}
+
+func TestStatsHandler_BatchStats(t *testing.T) {
+	t.Run("returns counts for requested codes", func(t *testing.T) {
+		var requested []string
+
+		store := &mockAnalyticsStore{
+			accessCountsFunc: func(_ context.Context, codes []string) (map[string]int64, error) {
+				requested = codes
+
+				return map[string]int64{"abc123": 3, "missing": 0}, nil
+			},
+		}
+		handler := handlers.NewStatsHandler(store, zap.NewNop())
+
+		req := &handlers.BatchStatsRequest{}
+		req.Body.Codes = []string{"abc123", "missing"}
+
+		resp, err := handler.BatchStats(context.Background(), req)
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"abc123", "missing"}, requested)
+		require.Len(t, resp.Body.Counts, 2)
+		assert.Equal(t, int64(3), resp.Body.Counts["abc123"].Int64())
+		assert.Equal(t, int64(0), resp.Body.Counts["missing"].Int64())
+	})
+
+	t.Run("returns 500 when store fails", func(t *testing.T) {
+		store := &mockAnalyticsStore{
+			accessCountsFunc: func(_ context.Context, _ []string) (map[string]int64, error) {
+				return nil, errMock
+			},
+		}
+		handler := handlers.NewStatsHandler(store, zap.NewNop())
+
+		req := &handlers.BatchStatsRequest{}
+		req.Body.Codes = []string{"abc123"}
+
+		resp, err := handler.BatchStats(context.Background(), req)
+
+		assert.Nil(t, resp)
+
+		var statusErr huma.StatusError
+		require.ErrorAs(t, err, &statusErr)
+		assert.Equal(t, http.StatusInternalServerError, statusErr.GetStatus())
+	})
+}
+
+func TestStatsHandler_UniqueVisitors(t *testing.T) {
+	t.Run("returns the unique visitor count since the requested time", func(t *testing.T) {
+		since := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+
+		var (
+			gotCode  string
+			gotSince time.Time
+		)
+
+		store := &mockAnalyticsStore{
+			uniqueVisitorsFunc: func(_ context.Context, code string, since time.Time) (int64, error) {
+				gotCode, gotSince = code, since
+
+				return 7, nil
+			},
+		}
+		handler := handlers.NewStatsHandler(store, zap.NewNop())
+
+		resp, err := handler.UniqueVisitors(context.Background(), &handlers.UniqueVisitorsRequest{Code: "abc123", Since: since})
+
+		require.NoError(t, err)
+		assert.Equal(t, "abc123", gotCode)
+		assert.Equal(t, since, gotSince)
+		assert.Equal(t, "abc123", resp.Body.Code)
+		assert.Equal(t, int64(7), resp.Body.UniqueVisitors.Int64())
+	})
+
+	t.Run("returns 500 when the store fails", func(t *testing.T) {
+		store := &mockAnalyticsStore{
+			uniqueVisitorsFunc: func(_ context.Context, _ string, _ time.Time) (int64, error) {
+				return 0, errors.New("connection refused")
+			},
+		}
+		handler := handlers.NewStatsHandler(store, zap.NewNop())
+
+		resp, err := handler.UniqueVisitors(context.Background(), &handlers.UniqueVisitorsRequest{Code: "abc123"})
+
+		assert.Nil(t, resp)
+
+		var statusErr huma.StatusError
+		require.ErrorAs(t, err, &statusErr)
+		assert.Equal(t, http.StatusInternalServerError, statusErr.GetStatus())
+	})
+}
+
+func TestStatsHandler_Referrers(t *testing.T) {
+	t.Run("returns the referrer breakdown since the requested time", func(t *testing.T) {
+		since := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+
+		var gotSince time.Time
+
+		store := &mockAnalyticsStore{
+			referrersFunc: func(_ context.Context, _ string, since time.Time) ([]analytics.ReferrerCount, error) {
+				gotSince = since
+
+				return []analytics.ReferrerCount{{Host: "example.org", Count: 3}, {Host: "", Count: 1}}, nil
+			},
+		}
+		handler := handlers.NewStatsHandler(store, zap.NewNop())
+
+		resp, err := handler.Referrers(context.Background(), &handlers.ReferrersRequest{Code: "abc123", Since: since})
+		require.NoError(t, err)
+		assert.Equal(t, since, gotSince)
+
+		body, err := json.Marshal(resp.Body)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"code":"abc123","referrers":[{"host":"example.org","count":3},{"host":"","count":1}]}`,
+			string(body))
+	})
+
+	t.Run("returns 500 when the store fails", func(t *testing.T) {
+		store := &mockAnalyticsStore{
+			referrersFunc: func(_ context.Context, _ string, _ time.Time) ([]analytics.ReferrerCount, error) {
+				return nil, errors.New("connection refused")
+			},
+		}
+		handler := handlers.NewStatsHandler(store, zap.NewNop())
+
+		resp, err := handler.Referrers(context.Background(), &handlers.ReferrersRequest{Code: "abc123"})
+
+		assert.Nil(t, resp)
+
+		var statusErr huma.StatusError
+		require.ErrorAs(t, err, &statusErr)
+		assert.Equal(t, http.StatusInternalServerError, statusErr.GetStatus())
+	})
+}
+
+func TestStatsHandler_LargeCountsAsStrings(t *testing.T) {
+	large := int64(handlers.MaxSafeInteger + 1)
+	store := &mockAnalyticsStore{
+		accessCountsFunc: func(_ context.Context, _ []string) (map[string]int64, error) {
+			return map[string]int64{"hot": large, "cold": 42}, nil
+		},
+	}
+
+	batch := func(t *testing.T, opts ...handlers.StatsHandlerOption) string {
+		t.Helper()
+
+		handler := handlers.NewStatsHandler(store, zap.NewNop(), opts...)
+
+		req := &handlers.BatchStatsRequest{}
+		req.Body.Codes = []string{"hot", "cold"}
+
+		resp, err := handler.BatchStats(context.Background(), req)
+		require.NoError(t, err)
+
+		body, err := json.Marshal(resp.Body)
+		require.NoError(t, err)
+
+		return string(body)
+	}
+
+	t.Run("encodes counts beyond the safe range as strings when enabled", func(t *testing.T) {
+		body := batch(t, handlers.WithLargeCountsAsStrings())
+
+		assert.JSONEq(t, `{"counts":{"hot":"9007199254740992","cold":42}}`, body)
+	})
+
+	t.Run("encodes counts as numbers by default", func(t *testing.T) {
+		body := batch(t)
+
+		assert.JSONEq(t, `{"counts":{"hot":9007199254740992,"cold":42}}`, body)
+	})
+
+	t.Run("encodes counts as cbor integers", func(t *testing.T) {
+		handler := handlers.NewStatsHandler(store, zap.NewNop(), handlers.WithLargeCountsAsStrings())
+
+		req := &handlers.BatchStatsRequest{}
+		req.Body.Codes = []string{"hot", "cold"}
+
+		resp, err := handler.BatchStats(context.Background(), req)
+		require.NoError(t, err)
+
+		body, err := cbor.Marshal(resp.Body)
+		require.NoError(t, err)
+
+		var decoded struct {
+			Counts map[string]int64 `cbor:"counts"`
+		}
+		require.NoError(t, cbor.Unmarshal(body, &decoded))
+		assert.Equal(t, map[string]int64{"hot": large, "cold": 42}, decoded.Counts)
+	})
+
+	t.Run("keeps the largest safe integer a number", func(t *testing.T) {
+		store := &mockAnalyticsStore{
+			uniqueVisitorsFunc: func(_ context.Context, _ string, _ time.Time) (int64, error) {
+				return handlers.MaxSafeInteger, nil
+			},
+		}
+		handler := handlers.NewStatsHandler(store, zap.NewNop(), handlers.WithLargeCountsAsStrings())
+
+		resp, err := handler.UniqueVisitors(context.Background(), &handlers.UniqueVisitorsRequest{Code: "hot"})
+		require.NoError(t, err)
+
+		body, err := json.Marshal(resp.Body)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"code":"hot","uniqueVisitors":9007199254740991}`, string(body))
+	})
+}
@@ -1,5 +1,16 @@
 package handlers
 
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/conditional"
+	"github.com/serroba/web-demo-go/internal/shortener"
+)
+
 // Strategy defines the URL shortening strategy.
 type Strategy string
 
@@ -8,25 +19,49 @@ const (
 	StrategyToken Strategy = "token"
 	// StrategyHash deduplicates by URL content - same URL returns same code.
 	StrategyHash Strategy = "hash"
+	// StrategyAlias stores the URL under a caller-chosen alias. It is selected by setting alias.
+	StrategyAlias Strategy = "alias"
 )
 
+// InvalidStrategyError wraps shortener.ErrInvalidStrategy with the names of
+// strategies, so the message stays accurate as strategies are registered.
+func InvalidStrategyError(strategies map[Strategy]shortener.Strategy) error {
+	names := make([]string, 0, len(strategies))
+	for name := range strategies {
+		names = append(names, "'"+string(name)+"'")
+	}
+
+	slices.Sort(names)
+
+	return fmt.Errorf("%w: supported strategies: %s", shortener.ErrInvalidStrategy, strings.Join(names, ", "))
+}
+
 // CreateShortURLRequest is the request body for creating a short URL.
 type CreateShortURLRequest struct {
-	Body struct {
-		URL      string   `doc:"The URL to shorten" format:"uri"   json:"url"`
-		Strategy Strategy `default:"token"          doc:"Strategy" enum:"token,hash" json:"strategy"`
+	Strategy    Strategy `doc:"Strategy, for clients that cannot set it in the body; the body field wins"                                    query:"strategy"`
+	IfNoneMatch string   `doc:"Set to * with an alias to create it only if absent; an alias already pointing to the URL is returned with 200" header:"If-None-Match"`
+	Body        struct {
+		URL             string   `doc:"The URL to shorten (http or https, at most 2048 characters)" format:"uri"              json:"url"                       maxLength:"2048" pattern:"^https?://[^\\s/?#]+[^\\s]*$"`
+		Strategy        Strategy `doc:"Strategy"                                                    json:"strategy,omitempty"`
+		Alias           string   `doc:"Optional vanity alias used instead of a generated code"      json:"alias,omitempty"`
+		FallbackURL     string   `doc:"Optional URL to redirect to if the original URL is flagged"  format:"uri"              json:"fallbackUrl,omitempty"     maxLength:"2048" pattern:"^https?://[^\\s/?#]+[^\\s]*$"`
+		ContentTypeHint string   `doc:"Optional media type the URL serves, such as application/pdf" example:"application/pdf" json:"contentTypeHint,omitempty" maxLength:"255"  pattern:"^[A-Za-z0-9][A-Za-z0-9!#$&^_.+-]*/[A-Za-z0-9][A-Za-z0-9!#$&^_.+-]*$"`
 	}
 }
 
 // CreateShortURLResponse is the response for a successfully created short URL.
 type CreateShortURLResponse struct {
+	Status  int
 	Headers struct {
 		Location string `doc:"The short URL location" header:"Location"`
 	}
 	Body struct {
-		Code        string `doc:"The short code"     example:"abc123"                             json:"code"`
-		ShortURL    string `doc:"The full short URL" example:"http://localhost:8888/abc123"       json:"shortUrl"`
-		OriginalURL string `doc:"The original URL"   example:"https://example.com/very/long/path" json:"originalUrl"`
+		Code        string    `doc:"The short code"                 example:"abc123"                             json:"code"`
+		ShortURL    string    `doc:"The full short URL"             example:"http://localhost:8888/abc123"       json:"shortUrl"`
+		OriginalURL string    `doc:"The original URL"               example:"https://example.com/very/long/path" json:"originalUrl"`
+		QRURL       string    `doc:"URL of the QR code image"       example:"http://localhost:8888/abc123/qr"    json:"qrUrl,omitempty"`
+		CreatedAt   time.Time `doc:"When the short URL was created" json:"createdAt"`
+		CreatedBy   string    `doc:"API key or user who created it" json:"createdBy,omitempty"`
 	}
 }
 
@@ -35,10 +70,259 @@ type RedirectRequest struct {
 	Code string `doc:"The short code" example:"abc123" path:"code"`
 }
 
-// RedirectResponse is the 301 redirect response.
+// RedirectResponse is the redirect response, 301 unless configured otherwise.
 type RedirectResponse struct {
 	Status  int
 	Headers struct {
-		Location string `doc:"The original URL to redirect to" header:"Location"`
+		Location     string `doc:"The original URL to redirect to"    header:"Location"`
+		CacheControl string `doc:"Caching policy for the redirect"    header:"Cache-Control"`
+		Link         string `doc:"Canonical link to the original URL" header:"Link"`
+	}
+}
+
+// ResolveRequest is the request for resolving a short code to its original URL.
+type ResolveRequest struct {
+	conditional.Params
+
+	Code string `doc:"The short code" example:"abc123" path:"code"`
+}
+
+// ResolveResponse is the cacheable JSON resolution of a short code.
+type ResolveResponse struct {
+	CacheControl string `doc:"Caching policy for the resolution" header:"Cache-Control"`
+	ETag         string `doc:"Entity tag of the resolution"      header:"ETag"`
+	Body         struct {
+		Code string `doc:"The short code"               example:"abc123"                             json:"code"`
+		URL  string `doc:"The URL the code resolves to" example:"https://example.com/very/long/path" json:"url"`
+	}
+}
+
+// UpdateTargetRequest is the request for pointing a code at a new URL.
+type UpdateTargetRequest struct {
+	Code string `doc:"The short code" example:"abc123" path:"code"`
+	Body struct {
+		URL string `doc:"The new target URL (http or https, at most 2048 characters)" format:"uri" json:"url" maxLength:"2048" pattern:"^https?://[^\\s/?#]+[^\\s]*$"`
+	}
+}
+
+// UpdateTargetResponse is the response for an updated short URL.
+type UpdateTargetResponse struct {
+	Body struct {
+		Code        string `doc:"The short code"            example:"abc123"                       json:"code"`
+		ShortURL    string `doc:"The full short URL"        example:"http://localhost:8888/abc123" json:"shortUrl"`
+		OriginalURL string `doc:"The new target"            example:"https://example.com/new"      json:"originalUrl"`
+	}
+}
+
+// FlagURLRequest is the request for flagging a code's target as bad.
+type FlagURLRequest struct {
+	Code string `doc:"The short code" example:"abc123" path:"code"`
+	Body struct {
+		Flagged bool `doc:"Whether redirects use the fallback URL instead of the target" json:"flagged"`
+	}
+}
+
+// FlagURLResponse is the response for a flagged or unflagged short URL.
+type FlagURLResponse struct {
+	Body struct {
+		Code    string `doc:"The short code"             example:"abc123" json:"code"`
+		Flagged bool   `doc:"Whether the code is flagged" example:"true"   json:"flagged"`
+	}
+}
+
+// RecentURLsRequest is the request for the most recently created short URLs.
+type RecentURLsRequest struct {
+	Limit int `default:"20" doc:"Maximum number of URLs to return" maximum:"100" minimum:"1" query:"limit"`
+}
+
+// MyURLsRequest is the request for the short URLs created with the caller's API key.
+type MyURLsRequest struct {
+	Limit int `default:"20" doc:"Maximum number of URLs to return" maximum:"100" minimum:"1" query:"limit"`
+}
+
+// RecentURL is a short URL in the recent activity feed.
+type RecentURL struct {
+	Code        string    `doc:"The short code"                 example:"abc123"                             json:"code"`
+	ShortURL    string    `doc:"The full short URL"             example:"http://localhost:8888/abc123"       json:"shortUrl"`
+	OriginalURL string    `doc:"The original URL"               example:"https://example.com/very/long/path" json:"originalUrl"`
+	CreatedAt   time.Time `doc:"When the short URL was created" json:"createdAt"`
+	CreatedBy   string    `doc:"API key or user who created it" json:"createdBy,omitempty"`
+}
+
+// URLMetadataRequest is the request for a short URL's metadata.
+type URLMetadataRequest struct {
+	Code string `doc:"The short code" example:"abc123" path:"code"`
+}
+
+// URLMetadataResponse describes a short URL and how often it was accessed.
+type URLMetadataResponse struct {
+	Body struct {
+		RecentURL
+
+		AccessCount     int64  `doc:"Redirects served, updated periodically by the analytics consumer" example:"42"              json:"accessCount"`
+		ContentTypeHint string `doc:"Media type the URL is expected to serve"                           example:"application/pdf" json:"contentTypeHint,omitempty"`
+	}
+}
+
+// RecentURLsResponse lists short URLs newest first.
+type RecentURLsResponse struct {
+	Body []RecentURL
+}
+
+// CheckAvailabilityRequest is the request for checking several candidate codes.
+type CheckAvailabilityRequest struct {
+	Body struct {
+		Codes []string `doc:"Candidate codes to check" json:"codes" maxItems:"100" minItems:"1"`
+	}
+}
+
+// CodeAvailability reports whether a candidate code can be used as an alias.
+type CodeAvailability struct {
+	Available bool   `doc:"Whether the code can be requested as an alias" json:"available"`
+	Reason    string `doc:"Why the code is unavailable: taken or invalid"  json:"reason,omitempty"`
+}
+
+// CheckAvailabilityResponse maps each candidate code to its availability.
+type CheckAvailabilityResponse struct {
+	Body struct {
+		Codes map[string]CodeAvailability `doc:"Availability per requested code" json:"codes"`
+	}
+}
+
+// BatchStatsRequest is the request for fetching access counts of several codes.
+type BatchStatsRequest struct {
+	Body struct {
+		Codes []string `doc:"The short codes to look up" json:"codes" maxItems:"100" minItems:"1"`
+	}
+}
+
+// BatchStatsResponse maps each requested code to its access count.
+type BatchStatsResponse struct {
+	Body struct {
+		Counts map[string]StatCount `doc:"Access count per code; unknown codes report 0" json:"counts"`
+	}
+}
+
+// UniqueVisitorsRequest is the request for the unique visitor count of a code.
+type UniqueVisitorsRequest struct {
+	Code  string    `doc:"The short code"                                                          example:"abc123" path:"code"`
+	Since time.Time `doc:"Only count accesses at or after this time (RFC 3339); omit for all time" query:"since"`
+}
+
+// UniqueVisitorsResponse is the number of distinct client IPs that accessed a code.
+type UniqueVisitorsResponse struct {
+	Body struct {
+		Code           string    `doc:"The short code"                             example:"abc123" json:"code"`
+		UniqueVisitors StatCount `doc:"Distinct client IPs that accessed the code" example:"42"     json:"uniqueVisitors"`
+	}
+}
+
+// ReferrersRequest is the request for the referrer breakdown of a code.
+type ReferrersRequest struct {
+	Code  string    `doc:"The short code"                                                          example:"abc123" path:"code"`
+	Since time.Time `doc:"Only count accesses at or after this time (RFC 3339); omit for all time" query:"since"`
+}
+
+// ReferrerCount is the number of accesses from one referrer host.
+type ReferrerCount struct {
+	Host  string    `doc:"Referrer host; empty for accesses without a referrer" example:"news.ycombinator.com" json:"host"`
+	Count StatCount `doc:"Accesses from the host"                                example:"12"                  json:"count"`
+}
+
+// ReferrersResponse lists the accesses of a code by referrer host.
+type ReferrersResponse struct {
+	Body struct {
+		Code      string          `doc:"The short code"                       example:"abc123" json:"code"`
+		Referrers []ReferrerCount `doc:"Accesses per host, most accesses first" json:"referrers"`
+	}
+}
+
+// CountURLsResponse is the response for the total URL count.
+type CountURLsResponse struct {
+	Body struct {
+		Count int64 `doc:"Total number of short URLs" example:"1024" json:"count"`
+	}
+}
+
+// InspectRateLimitRequest identifies the client whose rate limit buckets are inspected.
+type InspectRateLimitRequest struct {
+	Key string `doc:"Client key used by the rate limiter" query:"key" required:"true"`
+}
+
+// RateLimitBucket is the current usage of a single scope/window bucket.
+type RateLimitBucket struct {
+	Scope  string `doc:"Rate limit scope"           example:"write"              json:"scope"`
+	Window string `doc:"Window duration"            example:"1m0s"               json:"window"`
+	Max    int64  `doc:"Maximum requests allowed"   example:"10"                 json:"max"`
+	Count  int64  `doc:"Requests in current window" example:"3"                  json:"count"`
+	Key    string `doc:"Raw store key"              example:"abc123:write:60000" json:"key"`
+}
+
+// InspectRateLimitResponse lists the rate limit buckets for a client key.
+type InspectRateLimitResponse struct {
+	Body struct {
+		Key     string            `doc:"The inspected client key"   json:"key"`
+		Buckets []RateLimitBucket `doc:"Usage per scope and window" json:"buckets"`
+	}
+}
+
+// RateLimitPolicyLimit is one window of a rate limit policy.
+type RateLimitPolicyLimit struct {
+	Scope  string `doc:"Rate limit scope (global, read, write or tier:scope)" example:"write" json:"scope"`
+	Window string `doc:"Window duration (Go duration)"                        example:"1m"    json:"window"`
+	Max    int64  `doc:"Maximum requests in the window"                       example:"10"    json:"max"     minimum:"0"`
+}
+
+// UpdateRateLimitPolicyRequest replaces the enforced rate limit policy.
+type UpdateRateLimitPolicyRequest struct {
+	Body struct {
+		Limits []RateLimitPolicyLimit `doc:"Every limit of the new policy; scopes without limits are not limited" json:"limits" minItems:"1"`
+	}
+}
+
+// RateLimitPolicyResponse is the rate limit policy now enforced.
+type RateLimitPolicyResponse struct {
+	Body struct {
+		Limits []RateLimitPolicyLimit `doc:"Every limit of the policy, ordered by scope" json:"limits"`
+	}
+}
+
+// WarmCacheRequest selects how many of the most accessed codes are loaded into the cache.
+type WarmCacheRequest struct {
+	Count int       `default:"100" doc:"Number of most accessed codes to load" maximum:"1000" minimum:"1" query:"count"`
+	Since time.Time `doc:"Only rank accesses at or after this time (RFC 3339); omit for all retained accesses" query:"since"`
+}
+
+// WarmCacheResponse reports the outcome of a cache warm-up.
+type WarmCacheResponse struct {
+	Body struct {
+		Warmed  int      `doc:"Codes loaded into the cache"                    json:"warmed"`
+		Missing int      `doc:"Ranked codes that no longer exist"              json:"missing"`
+		Failed  int      `doc:"Codes whose lookup failed"                      json:"failed"`
+		Codes   []string `doc:"The warmed codes, most accessed first"          json:"codes"`
+	}
+}
+
+// ImportURLsRequest is a multipart upload of a CSV file with code,url[,created_at] rows.
+type ImportURLsRequest struct {
+	RawBody huma.MultipartFormFiles[struct {
+		File huma.FormFile `doc:"CSV file with code,url[,created_at] rows" form:"file" required:"true"`
+	}]
+}
+
+// ImportRowResult is the outcome of importing a single CSV row.
+type ImportRowResult struct {
+	Row    int    `doc:"1-based line number in the file" example:"2"                   json:"row"`
+	Code   string `doc:"The short code of the row"       example:"abc123"              json:"code,omitempty"`
+	Status string `doc:"imported or skipped"             example:"imported"            json:"status"`
+	Error  string `doc:"Why the row was skipped"         example:"code already in use" json:"error,omitempty"`
+}
+
+// ImportURLsResponse summarizes a CSV import.
+type ImportURLsResponse struct {
+	Body struct {
+		Imported int               `doc:"Number of rows saved"   json:"imported"`
+		Skipped  int               `doc:"Number of rows skipped" json:"skipped"`
+		Rows     []ImportRowResult `doc:"Per-row results"        json:"rows"`
 	}
 }
@@ -1,15 +1,26 @@
 package handlers
 
 import (
+	"cmp"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"maps"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/danielgtaylor/huma/v2"
 	"github.com/serroba/web-demo-go/internal/analytics"
+	"github.com/serroba/web-demo-go/internal/audit"
+	"github.com/serroba/web-demo-go/internal/clock"
+	"github.com/serroba/web-demo-go/internal/logging"
 	"github.com/serroba/web-demo-go/internal/messaging"
+	"github.com/serroba/web-demo-go/internal/ratelimit"
 	"github.com/serroba/web-demo-go/internal/shortener"
 	"go.uber.org/zap"
 )
@@ -21,8 +32,197 @@ type URLHandler struct {
 	baseURL            string
 	defaultStrategy    Strategy
 	publishURLCreated  messaging.Publish[analytics.URLCreatedEvent]
+	createdPublishers  map[Strategy]messaging.Publish[analytics.URLCreatedEvent]
 	publishURLAccessed messaging.Publish[analytics.URLAccessedEvent]
 	logger             *zap.Logger
+	caseInsensitive    bool
+	aliasPolicy        shortener.AliasPolicy
+	aliases            *shortener.AliasStrategy
+	redirectStatus     int
+	includeQRURL       bool
+	relativeShortURL   bool
+	notFoundRedirect   string
+	redirectMaxAge     time.Duration
+	redirectLink       bool
+	clock              clock.Clock
+	anonymizeIP        bool
+	publishFailure     PublishFailurePolicy
+	collapseSelf       bool
+	audit              audit.Recorder
+	normalizeOpts      []shortener.NormalizeOption
+	submissions        shortener.RecentSubmissions
+	codeLimitStore     ratelimit.Store
+	codeLimit          ratelimit.LimitConfig
+}
+
+// URLHandlerOption configures optional URLHandler behavior.
+type URLHandlerOption func(*URLHandler)
+
+// WithCaseInsensitiveCodes lowercases incoming codes before lookup.
+// It should be paired with a code generator that only emits lowercase codes.
+func WithCaseInsensitiveCodes() URLHandlerOption {
+	return func(h *URLHandler) {
+		h.caseInsensitive = true
+	}
+}
+
+// WithAudit records an audit entry for every admin change made through the
+// handler, such as updating a code's target.
+func WithAudit(recorder audit.Recorder) URLHandlerOption {
+	return func(h *URLHandler) {
+		h.audit = recorder
+	}
+}
+
+// WithAliasPolicy overrides the validation rules applied to vanity aliases.
+func WithAliasPolicy(policy shortener.AliasPolicy) URLHandlerOption {
+	return func(h *URLHandler) {
+		h.aliasPolicy = policy
+	}
+}
+
+// PublishFailurePolicy decides what CreateShortURL does when the created event cannot be published.
+type PublishFailurePolicy string
+
+const (
+	// PublishFailureIgnore logs the error and still returns the short URL (fail-open, default).
+	PublishFailureIgnore PublishFailurePolicy = "ignore"
+	// PublishFailureFail returns 500 so callers know analytics did not record the URL (fail-closed).
+	PublishFailureFail PublishFailurePolicy = "fail"
+)
+
+// IsValidPublishFailurePolicy reports whether policy is a known publish failure policy.
+func IsValidPublishFailurePolicy(policy PublishFailurePolicy) bool {
+	return policy == PublishFailureIgnore || policy == PublishFailureFail
+}
+
+// WithPublishFailurePolicy sets how create requests react to created-event publish errors.
+func WithPublishFailurePolicy(policy PublishFailurePolicy) URLHandlerOption {
+	return func(h *URLHandler) {
+		h.publishFailure = policy
+	}
+}
+
+// IsValidRedirectStatus reports whether status can be used for redirects.
+func IsValidRedirectStatus(status int) bool {
+	switch status {
+	case http.StatusMovedPermanently, http.StatusFound,
+		http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return true
+	default:
+		return false
+	}
+}
+
+// WithRedirectStatus sets the status code used for redirects (301, 302, 307 or 308).
+// 307 and 308 preserve the request method, which some clients rely on.
+func WithRedirectStatus(status int) URLHandlerOption {
+	return func(h *URLHandler) {
+		h.redirectStatus = status
+	}
+}
+
+// WithStrategyPublishers routes created events to a per-strategy publisher.
+// Strategies without an entry use the default created-event publisher.
+func WithStrategyPublishers(publishers map[Strategy]messaging.Publish[analytics.URLCreatedEvent]) URLHandlerOption {
+	return func(h *URLHandler) {
+		h.createdPublishers = publishers
+	}
+}
+
+// WithDefaultStrategy sets the strategy used when a request does not specify one.
+func WithDefaultStrategy(strategy Strategy) URLHandlerOption {
+	return func(h *URLHandler) {
+		h.defaultStrategy = strategy
+	}
+}
+
+// WithNotFoundRedirect redirects unknown codes to url with a 302 instead of returning 404.
+func WithNotFoundRedirect(url string) URLHandlerOption {
+	return func(h *URLHandler) {
+		h.notFoundRedirect = url
+	}
+}
+
+// WithRedirectCacheMaxAge sets a Cache-Control max-age on redirects so clients and
+// proxies can cache them. A zero duration omits the header.
+func WithRedirectCacheMaxAge(maxAge time.Duration) URLHandlerOption {
+	return func(h *URLHandler) {
+		h.redirectMaxAge = maxAge
+	}
+}
+
+// WithRedirectLinkHeader adds a Link header marking the original URL as canonical on redirects.
+func WithRedirectLinkHeader() URLHandlerOption {
+	return func(h *URLHandler) {
+		h.redirectLink = true
+	}
+}
+
+// WithClock sets the clock used for access timestamps and alias creation times.
+func WithClock(c clock.Clock) URLHandlerOption {
+	return func(h *URLHandler) {
+		h.clock = c
+	}
+}
+
+// WithAnonymizedIPs truncates client IPs with analytics.AnonymizeIP before they are
+// attached to analytics events, so full addresses never leave the handler.
+func WithAnonymizedIPs() URLHandlerOption {
+	return func(h *URLHandler) {
+		h.anonymizeIP = true
+	}
+}
+
+// WithCollapsedSelfRedirects makes create requests for one of this service's own
+// short URLs store the URL it resolves to instead, so redirects never chain.
+func WithCollapsedSelfRedirects() URLHandlerOption {
+	return func(h *URLHandler) {
+		h.collapseSelf = true
+	}
+}
+
+// WithQRURL includes the URL of the code's QR image in create responses.
+func WithQRURL() URLHandlerOption {
+	return func(h *URLHandler) {
+		h.includeQRURL = true
+	}
+}
+
+// WithRelativeShortURLs builds short URLs as root-relative paths such as
+// /abc123 instead of absolute URLs, for gateways that rewrite the public host.
+func WithRelativeShortURLs() URLHandlerOption {
+	return func(h *URLHandler) {
+		h.relativeShortURL = true
+	}
+}
+
+// WithNormalizeOptions passes opts to NormalizeURL when a target is updated.
+func WithNormalizeOptions(opts ...shortener.NormalizeOption) URLHandlerOption {
+	return func(h *URLHandler) {
+		h.normalizeOpts = opts
+	}
+}
+
+// WithRecentSubmissions answers a token-strategy create with the code the same
+// client got for the same URL within the submission window, instead of minting
+// a second code for a double-clicked form. Clients are identified by their
+// creator when authenticated and by client IP otherwise.
+func WithRecentSubmissions(submissions shortener.RecentSubmissions) URLHandlerOption {
+	return func(h *URLHandler) {
+		h.submissions = submissions
+	}
+}
+
+// WithCodeRedirectLimit answers 429 once a single code has been redirected
+// limit.Max times within limit.Window, however many clients are following it,
+// so a leaked code cannot be abused at scale. Redirects are counted in store
+// under a code-keyed namespace, separate from the per-client limits.
+func WithCodeRedirectLimit(store ratelimit.Store, limit ratelimit.LimitConfig) URLHandlerOption {
+	return func(h *URLHandler) {
+		h.codeLimitStore = store
+		h.codeLimit = limit
+	}
 }
 
 // NewURLHandler creates a new URL handler with injected strategies.
@@ -33,8 +233,9 @@ func NewURLHandler(
 	publishURLCreated messaging.Publish[analytics.URLCreatedEvent],
 	publishURLAccessed messaging.Publish[analytics.URLAccessedEvent],
 	logger *zap.Logger,
+	opts ...URLHandlerOption,
 ) *URLHandler {
-	return &URLHandler{
+	h := &URLHandler{
 		strategies:         strategies,
 		store:              store,
 		baseURL:            baseURL,
@@ -42,16 +243,51 @@ func NewURLHandler(
 		publishURLCreated:  publishURLCreated,
 		publishURLAccessed: publishURLAccessed,
 		logger:             logger,
+		aliasPolicy:        shortener.DefaultAliasPolicy(),
+		redirectStatus:     http.StatusMovedPermanently,
+		clock:              clock.Real{},
+		publishFailure:     PublishFailureIgnore,
+		audit:              audit.Nop{},
 	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	h.aliases = shortener.NewAliasStrategy(store, h.aliasPolicy, shortener.WithAliasClock(h.clock))
+
+	return h
+}
+
+// Strategies returns the names of the registered strategies in sorted order.
+func (h *URLHandler) Strategies() []Strategy {
+	return slices.Sorted(maps.Keys(h.strategies))
+}
+
+// DefaultStrategy returns the strategy used when a request does not specify one.
+func (h *URLHandler) DefaultStrategy() Strategy {
+	return h.defaultStrategy
 }
 
 type requestMetaKey struct{}
 
+// NoAnalyticsMetadataKey marks operations that must not publish analytics events.
+const NoAnalyticsMetadataKey = "noAnalytics"
+
+// GlobalThrottleMetadataKey marks operations that share the global request budget
+// enforced by middleware.GlobalThrottle.
+const GlobalThrottleMetadataKey = "globalThrottle"
+
 // RequestMeta holds HTTP request metadata for analytics.
 type RequestMeta struct {
-	ClientIP  string
-	UserAgent string
-	Referrer  string
+	ClientIP       string
+	UserAgent      string
+	Referrer       string
+	ForwardedProto string
+	ForwardedHost  string
+	RequestID      string
+	// SkipAnalytics is set for operations marked with NoAnalyticsMetadataKey.
+	SkipAnalytics bool
 }
 
 // ContextWithRequestMeta adds request metadata to context.
@@ -69,81 +305,613 @@ func RequestMetaFromContext(ctx context.Context) RequestMeta {
 }
 
 func (h *URLHandler) CreateShortURL(ctx context.Context, req *CreateShortURLRequest) (*CreateShortURLResponse, error) {
-	strategyName := req.Body.Strategy
-	if strategyName == "" {
-		strategyName = h.defaultStrategy
+	// Body field, then query parameter, then the configured default
+	strategyName := cmp.Or(req.Body.Strategy, req.Strategy, h.defaultStrategy)
+
+	if h.collapseSelf {
+		target, err := h.collapseSelfRedirects(ctx, req.Body.URL)
+		if err != nil {
+			return nil, err
+		}
+
+		req.Body.URL = target
+	}
+
+	submitter := h.submitter(ctx, strategyName, req)
+	if submitter != "" {
+		if shortURL := h.recentSubmission(ctx, submitter, req.Body.URL); shortURL != nil {
+			return h.createResponse(ctx, shortURL), nil
+		}
+	}
+
+	shortURL, created, err := h.shorten(ctx, strategyName, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if !created {
+		return h.createResponse(ctx, shortURL), nil
+	}
+
+	if req.Body.Alias != "" {
+		strategyName = StrategyAlias
+	}
+
+	if err := h.publishCreated(ctx, strategyName, shortURL); err != nil {
+		return nil, err
+	}
+
+	if submitter != "" {
+		if err := h.submissions.Remember(ctx, submitter, req.Body.URL, shortURL.Code); err != nil {
+			logging.FromContext(ctx, h.logger).Warn("failed to record submission", zap.Error(err))
+		}
+	}
+
+	resp := h.createResponse(ctx, shortURL)
+	if conditionalCreate(req) {
+		resp.Status = http.StatusCreated
+	}
+
+	return resp, nil
+}
+
+// conditionalCreate reports whether req asks for an alias to be created only if
+// it does not exist yet (If-None-Match: *).
+func conditionalCreate(req *CreateShortURLRequest) bool {
+	return req.Body.Alias != "" && strings.TrimSpace(req.IfNoneMatch) == "*"
+}
+
+// submitter returns the client a create is deduplicated for, or "" when the
+// create is not deduplicated: without a submission window, for aliases, for
+// strategies other than token (hash codes are stable already) and for
+// anonymous clients without an IP.
+func (h *URLHandler) submitter(ctx context.Context, strategyName Strategy, req *CreateShortURLRequest) string {
+	if h.submissions == nil || req.Body.Alias != "" || strategyName != StrategyToken {
+		return ""
+	}
+
+	return cmp.Or(shortener.CreatorFromContext(ctx), RequestMetaFromContext(ctx).ClientIP)
+}
+
+// recentSubmission returns the short URL submitter recently got for url, or nil.
+// Lookup failures are logged and treated as no recent submission.
+func (h *URLHandler) recentSubmission(ctx context.Context, submitter, url string) *shortener.ShortURL {
+	code, err := h.submissions.Recent(ctx, submitter, url)
+	if err != nil {
+		logging.FromContext(ctx, h.logger).Warn("failed to look up recent submission", zap.Error(err))
+
+		return nil
+	}
+
+	if code == "" {
+		return nil
+	}
+
+	shortURL, err := h.store.GetByCode(ctx, code)
+	if err != nil {
+		return nil
+	}
+
+	return shortURL
+}
+
+// createResponse builds the create response for shortURL.
+func (h *URLHandler) createResponse(ctx context.Context, shortURL *shortener.ShortURL) *CreateShortURLResponse {
+	fullShortURL := h.buildShortURL(ctx, shortURL.Code)
+
+	resp := &CreateShortURLResponse{Status: http.StatusOK}
+	resp.Headers.Location = fullShortURL
+	resp.Body.Code = string(shortURL.Code)
+	resp.Body.ShortURL = fullShortURL
+	resp.Body.OriginalURL = shortURL.OriginalURL
+	resp.Body.CreatedAt = shortURL.CreatedAt
+	resp.Body.CreatedBy = shortURL.CreatedBy
+
+	if h.includeQRURL {
+		resp.Body.QRURL = fullShortURL + "/qr"
+	}
+
+	return resp
+}
+
+// shortenOptions returns the optional details req sets on a new short URL.
+func shortenOptions(req *CreateShortURLRequest) []shortener.ShortenOption {
+	var opts []shortener.ShortenOption
+
+	if req.Body.FallbackURL != "" {
+		opts = append(opts, shortener.WithFallbackURL(req.Body.FallbackURL))
+	}
+
+	if req.Body.ContentTypeHint != "" {
+		opts = append(opts, shortener.WithContentTypeHint(req.Body.ContentTypeHint))
+	}
+
+	return opts
+}
+
+// shorten saves the URL under the requested alias, or with the chosen strategy when none is given.
+// It reports false when a conditional alias create returned the existing short URL instead.
+func (h *URLHandler) shorten(
+	ctx context.Context,
+	strategyName Strategy,
+	req *CreateShortURLRequest,
+) (*shortener.ShortURL, bool, error) {
+	opts := shortenOptions(req)
+
+	if req.Body.Alias != "" {
+		alias := string(h.normalizeCode(req.Body.Alias))
+
+		var (
+			shortURL *shortener.ShortURL
+			err      error
+		)
+
+		created := true
+		if conditionalCreate(req) {
+			shortURL, created, err = h.aliases.ShortenIfAbsent(ctx, alias, req.Body.URL, opts...)
+		} else {
+			shortURL, err = h.aliases.Shorten(ctx, alias, req.Body.URL, opts...)
+		}
+
+		if err != nil {
+			switch {
+			case errors.Is(err, shortener.ErrInvalidAlias):
+				return nil, false, huma.Error400BadRequest(err.Error())
+			case errors.Is(err, shortener.ErrAliasTaken):
+				return nil, false, huma.Error409Conflict("alias already in use")
+			default:
+				return nil, false, huma.Error500InternalServerError("failed to save url")
+			}
+		}
+
+		return shortURL, created, nil
 	}
 
 	strategy, ok := h.strategies[strategyName]
 	if !ok {
-		return nil, huma.Error400BadRequest("invalid strategy: must be 'token' or 'hash'")
+		return nil, false, huma.Error400BadRequest(InvalidStrategyError(h.strategies).Error())
 	}
 
-	shortURL, err := strategy.Shorten(ctx, req.Body.URL)
+	shortURL, err := strategy.Shorten(ctx, req.Body.URL, opts...)
 	if err != nil {
-		return nil, huma.Error500InternalServerError("failed to save url")
+		if errors.Is(err, shortener.ErrInvalidURL) {
+			return nil, false, huma.Error400BadRequest(err.Error())
+		}
+
+		return nil, false, huma.Error500InternalServerError("failed to save url")
 	}
 
-	// Publish analytics event
+	return shortURL, true, nil
+}
+
+// publishCreated publishes the created event unless the operation opted out of
+// analytics. It only returns an error under the fail publish failure policy.
+func (h *URLHandler) publishCreated(ctx context.Context, strategyName Strategy, shortURL *shortener.ShortURL) error {
 	meta := RequestMetaFromContext(ctx)
+	if meta.SkipAnalytics {
+		return nil
+	}
+
 	event := &analytics.URLCreatedEvent{
 		Code:        string(shortURL.Code),
 		OriginalURL: shortURL.OriginalURL,
 		URLHash:     string(shortURL.URLHash),
 		Strategy:    string(strategyName),
 		CreatedAt:   shortURL.CreatedAt,
-		ClientIP:    meta.ClientIP,
+		ClientIP:    h.clientIP(meta),
 		UserAgent:   meta.UserAgent,
 	}
 
-	if err := h.publishURLCreated(event); err != nil {
-		h.logger.Error("failed to publish analytics event",
+	if err := h.createdPublisher(strategyName)(event); err != nil {
+		logging.FromContext(ctx, h.logger).Error("failed to publish analytics event",
 			zap.String("code", event.Code),
 			zap.Error(err),
 		)
+
+		if h.publishFailure == PublishFailureFail {
+			return huma.Error500InternalServerError("failed to record short url")
+		}
 	}
 
-	fullShortURL := fmt.Sprintf("%s/%s", h.baseURL, shortURL.Code)
+	return nil
+}
 
-	resp := &CreateShortURLResponse{}
-	resp.Headers.Location = fullShortURL
-	resp.Body.Code = string(shortURL.Code)
-	resp.Body.ShortURL = fullShortURL
-	resp.Body.OriginalURL = shortURL.OriginalURL
+// publishAccessed publishes the accessed event unless the operation opted out
+// of analytics. Failures are logged and never fail the redirect.
+func (h *URLHandler) publishAccessed(ctx context.Context, code shortener.Code) {
+	meta := RequestMetaFromContext(ctx)
+	if meta.SkipAnalytics {
+		return
+	}
 
-	return resp, nil
+	event := &analytics.URLAccessedEvent{
+		Code:       string(code),
+		AccessedAt: h.clock.Now(),
+		ClientIP:   h.clientIP(meta),
+		UserAgent:  meta.UserAgent,
+		Referrer:   meta.Referrer,
+	}
+
+	if err := h.publishURLAccessed(event); err != nil {
+		// Drops from a full buffer are counted by the publisher; logging each
+		// one would flood the logs exactly when the broker is struggling.
+		if errors.Is(err, messaging.ErrBufferFull) {
+			return
+		}
+
+		logging.FromContext(ctx, h.logger).Error("failed to publish access event",
+			zap.String("code", event.Code),
+			zap.Error(err),
+		)
+	}
+}
+
+// createdPublisher returns the publisher for created events of the given strategy.
+func (h *URLHandler) createdPublisher(strategy Strategy) messaging.Publish[analytics.URLCreatedEvent] {
+	if publish, ok := h.createdPublishers[strategy]; ok {
+		return publish
+	}
+
+	return h.publishURLCreated
+}
+
+// buildShortURL forms the full short URL for a code, using the base URL resolved for the request,
+// or its root-relative path when relative short URLs are enabled.
+func (h *URLHandler) buildShortURL(ctx context.Context, code shortener.Code) string {
+	if h.relativeShortURL {
+		return "/" + string(code)
+	}
+
+	baseURL := resolveBaseURL(h.baseURL, RequestMetaFromContext(ctx))
+
+	return fmt.Sprintf("%s/%s", baseURL, code)
+}
+
+// clientIP returns the client IP recorded in analytics events, anonymized if configured.
+func (h *URLHandler) clientIP(meta RequestMeta) string {
+	if h.anonymizeIP {
+		return analytics.AnonymizeIP(meta.ClientIP)
+	}
+
+	return meta.ClientIP
+}
+
+// normalizeCode applies the handler's code casing rules to an incoming code.
+func (h *URLHandler) normalizeCode(code string) shortener.Code {
+	if h.caseInsensitive {
+		code = strings.ToLower(code)
+	}
+
+	return shortener.Code(code)
 }
 
 func (h *URLHandler) RedirectToURL(ctx context.Context, req *RedirectRequest) (*RedirectResponse, error) {
-	shortURL, err := h.store.GetByCode(ctx, shortener.Code(req.Code))
+	code := h.normalizeCode(req.Code)
+
+	shortURL, err := h.store.GetByCode(ctx, code)
 	if err != nil {
 		if errors.Is(err, shortener.ErrNotFound) {
+			if h.notFoundRedirect != "" {
+				resp := &RedirectResponse{Status: http.StatusFound}
+				resp.Headers.Location = h.notFoundRedirect
+
+				return resp, nil
+			}
+
 			return nil, huma.Error404NotFound("short url not found")
 		}
 
 		return nil, huma.Error500InternalServerError("failed to get url")
 	}
 
-	meta := RequestMetaFromContext(ctx)
-	event := &analytics.URLAccessedEvent{
-		Code:       req.Code,
-		AccessedAt: time.Now(),
-		ClientIP:   meta.ClientIP,
-		UserAgent:  meta.UserAgent,
-		Referrer:   meta.Referrer,
+	if shortURL.Flagged && shortURL.FallbackURL == "" {
+		return nil, huma.Error410Gone("short url target is disabled")
 	}
 
-	if err = h.publishURLAccessed(event); err != nil {
-		h.logger.Error("failed to publish access event",
-			zap.String("code", event.Code),
-			zap.Error(err),
-		)
+	if h.codeRedirectLimited(ctx, code) {
+		return nil, huma.Error429TooManyRequests("too many redirects for this short url")
+	}
+
+	h.publishAccessed(ctx, code)
+
+	// A flagged URL may be fixed later, so the fallback is a temporary redirect that is never cached.
+	if shortURL.Flagged {
+		resp := &RedirectResponse{Status: http.StatusFound}
+		resp.Headers.Location = shortURL.FallbackURL
+
+		return resp, nil
 	}
 
 	resp := &RedirectResponse{
-		Status: http.StatusMovedPermanently,
+		Status: h.redirectStatus,
 	}
 	resp.Headers.Location = shortURL.OriginalURL
 
+	if h.redirectMaxAge > 0 {
+		resp.Headers.CacheControl = fmt.Sprintf("public, max-age=%d", int64(h.redirectMaxAge.Seconds()))
+	}
+
+	if h.redirectLink {
+		resp.Headers.Link = fmt.Sprintf(`<%s>; rel="canonical"`, shortURL.OriginalURL)
+	}
+
+	return resp, nil
+}
+
+// codeRedirectLimited records a redirect of code and reports whether it exceeds
+// the per-code limit. Only known codes are counted, so scanners cannot fill
+// the store with keys. Store failures are logged and let the redirect through.
+func (h *URLHandler) codeRedirectLimited(ctx context.Context, code shortener.Code) bool {
+	if h.codeLimitStore == nil {
+		return false
+	}
+
+	count, err := h.codeLimitStore.Record(ctx, "code:"+string(code), h.codeLimit.Window)
+	if err != nil {
+		logging.FromContext(ctx, h.logger).Warn("failed to record code redirect", zap.Error(err))
+
+		return false
+	}
+
+	if count <= h.codeLimit.Max {
+		return false
+	}
+
+	logging.FromContext(ctx, h.logger).Warn("code redirect limit exceeded",
+		zap.String("code", string(code)),
+		zap.Int64("count", count),
+		zap.Int64("max", h.codeLimit.Max),
+		zap.Duration("window", h.codeLimit.Window),
+	)
+
+	return true
+}
+
+// RecentURLs returns the most recently created short URLs, newest first.
+func (h *URLHandler) RecentURLs(ctx context.Context, req *RecentURLsRequest) (*RecentURLsResponse, error) {
+	shortURLs, err := h.store.MostRecent(ctx, req.Limit)
+	if err != nil {
+		logging.FromContext(ctx, h.logger).Error("failed to load recent urls",
+			zap.Int("limit", req.Limit),
+			zap.Error(err),
+		)
+
+		return nil, huma.Error500InternalServerError("failed to load recent urls")
+	}
+
+	return h.listResponse(ctx, shortURLs), nil
+}
+
+// MyURLs returns the short URLs created with the caller's API key, newest first.
+func (h *URLHandler) MyURLs(ctx context.Context, req *MyURLsRequest) (*RecentURLsResponse, error) {
+	creator := shortener.CreatorFromContext(ctx)
+	if creator == "" {
+		return nil, huma.Error401Unauthorized("an API key is required")
+	}
+
+	shortURLs, err := h.store.ListByCreator(ctx, creator, req.Limit)
+	if err != nil {
+		logging.FromContext(ctx, h.logger).Error("failed to load creator urls",
+			zap.String("creator", creator),
+			zap.Int("limit", req.Limit),
+			zap.Error(err),
+		)
+
+		return nil, huma.Error500InternalServerError("failed to load short urls")
+	}
+
+	return h.listResponse(ctx, shortURLs), nil
+}
+
+// listResponse lists shortURLs in the order given.
+func (h *URLHandler) listResponse(ctx context.Context, shortURLs []*shortener.ShortURL) *RecentURLsResponse {
+	resp := &RecentURLsResponse{Body: make([]RecentURL, 0, len(shortURLs))}
+	for _, shortURL := range shortURLs {
+		resp.Body = append(resp.Body, RecentURL{
+			Code:        string(shortURL.Code),
+			ShortURL:    h.buildShortURL(ctx, shortURL.Code),
+			OriginalURL: shortURL.OriginalURL,
+			CreatedAt:   shortURL.CreatedAt,
+			CreatedBy:   shortURL.CreatedBy,
+		})
+	}
+
+	return resp
+}
+
+// URLMetadata returns a short URL with its access count, read in a single store call.
+func (h *URLHandler) URLMetadata(ctx context.Context, req *URLMetadataRequest) (*URLMetadataResponse, error) {
+	code := h.normalizeCode(req.Code)
+
+	stats, err := h.store.GetWithStats(ctx, code)
+	if err != nil {
+		if errors.Is(err, shortener.ErrNotFound) {
+			return nil, huma.Error404NotFound("short url not found")
+		}
+
+		logging.FromContext(ctx, h.logger).Error("failed to load short url metadata",
+			zap.String("code", string(code)),
+			zap.Error(err),
+		)
+
+		return nil, huma.Error500InternalServerError("failed to load short url")
+	}
+
+	resp := &URLMetadataResponse{}
+	resp.Body.RecentURL = RecentURL{
+		Code:        string(stats.ShortURL.Code),
+		ShortURL:    h.buildShortURL(ctx, stats.ShortURL.Code),
+		OriginalURL: stats.ShortURL.OriginalURL,
+		CreatedAt:   stats.ShortURL.CreatedAt,
+		CreatedBy:   stats.ShortURL.CreatedBy,
+	}
+	resp.Body.AccessCount = stats.AccessCount
+	resp.Body.ContentTypeHint = stats.ShortURL.ContentTypeHint
+
+	return resp, nil
+}
+
+// CheckAvailability reports which candidate codes could be requested as vanity
+// aliases. Codes that fail the alias policy are invalid; the rest are looked up
+// with a single Exists call.
+func (h *URLHandler) CheckAvailability(
+	ctx context.Context,
+	req *CheckAvailabilityRequest,
+) (*CheckAvailabilityResponse, error) {
+	resp := &CheckAvailabilityResponse{}
+	resp.Body.Codes = make(map[string]CodeAvailability, len(req.Body.Codes))
+
+	var candidates []shortener.Code
+
+	for _, code := range req.Body.Codes {
+		if err := h.aliasPolicy.Validate(code); err != nil {
+			resp.Body.Codes[code] = CodeAvailability{Reason: "invalid"}
+
+			continue
+		}
+
+		candidates = append(candidates, h.normalizeCode(code))
+	}
+
+	if len(candidates) == 0 {
+		return resp, nil
+	}
+
+	exists, err := h.store.Exists(ctx, candidates)
+	if err != nil {
+		logging.FromContext(ctx, h.logger).Error("failed to check code availability",
+			zap.Int("codes", len(candidates)),
+			zap.Error(err),
+		)
+
+		return nil, huma.Error500InternalServerError("failed to check availability")
+	}
+
+	for _, code := range req.Body.Codes {
+		if _, invalid := resp.Body.Codes[code]; invalid {
+			continue
+		}
+
+		if exists[h.normalizeCode(code)] {
+			resp.Body.Codes[code] = CodeAvailability{Reason: "taken"}
+		} else {
+			resp.Body.Codes[code] = CodeAvailability{Available: true}
+		}
+	}
+
 	return resp, nil
 }
+
+// UpdateTarget points an existing code at a new URL. The URL is stored as given,
+// so redirects keep its exact form; its normalized form, as the hash strategy
+// computes it, only rehashes the code.
+func (h *URLHandler) UpdateTarget(ctx context.Context, req *UpdateTargetRequest) (*UpdateTargetResponse, error) {
+	code := h.normalizeCode(req.Code)
+
+	newURL := req.Body.URL
+
+	normalizedURL, err := shortener.NormalizeURL(newURL, h.normalizeOpts...)
+	if err != nil {
+		return nil, huma.Error400BadRequest(err.Error())
+	}
+
+	if err = h.store.UpdateTarget(ctx, code, newURL, normalizedURL); err != nil {
+		if errors.Is(err, shortener.ErrNotFound) {
+			return nil, huma.Error404NotFound("short url not found")
+		}
+
+		logging.FromContext(ctx, h.logger).Error("failed to update short url target",
+			zap.String("code", string(code)),
+			zap.Error(err),
+		)
+
+		return nil, huma.Error500InternalServerError("failed to update short url")
+	}
+
+	h.audit.Record(ctx, auditEntry(ctx, AuditActionUpdateTarget, string(code), newURL))
+
+	resp := &UpdateTargetResponse{}
+	resp.Body.Code = string(code)
+	resp.Body.ShortURL = h.buildShortURL(ctx, code)
+	resp.Body.OriginalURL = newURL
+
+	return resp, nil
+}
+
+// FlagURL flags a code's target as bad, or clears the flag. Flagged codes
+// redirect to their fallback URL, or return 410 when they have none.
+func (h *URLHandler) FlagURL(ctx context.Context, req *FlagURLRequest) (*FlagURLResponse, error) {
+	code := h.normalizeCode(req.Code)
+
+	if err := h.store.Flag(ctx, code, req.Body.Flagged); err != nil {
+		if errors.Is(err, shortener.ErrNotFound) {
+			return nil, huma.Error404NotFound("short url not found")
+		}
+
+		logging.FromContext(ctx, h.logger).Error("failed to flag short url",
+			zap.String("code", string(code)),
+			zap.Error(err),
+		)
+
+		return nil, huma.Error500InternalServerError("failed to flag short url")
+	}
+
+	h.audit.Record(ctx, auditEntry(ctx, AuditActionFlagURL, string(code), strconv.FormatBool(req.Body.Flagged)))
+
+	resp := &FlagURLResponse{}
+	resp.Body.Code = string(code)
+	resp.Body.Flagged = req.Body.Flagged
+
+	return resp, nil
+}
+
+// resolveCacheControl lets CDNs keep resolutions for an hour, then revalidate with
+// the ETag, since an admin may repoint a code with PUT /api/urls/{code}.
+const resolveCacheControl = "public, max-age=3600, must-revalidate"
+
+// ResolveURL returns the URL a code points to as JSON without redirecting or
+// publishing an access event, so edge caches can serve resolutions directly.
+func (h *URLHandler) ResolveURL(ctx context.Context, req *ResolveRequest) (*ResolveResponse, error) {
+	code := h.normalizeCode(req.Code)
+
+	shortURL, err := h.store.GetByCode(ctx, code)
+	if err != nil {
+		if errors.Is(err, shortener.ErrNotFound) {
+			return nil, huma.Error404NotFound("short url not found")
+		}
+
+		return nil, huma.Error500InternalServerError("failed to get url")
+	}
+
+	if shortURL.Flagged && shortURL.FallbackURL == "" {
+		return nil, huma.Error410Gone("short url target is disabled")
+	}
+
+	resp := &ResolveResponse{}
+	resp.Body.Code = string(code)
+	resp.Body.URL = shortURL.OriginalURL
+	resp.CacheControl = resolveCacheControl
+
+	// Like redirects, a flagged URL may be fixed later, so its fallback is never cached.
+	if shortURL.Flagged {
+		resp.Body.URL = shortURL.FallbackURL
+		resp.CacheControl = "no-cache"
+	}
+
+	etag := resolveETag(resp.Body.Code, resp.Body.URL)
+	resp.ETag = `"` + etag + `"`
+
+	// A matching If-None-Match short-circuits with 304 Not Modified.
+	if statusErr := req.PreconditionFailed(etag, time.Time{}); statusErr != nil {
+		return nil, statusErr
+	}
+
+	return resp, nil
+}
+
+// resolveETag derives an unquoted strong entity tag from a code and the URL it resolves to.
+func resolveETag(code, url string) string {
+	sum := sha256.Sum256([]byte(code + "\x00" + url))
+
+	return hex.EncodeToString(sum[:16])
+}
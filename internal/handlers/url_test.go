@@ -2,19 +2,31 @@ package handlers_test
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"testing"
+	"time"
 
+	"github.com/danielgtaylor/huma/v2"
 	"github.com/jaevor/go-nanoid"
 	"github.com/serroba/web-demo-go/internal/analytics"
+	"github.com/serroba/web-demo-go/internal/audit"
+	"github.com/serroba/web-demo-go/internal/cache"
+	"github.com/serroba/web-demo-go/internal/clock"
 	"github.com/serroba/web-demo-go/internal/handlers"
+	"github.com/serroba/web-demo-go/internal/logging"
 	"github.com/serroba/web-demo-go/internal/messaging"
+	"github.com/serroba/web-demo-go/internal/ratelimit"
+	ratelimitstore "github.com/serroba/web-demo-go/internal/ratelimit/store"
 	"github.com/serroba/web-demo-go/internal/shortener"
 	"github.com/serroba/web-demo-go/internal/store"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 // noopPublish returns a publish function that always succeeds.
@@ -27,25 +39,26 @@ func errorPublish[T any](err error) messaging.Publish[T] {
 	return func(_ *T) error { return err }
 }
 
-func newTestHandler(s shortener.Repository) *handlers.URLHandler {
-	gen, _ := nanoid.Standard(8)
-
-	strategies := map[handlers.Strategy]shortener.Strategy{
-		handlers.StrategyToken: shortener.NewTokenStrategy(s, gen),
-		handlers.StrategyHash:  shortener.NewHashStrategy(s, gen),
-	}
+func newTestHandler(s shortener.Repository, opts ...handlers.URLHandlerOption) *handlers.URLHandler {
+	return newTestHandlerWithPublishers(s,
+		noopPublish[analytics.URLCreatedEvent](), noopPublish[analytics.URLAccessedEvent](), opts...)
+}
 
-	return handlers.NewURLHandler(
-		s,
-		"http://localhost:8888",
-		strategies,
-		noopPublish[analytics.URLCreatedEvent](),
-		noopPublish[analytics.URLAccessedEvent](),
-		zap.NewNop(),
-	)
+func newTestHandlerWithPublishError(s shortener.Repository, opts ...handlers.URLHandlerOption) *handlers.URLHandler {
+	return newTestHandlerWithPublishers(s,
+		errorPublish[analytics.URLCreatedEvent](errors.New("publish error")),
+		errorPublish[analytics.URLAccessedEvent](errors.New("publish error")),
+		opts...)
 }
 
-func newTestHandlerWithPublishError(s shortener.Repository) *handlers.URLHandler {
+// newTestHandlerWithPublishers builds a handler with the token and hash
+// strategies that sends events to the given publish functions.
+func newTestHandlerWithPublishers(
+	s shortener.Repository,
+	publishCreated messaging.Publish[analytics.URLCreatedEvent],
+	publishAccessed messaging.Publish[analytics.URLAccessedEvent],
+	opts ...handlers.URLHandlerOption,
+) *handlers.URLHandler {
 	gen, _ := nanoid.Standard(8)
 
 	strategies := map[handlers.Strategy]shortener.Strategy{
@@ -57,9 +70,10 @@ func newTestHandlerWithPublishError(s shortener.Repository) *handlers.URLHandler
 		s,
 		"http://localhost:8888",
 		strategies,
-		errorPublish[analytics.URLCreatedEvent](errors.New("publish error")),
-		errorPublish[analytics.URLAccessedEvent](errors.New("publish error")),
+		publishCreated,
+		publishAccessed,
 		zap.NewNop(),
+		opts...,
 	)
 }
 
@@ -78,6 +92,7 @@ func TestCreateShortURL(t *testing.T) {
 		assert.Equal(t, "https://example.com/very/long/path", resp.Body.OriginalURL)
 		assert.Contains(t, resp.Body.ShortURL, resp.Body.Code)
 		assert.Equal(t, resp.Body.ShortURL, resp.Headers.Location)
+		assert.False(t, resp.Body.CreatedAt.IsZero())
 	})
 
 	t.Run("returns error for invalid strategy", func(t *testing.T) {
@@ -91,7 +106,11 @@ func TestCreateShortURL(t *testing.T) {
 		resp, err := handler.CreateShortURL(context.Background(), req)
 
 		assert.Nil(t, resp)
-		assert.Error(t, err)
+
+		var statusErr huma.StatusError
+		require.ErrorAs(t, err, &statusErr)
+		assert.Equal(t, http.StatusBadRequest, statusErr.GetStatus())
+		assert.Contains(t, err.Error(), "invalid strategy: supported strategies: 'hash', 'token'")
 	})
 
 	t.Run("token strategy creates new code for same URL", func(t *testing.T) {
@@ -124,6 +143,7 @@ func TestCreateShortURL(t *testing.T) {
 		require.NoError(t, err1)
 		require.NoError(t, err2)
 		assert.Equal(t, resp1.Body.Code, resp2.Body.Code)
+		assert.Equal(t, resp1.Body.CreatedAt, resp2.Body.CreatedAt, "dedup should report the original creation time")
 	})
 
 	t.Run("hash strategy returns same code for equivalent URLs", func(t *testing.T) {
@@ -184,6 +204,42 @@ func TestCreateShortURL(t *testing.T) {
 	})
 }
 
+func TestCreateShortURL_HashHostCase(t *testing.T) {
+	const (
+		firstURL  = "https://Example.COM/Docs/Page"
+		secondURL = "https://example.com/Docs/Page"
+	)
+
+	create := func(handler *handlers.URLHandler, url string) string {
+		req := &handlers.CreateShortURLRequest{}
+		req.Body.URL = url
+		req.Body.Strategy = handlers.StrategyHash
+
+		resp, err := handler.CreateShortURL(context.Background(), req)
+		require.NoError(t, err)
+
+		return resp.Body.Code
+	}
+
+	t.Run("dedups host case while redirecting to the first stored url", func(t *testing.T) {
+		handler := newTestHandler(store.NewMemoryStore())
+
+		code := create(handler, firstURL)
+		assert.Equal(t, code, create(handler, secondURL))
+
+		resp, err := handler.RedirectToURL(context.Background(), &handlers.RedirectRequest{Code: code})
+
+		require.NoError(t, err)
+		assert.Equal(t, firstURL, resp.Headers.Location)
+	})
+
+	t.Run("keeps path case in the dedup key", func(t *testing.T) {
+		handler := newTestHandler(store.NewMemoryStore())
+
+		assert.NotEqual(t, create(handler, firstURL), create(handler, "https://example.com/docs/page"))
+	})
+}
+
 func TestRedirectToURL(t *testing.T) {
 	t.Run("redirects to original url", func(t *testing.T) {
 		memStore := store.NewMemoryStore()
@@ -227,6 +283,130 @@ func TestRedirectToURL(t *testing.T) {
 	})
 }
 
+func TestUpdateTarget(t *testing.T) {
+	t.Run("redirects to the new url after an update", func(t *testing.T) {
+		memStore := store.NewMemoryStore()
+		handler := newTestHandler(memStore)
+
+		create := &handlers.CreateShortURLRequest{}
+		create.Body.URL = testURL
+		create.Body.Strategy = handlers.StrategyHash
+
+		created, err := handler.CreateShortURL(context.Background(), create)
+		require.NoError(t, err)
+
+		req := &handlers.UpdateTargetRequest{Code: created.Body.Code}
+		req.Body.URL = "HTTPS://Example.org/new"
+
+		resp, err := handler.UpdateTarget(context.Background(), req)
+
+		require.NoError(t, err)
+		assert.Equal(t, created.Body.Code, resp.Body.Code)
+		assert.Equal(t, "HTTPS://Example.org/new", resp.Body.OriginalURL)
+
+		redirect, err := handler.RedirectToURL(context.Background(), &handlers.RedirectRequest{Code: created.Body.Code})
+
+		require.NoError(t, err)
+		assert.Equal(t, "HTTPS://Example.org/new", redirect.Headers.Location, "redirects keep the url as given")
+
+		rehashed := &handlers.CreateShortURLRequest{}
+		rehashed.Body.URL = "https://example.org/new/"
+		rehashed.Body.Strategy = handlers.StrategyHash
+
+		same, err := handler.CreateShortURL(context.Background(), rehashed)
+		require.NoError(t, err)
+		assert.Equal(t, created.Body.Code, same.Body.Code, "the code is rehashed from the normalized url")
+	})
+
+	t.Run("records an audit entry with the actor and target", func(t *testing.T) {
+		memStore := store.NewMemoryStore()
+		_ = memStore.Save(context.Background(), &shortener.ShortURL{Code: "abc123", OriginalURL: testURL})
+		recorder := &auditRecorder{}
+		handler := newTestHandler(memStore, handlers.WithAudit(recorder))
+
+		ctx := audit.ContextWithActor(context.Background(), "admin:1234abcd")
+		ctx = handlers.ContextWithRequestMeta(ctx, handlers.RequestMeta{ClientIP: "10.0.0.1"})
+
+		req := &handlers.UpdateTargetRequest{Code: "abc123"}
+		req.Body.URL = "https://example.org/new"
+
+		_, err := handler.UpdateTarget(ctx, req)
+
+		require.NoError(t, err)
+		require.Len(t, recorder.entries, 1)
+		assert.Equal(t, audit.Entry{
+			Actor:    "admin:1234abcd",
+			Action:   handlers.AuditActionUpdateTarget,
+			Target:   "abc123",
+			Detail:   "https://example.org/new",
+			ClientIP: "10.0.0.1",
+		}, recorder.entries[0])
+	})
+
+	t.Run("returns 404 when code not found", func(t *testing.T) {
+		recorder := &auditRecorder{}
+		handler := newTestHandler(store.NewMemoryStore(), handlers.WithAudit(recorder))
+
+		req := &handlers.UpdateTargetRequest{Code: "notfound"}
+		req.Body.URL = testURL
+
+		resp, err := handler.UpdateTarget(context.Background(), req)
+
+		assert.Nil(t, resp)
+
+		var statusErr huma.StatusError
+		require.ErrorAs(t, err, &statusErr)
+		assert.Equal(t, http.StatusNotFound, statusErr.GetStatus())
+		assert.Empty(t, recorder.entries, "failed changes are not audited")
+	})
+
+	t.Run("returns 400 for an unparseable url", func(t *testing.T) {
+		handler := newTestHandler(&mockStore{})
+
+		req := &handlers.UpdateTargetRequest{Code: "abc123"}
+		req.Body.URL = "http://[::1"
+
+		resp, err := handler.UpdateTarget(context.Background(), req)
+
+		assert.Nil(t, resp)
+
+		var statusErr huma.StatusError
+		require.ErrorAs(t, err, &statusErr)
+		assert.Equal(t, http.StatusBadRequest, statusErr.GetStatus())
+	})
+
+	t.Run("returns 400 for an over-deep path", func(t *testing.T) {
+		handler := newTestHandler(&mockStore{}, handlers.WithNormalizeOptions(shortener.WithMaxPathSegments(2)))
+
+		req := &handlers.UpdateTargetRequest{Code: "abc123"}
+		req.Body.URL = "https://example.org/a/b/c"
+
+		resp, err := handler.UpdateTarget(context.Background(), req)
+
+		assert.Nil(t, resp)
+
+		var statusErr huma.StatusError
+		require.ErrorAs(t, err, &statusErr)
+		assert.Equal(t, http.StatusBadRequest, statusErr.GetStatus())
+		assert.Contains(t, statusErr.Error(), "at most 2 segments")
+	})
+
+	t.Run("returns 500 on store error", func(t *testing.T) {
+		handler := newTestHandler(&mockStore{updateErr: errMock})
+
+		req := &handlers.UpdateTargetRequest{Code: "abc123"}
+		req.Body.URL = testURL
+
+		resp, err := handler.UpdateTarget(context.Background(), req)
+
+		assert.Nil(t, resp)
+
+		var statusErr huma.StatusError
+		require.ErrorAs(t, err, &statusErr)
+		assert.Equal(t, http.StatusInternalServerError, statusErr.GetStatus())
+	})
+}
+
 func TestCreateShortURL_ErrorPaths(t *testing.T) {
 	t.Run("token strategy returns error when save fails", func(t *testing.T) {
 		mockStore := &mockStore{
@@ -327,6 +507,72 @@ func TestCreateShortURL_PublishError(t *testing.T) {
 		require.NoError(t, err)
 		assert.NotEmpty(t, resp.Body.Code)
 	})
+	t.Run("logs publish error with request-scoped logger", func(t *testing.T) {
+		core, logs := observer.New(zapcore.ErrorLevel)
+		handler := newTestHandlerWithPublishError(store.NewMemoryStore())
+
+		ctx := logging.WithLogger(context.Background(), zap.New(core).With(zap.String("request_id", "req-1")))
+
+		req := &handlers.CreateShortURLRequest{}
+		req.Body.URL = testURL
+
+		_, err := handler.CreateShortURL(ctx, req)
+
+		require.NoError(t, err)
+		require.Equal(t, 1, logs.Len())
+		assert.Equal(t, "req-1", logs.All()[0].ContextMap()["request_id"])
+	})
+}
+
+func TestCreateShortURL_PublishFailurePolicy(t *testing.T) {
+	t.Run("ignore returns the short url", func(t *testing.T) {
+		handler := newTestHandlerWithPublishError(store.NewMemoryStore(),
+			handlers.WithPublishFailurePolicy(handlers.PublishFailureIgnore))
+
+		req := &handlers.CreateShortURLRequest{}
+		req.Body.URL = testURL
+
+		resp, err := handler.CreateShortURL(context.Background(), req)
+
+		require.NoError(t, err)
+		assert.NotEmpty(t, resp.Body.Code)
+	})
+
+	t.Run("fail returns 500", func(t *testing.T) {
+		handler := newTestHandlerWithPublishError(store.NewMemoryStore(),
+			handlers.WithPublishFailurePolicy(handlers.PublishFailureFail))
+
+		req := &handlers.CreateShortURLRequest{}
+		req.Body.URL = testURL
+
+		resp, err := handler.CreateShortURL(context.Background(), req)
+
+		assert.Nil(t, resp)
+
+		var statusErr huma.StatusError
+		require.ErrorAs(t, err, &statusErr)
+		assert.Equal(t, http.StatusInternalServerError, statusErr.GetStatus())
+	})
+
+	t.Run("fail still succeeds when publishing works", func(t *testing.T) {
+		handler := newTestHandler(store.NewMemoryStore(),
+			handlers.WithPublishFailurePolicy(handlers.PublishFailureFail))
+
+		req := &handlers.CreateShortURLRequest{}
+		req.Body.URL = testURL
+
+		resp, err := handler.CreateShortURL(context.Background(), req)
+
+		require.NoError(t, err)
+		assert.NotEmpty(t, resp.Body.Code)
+	})
+}
+
+func TestIsValidPublishFailurePolicy(t *testing.T) {
+	assert.True(t, handlers.IsValidPublishFailurePolicy(handlers.PublishFailureIgnore))
+	assert.True(t, handlers.IsValidPublishFailurePolicy(handlers.PublishFailureFail))
+	assert.False(t, handlers.IsValidPublishFailurePolicy(""))
+	assert.False(t, handlers.IsValidPublishFailurePolicy("retry"))
 }
 
 func TestRedirectToURL_WithRequestMeta(t *testing.T) {
@@ -372,3 +618,1439 @@ func TestRedirectToURL_PublishError(t *testing.T) {
 		assert.Equal(t, http.StatusMovedPermanently, resp.Status)
 	})
 }
+
+func TestCreateShortURL_ForwardedHeaders(t *testing.T) {
+	t.Run("uses https when X-Forwarded-Proto is https", func(t *testing.T) {
+		memStore := store.NewMemoryStore()
+		handler := newTestHandler(memStore)
+
+		ctx := handlers.ContextWithRequestMeta(context.Background(), handlers.RequestMeta{
+			ForwardedProto: "https",
+		})
+
+		req := &handlers.CreateShortURLRequest{}
+		req.Body.URL = testURL
+
+		resp, err := handler.CreateShortURL(ctx, req)
+
+		require.NoError(t, err)
+		assert.Equal(t, "https://localhost:8888/"+resp.Body.Code, resp.Body.ShortURL)
+		assert.Equal(t, resp.Body.ShortURL, resp.Headers.Location)
+	})
+
+	t.Run("uses X-Forwarded-Host when present", func(t *testing.T) {
+		memStore := store.NewMemoryStore()
+		handler := newTestHandler(memStore)
+
+		ctx := handlers.ContextWithRequestMeta(context.Background(), handlers.RequestMeta{
+			ForwardedProto: "https, http",
+			ForwardedHost:  "sho.rt",
+		})
+
+		req := &handlers.CreateShortURLRequest{}
+		req.Body.URL = testURL
+
+		resp, err := handler.CreateShortURL(ctx, req)
+
+		require.NoError(t, err)
+		assert.Equal(t, "https://sho.rt/"+resp.Body.Code, resp.Body.ShortURL)
+	})
+
+	t.Run("ignores unknown forwarded proto", func(t *testing.T) {
+		memStore := store.NewMemoryStore()
+		handler := newTestHandler(memStore)
+
+		ctx := handlers.ContextWithRequestMeta(context.Background(), handlers.RequestMeta{
+			ForwardedProto: "javascript",
+		})
+
+		req := &handlers.CreateShortURLRequest{}
+		req.Body.URL = testURL
+
+		resp, err := handler.CreateShortURL(ctx, req)
+
+		require.NoError(t, err)
+		assert.Equal(t, "http://localhost:8888/"+resp.Body.Code, resp.Body.ShortURL)
+	})
+}
+
+func TestRedirectToURL_CaseInsensitiveCodes(t *testing.T) {
+	t.Run("resolves uppercase code when enabled", func(t *testing.T) {
+		memStore := store.NewMemoryStore()
+		_ = memStore.Save(context.Background(), &shortener.ShortURL{
+			Code:        "abc123",
+			OriginalURL: testURL,
+		})
+		handler := newTestHandler(memStore, handlers.WithCaseInsensitiveCodes())
+
+		resp, err := handler.RedirectToURL(context.Background(), &handlers.RedirectRequest{Code: "ABC123"})
+
+		require.NoError(t, err)
+		assert.Equal(t, testURL, resp.Headers.Location)
+	})
+
+	t.Run("is case-sensitive when disabled", func(t *testing.T) {
+		memStore := store.NewMemoryStore()
+		_ = memStore.Save(context.Background(), &shortener.ShortURL{
+			Code:        "abc123",
+			OriginalURL: testURL,
+		})
+		handler := newTestHandler(memStore)
+
+		resp, err := handler.RedirectToURL(context.Background(), &handlers.RedirectRequest{Code: "ABC123"})
+
+		assert.Nil(t, resp)
+		assert.Error(t, err)
+	})
+}
+
+func TestCreateShortURL_Alias(t *testing.T) {
+	aliasPolicy := handlers.WithAliasPolicy(shortener.AliasPolicy{MaxLength: 8, ReservedPrefixes: []string{"_"}})
+
+	t.Run("creates short url with valid alias", func(t *testing.T) {
+		memStore := store.NewMemoryStore()
+		handler := newTestHandler(memStore, aliasPolicy)
+
+		req := &handlers.CreateShortURLRequest{}
+		req.Body.URL = testURL
+		req.Body.Alias = "docs"
+
+		resp, err := handler.CreateShortURL(context.Background(), req)
+
+		require.NoError(t, err)
+		assert.Equal(t, "docs", resp.Body.Code)
+		assert.Equal(t, "http://localhost:8888/docs", resp.Body.ShortURL)
+
+		saved, err := memStore.GetByCode(context.Background(), "docs")
+		require.NoError(t, err)
+		assert.Equal(t, testURL, saved.OriginalURL)
+		assert.Equal(t, saved.CreatedAt, resp.Body.CreatedAt)
+	})
+
+	t.Run("rejects over-length alias with 400", func(t *testing.T) {
+		handler := newTestHandler(store.NewMemoryStore(), aliasPolicy)
+
+		req := &handlers.CreateShortURLRequest{}
+		req.Body.URL = testURL
+		req.Body.Alias = "waytoolongalias"
+
+		resp, err := handler.CreateShortURL(context.Background(), req)
+
+		assert.Nil(t, resp)
+
+		var statusErr huma.StatusError
+		require.ErrorAs(t, err, &statusErr)
+		assert.Equal(t, http.StatusBadRequest, statusErr.GetStatus())
+		assert.Contains(t, err.Error(), "at most 8 characters")
+	})
+
+	t.Run("rejects alias with forbidden characters with 400", func(t *testing.T) {
+		handler := newTestHandler(store.NewMemoryStore(), aliasPolicy)
+
+		req := &handlers.CreateShortURLRequest{}
+		req.Body.URL = testURL
+		req.Body.Alias = "my docs"
+
+		resp, err := handler.CreateShortURL(context.Background(), req)
+
+		assert.Nil(t, resp)
+
+		var statusErr huma.StatusError
+		require.ErrorAs(t, err, &statusErr)
+		assert.Equal(t, http.StatusBadRequest, statusErr.GetStatus())
+		assert.Contains(t, err.Error(), "may only contain")
+	})
+
+	t.Run("rejects alias with reserved prefix with 400", func(t *testing.T) {
+		handler := newTestHandler(store.NewMemoryStore(), aliasPolicy)
+
+		req := &handlers.CreateShortURLRequest{}
+		req.Body.URL = testURL
+		req.Body.Alias = "_docs"
+
+		resp, err := handler.CreateShortURL(context.Background(), req)
+
+		assert.Nil(t, resp)
+
+		var statusErr huma.StatusError
+		require.ErrorAs(t, err, &statusErr)
+		assert.Equal(t, http.StatusBadRequest, statusErr.GetStatus())
+		assert.Contains(t, err.Error(), "reserved prefix")
+	})
+
+	t.Run("rejects taken alias with 409", func(t *testing.T) {
+		memStore := store.NewMemoryStore()
+		_ = memStore.Save(context.Background(), &shortener.ShortURL{Code: "docs", OriginalURL: testURL})
+		handler := newTestHandler(memStore, aliasPolicy)
+
+		req := &handlers.CreateShortURLRequest{}
+		req.Body.URL = testURL
+		req.Body.Alias = "docs"
+
+		resp, err := handler.CreateShortURL(context.Background(), req)
+
+		assert.Nil(t, resp)
+
+		var statusErr huma.StatusError
+		require.ErrorAs(t, err, &statusErr)
+		assert.Equal(t, http.StatusConflict, statusErr.GetStatus())
+	})
+
+	t.Run("returns 500 when save fails", func(t *testing.T) {
+		handler := newTestHandler(&mockStore{getByCodeErr: shortener.ErrNotFound, saveErr: errMock}, aliasPolicy)
+
+		req := &handlers.CreateShortURLRequest{}
+		req.Body.URL = testURL
+		req.Body.Alias = "docs"
+
+		resp, err := handler.CreateShortURL(context.Background(), req)
+
+		assert.Nil(t, resp)
+
+		var statusErr huma.StatusError
+		require.ErrorAs(t, err, &statusErr)
+		assert.Equal(t, http.StatusInternalServerError, statusErr.GetStatus())
+	})
+}
+
+func TestCreateShortURL_IfNoneMatch(t *testing.T) {
+	create := func(handler *handlers.URLHandler, url string) (*handlers.CreateShortURLResponse, error) {
+		req := &handlers.CreateShortURLRequest{IfNoneMatch: "*"}
+		req.Body.URL = url
+		req.Body.Alias = "docs"
+
+		return handler.CreateShortURL(context.Background(), req)
+	}
+
+	t.Run("creates a new alias with 201", func(t *testing.T) {
+		memStore := store.NewMemoryStore()
+
+		resp, err := create(newTestHandler(memStore), testURL)
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, resp.Status)
+		assert.Equal(t, "docs", resp.Body.Code)
+
+		saved, err := memStore.GetByCode(context.Background(), "docs")
+		require.NoError(t, err)
+		assert.Equal(t, testURL, saved.OriginalURL)
+	})
+
+	t.Run("returns the existing alias for the same url with 200", func(t *testing.T) {
+		memStore := store.NewMemoryStore()
+		createdAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+		require.NoError(t, memStore.Save(context.Background(),
+			&shortener.ShortURL{Code: "docs", OriginalURL: testURL, CreatedAt: createdAt}))
+
+		var published []*analytics.URLCreatedEvent
+
+		handler := handlers.NewURLHandler(
+			memStore,
+			"http://localhost:8888",
+			map[handlers.Strategy]shortener.Strategy{},
+			func(e *analytics.URLCreatedEvent) error {
+				published = append(published, e)
+
+				return nil
+			},
+			noopPublish[analytics.URLAccessedEvent](),
+			zap.NewNop(),
+		)
+
+		resp, err := create(handler, testURL)
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.Status)
+		assert.Equal(t, "docs", resp.Body.Code)
+		assert.Equal(t, createdAt, resp.Body.CreatedAt)
+		assert.Empty(t, published, "returning the existing alias publishes no created event")
+	})
+
+	t.Run("rejects an alias pointing to a different url with 409", func(t *testing.T) {
+		memStore := store.NewMemoryStore()
+		require.NoError(t, memStore.Save(context.Background(),
+			&shortener.ShortURL{Code: "docs", OriginalURL: "https://other.example.com"}))
+
+		resp, err := create(newTestHandler(memStore), testURL)
+
+		assert.Nil(t, resp)
+
+		var statusErr huma.StatusError
+		require.ErrorAs(t, err, &statusErr)
+		assert.Equal(t, http.StatusConflict, statusErr.GetStatus())
+	})
+
+	t.Run("without the header an existing alias is a conflict", func(t *testing.T) {
+		memStore := store.NewMemoryStore()
+		require.NoError(t, memStore.Save(context.Background(),
+			&shortener.ShortURL{Code: "docs", OriginalURL: testURL}))
+
+		req := &handlers.CreateShortURLRequest{}
+		req.Body.URL = testURL
+		req.Body.Alias = "docs"
+
+		_, err := newTestHandler(memStore).CreateShortURL(context.Background(), req)
+
+		var statusErr huma.StatusError
+		require.ErrorAs(t, err, &statusErr)
+		assert.Equal(t, http.StatusConflict, statusErr.GetStatus())
+	})
+}
+
+func TestRedirectToURL_RedirectStatus(t *testing.T) {
+	memStore := store.NewMemoryStore()
+	_ = memStore.Save(context.Background(), &shortener.ShortURL{Code: "abc123", OriginalURL: testURL})
+
+	t.Run("defaults to 301", func(t *testing.T) {
+		resp, err := newTestHandler(memStore).RedirectToURL(context.Background(), &handlers.RedirectRequest{Code: "abc123"})
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusMovedPermanently, resp.Status)
+	})
+
+	t.Run("uses configured 308 with location", func(t *testing.T) {
+		handler := newTestHandler(memStore, handlers.WithRedirectStatus(http.StatusPermanentRedirect))
+
+		resp, err := handler.RedirectToURL(context.Background(), &handlers.RedirectRequest{Code: "abc123"})
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusPermanentRedirect, resp.Status)
+		assert.Equal(t, testURL, resp.Headers.Location)
+	})
+
+	t.Run("uses configured 307", func(t *testing.T) {
+		handler := newTestHandler(memStore, handlers.WithRedirectStatus(http.StatusTemporaryRedirect))
+
+		resp, err := handler.RedirectToURL(context.Background(), &handlers.RedirectRequest{Code: "abc123"})
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusTemporaryRedirect, resp.Status)
+	})
+}
+
+func TestIsValidRedirectStatus(t *testing.T) {
+	for _, status := range []int{301, 302, 307, 308} {
+		assert.True(t, handlers.IsValidRedirectStatus(status), status)
+	}
+
+	for _, status := range []int{200, 303, 404} {
+		assert.False(t, handlers.IsValidRedirectStatus(status), status)
+	}
+}
+
+func TestCreateShortURL_StrategyPublishers(t *testing.T) {
+	recordPublish := func(calls map[string]int, name string) messaging.Publish[analytics.URLCreatedEvent] {
+		return func(_ *analytics.URLCreatedEvent) error {
+			calls[name]++
+
+			return nil
+		}
+	}
+
+	t.Run("publishes to strategy topic when mapped", func(t *testing.T) {
+		calls := map[string]int{}
+		handler := newTestHandlerWithPublishers(store.NewMemoryStore(),
+			recordPublish(calls, "default"), noopPublish[analytics.URLAccessedEvent](),
+			handlers.WithStrategyPublishers(map[handlers.Strategy]messaging.Publish[analytics.URLCreatedEvent]{
+				handlers.StrategyHash: recordPublish(calls, "hash"),
+			}))
+
+		req := &handlers.CreateShortURLRequest{}
+		req.Body.URL = testURL
+		req.Body.Strategy = handlers.StrategyHash
+
+		_, err := handler.CreateShortURL(context.Background(), req)
+
+		require.NoError(t, err)
+		assert.Equal(t, map[string]int{"hash": 1}, calls)
+	})
+
+	t.Run("falls back to default topic when unmapped", func(t *testing.T) {
+		calls := map[string]int{}
+		handler := newTestHandlerWithPublishers(store.NewMemoryStore(),
+			recordPublish(calls, "default"), noopPublish[analytics.URLAccessedEvent](),
+			handlers.WithStrategyPublishers(map[handlers.Strategy]messaging.Publish[analytics.URLCreatedEvent]{
+				handlers.StrategyHash: recordPublish(calls, "hash"),
+			}))
+
+		req := &handlers.CreateShortURLRequest{}
+		req.Body.URL = testURL
+		req.Body.Strategy = handlers.StrategyToken
+
+		_, err := handler.CreateShortURL(context.Background(), req)
+
+		require.NoError(t, err)
+		assert.Equal(t, map[string]int{"default": 1}, calls)
+	})
+}
+
+func TestCreateShortURL_InvalidURLRules(t *testing.T) {
+	memStore := store.NewMemoryStore()
+	gen, _ := nanoid.Standard(8)
+	handler := handlers.NewURLHandler(
+		memStore,
+		"http://localhost:8888",
+		map[handlers.Strategy]shortener.Strategy{
+			handlers.StrategyHash: shortener.NewHashStrategy(memStore, gen,
+				shortener.WithURLRules(shortener.URLRules{RequireDottedHost: true})),
+		},
+		noopPublish[analytics.URLCreatedEvent](),
+		noopPublish[analytics.URLAccessedEvent](),
+		zap.NewNop(),
+	)
+
+	req := &handlers.CreateShortURLRequest{}
+	req.Body.URL = "http://a"
+	req.Body.Strategy = handlers.StrategyHash
+
+	resp, err := handler.CreateShortURL(context.Background(), req)
+
+	assert.Nil(t, resp)
+
+	var statusErr huma.StatusError
+	require.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, http.StatusBadRequest, statusErr.GetStatus())
+}
+
+func TestCreateShortURL_UnparseableURL(t *testing.T) {
+	req := &handlers.CreateShortURLRequest{}
+	req.Body.URL = "http://[::1"
+	req.Body.Strategy = handlers.StrategyHash
+
+	resp, err := newTestHandler(store.NewMemoryStore()).CreateShortURL(context.Background(), req)
+
+	assert.Nil(t, resp)
+
+	var statusErr huma.StatusError
+	require.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, http.StatusBadRequest, statusErr.GetStatus())
+	assert.Contains(t, statusErr.Error(), "invalid url")
+}
+
+func TestCreateShortURL_QRURL(t *testing.T) {
+	req := &handlers.CreateShortURLRequest{}
+	req.Body.URL = testURL
+
+	t.Run("includes qr url when enabled", func(t *testing.T) {
+		resp, err := newTestHandler(store.NewMemoryStore(), handlers.WithQRURL()).CreateShortURL(context.Background(), req)
+
+		require.NoError(t, err)
+		assert.Equal(t, "http://localhost:8888/"+resp.Body.Code+"/qr", resp.Body.QRURL)
+	})
+
+	t.Run("omits qr url when disabled", func(t *testing.T) {
+		resp, err := newTestHandler(store.NewMemoryStore()).CreateShortURL(context.Background(), req)
+
+		require.NoError(t, err)
+		assert.Empty(t, resp.Body.QRURL)
+	})
+}
+
+func TestCreateShortURL_RelativeShortURL(t *testing.T) {
+	req := &handlers.CreateShortURLRequest{}
+	req.Body.URL = testURL
+
+	t.Run("returns a root-relative path when enabled", func(t *testing.T) {
+		resp, err := newTestHandler(store.NewMemoryStore(), handlers.WithRelativeShortURLs(), handlers.WithQRURL()).
+			CreateShortURL(context.Background(), req)
+
+		require.NoError(t, err)
+		assert.Equal(t, "/"+resp.Body.Code, resp.Body.ShortURL)
+		assert.Equal(t, "/"+resp.Body.Code, resp.Headers.Location)
+		assert.Equal(t, "/"+resp.Body.Code+"/qr", resp.Body.QRURL)
+	})
+
+	t.Run("ignores forwarded headers when enabled", func(t *testing.T) {
+		ctx := handlers.ContextWithRequestMeta(context.Background(), handlers.RequestMeta{
+			ForwardedProto: "https",
+			ForwardedHost:  "sho.rt",
+		})
+
+		resp, err := newTestHandler(store.NewMemoryStore(), handlers.WithRelativeShortURLs()).CreateShortURL(ctx, req)
+
+		require.NoError(t, err)
+		assert.Equal(t, "/"+resp.Body.Code, resp.Body.ShortURL)
+	})
+
+	t.Run("returns an absolute url by default", func(t *testing.T) {
+		resp, err := newTestHandler(store.NewMemoryStore()).CreateShortURL(context.Background(), req)
+
+		require.NoError(t, err)
+		assert.Equal(t, "http://localhost:8888/"+resp.Body.Code, resp.Body.ShortURL)
+		assert.Equal(t, resp.Body.ShortURL, resp.Headers.Location)
+	})
+}
+
+func TestCreateShortURL_CreatedBy(t *testing.T) {
+	req := &handlers.CreateShortURLRequest{}
+	req.Body.URL = testURL
+
+	t.Run("returns the creator from the context", func(t *testing.T) {
+		memStore := store.NewMemoryStore()
+		ctx := shortener.WithCreator(context.Background(), "key:team-a")
+
+		resp, err := newTestHandler(memStore).CreateShortURL(ctx, req)
+
+		require.NoError(t, err)
+		assert.Equal(t, "key:team-a", resp.Body.CreatedBy)
+
+		mine, err := memStore.ListByCreator(context.Background(), "key:team-a", 10)
+		require.NoError(t, err)
+		require.Len(t, mine, 1)
+		assert.Equal(t, shortener.Code(resp.Body.Code), mine[0].Code)
+	})
+
+	t.Run("unauthenticated creates have no creator", func(t *testing.T) {
+		resp, err := newTestHandler(store.NewMemoryStore()).CreateShortURL(context.Background(), req)
+
+		require.NoError(t, err)
+		assert.Empty(t, resp.Body.CreatedBy)
+	})
+}
+
+func TestCreateShortURL_DefaultStrategy(t *testing.T) {
+	t.Run("uses token strategy by default", func(t *testing.T) {
+		handler := newTestHandler(store.NewMemoryStore())
+
+		req := &handlers.CreateShortURLRequest{}
+		req.Body.URL = testURL
+
+		first, err := handler.CreateShortURL(context.Background(), req)
+		require.NoError(t, err)
+
+		second, err := handler.CreateShortURL(context.Background(), req)
+		require.NoError(t, err)
+
+		assert.NotEqual(t, first.Body.Code, second.Body.Code)
+	})
+
+	t.Run("uses configured hash strategy when none is given", func(t *testing.T) {
+		handler := newTestHandler(store.NewMemoryStore(), handlers.WithDefaultStrategy(handlers.StrategyHash))
+
+		req := &handlers.CreateShortURLRequest{}
+		req.Body.URL = testURL
+
+		first, err := handler.CreateShortURL(context.Background(), req)
+		require.NoError(t, err)
+
+		second, err := handler.CreateShortURL(context.Background(), req)
+		require.NoError(t, err)
+
+		assert.Equal(t, first.Body.Code, second.Body.Code, "hash strategy should deduplicate")
+	})
+
+	t.Run("explicit strategy overrides the default", func(t *testing.T) {
+		handler := newTestHandler(store.NewMemoryStore(), handlers.WithDefaultStrategy(handlers.StrategyHash))
+
+		req := &handlers.CreateShortURLRequest{}
+		req.Body.URL = testURL
+		req.Body.Strategy = handlers.StrategyToken
+
+		first, err := handler.CreateShortURL(context.Background(), req)
+		require.NoError(t, err)
+
+		second, err := handler.CreateShortURL(context.Background(), req)
+		require.NoError(t, err)
+
+		assert.NotEqual(t, first.Body.Code, second.Body.Code)
+	})
+
+	t.Run("query parameter selects the strategy", func(t *testing.T) {
+		handler := newTestHandler(store.NewMemoryStore())
+
+		req := &handlers.CreateShortURLRequest{Strategy: handlers.StrategyHash}
+		req.Body.URL = testURL
+
+		first, err := handler.CreateShortURL(context.Background(), req)
+		require.NoError(t, err)
+
+		second, err := handler.CreateShortURL(context.Background(), req)
+		require.NoError(t, err)
+
+		assert.Equal(t, first.Body.Code, second.Body.Code, "hash strategy should deduplicate")
+	})
+
+	t.Run("body field wins over the query parameter", func(t *testing.T) {
+		handler := newTestHandler(store.NewMemoryStore())
+
+		req := &handlers.CreateShortURLRequest{Strategy: handlers.StrategyHash}
+		req.Body.URL = testURL
+		req.Body.Strategy = handlers.StrategyToken
+
+		first, err := handler.CreateShortURL(context.Background(), req)
+		require.NoError(t, err)
+
+		second, err := handler.CreateShortURL(context.Background(), req)
+		require.NoError(t, err)
+
+		assert.NotEqual(t, first.Body.Code, second.Body.Code)
+	})
+
+	t.Run("rejects an unknown query strategy", func(t *testing.T) {
+		handler := newTestHandler(store.NewMemoryStore())
+
+		req := &handlers.CreateShortURLRequest{Strategy: "bogus"}
+		req.Body.URL = testURL
+
+		resp, err := handler.CreateShortURL(context.Background(), req)
+
+		assert.Nil(t, resp)
+
+		var statusErr huma.StatusError
+		require.ErrorAs(t, err, &statusErr)
+		assert.Equal(t, http.StatusBadRequest, statusErr.GetStatus())
+	})
+}
+
+func TestRedirectToURL_NotFoundRedirect(t *testing.T) {
+	t.Run("returns 404 when unset", func(t *testing.T) {
+		handler := newTestHandler(store.NewMemoryStore())
+
+		resp, err := handler.RedirectToURL(context.Background(), &handlers.RedirectRequest{Code: "missing"})
+
+		assert.Nil(t, resp)
+
+		var statusErr huma.StatusError
+		require.ErrorAs(t, err, &statusErr)
+		assert.Equal(t, http.StatusNotFound, statusErr.GetStatus())
+	})
+
+	t.Run("redirects unknown codes to fallback", func(t *testing.T) {
+		handler := newTestHandler(store.NewMemoryStore(), handlers.WithNotFoundRedirect("https://example.com/"))
+
+		resp, err := handler.RedirectToURL(context.Background(), &handlers.RedirectRequest{Code: "missing"})
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusFound, resp.Status)
+		assert.Equal(t, "https://example.com/", resp.Headers.Location)
+	})
+}
+
+func TestRedirectToURL_CacheHeaders(t *testing.T) {
+	memStore := store.NewMemoryStore()
+	_ = memStore.Save(context.Background(), &shortener.ShortURL{Code: "abc123", OriginalURL: testURL})
+
+	t.Run("omits headers by default", func(t *testing.T) {
+		resp, err := newTestHandler(memStore).RedirectToURL(context.Background(), &handlers.RedirectRequest{Code: "abc123"})
+
+		require.NoError(t, err)
+		assert.Empty(t, resp.Headers.CacheControl)
+		assert.Empty(t, resp.Headers.Link)
+	})
+
+	t.Run("sets configured max-age", func(t *testing.T) {
+		handler := newTestHandler(memStore, handlers.WithRedirectCacheMaxAge(time.Hour))
+
+		resp, err := handler.RedirectToURL(context.Background(), &handlers.RedirectRequest{Code: "abc123"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "public, max-age=3600", resp.Headers.CacheControl)
+	})
+
+	t.Run("sets canonical link header", func(t *testing.T) {
+		handler := newTestHandler(memStore, handlers.WithRedirectLinkHeader())
+
+		resp, err := handler.RedirectToURL(context.Background(), &handlers.RedirectRequest{Code: "abc123"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "<"+testURL+`>; rel="canonical"`, resp.Headers.Link)
+	})
+}
+
+func TestRedirectToURL_Clock(t *testing.T) {
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	memStore := store.NewMemoryStore()
+	_ = memStore.Save(context.Background(), &shortener.ShortURL{Code: "abc123", OriginalURL: testURL})
+
+	var accessed *analytics.URLAccessedEvent
+
+	handler := handlers.NewURLHandler(
+		memStore,
+		"http://localhost:8888",
+		map[handlers.Strategy]shortener.Strategy{},
+		noopPublish[analytics.URLCreatedEvent](),
+		func(e *analytics.URLAccessedEvent) error {
+			accessed = e
+
+			return nil
+		},
+		zap.NewNop(),
+		handlers.WithClock(clock.NewFake(now)),
+	)
+
+	_, err := handler.RedirectToURL(context.Background(), &handlers.RedirectRequest{Code: "abc123"})
+
+	require.NoError(t, err)
+	require.NotNil(t, accessed)
+	assert.Equal(t, now, accessed.AccessedAt)
+}
+
+func TestFallbackURL(t *testing.T) {
+	const fallback = "https://backup.example.com"
+
+	t.Run("create stores the fallback url", func(t *testing.T) {
+		memStore := store.NewMemoryStore()
+		handler := newTestHandler(memStore)
+
+		req := &handlers.CreateShortURLRequest{}
+		req.Body.URL = testURL
+		req.Body.FallbackURL = fallback
+
+		resp, err := handler.CreateShortURL(context.Background(), req)
+		require.NoError(t, err)
+
+		saved, err := memStore.GetByCode(context.Background(), shortener.Code(resp.Body.Code))
+		require.NoError(t, err)
+		assert.Equal(t, fallback, saved.FallbackURL)
+	})
+
+	t.Run("create stores the fallback url for aliases", func(t *testing.T) {
+		memStore := store.NewMemoryStore()
+		handler := newTestHandler(memStore)
+
+		req := &handlers.CreateShortURLRequest{}
+		req.Body.URL = testURL
+		req.Body.Alias = "docs"
+		req.Body.FallbackURL = fallback
+
+		_, err := handler.CreateShortURL(context.Background(), req)
+		require.NoError(t, err)
+
+		saved, err := memStore.GetByCode(context.Background(), "docs")
+		require.NoError(t, err)
+		assert.Equal(t, fallback, saved.FallbackURL)
+	})
+
+	tests := []struct {
+		name        string
+		flagged     bool
+		fallbackURL string
+		wantStatus  int
+		wantTarget  string
+	}{
+		{name: "active primary uses primary", fallbackURL: fallback, wantStatus: http.StatusMovedPermanently, wantTarget: testURL},
+		{name: "flagged primary uses fallback", flagged: true, fallbackURL: fallback, wantStatus: http.StatusFound, wantTarget: fallback},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			memStore := store.NewMemoryStore()
+			_ = memStore.Save(context.Background(), &shortener.ShortURL{
+				Code:        "abc123",
+				OriginalURL: testURL,
+				FallbackURL: tt.fallbackURL,
+				Flagged:     tt.flagged,
+			})
+			handler := newTestHandler(memStore, handlers.WithRedirectCacheMaxAge(time.Hour))
+
+			resp, err := handler.RedirectToURL(context.Background(), &handlers.RedirectRequest{Code: "abc123"})
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, resp.Status)
+			assert.Equal(t, tt.wantTarget, resp.Headers.Location)
+
+			if tt.flagged {
+				assert.Empty(t, resp.Headers.CacheControl, "fallback redirects must not be cached")
+			}
+		})
+	}
+
+	t.Run("flagged primary without fallback is gone", func(t *testing.T) {
+		memStore := store.NewMemoryStore()
+		_ = memStore.Save(context.Background(), &shortener.ShortURL{Code: "abc123", OriginalURL: testURL, Flagged: true})
+		handler := newTestHandler(memStore)
+
+		resp, err := handler.RedirectToURL(context.Background(), &handlers.RedirectRequest{Code: "abc123"})
+
+		assert.Nil(t, resp)
+
+		var statusErr huma.StatusError
+		require.ErrorAs(t, err, &statusErr)
+		assert.Equal(t, http.StatusGone, statusErr.GetStatus())
+	})
+}
+
+func TestFlagURL(t *testing.T) {
+	const fallback = "https://example.org/fallback"
+
+	t.Run("redirects to the fallback once a cached code is flagged", func(t *testing.T) {
+		memStore := store.NewMemoryStore()
+		_ = memStore.Save(context.Background(), &shortener.ShortURL{
+			Code:        "abc123",
+			OriginalURL: testURL,
+			FallbackURL: fallback,
+		})
+		handler := newTestHandler(store.NewCachedRepository(memStore, cache.New(10)))
+
+		// Warm the cache with the unflagged entry
+		redirect, err := handler.RedirectToURL(context.Background(), &handlers.RedirectRequest{Code: "abc123"})
+		require.NoError(t, err)
+		assert.Equal(t, testURL, redirect.Headers.Location)
+
+		req := &handlers.FlagURLRequest{Code: "abc123"}
+		req.Body.Flagged = true
+
+		resp, err := handler.FlagURL(context.Background(), req)
+
+		require.NoError(t, err)
+		assert.Equal(t, "abc123", resp.Body.Code)
+		assert.True(t, resp.Body.Flagged)
+
+		redirect, err = handler.RedirectToURL(context.Background(), &handlers.RedirectRequest{Code: "abc123"})
+		require.NoError(t, err)
+		assert.Equal(t, fallback, redirect.Headers.Location)
+	})
+
+	t.Run("records an audit entry with the flag", func(t *testing.T) {
+		memStore := store.NewMemoryStore()
+		_ = memStore.Save(context.Background(), &shortener.ShortURL{Code: "abc123", OriginalURL: testURL})
+		recorder := &auditRecorder{}
+		handler := newTestHandler(memStore, handlers.WithAudit(recorder))
+
+		ctx := audit.ContextWithActor(context.Background(), "admin:1234abcd")
+
+		req := &handlers.FlagURLRequest{Code: "abc123"}
+		req.Body.Flagged = true
+
+		_, err := handler.FlagURL(ctx, req)
+
+		require.NoError(t, err)
+		require.Len(t, recorder.entries, 1)
+		assert.Equal(t, audit.Entry{
+			Actor:  "admin:1234abcd",
+			Action: handlers.AuditActionFlagURL,
+			Target: "abc123",
+			Detail: "true",
+		}, recorder.entries[0])
+	})
+
+	t.Run("returns 404 when code not found", func(t *testing.T) {
+		recorder := &auditRecorder{}
+		handler := newTestHandler(store.NewMemoryStore(), handlers.WithAudit(recorder))
+
+		resp, err := handler.FlagURL(context.Background(), &handlers.FlagURLRequest{Code: "notfound"})
+
+		assert.Nil(t, resp)
+
+		var statusErr huma.StatusError
+		require.ErrorAs(t, err, &statusErr)
+		assert.Equal(t, http.StatusNotFound, statusErr.GetStatus())
+		assert.Empty(t, recorder.entries, "failed changes are not audited")
+	})
+
+	t.Run("returns 500 when the store fails", func(t *testing.T) {
+		handler := newTestHandler(&mockStore{flagErr: errors.New("store down")})
+
+		resp, err := handler.FlagURL(context.Background(), &handlers.FlagURLRequest{Code: "abc123"})
+
+		assert.Nil(t, resp)
+
+		var statusErr huma.StatusError
+		require.ErrorAs(t, err, &statusErr)
+		assert.Equal(t, http.StatusInternalServerError, statusErr.GetStatus())
+	})
+}
+
+func TestResolveURL(t *testing.T) {
+	// publishAccessed fails the test if resolving publishes an access event.
+	publishAccessed := func(t *testing.T) messaging.Publish[analytics.URLAccessedEvent] {
+		return func(_ *analytics.URLAccessedEvent) error {
+			t.Error("resolve must not publish access events")
+
+			return nil
+		}
+	}
+
+	t.Run("resolves code without publishing", func(t *testing.T) {
+		memStore := store.NewMemoryStore()
+		_ = memStore.Save(context.Background(), &shortener.ShortURL{Code: "abc123", OriginalURL: testURL})
+
+		handler := newTestHandlerWithPublishers(memStore, noopPublish[analytics.URLCreatedEvent](), publishAccessed(t))
+
+		resp, err := handler.ResolveURL(context.Background(), &handlers.ResolveRequest{Code: "abc123"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "abc123", resp.Body.Code)
+		assert.Equal(t, testURL, resp.Body.URL)
+		assert.Equal(t, "public, max-age=3600, must-revalidate", resp.CacheControl)
+		assert.NotEmpty(t, resp.ETag)
+	})
+
+	t.Run("flagged code resolves to uncached fallback", func(t *testing.T) {
+		memStore := store.NewMemoryStore()
+		_ = memStore.Save(context.Background(), &shortener.ShortURL{
+			Code:        "abc123",
+			OriginalURL: testURL,
+			FallbackURL: "https://backup.example.com",
+			Flagged:     true,
+		})
+
+		handler := newTestHandlerWithPublishers(memStore, noopPublish[analytics.URLCreatedEvent](), publishAccessed(t))
+
+		resp, err := handler.ResolveURL(context.Background(), &handlers.ResolveRequest{Code: "abc123"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "https://backup.example.com", resp.Body.URL)
+		assert.Equal(t, "no-cache", resp.CacheControl)
+	})
+
+	errorTests := []struct {
+		name       string
+		store      shortener.Repository
+		wantStatus int
+	}{
+		{name: "unknown code", store: store.NewMemoryStore(), wantStatus: http.StatusNotFound},
+		{
+			name:       "store error",
+			store:      &mockStore{getByCodeErr: errors.New("connection refused")},
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := newTestHandlerWithPublishers(tt.store, noopPublish[analytics.URLCreatedEvent](), publishAccessed(t))
+
+			resp, err := handler.ResolveURL(context.Background(), &handlers.ResolveRequest{Code: "missing"})
+
+			assert.Nil(t, resp)
+
+			var statusErr huma.StatusError
+			require.ErrorAs(t, err, &statusErr)
+			assert.Equal(t, tt.wantStatus, statusErr.GetStatus())
+		})
+	}
+}
+
+func TestAnonymizedIPs(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   []handlers.URLHandlerOption
+		wantIP string
+	}{
+		{name: "off records the full ip", wantIP: "203.0.113.42"},
+		{name: "on records the truncated ip", opts: []handlers.URLHandlerOption{handlers.WithAnonymizedIPs()}, wantIP: "203.0.113.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				created  *analytics.URLCreatedEvent
+				accessed *analytics.URLAccessedEvent
+			)
+
+			memStore := store.NewMemoryStore()
+			gen, _ := nanoid.Standard(8)
+			handler := handlers.NewURLHandler(
+				memStore,
+				"http://localhost:8888",
+				map[handlers.Strategy]shortener.Strategy{
+					handlers.StrategyToken: shortener.NewTokenStrategy(memStore, gen),
+				},
+				func(e *analytics.URLCreatedEvent) error {
+					created = e
+
+					return nil
+				},
+				func(e *analytics.URLAccessedEvent) error {
+					accessed = e
+
+					return nil
+				},
+				zap.NewNop(),
+				tt.opts...,
+			)
+
+			ctx := handlers.ContextWithRequestMeta(context.Background(), handlers.RequestMeta{ClientIP: "203.0.113.42"})
+
+			req := &handlers.CreateShortURLRequest{}
+			req.Body.URL = testURL
+
+			resp, err := handler.CreateShortURL(ctx, req)
+			require.NoError(t, err)
+
+			_, err = handler.RedirectToURL(ctx, &handlers.RedirectRequest{Code: resp.Body.Code})
+			require.NoError(t, err)
+
+			require.NotNil(t, created)
+			require.NotNil(t, accessed)
+			assert.Equal(t, tt.wantIP, created.ClientIP)
+			assert.Equal(t, tt.wantIP, accessed.ClientIP)
+		})
+	}
+}
+
+func TestCheckAvailability(t *testing.T) {
+	newRequest := func(codes ...string) *handlers.CheckAvailabilityRequest {
+		req := &handlers.CheckAvailabilityRequest{}
+		req.Body.Codes = codes
+
+		return req
+	}
+
+	t.Run("reports a mix of taken and free codes", func(t *testing.T) {
+		memStore := store.NewMemoryStore()
+		require.NoError(t, memStore.Save(context.Background(), &shortener.ShortURL{Code: "docs", OriginalURL: testURL}))
+		require.NoError(t, memStore.Save(context.Background(), &shortener.ShortURL{Code: "blog", OriginalURL: testURL}))
+
+		resp, err := newTestHandler(memStore).
+			CheckAvailability(context.Background(), newRequest("docs", "shop", "blog", "news"))
+
+		require.NoError(t, err)
+		assert.Equal(t, map[string]handlers.CodeAvailability{
+			"docs": {Reason: "taken"},
+			"shop": {Available: true},
+			"blog": {Reason: "taken"},
+			"news": {Available: true},
+		}, resp.Body.Codes)
+	})
+
+	t.Run("checks all valid codes in one call and skips invalid ones", func(t *testing.T) {
+		mock := &mockStore{}
+
+		resp, err := newTestHandler(mock).CheckAvailability(context.Background(), newRequest("free", "_admin", "my/link"))
+
+		require.NoError(t, err)
+		assert.Equal(t, []shortener.Code{"free"}, mock.existsCodes)
+		assert.Equal(t, handlers.CodeAvailability{Available: true}, resp.Body.Codes["free"])
+		assert.Equal(t, handlers.CodeAvailability{Reason: "invalid"}, resp.Body.Codes["_admin"])
+		assert.Equal(t, handlers.CodeAvailability{Reason: "invalid"}, resp.Body.Codes["my/link"])
+	})
+
+	t.Run("does not query the store when every code is invalid", func(t *testing.T) {
+		mock := &mockStore{existsErr: errMock}
+
+		resp, err := newTestHandler(mock).CheckAvailability(context.Background(), newRequest("_admin"))
+
+		require.NoError(t, err)
+		assert.Nil(t, mock.existsCodes)
+		assert.False(t, resp.Body.Codes["_admin"].Available)
+	})
+
+	t.Run("matches codes case-insensitively when enabled", func(t *testing.T) {
+		memStore := store.NewMemoryStore()
+		require.NoError(t, memStore.Save(context.Background(), &shortener.ShortURL{Code: "docs", OriginalURL: testURL}))
+
+		resp, err := newTestHandler(memStore, handlers.WithCaseInsensitiveCodes()).
+			CheckAvailability(context.Background(), newRequest("DOCS"))
+
+		require.NoError(t, err)
+		assert.Equal(t, handlers.CodeAvailability{Reason: "taken"}, resp.Body.Codes["DOCS"])
+	})
+
+	t.Run("returns 500 when the store fails", func(t *testing.T) {
+		resp, err := newTestHandler(&mockStore{existsErr: errMock}).
+			CheckAvailability(context.Background(), newRequest("free"))
+
+		assert.Nil(t, resp)
+
+		var statusErr huma.StatusError
+		require.ErrorAs(t, err, &statusErr)
+		assert.Equal(t, http.StatusInternalServerError, statusErr.GetStatus())
+	})
+}
+
+func TestRecentURLs(t *testing.T) {
+	t.Run("returns short urls in store order", func(t *testing.T) {
+		createdAt := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+		mock := &mockStore{recent: []*shortener.ShortURL{
+			{
+				Code:        "new",
+				OriginalURL: "https://example.com/new",
+				CreatedAt:   createdAt.Add(time.Minute),
+				CreatedBy:   "key:team-a",
+			},
+			{Code: "old", OriginalURL: "https://example.com/old", CreatedAt: createdAt},
+		}}
+		handler := newTestHandler(mock)
+
+		resp, err := handler.RecentURLs(context.Background(), &handlers.RecentURLsRequest{Limit: 5})
+
+		require.NoError(t, err)
+		assert.Equal(t, 5, mock.recentLimit)
+		assert.Equal(t, []handlers.RecentURL{
+			{
+				Code:        "new",
+				ShortURL:    "http://localhost:8888/new",
+				OriginalURL: "https://example.com/new",
+				CreatedAt:   createdAt.Add(time.Minute),
+				CreatedBy:   "key:team-a",
+			},
+			{
+				Code:        "old",
+				ShortURL:    "http://localhost:8888/old",
+				OriginalURL: "https://example.com/old",
+				CreatedAt:   createdAt,
+			},
+		}, resp.Body)
+	})
+
+	t.Run("returns 500 when the store fails", func(t *testing.T) {
+		handler := newTestHandler(&mockStore{recentErr: errors.New("connection refused")})
+
+		resp, err := handler.RecentURLs(context.Background(), &handlers.RecentURLsRequest{Limit: 5})
+
+		assert.Nil(t, resp)
+
+		var statusErr huma.StatusError
+		require.ErrorAs(t, err, &statusErr)
+		assert.Equal(t, http.StatusInternalServerError, statusErr.GetStatus())
+	})
+}
+
+func TestURLMetadata(t *testing.T) {
+	t.Run("returns the short url with its access count", func(t *testing.T) {
+		handler := newTestHandler(&mockStore{accessCount: 42})
+
+		resp, err := handler.URLMetadata(context.Background(), &handlers.URLMetadataRequest{Code: "abc123"})
+		require.NoError(t, err)
+
+		body, err := json.Marshal(resp.Body)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{
+			"code": "abc123",
+			"shortUrl": "http://localhost:8888/abc123",
+			"originalUrl": "https://example.com",
+			"createdAt": "0001-01-01T00:00:00Z",
+			"accessCount": 42
+		}`, string(body))
+	})
+
+	t.Run("returns the content type hint given at create", func(t *testing.T) {
+		handler := newTestHandler(store.NewMemoryStore())
+
+		req := &handlers.CreateShortURLRequest{}
+		req.Body.URL = "https://example.com/report.pdf"
+		req.Body.ContentTypeHint = "application/pdf"
+
+		created, err := handler.CreateShortURL(context.Background(), req)
+		require.NoError(t, err)
+
+		resp, err := handler.URLMetadata(context.Background(), &handlers.URLMetadataRequest{Code: created.Body.Code})
+		require.NoError(t, err)
+		assert.Equal(t, "application/pdf", resp.Body.ContentTypeHint)
+	})
+
+	t.Run("returns 404 when code not found", func(t *testing.T) {
+		handler := newTestHandler(store.NewMemoryStore())
+
+		resp, err := handler.URLMetadata(context.Background(), &handlers.URLMetadataRequest{Code: "notfound"})
+
+		assert.Nil(t, resp)
+
+		var statusErr huma.StatusError
+		require.ErrorAs(t, err, &statusErr)
+		assert.Equal(t, http.StatusNotFound, statusErr.GetStatus())
+	})
+
+	t.Run("returns 500 when the store fails", func(t *testing.T) {
+		handler := newTestHandler(&mockStore{statsErr: errors.New("connection refused")})
+
+		resp, err := handler.URLMetadata(context.Background(), &handlers.URLMetadataRequest{Code: "abc123"})
+
+		assert.Nil(t, resp)
+
+		var statusErr huma.StatusError
+		require.ErrorAs(t, err, &statusErr)
+		assert.Equal(t, http.StatusInternalServerError, statusErr.GetStatus())
+	})
+}
+
+func TestSkipAnalytics(t *testing.T) {
+	tests := []struct {
+		name      string
+		skip      bool
+		wantCount int
+	}{
+		{name: "normal operations publish events", wantCount: 1},
+		{name: "flagged operations publish nothing", skip: true, wantCount: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var created, accessed int
+
+			memStore := store.NewMemoryStore()
+			gen, _ := nanoid.Standard(8)
+			handler := handlers.NewURLHandler(
+				memStore,
+				"http://localhost:8888",
+				map[handlers.Strategy]shortener.Strategy{
+					handlers.StrategyToken: shortener.NewTokenStrategy(memStore, gen),
+				},
+				func(_ *analytics.URLCreatedEvent) error {
+					created++
+
+					return nil
+				},
+				func(_ *analytics.URLAccessedEvent) error {
+					accessed++
+
+					return nil
+				},
+				zap.NewNop(),
+			)
+
+			ctx := handlers.ContextWithRequestMeta(context.Background(), handlers.RequestMeta{SkipAnalytics: tt.skip})
+
+			req := &handlers.CreateShortURLRequest{}
+			req.Body.URL = testURL
+
+			resp, err := handler.CreateShortURL(ctx, req)
+			require.NoError(t, err)
+
+			_, err = handler.RedirectToURL(ctx, &handlers.RedirectRequest{Code: resp.Body.Code})
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.wantCount, created)
+			assert.Equal(t, tt.wantCount, accessed)
+		})
+	}
+}
+
+func TestCreateShortURL_CollapseSelfRedirects(t *testing.T) {
+	create := func(t *testing.T, handler *handlers.URLHandler, ctx context.Context, target string) *handlers.CreateShortURLResponse {
+		t.Helper()
+
+		req := &handlers.CreateShortURLRequest{}
+		req.Body.URL = target
+
+		resp, err := handler.CreateShortURL(ctx, req)
+		require.NoError(t, err)
+
+		return resp
+	}
+
+	t.Run("stores the target of one of our own short urls", func(t *testing.T) {
+		memStore := store.NewMemoryStore()
+		handler := newTestHandler(memStore, handlers.WithCollapsedSelfRedirects())
+
+		first := create(t, handler, context.Background(), testURL)
+		second := create(t, handler, context.Background(), first.Body.ShortURL)
+		third := create(t, handler, context.Background(), second.Body.ShortURL)
+
+		assert.Equal(t, testURL, second.Body.OriginalURL)
+		assert.Equal(t, testURL, third.Body.OriginalURL)
+	})
+
+	t.Run("recognizes the forwarded host", func(t *testing.T) {
+		memStore := store.NewMemoryStore()
+		handler := newTestHandler(memStore, handlers.WithCollapsedSelfRedirects())
+		ctx := handlers.ContextWithRequestMeta(context.Background(), handlers.RequestMeta{
+			ForwardedProto: "https",
+			ForwardedHost:  "sho.rt",
+		})
+
+		first := create(t, handler, ctx, testURL)
+		require.Equal(t, "https://sho.rt/"+first.Body.Code, first.Body.ShortURL)
+
+		assert.Equal(t, testURL, create(t, handler, ctx, first.Body.ShortURL).Body.OriginalURL)
+	})
+
+	t.Run("keeps other and unknown urls as given", func(t *testing.T) {
+		handler := newTestHandler(store.NewMemoryStore(), handlers.WithCollapsedSelfRedirects())
+
+		for _, target := range []string{
+			"http://localhost:8888/missing",
+			"http://localhost:8888/stats/abc123",
+			"https://other.example/abc123",
+		} {
+			assert.Equal(t, target, create(t, handler, context.Background(), target).Body.OriginalURL)
+		}
+	})
+
+	t.Run("keeps flagged short urls so their fallback still applies", func(t *testing.T) {
+		memStore := store.NewMemoryStore()
+		require.NoError(t, memStore.Save(context.Background(), &shortener.ShortURL{
+			Code: "flagged", OriginalURL: testURL, Flagged: true,
+		}))
+
+		handler := newTestHandler(memStore, handlers.WithCollapsedSelfRedirects())
+		target := "http://localhost:8888/flagged"
+
+		assert.Equal(t, target, create(t, handler, context.Background(), target).Body.OriginalURL)
+	})
+
+	t.Run("rejects redirect loops", func(t *testing.T) {
+		memStore := store.NewMemoryStore()
+		require.NoError(t, memStore.Save(context.Background(), &shortener.ShortURL{
+			Code: "loopa", OriginalURL: "http://localhost:8888/loopb",
+		}))
+		require.NoError(t, memStore.Save(context.Background(), &shortener.ShortURL{
+			Code: "loopb", OriginalURL: "http://localhost:8888/loopa",
+		}))
+
+		handler := newTestHandler(memStore, handlers.WithCollapsedSelfRedirects())
+
+		req := &handlers.CreateShortURLRequest{}
+		req.Body.URL = "http://localhost:8888/loopa"
+
+		_, err := handler.CreateShortURL(context.Background(), req)
+
+		var statusErr huma.StatusError
+		require.ErrorAs(t, err, &statusErr)
+		assert.Equal(t, http.StatusBadRequest, statusErr.GetStatus())
+	})
+
+	// chain saves hops short urls, each pointing at the next and the last at testURL
+	chain := func(t *testing.T, hops int) *store.MemoryStore {
+		t.Helper()
+
+		memStore := store.NewMemoryStore()
+
+		for i := 1; i <= hops; i++ {
+			target := fmt.Sprintf("http://localhost:8888/hop%d", i+1)
+			if i == hops {
+				target = testURL
+			}
+
+			require.NoError(t, memStore.Save(context.Background(), &shortener.ShortURL{
+				Code: shortener.Code(fmt.Sprintf("hop%d", i)), OriginalURL: target,
+			}))
+		}
+
+		return memStore
+	}
+
+	t.Run("follows a chain of exactly the maximum hops", func(t *testing.T) {
+		handler := newTestHandler(chain(t, 5), handlers.WithCollapsedSelfRedirects())
+
+		resp := create(t, handler, context.Background(), "http://localhost:8888/hop1")
+
+		assert.Equal(t, testURL, resp.Body.OriginalURL)
+	})
+
+	t.Run("rejects a chain longer than the maximum hops", func(t *testing.T) {
+		handler := newTestHandler(chain(t, 6), handlers.WithCollapsedSelfRedirects())
+
+		req := &handlers.CreateShortURLRequest{}
+		req.Body.URL = "http://localhost:8888/hop1"
+
+		_, err := handler.CreateShortURL(context.Background(), req)
+
+		var statusErr huma.StatusError
+		require.ErrorAs(t, err, &statusErr)
+		assert.Equal(t, http.StatusBadRequest, statusErr.GetStatus())
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		handler := newTestHandler(store.NewMemoryStore())
+
+		first := create(t, handler, context.Background(), testURL)
+
+		assert.Equal(t, first.Body.ShortURL, create(t, handler, context.Background(), first.Body.ShortURL).Body.OriginalURL)
+	})
+}
+
+func TestCreateShortURL_RecentSubmissions(t *testing.T) {
+	create := func(t *testing.T, handler *handlers.URLHandler, ctx context.Context, target string) string {
+		t.Helper()
+
+		req := &handlers.CreateShortURLRequest{}
+		req.Body.URL = target
+
+		resp, err := handler.CreateShortURL(ctx, req)
+		require.NoError(t, err)
+
+		return resp.Body.Code
+	}
+
+	client := func(ip string) context.Context {
+		return handlers.ContextWithRequestMeta(context.Background(), handlers.RequestMeta{ClientIP: ip})
+	}
+
+	t.Run("rapid identical submissions from one client get the same code", func(t *testing.T) {
+		handler := newTestHandler(store.NewMemoryStore(),
+			handlers.WithRecentSubmissions(store.NewMemoryRecentSubmissions(10*time.Second)))
+
+		first := create(t, handler, client("10.0.0.1"), testURL)
+
+		assert.Equal(t, first, create(t, handler, client("10.0.0.1"), testURL))
+	})
+
+	t.Run("other clients and other urls get new codes", func(t *testing.T) {
+		handler := newTestHandler(store.NewMemoryStore(),
+			handlers.WithRecentSubmissions(store.NewMemoryRecentSubmissions(10*time.Second)))
+
+		first := create(t, handler, client("10.0.0.1"), testURL)
+
+		assert.NotEqual(t, first, create(t, handler, client("10.0.0.2"), testURL))
+		assert.NotEqual(t, first, create(t, handler, client("10.0.0.1"), "https://example.com/other"))
+	})
+
+	t.Run("authenticated clients are identified by creator", func(t *testing.T) {
+		handler := newTestHandler(store.NewMemoryStore(),
+			handlers.WithRecentSubmissions(store.NewMemoryRecentSubmissions(10*time.Second)))
+
+		first := create(t, handler, shortener.WithCreator(client("10.0.0.1"), "key:team-a"), testURL)
+
+		assert.Equal(t, first, create(t, handler, shortener.WithCreator(client("10.0.0.2"), "key:team-a"), testURL))
+	})
+
+	t.Run("submissions outside the window get new codes", func(t *testing.T) {
+		handler := newTestHandler(store.NewMemoryStore(),
+			handlers.WithRecentSubmissions(store.NewMemoryRecentSubmissions(time.Millisecond)))
+
+		first := create(t, handler, client("10.0.0.1"), testURL)
+		time.Sleep(5 * time.Millisecond)
+
+		assert.NotEqual(t, first, create(t, handler, client("10.0.0.1"), testURL))
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		handler := newTestHandler(store.NewMemoryStore())
+
+		first := create(t, handler, client("10.0.0.1"), testURL)
+
+		assert.NotEqual(t, first, create(t, handler, client("10.0.0.1"), testURL))
+	})
+}
+
+func TestRedirectToURL_CodeRedirectLimit(t *testing.T) {
+	memStore := store.NewMemoryStore()
+	require.NoError(t, memStore.Save(context.Background(), &shortener.ShortURL{Code: "hot123", OriginalURL: testURL}))
+	require.NoError(t, memStore.Save(context.Background(), &shortener.ShortURL{Code: "cold12", OriginalURL: testURL}))
+
+	redirect := func(handler *handlers.URLHandler, code string) error {
+		_, err := handler.RedirectToURL(context.Background(), &handlers.RedirectRequest{Code: code})
+
+		return err
+	}
+
+	t.Run("throttles a hot code while other codes are unaffected", func(t *testing.T) {
+		handler := newTestHandler(memStore, handlers.WithCodeRedirectLimit(ratelimitstore.NewMemory(),
+			ratelimit.LimitConfig{Window: time.Minute, Max: 3}))
+
+		for range 3 {
+			require.NoError(t, redirect(handler, "hot123"))
+		}
+
+		var statusErr huma.StatusError
+		require.ErrorAs(t, redirect(handler, "hot123"), &statusErr)
+		assert.Equal(t, http.StatusTooManyRequests, statusErr.GetStatus())
+
+		assert.NoError(t, redirect(handler, "cold12"))
+	})
+
+	t.Run("unknown codes are not counted", func(t *testing.T) {
+		limitStore := ratelimitstore.NewMemory()
+		handler := newTestHandler(memStore, handlers.WithCodeRedirectLimit(limitStore,
+			ratelimit.LimitConfig{Window: time.Minute, Max: 1}))
+
+		var statusErr huma.StatusError
+		require.ErrorAs(t, redirect(handler, "nope12"), &statusErr)
+		assert.Equal(t, http.StatusNotFound, statusErr.GetStatus())
+
+		count, err := limitStore.Peek(context.Background(), "code:nope12", time.Minute)
+		require.NoError(t, err)
+		assert.Zero(t, count)
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		handler := newTestHandler(memStore)
+
+		for range 10 {
+			require.NoError(t, redirect(handler, "hot123"))
+		}
+	})
+}
@@ -2,6 +2,9 @@ package health
 
 import (
 	"context"
+	"net/http"
+	"sync"
+	"time"
 
 	"github.com/danielgtaylor/huma/v2"
 	"github.com/redis/go-redis/v9"
@@ -29,25 +32,50 @@ func (r *RedisChecker) Ping(ctx context.Context) error {
 
 // Handler handles health check operations.
 type Handler struct {
-	redis Checker
+	redis  Checker
+	checks []namedChecker
+	gate   *Gate
+}
+
+type namedChecker struct {
+	name    string
+	checker Checker
+}
+
+// HandlerOption configures optional health handler behavior.
+type HandlerOption func(*Handler)
+
+// WithChecker adds a named dependency check reported under checks in the response.
+func WithChecker(name string, checker Checker) HandlerOption {
+	return func(h *Handler) {
+		h.checks = append(h.checks, namedChecker{name: name, checker: checker})
+	}
 }
 
 // NewHandler creates a new health handler.
-func NewHandler(redis Checker) *Handler {
-	return &Handler{redis: redis}
+func NewHandler(redis Checker, opts ...HandlerOption) *Handler {
+	h := &Handler{redis: redis}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h
 }
 
 // Response is the response for health check endpoint.
 type Response struct {
-	Body struct {
-		Status string `json:"status"`
-		Redis  string `json:"redis"`
+	Status int
+	Body   struct {
+		Status string            `json:"status"`
+		Redis  string            `json:"redis"`
+		Checks map[string]string `json:"checks,omitempty"`
 	}
 }
 
 // Check performs a health check of the application and its dependencies.
 func (h *Handler) Check(ctx context.Context, _ *struct{}) (*Response, error) {
-	resp := &Response{}
+	resp := &Response{Status: http.StatusOK}
 	resp.Body.Status = "ok"
 
 	if err := h.redis.Ping(ctx); err != nil {
@@ -57,10 +85,104 @@ func (h *Handler) Check(ctx context.Context, _ *struct{}) (*Response, error) {
 		resp.Body.Redis = "healthy"
 	}
 
+	for _, c := range h.checks {
+		if resp.Body.Checks == nil {
+			resp.Body.Checks = make(map[string]string, len(h.checks))
+		}
+
+		if err := c.checker.Ping(ctx); err != nil {
+			resp.Body.Checks[c.name] = "unhealthy"
+			resp.Body.Status = "degraded"
+		} else {
+			resp.Body.Checks[c.name] = "healthy"
+		}
+	}
+
+	return resp, nil
+}
+
+// HeadResponse is the bodiless response for HEAD probes of the health endpoint.
+type HeadResponse struct {
+	Status int
+}
+
+// Head runs the same checks as Check and returns only its status code.
+func (h *Handler) Head(ctx context.Context, _ *struct{}) (*HeadResponse, error) {
+	resp, err := h.Check(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &HeadResponse{Status: resp.Status}, nil
+}
+
+// CheckResult is the outcome of a single dependency check.
+type CheckResult struct {
+	Status    string  `json:"status"`
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// DetailedResponse is the response for the detailed health endpoint.
+type DetailedResponse struct {
+	Status int
+	Body   struct {
+		Status string                 `json:"status"`
+		Checks map[string]CheckResult `json:"checks"`
+	}
+}
+
+// CheckDetailed runs Redis and every named check concurrently and reports each
+// one's status, latency and error, for dashboards that want one call.
+func (h *Handler) CheckDetailed(ctx context.Context, _ *struct{}) (*DetailedResponse, error) {
+	checks := append([]namedChecker{{name: "redis", checker: h.redis}}, h.checks...)
+	results := make([]CheckResult, len(checks))
+
+	var wg sync.WaitGroup
+
+	for i, c := range checks {
+		wg.Go(func() {
+			results[i] = runCheck(ctx, c.checker)
+		})
+	}
+
+	wg.Wait()
+
+	resp := &DetailedResponse{Status: http.StatusOK}
+	resp.Body.Status = "ok"
+	resp.Body.Checks = make(map[string]CheckResult, len(checks))
+
+	for i, c := range checks {
+		resp.Body.Checks[c.name] = results[i]
+
+		if results[i].Status != "healthy" {
+			resp.Body.Status = "degraded"
+		}
+	}
+
 	return resp, nil
 }
 
+func runCheck(ctx context.Context, checker Checker) CheckResult {
+	start := time.Now()
+	err := checker.Ping(ctx)
+	result := CheckResult{
+		Status:    "healthy",
+		LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+	}
+
+	if err != nil {
+		result.Status = "unhealthy"
+		result.Error = err.Error()
+	}
+
+	return result
+}
+
 // RegisterRoutes registers health check routes.
 func RegisterRoutes(api huma.API, h *Handler) {
 	huma.Get(api, "/health", h.Check)
+	huma.Head(api, "/health", h.Head)
+	huma.Get(api, "/health/detailed", h.CheckDetailed)
+	huma.Get(api, "/readyz", h.Ready)
 }
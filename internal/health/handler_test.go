@@ -2,11 +2,17 @@ package health_test
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
 	"time"
 
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/adapters/humachi"
+	"github.com/go-chi/chi/v5"
 	"github.com/redis/go-redis/v9"
 	"github.com/serroba/web-demo-go/internal/health"
 	"github.com/stretchr/testify/assert"
@@ -36,6 +42,7 @@ func TestHandler_Check(t *testing.T) {
 		resp, err := handler.Check(context.Background(), nil)
 
 		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.Status)
 		assert.Equal(t, "ok", resp.Body.Status)
 		assert.Equal(t, "healthy", resp.Body.Redis)
 	})
@@ -50,6 +57,114 @@ func TestHandler_Check(t *testing.T) {
 		assert.Equal(t, "degraded", resp.Body.Status)
 		assert.Equal(t, "unhealthy", resp.Body.Redis)
 	})
+	t.Run("reports named checkers", func(t *testing.T) {
+		handler := health.NewHandler(&mockChecker{},
+			health.WithChecker("broker", &mockChecker{}),
+		)
+
+		resp, err := handler.Check(context.Background(), nil)
+
+		require.NoError(t, err)
+		assert.Equal(t, "ok", resp.Body.Status)
+		assert.Equal(t, map[string]string{"broker": "healthy"}, resp.Body.Checks)
+	})
+
+	t.Run("returns degraded when a named checker is unhealthy", func(t *testing.T) {
+		handler := health.NewHandler(&mockChecker{},
+			health.WithChecker("broker", &mockChecker{err: errors.New("stream unavailable")}),
+		)
+
+		resp, err := handler.Check(context.Background(), nil)
+
+		require.NoError(t, err)
+		assert.Equal(t, "degraded", resp.Body.Status)
+		assert.Equal(t, "healthy", resp.Body.Redis)
+		assert.Equal(t, "unhealthy", resp.Body.Checks["broker"])
+	})
+}
+
+func TestRegisterRoutes_Head(t *testing.T) {
+	router := chi.NewMux()
+	api := humachi.New(router, huma.DefaultConfig("Test", "1.0.0"))
+	health.RegisterRoutes(api, health.NewHandler(&mockChecker{err: errors.New("connection refused")}))
+
+	head := httptest.NewRecorder()
+	router.ServeHTTP(head, httptest.NewRequest(http.MethodHead, "/health", nil))
+
+	get := httptest.NewRecorder()
+	router.ServeHTTP(get, httptest.NewRequest(http.MethodGet, "/health", nil))
+
+	assert.Equal(t, http.StatusOK, head.Code)
+	assert.Equal(t, get.Code, head.Code, "HEAD should report the same status as GET")
+	assert.Empty(t, head.Body.String())
+	assert.NotEmpty(t, get.Body.String())
+}
+
+type slowChecker struct {
+	delay time.Duration
+}
+
+func (s *slowChecker) Ping(ctx context.Context) error {
+	select {
+	case <-time.After(s.delay):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func TestHandler_CheckDetailed(t *testing.T) {
+	serve := func(t *testing.T, handler *health.Handler) (int, map[string]any) {
+		t.Helper()
+
+		router := chi.NewMux()
+		api := humachi.New(router, huma.DefaultConfig("Test", "1.0.0"))
+		health.RegisterRoutes(api, handler)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health/detailed", nil))
+
+		var body map[string]any
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+
+		return w.Code, body
+	}
+
+	t.Run("aggregates mixed healthy and unhealthy checks", func(t *testing.T) {
+		handler := health.NewHandler(&mockChecker{},
+			health.WithChecker("postgres", &slowChecker{delay: 20 * time.Millisecond}),
+			health.WithChecker("broker", &mockChecker{err: errors.New("stream missing")}),
+		)
+
+		code, body := serve(t, handler)
+
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, "degraded", body["status"])
+
+		checks, ok := body["checks"].(map[string]any)
+		require.True(t, ok)
+		require.Len(t, checks, 3)
+
+		redis := checks["redis"].(map[string]any)
+		assert.Equal(t, "healthy", redis["status"])
+		assert.NotContains(t, redis, "error")
+
+		postgres := checks["postgres"].(map[string]any)
+		assert.Equal(t, "healthy", postgres["status"])
+		assert.GreaterOrEqual(t, postgres["latency_ms"], 20.0)
+
+		broker := checks["broker"].(map[string]any)
+		assert.Equal(t, "unhealthy", broker["status"])
+		assert.Equal(t, "stream missing", broker["error"])
+		assert.Contains(t, broker, "latency_ms")
+	})
+
+	t.Run("reports ok when every check passes", func(t *testing.T) {
+		code, body := serve(t, health.NewHandler(&mockChecker{}, health.WithChecker("broker", &mockChecker{})))
+
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, "ok", body["status"])
+	})
 }
 
 func TestRedisChecker(t *testing.T) {
@@ -83,3 +198,63 @@ func TestRedisChecker(t *testing.T) {
 		assert.NoError(t, err)
 	})
 }
+
+// flakyChecker fails the pings whose index is in failAt.
+type flakyChecker struct {
+	calls  int
+	failAt map[int]bool
+}
+
+func (f *flakyChecker) Ping(_ context.Context) error {
+	defer func() { f.calls++ }()
+
+	if f.failAt[f.calls] {
+		return errors.New("connection reset")
+	}
+
+	return nil
+}
+
+func TestThresholdChecker(t *testing.T) {
+	t.Run("one transient failure stays healthy", func(t *testing.T) {
+		redis := &flakyChecker{failAt: map[int]bool{1: true}}
+		handler := health.NewHandler(health.NewThresholdChecker(redis, 3))
+
+		for range 4 {
+			resp, err := handler.Check(context.Background(), nil)
+			require.NoError(t, err)
+			assert.Equal(t, "healthy", resp.Body.Redis)
+			assert.Equal(t, "ok", resp.Body.Status)
+		}
+	})
+
+	t.Run("sustained failures become unhealthy", func(t *testing.T) {
+		handler := health.NewHandler(
+			health.NewThresholdChecker(&mockChecker{err: errors.New("connection refused")}, 3))
+
+		for range 2 {
+			resp, err := handler.Check(context.Background(), nil)
+			require.NoError(t, err)
+			assert.Equal(t, "healthy", resp.Body.Redis)
+		}
+
+		resp, err := handler.Check(context.Background(), nil)
+		require.NoError(t, err)
+		assert.Equal(t, "unhealthy", resp.Body.Redis)
+		assert.Equal(t, "degraded", resp.Body.Status)
+	})
+
+	t.Run("a success resets the failure count", func(t *testing.T) {
+		checker := health.NewThresholdChecker(&flakyChecker{failAt: map[int]bool{0: true, 1: true, 3: true, 4: true}}, 3)
+
+		for range 5 {
+			require.NoError(t, checker.Ping(context.Background()))
+		}
+	})
+
+	t.Run("threshold of one reports the first failure", func(t *testing.T) {
+		checker := health.NewThresholdChecker(&mockChecker{err: errors.New("connection refused")}, 1)
+
+		require.Error(t, checker.Ping(context.Background()))
+	})
+}
@@ -0,0 +1,86 @@
+package health
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// Gate holds readiness closed while the service starts up, so load balancers
+// send no traffic before dependencies answer and any warm-up has finished.
+type Gate struct {
+	open atomic.Bool
+}
+
+// NewGate creates a closed gate.
+func NewGate() *Gate {
+	return &Gate{}
+}
+
+// Open marks the service ready. It is safe to call more than once.
+func (g *Gate) Open() {
+	g.open.Store(true)
+}
+
+// IsOpen reports whether the service is ready.
+func (g *Gate) IsOpen() bool {
+	return g.open.Load()
+}
+
+// OpenWhen opens the gate once every checker has answered a ping and at least
+// delay has passed. Failed pings are retried every interval. It returns false
+// without opening the gate when ctx is done first.
+func (g *Gate) OpenWhen(ctx context.Context, delay, interval time.Duration, checkers ...Checker) bool {
+	deadline := time.NewTimer(delay)
+	defer deadline.Stop()
+
+	for _, checker := range checkers {
+		for checker.Ping(ctx) != nil {
+			select {
+			case <-ctx.Done():
+				return false
+			case <-time.After(interval):
+			}
+		}
+	}
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-deadline.C:
+	}
+
+	g.Open()
+
+	return true
+}
+
+// WithReadinessGate makes /readyz answer 503 until gate opens.
+func WithReadinessGate(gate *Gate) HandlerOption {
+	return func(h *Handler) {
+		h.gate = gate
+	}
+}
+
+// ReadyResponse is the response for the readiness endpoint.
+type ReadyResponse struct {
+	Status int
+	Body   struct {
+		Status string `json:"status"`
+	}
+}
+
+// Ready reports whether the service finished starting up. Unlike Check it does
+// not ping dependencies, so probes stay cheap once the gate is open.
+func (h *Handler) Ready(_ context.Context, _ *struct{}) (*ReadyResponse, error) {
+	resp := &ReadyResponse{Status: http.StatusOK}
+	resp.Body.Status = "ready"
+
+	if h.gate != nil && !h.gate.IsOpen() {
+		resp.Status = http.StatusServiceUnavailable
+		resp.Body.Status = "starting"
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,73 @@
+package health_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/adapters/humachi"
+	"github.com/go-chi/chi/v5"
+	"github.com/serroba/web-demo-go/internal/health"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadyz(t *testing.T) {
+	readyz := func(router http.Handler) int {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+		return rec.Code
+	}
+
+	t.Run("transitions from 503 to 200 when the gate opens", func(t *testing.T) {
+		gate := health.NewGate()
+		router := chi.NewMux()
+		api := humachi.New(router, huma.DefaultConfig("Test", "1.0.0"))
+		health.RegisterRoutes(api, health.NewHandler(&mockChecker{}, health.WithReadinessGate(gate)))
+
+		assert.Equal(t, http.StatusServiceUnavailable, readyz(router))
+
+		gate.Open()
+
+		assert.Equal(t, http.StatusOK, readyz(router))
+	})
+
+	t.Run("is ready without a gate", func(t *testing.T) {
+		router := chi.NewMux()
+		api := humachi.New(router, huma.DefaultConfig("Test", "1.0.0"))
+		health.RegisterRoutes(api, health.NewHandler(&mockChecker{}))
+
+		assert.Equal(t, http.StatusOK, readyz(router))
+	})
+}
+
+func TestGate_OpenWhen(t *testing.T) {
+	t.Run("opens once dependencies answer and the delay passed", func(t *testing.T) {
+		gate := health.NewGate()
+		checker := &flakyChecker{failAt: map[int]bool{0: true, 1: true}}
+
+		start := time.Now()
+		opened := gate.OpenWhen(context.Background(), 20*time.Millisecond, time.Millisecond, checker)
+
+		assert.True(t, opened)
+		assert.True(t, gate.IsOpen())
+		assert.Equal(t, 3, checker.calls)
+		assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+	})
+
+	t.Run("stays closed when the context ends first", func(t *testing.T) {
+		gate := health.NewGate()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		opened := gate.OpenWhen(ctx, 0, time.Millisecond, &mockChecker{err: errors.New("down")})
+
+		assert.False(t, opened)
+		assert.False(t, gate.IsOpen())
+	})
+}
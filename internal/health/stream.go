@@ -0,0 +1,76 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// StreamGroupReader reads consumer group information for a Redis stream.
+// It is satisfied by *redis.Client.
+type StreamGroupReader interface {
+	XInfoGroups(ctx context.Context, stream string) *redis.XInfoGroupsCmd
+}
+
+// StreamChecker verifies that the analytics streams are reachable through the broker
+// and that the consumer group is not lagging too far behind.
+type StreamChecker struct {
+	client  StreamGroupReader
+	group   string
+	streams []string
+	maxLag  int64
+}
+
+// NewStreamChecker creates a broker checker for the given consumer group and streams.
+// A maxLag of 0 disables the lag check.
+func NewStreamChecker(client StreamGroupReader, group string, streams []string, maxLag int64) *StreamChecker {
+	return &StreamChecker{
+		client:  client,
+		group:   group,
+		streams: streams,
+		maxLag:  maxLag,
+	}
+}
+
+// Ping checks each stream's consumer group. A stream that does not exist yet is
+// considered healthy since it is created on first publish.
+func (s *StreamChecker) Ping(ctx context.Context) error {
+	for _, stream := range s.streams {
+		groups, err := s.client.XInfoGroups(ctx, stream).Result()
+		if err != nil {
+			if isNoSuchKey(err) {
+				continue
+			}
+
+			return fmt.Errorf("stream %s: %w", stream, err)
+		}
+
+		if err := s.checkGroup(stream, groups); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *StreamChecker) checkGroup(stream string, groups []redis.XInfoGroup) error {
+	for _, g := range groups {
+		if g.Name != s.group {
+			continue
+		}
+
+		if s.maxLag > 0 && g.Lag > s.maxLag {
+			return fmt.Errorf("stream %s: consumer group %s lag %d exceeds %d", stream, s.group, g.Lag, s.maxLag)
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf("stream %s: consumer group %s not found", stream, s.group)
+}
+
+func isNoSuchKey(err error) bool {
+	return strings.Contains(err.Error(), "no such key")
+}
@@ -0,0 +1,88 @@
+package health_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/serroba/web-demo-go/internal/health"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeStreams reports canned consumer group state per stream.
+type fakeStreams struct {
+	groups map[string][]redis.XInfoGroup
+	errs   map[string]error
+}
+
+func (f *fakeStreams) XInfoGroups(ctx context.Context, stream string) *redis.XInfoGroupsCmd {
+	cmd := redis.NewXInfoGroupsCmd(ctx, stream)
+	if err, ok := f.errs[stream]; ok {
+		cmd.SetErr(err)
+
+		return cmd
+	}
+
+	cmd.SetVal(f.groups[stream])
+
+	return cmd
+}
+
+func TestStreamChecker_Ping(t *testing.T) {
+	streams := []string{"url.created", "url.accessed"}
+
+	t.Run("healthy when group exists on all streams", func(t *testing.T) {
+		fake := &fakeStreams{groups: map[string][]redis.XInfoGroup{
+			"url.created":  {{Name: "analytics", Lag: 2}},
+			"url.accessed": {{Name: "analytics", Lag: 0}},
+		}}
+		checker := health.NewStreamChecker(fake, "analytics", streams, 10)
+
+		require.NoError(t, checker.Ping(context.Background()))
+	})
+
+	t.Run("healthy when stream does not exist yet", func(t *testing.T) {
+		fake := &fakeStreams{errs: map[string]error{
+			"url.created":  errors.New("ERR no such key"),
+			"url.accessed": errors.New("ERR no such key"),
+		}}
+		checker := health.NewStreamChecker(fake, "analytics", streams, 0)
+
+		require.NoError(t, checker.Ping(context.Background()))
+	})
+
+	t.Run("unhealthy when consumer group is missing", func(t *testing.T) {
+		fake := &fakeStreams{groups: map[string][]redis.XInfoGroup{
+			"url.created":  {{Name: "other"}},
+			"url.accessed": {{Name: "analytics"}},
+		}}
+		checker := health.NewStreamChecker(fake, "analytics", streams, 0)
+
+		err := checker.Ping(context.Background())
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "consumer group analytics not found")
+	})
+
+	t.Run("unhealthy when lag exceeds max", func(t *testing.T) {
+		fake := &fakeStreams{groups: map[string][]redis.XInfoGroup{
+			"url.created":  {{Name: "analytics", Lag: 500}},
+			"url.accessed": {{Name: "analytics"}},
+		}}
+		checker := health.NewStreamChecker(fake, "analytics", streams, 100)
+
+		err := checker.Ping(context.Background())
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "lag 500 exceeds 100")
+	})
+
+	t.Run("unhealthy when broker errors", func(t *testing.T) {
+		fake := &fakeStreams{errs: map[string]error{"url.created": errors.New("connection refused")}}
+		checker := health.NewStreamChecker(fake, "analytics", streams, 0)
+
+		require.Error(t, checker.Ping(context.Background()))
+	})
+}
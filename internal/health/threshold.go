@@ -0,0 +1,49 @@
+package health
+
+import (
+	"context"
+	"sync"
+)
+
+// ThresholdChecker wraps a Checker and only reports failure once it has failed
+// threshold times in a row, so a single dropped ping does not flip the health
+// status. Any successful ping resets the count.
+type ThresholdChecker struct {
+	checker   Checker
+	threshold int
+
+	mu       sync.Mutex
+	failures int
+}
+
+// NewThresholdChecker creates a checker that tolerates threshold-1 consecutive
+// failures of checker. A threshold of 1 or less reports every failure.
+func NewThresholdChecker(checker Checker, threshold int) *ThresholdChecker {
+	return &ThresholdChecker{
+		checker:   checker,
+		threshold: max(threshold, 1),
+	}
+}
+
+// Ping checks the wrapped dependency and returns its error once the failure
+// threshold is reached.
+func (t *ThresholdChecker) Ping(ctx context.Context) error {
+	err := t.checker.Ping(ctx)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err == nil {
+		t.failures = 0
+
+		return nil
+	}
+
+	t.failures++
+
+	if t.failures < t.threshold {
+		return nil
+	}
+
+	return err
+}
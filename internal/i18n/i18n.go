@@ -0,0 +1,123 @@
+// Package i18n localizes user-facing error messages based on Accept-Language.
+package i18n
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/serroba/web-demo-go/internal/shortener"
+)
+
+// DefaultLanguage is used when a request accepts none of the catalog languages.
+// Messages are written in it, so it needs no catalog entries.
+const DefaultLanguage = "en"
+
+// catalog maps a language to translations of English messages. Messages that
+// come from sentinel errors are keyed by the error, so rewording one cannot
+// silently drop its translation. Messages of the form "message: details" are
+// translated part by part, so dynamic details such as rate limit counts pass
+// through unchanged.
+var catalog = map[string]map[string]string{
+	"es": {
+		"Bad Request":                        "Solicitud incorrecta",
+		"Not Found":                          "No encontrado",
+		"Conflict":                           "Conflicto",
+		"Gone":                               "Ya no disponible",
+		"Too Many Requests":                  "Demasiadas solicitudes",
+		"Unprocessable Entity":               "Entidad no procesable",
+		shortener.ErrNotFound.Error():        "URL corta no encontrada",
+		"short url target is disabled":       "el destino de la URL corta está deshabilitado",
+		shortener.ErrAliasTaken.Error():      "el alias ya está en uso",
+		shortener.ErrInvalidAlias.Error():    "alias no válido",
+		shortener.ErrInvalidURL.Error():      "URL no válida",
+		shortener.ErrInvalidStrategy.Error(): "estrategia no válida",
+		"supported strategies":               "estrategias admitidas",
+		"rate limit exceeded":                "límite de solicitudes excedido",
+		"host not allowed":                   "host no permitido",
+		"forwarded host not allowed":         "host reenviado no permitido",
+		"validation failed":                  "la validación falló",
+	},
+	"de": {
+		"Bad Request":                        "Ungültige Anfrage",
+		"Not Found":                          "Nicht gefunden",
+		"Conflict":                           "Konflikt",
+		"Gone":                               "Nicht mehr verfügbar",
+		"Too Many Requests":                  "Zu viele Anfragen",
+		"Unprocessable Entity":               "Nicht verarbeitbare Anfrage",
+		shortener.ErrNotFound.Error():        "Kurz-URL nicht gefunden",
+		"short url target is disabled":       "das Ziel der Kurz-URL ist deaktiviert",
+		shortener.ErrAliasTaken.Error():      "Alias wird bereits verwendet",
+		shortener.ErrInvalidAlias.Error():    "ungültiger Alias",
+		shortener.ErrInvalidURL.Error():      "ungültige URL",
+		shortener.ErrInvalidStrategy.Error(): "ungültige Strategie",
+		"supported strategies":               "unterstützte Strategien",
+		"rate limit exceeded":                "Anfragelimit überschritten",
+		"host not allowed":                   "Host nicht erlaubt",
+		"forwarded host not allowed":         "weitergeleiteter Host nicht erlaubt",
+		"validation failed":                  "Validierung fehlgeschlagen",
+	},
+}
+
+// Negotiate returns the supported language with the highest quality in an
+// Accept-Language header, matching regional tags such as es-MX by their
+// primary subtag. It falls back to DefaultLanguage.
+func Negotiate(acceptLanguage string) string {
+	best, bestQ := DefaultLanguage, 0.0
+
+	for part := range strings.SplitSeq(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		primary, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+
+		if primary != DefaultLanguage && catalog[primary] == nil {
+			continue
+		}
+
+		if q := quality(params); q > bestQ {
+			best, bestQ = primary, q
+		}
+	}
+
+	return best
+}
+
+// quality parses the q parameter of an Accept-Language entry, defaulting to 1.
+func quality(params string) float64 {
+	for param := range strings.SplitSeq(params, ";") {
+		if value, ok := strings.CutPrefix(strings.TrimSpace(param), "q="); ok {
+			q, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return 0
+			}
+
+			return q
+		}
+	}
+
+	return 1
+}
+
+// Translate returns msg in lang, or msg unchanged when there is no translation.
+func Translate(lang, msg string) string {
+	messages := catalog[lang]
+	if messages == nil {
+		return msg
+	}
+
+	if translated, ok := messages[msg]; ok {
+		return translated
+	}
+
+	parts := strings.Split(msg, ": ")
+	for i, part := range parts {
+		if translated, ok := messages[part]; ok {
+			parts[i] = translated
+		}
+	}
+
+	return strings.Join(parts, ": ")
+}
+
+// Localize translates msg into the best language for an Accept-Language header.
+func Localize(acceptLanguage, msg string) string {
+	return Translate(Negotiate(acceptLanguage), msg)
+}
@@ -0,0 +1,66 @@
+package i18n_test
+
+import (
+	"testing"
+
+	"github.com/serroba/web-demo-go/internal/i18n"
+	"github.com/serroba/web-demo-go/internal/shortener"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := map[string]string{
+		"":                        "en",
+		"es":                      "es",
+		"es-MX":                   "es",
+		"DE-at":                   "de",
+		"fr, es;q=0.5":            "es",
+		"en;q=0.9, es;q=0.8":      "en",
+		"es;q=0.2, de;q=0.7":      "de",
+		"es;q=0":                  "en",
+		"ja, zh-CN;q=0.9":         "en",
+		"es;q=abc":                "en",
+		"es-ES,es;q=0.9,en;q=0.5": "es",
+	}
+
+	for header, want := range tests {
+		t.Run(header, func(t *testing.T) {
+			assert.Equal(t, want, i18n.Negotiate(header))
+		})
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	t.Run("exact message", func(t *testing.T) {
+		assert.Equal(t, "URL corta no encontrada", i18n.Translate("es", "short url not found"))
+	})
+
+	t.Run("keeps details after a translated prefix", func(t *testing.T) {
+		assert.Equal(t,
+			"límite de solicitudes excedido: write scope, 3/10 requests in 1m0s",
+			i18n.Translate("es", "rate limit exceeded: write scope, 3/10 requests in 1m0s"))
+	})
+
+	t.Run("translates every known part", func(t *testing.T) {
+		assert.Equal(t,
+			"estrategia no válida: estrategias admitidas: 'hash', 'token'",
+			i18n.Translate("es", "invalid strategy: supported strategies: 'hash', 'token'"))
+	})
+
+	t.Run("sentinel errors are translated by their message", func(t *testing.T) {
+		assert.Equal(t, "ungültige Strategie", i18n.Translate("de", shortener.ErrInvalidStrategy.Error()))
+	})
+
+	t.Run("unknown message is unchanged", func(t *testing.T) {
+		assert.Equal(t, "something else", i18n.Translate("es", "something else"))
+	})
+
+	t.Run("default language is unchanged", func(t *testing.T) {
+		assert.Equal(t, "short url not found", i18n.Translate("en", "short url not found"))
+	})
+}
+
+func TestLocalize(t *testing.T) {
+	assert.Equal(t, "Host nicht erlaubt", i18n.Localize("de-DE", "host not allowed"))
+	assert.Equal(t, "host not allowed", i18n.Localize("it", "host not allowed"))
+}
@@ -0,0 +1,25 @@
+package logging_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/serroba/web-demo-go/internal/logging"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestFromContext(t *testing.T) {
+	t.Run("returns stored logger", func(t *testing.T) {
+		logger := zap.NewNop()
+		ctx := logging.WithLogger(context.Background(), logger)
+
+		assert.Same(t, logger, logging.FromContext(ctx, zap.NewNop()))
+	})
+
+	t.Run("returns fallback when none stored", func(t *testing.T) {
+		fallback := zap.NewNop()
+
+		assert.Same(t, fallback, logging.FromContext(context.Background(), fallback))
+	})
+}
@@ -0,0 +1,28 @@
+package logging
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// TimeShutdown runs shutdown and logs how long the named component took to stop.
+func TimeShutdown(logger *zap.Logger, component string, shutdown func() error) error {
+	start := time.Now()
+	err := shutdown()
+
+	fields := []zap.Field{
+		zap.String("component", component),
+		zap.Duration("duration", time.Since(start)),
+	}
+
+	if err != nil {
+		logger.Error("component shutdown failed", append(fields, zap.Error(err))...)
+
+		return err
+	}
+
+	logger.Info("component shut down", fields...)
+
+	return nil
+}
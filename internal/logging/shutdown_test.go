@@ -0,0 +1,48 @@
+package logging_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/serroba/web-demo-go/internal/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestTimeShutdown(t *testing.T) {
+	t.Run("logs how long shutdown took", func(t *testing.T) {
+		core, logs := observer.New(zapcore.InfoLevel)
+
+		err := logging.TimeShutdown(zap.New(core), "redis", func() error {
+			time.Sleep(20 * time.Millisecond)
+
+			return nil
+		})
+
+		require.NoError(t, err)
+		require.Equal(t, 1, logs.Len())
+
+		entry := logs.All()[0]
+		fields := entry.ContextMap()
+
+		assert.Equal(t, "component shut down", entry.Message)
+		assert.Equal(t, "redis", fields["component"])
+		assert.GreaterOrEqual(t, fields["duration"], 20*time.Millisecond)
+	})
+
+	t.Run("logs and returns shutdown errors", func(t *testing.T) {
+		core, logs := observer.New(zapcore.InfoLevel)
+		shutdownErr := errors.New("close failed")
+
+		err := logging.TimeShutdown(zap.New(core), "postgres", func() error { return shutdownErr })
+
+		require.ErrorIs(t, err, shutdownErr)
+		require.Equal(t, 1, logs.Len())
+		assert.Equal(t, zapcore.ErrorLevel, logs.All()[0].Level)
+		assert.Equal(t, "postgres", logs.All()[0].ContextMap()["component"])
+	})
+}
@@ -0,0 +1,151 @@
+package messaging
+
+import (
+	"errors"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrBufferFull is returned when an AsyncPublisher already holds its maximum
+	// number of in-flight events. The event is dropped.
+	ErrBufferFull = errors.New("publish buffer full")
+	// ErrPublisherClosed is returned when an event is published after Shutdown.
+	// The event is dropped.
+	ErrPublisherClosed = errors.New("publisher closed")
+)
+
+// BufferRecorder records the occupancy of an AsyncPublisher buffer and the
+// events it drops.
+type BufferRecorder interface {
+	PublishBufferOccupancy(events int)
+	PublishDropped()
+}
+
+// NopBufferRecorder is a BufferRecorder that discards all observations.
+type NopBufferRecorder struct{}
+
+// PublishBufferOccupancy implements BufferRecorder.
+func (NopBufferRecorder) PublishBufferOccupancy(int) {}
+
+// PublishDropped implements BufferRecorder.
+func (NopBufferRecorder) PublishDropped() {}
+
+// AsyncPublisherOption configures optional AsyncPublisher behavior.
+type AsyncPublisherOption func(*asyncConfig)
+
+type asyncConfig struct {
+	recorder BufferRecorder
+}
+
+// WithBufferRecorder sets the recorder notified when the buffer occupancy
+// changes and when an event is dropped.
+func WithBufferRecorder(recorder BufferRecorder) AsyncPublisherOption {
+	return func(c *asyncConfig) {
+		c.recorder = recorder
+	}
+}
+
+// AsyncPublisher publishes events from a background goroutine so callers never
+// wait on the broker. At most size events are in flight, counting the one being
+// published; further events are dropped rather than blocking the caller.
+type AsyncPublisher[T any] struct {
+	publish  Publish[T]
+	logger   *zap.Logger
+	recorder BufferRecorder
+	size     int
+	events   chan *T
+	done     chan struct{}
+
+	// mu guards inFlight and closed, and is held while sending to events so
+	// the occupancy reported to the recorder never lags the buffer.
+	mu       sync.Mutex
+	inFlight int
+	closed   bool
+}
+
+// NewAsyncPublisher starts a publisher that hands events to publish in the
+// background, holding at most size of them. Sizes below 1 are treated as 1.
+func NewAsyncPublisher[T any](
+	publish Publish[T],
+	size int,
+	logger *zap.Logger,
+	opts ...AsyncPublisherOption,
+) *AsyncPublisher[T] {
+	cfg := asyncConfig{recorder: NopBufferRecorder{}}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	size = max(size, 1)
+
+	p := &AsyncPublisher[T]{
+		publish:  publish,
+		logger:   logger,
+		recorder: cfg.recorder,
+		size:     size,
+		events:   make(chan *T, size),
+		done:     make(chan struct{}),
+	}
+
+	go p.run()
+
+	return p
+}
+
+// Publish queues event for publishing. It returns ErrBufferFull or
+// ErrPublisherClosed, after counting the drop, when the event cannot be queued.
+// Its signature matches Publish so it can be passed wherever one is expected.
+func (p *AsyncPublisher[T]) Publish(event *T) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		p.recorder.PublishDropped()
+
+		return ErrPublisherClosed
+	}
+
+	if p.inFlight >= p.size {
+		p.recorder.PublishDropped()
+
+		return ErrBufferFull
+	}
+
+	// Cannot block: the channel never holds more than inFlight events.
+	p.events <- event
+	p.inFlight++
+	p.recorder.PublishBufferOccupancy(p.inFlight)
+
+	return nil
+}
+
+func (p *AsyncPublisher[T]) run() {
+	defer close(p.done)
+
+	for event := range p.events {
+		if err := p.publish(event); err != nil {
+			p.logger.Error("failed to publish buffered event", zap.Error(err))
+		}
+
+		p.mu.Lock()
+		p.inFlight--
+		p.recorder.PublishBufferOccupancy(p.inFlight)
+		p.mu.Unlock()
+	}
+}
+
+// Shutdown stops accepting events and waits until the buffered ones are published.
+func (p *AsyncPublisher[T]) Shutdown() error {
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.events)
+	}
+	p.mu.Unlock()
+
+	<-p.done
+
+	return nil
+}
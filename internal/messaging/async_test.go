@@ -0,0 +1,125 @@
+package messaging_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/serroba/web-demo-go/internal/messaging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// bufferRecorder keeps the latest occupancy and the number of drops.
+type bufferRecorder struct {
+	mu        sync.Mutex
+	occupancy int
+	peak      int
+	dropped   int
+}
+
+func (r *bufferRecorder) PublishBufferOccupancy(events int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.occupancy = events
+	r.peak = max(r.peak, events)
+}
+
+func (r *bufferRecorder) PublishDropped() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.dropped++
+}
+
+func (r *bufferRecorder) snapshot() (occupancy, peak, dropped int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.occupancy, r.peak, r.dropped
+}
+
+func TestAsyncPublisher(t *testing.T) {
+	t.Run("reports occupancy and drops events beyond the buffer", func(t *testing.T) {
+		release := make(chan struct{})
+
+		var (
+			mu        sync.Mutex
+			published []string
+		)
+
+		publish := func(event *testEvent) error {
+			<-release
+
+			mu.Lock()
+			published = append(published, event.ID)
+			mu.Unlock()
+
+			return nil
+		}
+
+		recorder := &bufferRecorder{}
+		p := messaging.NewAsyncPublisher(publish, 2, zap.NewNop(), messaging.WithBufferRecorder(recorder))
+
+		require.NoError(t, p.Publish(&testEvent{ID: "1"}))
+		require.NoError(t, p.Publish(&testEvent{ID: "2"}))
+
+		occupancy, _, dropped := recorder.snapshot()
+		assert.Equal(t, 2, occupancy)
+		assert.Zero(t, dropped)
+
+		require.ErrorIs(t, p.Publish(&testEvent{ID: "3"}), messaging.ErrBufferFull)
+
+		occupancy, _, dropped = recorder.snapshot()
+		assert.Equal(t, 2, occupancy)
+		assert.Equal(t, 1, dropped)
+
+		close(release)
+		require.NoError(t, p.Shutdown())
+
+		occupancy, peak, dropped := recorder.snapshot()
+		assert.Zero(t, occupancy)
+		assert.Equal(t, 2, peak)
+		assert.Equal(t, 1, dropped)
+		assert.Equal(t, []string{"1", "2"}, published)
+	})
+
+	t.Run("frees room once an event is published", func(t *testing.T) {
+		done := make(chan struct{}, 1)
+		p := messaging.NewAsyncPublisher(func(_ *testEvent) error {
+			done <- struct{}{}
+
+			return nil
+		}, 1, zap.NewNop())
+
+		require.NoError(t, p.Publish(&testEvent{ID: "1"}))
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("timeout waiting for publish")
+		}
+
+		assert.Eventually(t, func() bool {
+			return p.Publish(&testEvent{ID: "2"}) == nil
+		}, time.Second, time.Millisecond)
+
+		require.NoError(t, p.Shutdown())
+	})
+
+	t.Run("drops events after shutdown", func(t *testing.T) {
+		recorder := &bufferRecorder{}
+		p := messaging.NewAsyncPublisher(func(_ *testEvent) error { return nil }, 1, zap.NewNop(),
+			messaging.WithBufferRecorder(recorder))
+
+		require.NoError(t, p.Shutdown())
+		require.NoError(t, p.Shutdown(), "shutdown is idempotent")
+
+		require.ErrorIs(t, p.Publish(&testEvent{ID: "1"}), messaging.ErrPublisherClosed)
+
+		_, _, dropped := recorder.snapshot()
+		assert.Equal(t, 1, dropped)
+	})
+}
@@ -0,0 +1,67 @@
+package messaging
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// CloudEventsSpecVersion is the CloudEvents specification version of published envelopes.
+const CloudEventsSpecVersion = "1.0"
+
+// CloudEventsContentType is the content-type metadata set on messages carrying a
+// CloudEvents envelope (structured content mode).
+const CloudEventsContentType = "application/cloudevents+json"
+
+// CloudEvent is the CloudEvents JSON envelope wrapped around published events
+// when WithCloudEvents is set.
+type CloudEvent struct {
+	SpecVersion     string          `json:"specversion"`
+	Type            string          `json:"type"`
+	Source          string          `json:"source"`
+	ID              string          `json:"id"`
+	Time            time.Time       `json:"time"`
+	DataContentType string          `json:"datacontenttype"`
+	Data            json.RawMessage `json:"data"`
+}
+
+// WithCloudEvents wraps each published event in a CloudEvents envelope. The
+// envelope type is the topic, source identifies this service and id is the
+// message ID, so retries of one event share it. Consumers unwrap envelopes
+// automatically, so publishers can switch formats without a coordinated rollout.
+func WithCloudEvents(source string) PublishOption {
+	return func(c *publishConfig) {
+		c.cloudEventsSource = source
+	}
+}
+
+// newCloudEvent wraps data, the JSON encoding of an event, in a CloudEvents envelope.
+func newCloudEvent(id, topic, source string, data []byte) ([]byte, error) {
+	return json.Marshal(CloudEvent{
+		SpecVersion:     CloudEventsSpecVersion,
+		Type:            topic,
+		Source:          source,
+		ID:              id,
+		Time:            time.Now().UTC(),
+		DataContentType: "application/json",
+		Data:            data,
+	})
+}
+
+// decodeEvent unmarshals payload into event, unwrapping it first when it is a
+// CloudEvents envelope.
+func decodeEvent(payload []byte, event any) error {
+	var envelope struct {
+		SpecVersion string          `json:"specversion"`
+		Data        json.RawMessage `json:"data"`
+	}
+
+	if err := json.Unmarshal(payload, &envelope); err != nil {
+		return err
+	}
+
+	if envelope.SpecVersion == "" {
+		return json.Unmarshal(payload, event)
+	}
+
+	return json.Unmarshal(envelope.Data, event)
+}
@@ -0,0 +1,68 @@
+package messaging_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/serroba/web-demo-go/internal/messaging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestCloudEvents(t *testing.T) {
+	t.Run("publish wraps the event in a CloudEvents envelope", func(t *testing.T) {
+		mock := &mockPublisher{}
+		publish := messaging.NewPublishFunc[testEvent](mock, "url.created", messaging.WithCloudEvents("/shortener"))
+
+		require.NoError(t, publish(&testEvent{ID: "123", Name: "test"}))
+		require.Len(t, mock.messages, 1)
+
+		msg := mock.messages[0]
+
+		var envelope messaging.CloudEvent
+		require.NoError(t, json.Unmarshal(msg.Payload, &envelope))
+
+		assert.Equal(t, messaging.CloudEventsSpecVersion, envelope.SpecVersion)
+		assert.Equal(t, "url.created", envelope.Type)
+		assert.Equal(t, "/shortener", envelope.Source)
+		assert.Equal(t, msg.UUID, envelope.ID)
+		assert.WithinDuration(t, time.Now(), envelope.Time, time.Minute)
+		assert.Equal(t, "application/json", envelope.DataContentType)
+		assert.JSONEq(t, `{"id":"123","name":"test"}`, string(envelope.Data))
+		assert.Equal(t, messaging.CloudEventsContentType, msg.Metadata.Get("content-type"))
+	})
+
+	t.Run("consumer unwraps published envelopes", func(t *testing.T) {
+		mock := &mockPublisher{}
+		publish := messaging.NewPublishFunc[testEvent](mock, "url.created", messaging.WithCloudEvents("/shortener"))
+		require.NoError(t, publish(&testEvent{ID: "123", Name: "test"}))
+
+		sub := newMockSubscriber()
+		received := make(chan *testEvent, 1)
+		consumer := messaging.NewConsumer(sub, "url.created",
+			func(_ context.Context, event *testEvent) error {
+				received <- event
+
+				return nil
+			},
+			zap.NewNop(),
+		)
+		require.NoError(t, consumer.Start(context.Background()))
+		t.Cleanup(func() { _ = consumer.Shutdown() })
+
+		msg := mock.messages[0]
+		sub.msgChan <- msg
+
+		select {
+		case <-msg.Acked():
+			assert.Equal(t, &testEvent{ID: "123", Name: "test"}, <-received)
+		case <-msg.Nacked():
+			t.Fatal("message was nacked")
+		case <-time.After(time.Second):
+			t.Fatal("timeout waiting for ack")
+		}
+	})
+}
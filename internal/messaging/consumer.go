@@ -2,21 +2,52 @@ package messaging
 
 import (
 	"context"
-	"encoding/json"
+	"fmt"
+	"time"
 
 	"github.com/ThreeDotsLabs/watermill/message"
 	"go.uber.org/zap"
 )
 
 // Handler processes a single event. Handlers are synchronous and easy to test.
+// They must return promptly once ctx is done: with WithAckTimeout the message
+// is nacked and may be redelivered while a handler that ignores ctx is still
+// working on it.
 type Handler[T any] func(ctx context.Context, event *T) error
 
+// ConsumerOption configures optional Consumer behavior.
+type ConsumerOption func(*consumerConfig)
+
+type consumerConfig struct {
+	ackTimeout time.Duration
+	recorder   HandlerRecorder
+}
+
+// WithAckTimeout nacks a message when its handler does not finish within timeout,
+// so a hung store call cannot leave the message pending forever. The handler's
+// context is cancelled when the timeout fires.
+func WithAckTimeout(timeout time.Duration) ConsumerOption {
+	return func(c *consumerConfig) {
+		c.ackTimeout = timeout
+	}
+}
+
+// WithHandlerRecorder sets the recorder that observes how long the handler takes
+// for each event, whether it succeeds, fails or times out.
+func WithHandlerRecorder(recorder HandlerRecorder) ConsumerOption {
+	return func(c *consumerConfig) {
+		c.recorder = recorder
+	}
+}
+
 // Consumer subscribes to a topic and processes messages with a typed handler.
 type Consumer[T any] struct {
 	subscriber message.Subscriber
 	topic      string
 	handler    Handler[T]
 	logger     *zap.Logger
+	ackTimeout time.Duration
+	recorder   HandlerRecorder
 	cancel     context.CancelFunc
 	done       chan struct{}
 }
@@ -27,12 +58,20 @@ func NewConsumer[T any](
 	topic string,
 	handler Handler[T],
 	logger *zap.Logger,
+	opts ...ConsumerOption,
 ) *Consumer[T] {
+	cfg := consumerConfig{recorder: NopHandlerRecorder{}}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
 	return &Consumer[T]{
 		subscriber: subscriber,
 		topic:      topic,
 		handler:    handler,
 		logger:     logger,
+		ackTimeout: cfg.ackTimeout,
+		recorder:   cfg.recorder,
 		done:       make(chan struct{}),
 	}
 }
@@ -44,13 +83,17 @@ func (c *Consumer[T]) Topic() string {
 
 // Start begins consuming messages from the topic.
 func (c *Consumer[T]) Start(ctx context.Context) error {
-	ctx, c.cancel = context.WithCancel(ctx)
+	ctx, cancel := context.WithCancel(ctx)
 
 	msgs, err := c.subscriber.Subscribe(ctx, c.topic)
 	if err != nil {
+		cancel()
+
 		return err
 	}
 
+	c.cancel = cancel
+
 	go c.consumeLoop(ctx, msgs)
 
 	return nil
@@ -68,6 +111,14 @@ func (c *Consumer[T]) consumeLoop(ctx context.Context, msgs <-chan *message.Mess
 				return
 			}
 
+			// select picks randomly when a message and shutdown are both ready;
+			// hand the message back for redelivery instead of processing it.
+			if ctx.Err() != nil {
+				msg.Nack()
+
+				return
+			}
+
 			c.handleMessage(ctx, msg)
 		}
 	}
@@ -75,7 +126,7 @@ func (c *Consumer[T]) consumeLoop(ctx context.Context, msgs <-chan *message.Mess
 
 func (c *Consumer[T]) handleMessage(ctx context.Context, msg *message.Message) {
 	var event T
-	if err := json.Unmarshal(msg.Payload, &event); err != nil {
+	if err := decodeEvent(msg.Payload, &event); err != nil {
 		c.logger.Error("failed to unmarshal event",
 			zap.String("topic", c.topic),
 			zap.Error(err),
@@ -85,7 +136,11 @@ func (c *Consumer[T]) handleMessage(ctx context.Context, msg *message.Message) {
 		return
 	}
 
-	if err := c.handler(ctx, &event); err != nil {
+	start := time.Now()
+	err := c.handle(ctx, &event)
+	c.recorder.ObserveHandlerDuration(c.topic, time.Since(start))
+
+	if err != nil {
 		c.logger.Error("failed to handle event",
 			zap.String("topic", c.topic),
 			zap.Error(err),
@@ -102,12 +157,48 @@ func (c *Consumer[T]) handleMessage(ctx context.Context, msg *message.Message) {
 	)
 }
 
+// handle runs the handler, giving up once the ack timeout elapses. On timeout
+// the handler's context is cancelled so it stops before the message is
+// redelivered; its result is ignored.
+func (c *Consumer[T]) handle(ctx context.Context, event *T) error {
+	if c.ackTimeout <= 0 {
+		return c.handler(ctx, event)
+	}
+
+	handlerCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	timer := time.NewTimer(c.ackTimeout)
+	defer timer.Stop()
+
+	result := make(chan error, 1)
+
+	go func() {
+		result <- c.handler(handlerCtx, event)
+	}()
+
+	select {
+	case err := <-result:
+		return err
+	case <-timer.C:
+		cancel()
+
+		return fmt.Errorf("handler did not finish within %s: %w", c.ackTimeout, context.DeadlineExceeded)
+	case <-ctx.Done():
+		cancel()
+
+		return ctx.Err()
+	}
+}
+
 // Shutdown stops the consumer and waits for in-flight messages to complete.
+// It is a no-op if the consumer was never started successfully.
 func (c *Consumer[T]) Shutdown() error {
-	if c.cancel != nil {
-		c.cancel()
+	if c.cancel == nil {
+		return nil
 	}
 
+	c.cancel()
 	<-c.done
 
 	return nil
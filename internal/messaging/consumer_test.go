@@ -187,6 +187,157 @@ func TestConsumer_HandleMessage(t *testing.T) {
 	})
 }
 
+func TestConsumer_AckTimeout(t *testing.T) {
+	t.Run("nacks when the handler outlives the timeout", func(t *testing.T) {
+		sub := newMockSubscriber()
+		release := make(chan struct{})
+		defer close(release)
+
+		consumer := messaging.NewConsumer(
+			sub,
+			"test.topic",
+			func(_ context.Context, _ *testEvent) error {
+				<-release
+
+				return nil
+			},
+			zap.NewNop(),
+			messaging.WithAckTimeout(20*time.Millisecond),
+		)
+
+		require.NoError(t, consumer.Start(context.Background()))
+
+		payload, _ := json.Marshal(&testEvent{ID: "123"})
+		msg := message.NewMessage(uuid.NewString(), payload)
+
+		sub.msgChan <- msg
+
+		select {
+		case <-msg.Nacked():
+			// Success
+		case <-msg.Acked():
+			t.Fatal("message should have been nacked")
+		case <-time.After(time.Second):
+			t.Fatal("timeout waiting for nack")
+		}
+
+		_ = consumer.Shutdown()
+	})
+
+	t.Run("cancels the handler context on timeout", func(t *testing.T) {
+		sub := newMockSubscriber()
+		handlerDone := make(chan error, 1)
+
+		consumer := messaging.NewConsumer(
+			sub,
+			"test.topic",
+			func(ctx context.Context, _ *testEvent) error {
+				<-ctx.Done()
+				handlerDone <- ctx.Err()
+
+				return ctx.Err()
+			},
+			zap.NewNop(),
+			messaging.WithAckTimeout(20*time.Millisecond),
+		)
+
+		require.NoError(t, consumer.Start(context.Background()))
+
+		payload, _ := json.Marshal(&testEvent{ID: "123"})
+		msg := message.NewMessage(uuid.NewString(), payload)
+
+		sub.msgChan <- msg
+
+		select {
+		case err := <-handlerDone:
+			require.ErrorIs(t, err, context.Canceled)
+		case <-time.After(time.Second):
+			t.Fatal("handler context was not cancelled after the ack timeout")
+		}
+
+		select {
+		case <-msg.Nacked():
+		case <-time.After(time.Second):
+			t.Fatal("timeout waiting for nack")
+		}
+
+		_ = consumer.Shutdown()
+	})
+
+	t.Run("acks when the handler finishes in time", func(t *testing.T) {
+		sub := newMockSubscriber()
+		consumer := messaging.NewConsumer(
+			sub,
+			"test.topic",
+			func(_ context.Context, _ *testEvent) error { return nil },
+			zap.NewNop(),
+			messaging.WithAckTimeout(time.Second),
+		)
+
+		require.NoError(t, consumer.Start(context.Background()))
+
+		payload, _ := json.Marshal(&testEvent{ID: "123"})
+		msg := message.NewMessage(uuid.NewString(), payload)
+
+		sub.msgChan <- msg
+
+		select {
+		case <-msg.Acked():
+			// Success
+		case <-msg.Nacked():
+			t.Fatal("message should have been acked")
+		case <-time.After(time.Second):
+			t.Fatal("timeout waiting for ack")
+		}
+
+		_ = consumer.Shutdown()
+	})
+}
+
+type handlerObservation struct {
+	topic    string
+	duration time.Duration
+}
+
+type recordingHandlerRecorder struct {
+	observed chan handlerObservation
+}
+
+func (r *recordingHandlerRecorder) ObserveHandlerDuration(topic string, duration time.Duration) {
+	r.observed <- handlerObservation{topic: topic, duration: duration}
+}
+
+func TestConsumer_HandlerRecorder(t *testing.T) {
+	sub := newMockSubscriber()
+	recorder := &recordingHandlerRecorder{observed: make(chan handlerObservation, 1)}
+	consumer := messaging.NewConsumer(
+		sub,
+		"test.topic",
+		func(_ context.Context, _ *testEvent) error {
+			time.Sleep(5 * time.Millisecond)
+
+			return nil
+		},
+		zap.NewNop(),
+		messaging.WithHandlerRecorder(recorder),
+	)
+
+	require.NoError(t, consumer.Start(context.Background()))
+
+	payload, _ := json.Marshal(&testEvent{ID: "123"})
+	sub.msgChan <- message.NewMessage(uuid.NewString(), payload)
+
+	select {
+	case got := <-recorder.observed:
+		assert.Equal(t, "test.topic", got.topic)
+		assert.GreaterOrEqual(t, got.duration, 5*time.Millisecond)
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for handler observation")
+	}
+
+	_ = consumer.Shutdown()
+}
+
 func TestConsumer_Shutdown(t *testing.T) {
 	t.Run("shuts down gracefully", func(t *testing.T) {
 		sub := newMockSubscriber()
@@ -205,3 +356,159 @@ func TestConsumer_Shutdown(t *testing.T) {
 		require.NoError(t, err)
 	})
 }
+
+// settled reports whether msg was acked, nacked, or neither.
+func settled(msg *message.Message) (acked, nacked bool) {
+	select {
+	case <-msg.Acked():
+		acked = true
+	default:
+	}
+
+	select {
+	case <-msg.Nacked():
+		nacked = true
+	default:
+	}
+
+	return acked, nacked
+}
+
+func TestConsumer_AckOrdering(t *testing.T) {
+	t.Run("settles each message exactly once under rapid delivery", func(t *testing.T) {
+		const count = 50
+
+		sub := &mockSubscriber{msgChan: make(chan *message.Message, count)}
+		consumer := messaging.NewConsumer(
+			sub,
+			"test.topic",
+			func(_ context.Context, event *testEvent) error {
+				if event.Name == "fail" {
+					return errors.New("handler error")
+				}
+
+				return nil
+			},
+			zap.NewNop(),
+		)
+
+		require.NoError(t, consumer.Start(context.Background()))
+
+		msgs := make([]*message.Message, count)
+		for i := range msgs {
+			name := "ok"
+			if i%3 == 0 {
+				name = "fail"
+			}
+
+			payload, _ := json.Marshal(&testEvent{ID: uuid.NewString(), Name: name})
+			msgs[i] = message.NewMessage(uuid.NewString(), payload)
+			sub.msgChan <- msgs[i]
+		}
+
+		for i, msg := range msgs {
+			select {
+			case <-msg.Acked():
+			case <-msg.Nacked():
+			case <-time.After(time.Second):
+				t.Fatalf("message %d was never settled", i)
+			}
+
+			acked, nacked := settled(msg)
+			assert.NotEqual(t, acked, nacked, "message %d must be either acked or nacked", i)
+			assert.Equal(t, i%3 == 0, nacked, "message %d settled with the wrong outcome", i)
+		}
+
+		require.NoError(t, consumer.Shutdown())
+	})
+
+	t.Run("shutdown does not ack messages that were not processed", func(t *testing.T) {
+		sub := newMockSubscriber()
+		started := make(chan context.Context, 1)
+		release := make(chan struct{})
+
+		var processed sync.Map
+
+		consumer := messaging.NewConsumer(
+			sub,
+			"test.topic",
+			func(ctx context.Context, event *testEvent) error {
+				processed.Store(event.ID, true)
+
+				if event.Name == "block" {
+					started <- ctx
+					<-release
+				}
+
+				return nil
+			},
+			zap.NewNop(),
+		)
+
+		require.NoError(t, consumer.Start(context.Background()))
+
+		newMsg := func(id, name string) *message.Message {
+			payload, _ := json.Marshal(&testEvent{ID: id, Name: name})
+
+			return message.NewMessage(uuid.NewString(), payload)
+		}
+
+		inFlight := newMsg("in-flight", "block")
+		sub.msgChan <- inFlight
+
+		handlerCtx := <-started
+
+		shutdownErr := make(chan error, 1)
+
+		go func() { shutdownErr <- consumer.Shutdown() }()
+
+		// Queue more messages only once shutdown has cancelled the consumer.
+		<-handlerCtx.Done()
+
+		var queued []*message.Message
+
+		for i := range 5 {
+			msg := newMsg("queued-"+string(rune('a'+i)), "")
+			queued = append(queued, msg)
+			sub.msgChan <- msg
+		}
+
+		close(release)
+		require.NoError(t, <-shutdownErr)
+
+		acked, nacked := settled(inFlight)
+		assert.True(t, acked, "the in-flight message finished and should be acked")
+		assert.False(t, nacked)
+
+		for i, msg := range queued {
+			acked, _ := settled(msg)
+			assert.False(t, acked, "queued message %d must not be acked after shutdown", i)
+
+			_, wasProcessed := processed.Load("queued-" + string(rune('a'+i)))
+			assert.False(t, wasProcessed, "queued message %d must not be processed after shutdown", i)
+		}
+	})
+
+	t.Run("shutdown without a successful start returns immediately", func(t *testing.T) {
+		sub := &mockSubscriber{subscribeErr: errors.New("subscribe error")}
+		consumer := messaging.NewConsumer(
+			sub,
+			"test.topic",
+			func(_ context.Context, _ *testEvent) error { return nil },
+			zap.NewNop(),
+		)
+
+		require.Error(t, consumer.Start(context.Background()))
+
+		done := make(chan error, 1)
+
+		go func() { done <- consumer.Shutdown() }()
+
+		select {
+		case err := <-done:
+			require.NoError(t, err)
+		case <-time.After(time.Second):
+			t.Fatal("shutdown blocked after a failed start")
+		}
+	})
+}
@@ -0,0 +1,58 @@
+package messaging
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+)
+
+// FanOutPublisher is a message.Publisher that publishes every message to all
+// of its publishers, e.g. to mirror events to a second broker.
+type FanOutPublisher struct {
+	publishers []message.Publisher
+}
+
+// NewFanOutPublisher creates a publisher that fans out to publishers.
+func NewFanOutPublisher(publishers ...message.Publisher) *FanOutPublisher {
+	return &FanOutPublisher{publishers: publishers}
+}
+
+// Publish publishes copies of msgs to every publisher concurrently, so a failing
+// or slow publisher does not keep the others from receiving them. Copies keep
+// the message ID, letting consumers of either target recognize duplicates. The
+// errors of all failed publishers are joined.
+func (f *FanOutPublisher) Publish(topic string, msgs ...*message.Message) error {
+	errs := make([]error, len(f.publishers))
+
+	var wg sync.WaitGroup
+
+	for i, publisher := range f.publishers {
+		copies := make([]*message.Message, len(msgs))
+		for j, msg := range msgs {
+			copies[j] = msg.Copy()
+		}
+
+		wg.Go(func() {
+			if err := publisher.Publish(topic, copies...); err != nil {
+				errs[i] = fmt.Errorf("publisher %d: %w", i, err)
+			}
+		})
+	}
+
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// Close closes every publisher and joins their errors.
+func (f *FanOutPublisher) Close() error {
+	errs := make([]error, len(f.publishers))
+
+	for i, publisher := range f.publishers {
+		errs[i] = publisher.Close()
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,49 @@
+package messaging_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/serroba/web-demo-go/internal/messaging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFanOutPublisher(t *testing.T) {
+	t.Run("publishes every message to every publisher", func(t *testing.T) {
+		first, second := &mockPublisher{}, &mockPublisher{}
+		fanOut := messaging.NewFanOutPublisher(first, second)
+
+		require.NoError(t, fanOut.Publish("test.topic",
+			message.NewMessage("1", []byte(`{"id":"1"}`)),
+			message.NewMessage("2", []byte(`{"id":"2"}`)),
+		))
+
+		for _, publisher := range []*mockPublisher{first, second} {
+			assert.Equal(t, "test.topic", publisher.topic)
+			require.Len(t, publisher.messages, 2)
+			assert.Equal(t, "1", publisher.messages[0].UUID)
+			assert.JSONEq(t, `{"id":"2"}`, string(publisher.messages[1].Payload))
+		}
+	})
+
+	t.Run("reports a failing publisher without blocking the others", func(t *testing.T) {
+		publishErr := errors.New("broker down")
+		failing, healthy := &mockPublisher{publishErr: publishErr}, &mockPublisher{}
+		fanOut := messaging.NewFanOutPublisher(failing, healthy)
+
+		err := fanOut.Publish("test.topic", message.NewMessage("1", []byte(`{}`)))
+
+		require.ErrorIs(t, err, publishErr)
+		require.Len(t, healthy.messages, 1)
+		assert.Equal(t, "1", healthy.messages[0].UUID)
+	})
+
+	t.Run("joins close errors", func(t *testing.T) {
+		closeErr := errors.New("close failed")
+		fanOut := messaging.NewFanOutPublisher(&mockPublisher{closeErr: closeErr}, &mockPublisher{})
+
+		require.ErrorIs(t, fanOut.Close(), closeErr)
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/serroba/web-demo-go/internal/logging"
 	"go.uber.org/zap"
 )
 
@@ -14,19 +15,38 @@ type Runnable interface {
 	Shutdown() error
 }
 
+// ConsumerGroupOption configures optional ConsumerGroup behavior.
+type ConsumerGroupOption func(*ConsumerGroup)
+
+// WithRecorder sets the recorder notified when consumers start and stop.
+func WithRecorder(recorder Recorder) ConsumerGroupOption {
+	return func(g *ConsumerGroup) {
+		g.recorder = recorder
+	}
+}
+
 // ConsumerGroup manages multiple consumers with unified lifecycle.
 type ConsumerGroup struct {
 	consumers  []Runnable
 	subscriber message.Subscriber
 	logger     *zap.Logger
+	recorder   Recorder
+	running    int
 }
 
 // NewConsumerGroup creates a new consumer group.
-func NewConsumerGroup(subscriber message.Subscriber, logger *zap.Logger) *ConsumerGroup {
-	return &ConsumerGroup{
+func NewConsumerGroup(subscriber message.Subscriber, logger *zap.Logger, opts ...ConsumerGroupOption) *ConsumerGroup {
+	g := &ConsumerGroup{
 		subscriber: subscriber,
 		logger:     logger,
+		recorder:   NopRecorder{},
 	}
+
+	for _, opt := range opts {
+		opt(g)
+	}
+
+	return g
 }
 
 // Add registers a consumer to the group.
@@ -41,10 +61,16 @@ func (g *ConsumerGroup) Start(ctx context.Context) error {
 			// Shutdown already started consumers on failure
 			for j := i - 1; j >= 0; j-- {
 				_ = g.consumers[j].Shutdown()
+				g.recorder.ConsumerStopped()
 			}
 
+			g.running = 0
+
 			return fmt.Errorf("failed to start consumer %d: %w", i, err)
 		}
+
+		g.running++
+		g.recorder.ConsumerStarted()
 	}
 
 	g.logger.Info("consumer group started", zap.Int("count", len(g.consumers)))
@@ -56,6 +82,10 @@ func (g *ConsumerGroup) Start(ctx context.Context) error {
 func (g *ConsumerGroup) Shutdown() error {
 	g.logger.Info("shutting down consumer group")
 
+	return logging.TimeShutdown(g.logger, "consumer group", g.shutdown)
+}
+
+func (g *ConsumerGroup) shutdown() error {
 	var firstErr error
 
 	for _, consumer := range g.consumers {
@@ -64,6 +94,10 @@ func (g *ConsumerGroup) Shutdown() error {
 		}
 	}
 
+	for ; g.running > 0; g.running-- {
+		g.recorder.ConsumerStopped()
+	}
+
 	if err := g.subscriber.Close(); err != nil && firstErr == nil {
 		firstErr = err
 	}
@@ -69,6 +69,41 @@ func TestConsumerGroup_Start(t *testing.T) {
 	})
 }
 
+type countingRecorder struct {
+	active int
+}
+
+func (r *countingRecorder) ConsumerStarted() { r.active++ }
+
+func (r *countingRecorder) ConsumerStopped() { r.active-- }
+
+func TestConsumerGroup_Recorder(t *testing.T) {
+	t.Run("tracks started and stopped consumers", func(t *testing.T) {
+		recorder := &countingRecorder{}
+		group := messaging.NewConsumerGroup(newMockSubscriber(), zap.NewNop(), messaging.WithRecorder(recorder))
+
+		group.Add(&mockRunnable{})
+		group.Add(&mockRunnable{})
+
+		require.NoError(t, group.Start(context.Background()))
+		assert.Equal(t, 2, recorder.active)
+
+		require.NoError(t, group.Shutdown())
+		assert.Equal(t, 0, recorder.active)
+	})
+
+	t.Run("releases rolled back consumers", func(t *testing.T) {
+		recorder := &countingRecorder{}
+		group := messaging.NewConsumerGroup(newMockSubscriber(), zap.NewNop(), messaging.WithRecorder(recorder))
+
+		group.Add(&mockRunnable{})
+		group.Add(&mockRunnable{startErr: errors.New("start error")})
+
+		require.Error(t, group.Start(context.Background()))
+		assert.Equal(t, 0, recorder.active)
+	})
+}
+
 func TestConsumerGroup_Shutdown(t *testing.T) {
 	t.Run("shuts down all consumers", func(t *testing.T) {
 		sub := newMockSubscriber()
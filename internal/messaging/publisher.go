@@ -2,6 +2,8 @@ package messaging
 
 import (
 	"encoding/json"
+	"fmt"
+	"time"
 
 	"github.com/ThreeDotsLabs/watermill"
 	"github.com/ThreeDotsLabs/watermill/message"
@@ -10,17 +12,74 @@ import (
 // Publish is a function that publishes a typed event.
 type Publish[T any] func(event *T) error
 
+// PublishOption configures optional publish function behavior.
+type PublishOption func(*publishConfig)
+
+type publishConfig struct {
+	attempts int
+	backoff  time.Duration
+
+	// cloudEventsSource enables the CloudEvents envelope when not empty.
+	cloudEventsSource string
+}
+
+// WithRetry retries a failed publish up to attempts times in total, sleeping
+// backoff before the first retry and doubling it after each one. The retries run
+// on the caller's goroutine, so the worst case adds about backoff*2^(attempts-1)
+// to the request. Attempts below 2 disable retrying.
+func WithRetry(attempts int, backoff time.Duration) PublishOption {
+	return func(c *publishConfig) {
+		c.attempts = attempts
+		c.backoff = backoff
+	}
+}
+
 // NewPublishFunc creates a typed publish function for a specific topic.
-func NewPublishFunc[T any](publisher message.Publisher, topic string) Publish[T] {
+func NewPublishFunc[T any](publisher message.Publisher, topic string, opts ...PublishOption) Publish[T] {
+	cfg := publishConfig{attempts: 1}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
 	return func(event *T) error {
 		payload, err := json.Marshal(event)
 		if err != nil {
 			return err
 		}
 
-		msg := message.NewMessage(watermill.NewUUID(), payload)
+		// Retries reuse the message ID so consumers can recognize duplicates.
+		id := watermill.NewUUID()
+		backoff := cfg.backoff
+
+		if cfg.cloudEventsSource != "" {
+			if payload, err = newCloudEvent(id, topic, cfg.cloudEventsSource, payload); err != nil {
+				return err
+			}
+		}
+
+		for attempt := 1; ; attempt++ {
+			msg := message.NewMessage(id, payload)
+			if cfg.cloudEventsSource != "" {
+				msg.Metadata.Set("content-type", CloudEventsContentType)
+			}
+
+			if err = publisher.Publish(topic, msg); err == nil {
+				return nil
+			}
+
+			if attempt >= cfg.attempts {
+				break
+			}
+
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+
+		if cfg.attempts > 1 {
+			return fmt.Errorf("publish to %s failed after %d attempts: %w", topic, cfg.attempts, err)
+		}
 
-		return publisher.Publish(topic, msg)
+		return err
 	}
 }
 
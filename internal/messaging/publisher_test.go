@@ -3,6 +3,7 @@ package messaging_test
 import (
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/ThreeDotsLabs/watermill/message"
 	"github.com/serroba/web-demo-go/internal/messaging"
@@ -90,3 +91,63 @@ func TestPublisherGroup(t *testing.T) {
 		assert.Error(t, err)
 	})
 }
+
+// flakyPublisher fails the first failures calls and records every attempt.
+type flakyPublisher struct {
+	failures int
+	attempts int
+	ids      []string
+}
+
+func (f *flakyPublisher) Publish(_ string, msgs ...*message.Message) error {
+	f.attempts++
+	f.ids = append(f.ids, msgs[0].UUID)
+
+	if f.attempts <= f.failures {
+		return errors.New("redis: connection reset")
+	}
+
+	return nil
+}
+
+func (f *flakyPublisher) Close() error {
+	return nil
+}
+
+func TestNewPublishFunc_WithRetry(t *testing.T) {
+	const attempts = 3
+
+	t.Run("succeeds after transient failures", func(t *testing.T) {
+		flaky := &flakyPublisher{failures: attempts - 1}
+		publish := messaging.NewPublishFunc[publishTestEvent](flaky, "test.topic",
+			messaging.WithRetry(attempts, time.Millisecond))
+
+		err := publish(&publishTestEvent{ID: "123"})
+
+		require.NoError(t, err)
+		assert.Equal(t, attempts, flaky.attempts)
+		assert.Equal(t, flaky.ids[0], flaky.ids[attempts-1], "retries reuse the message id")
+	})
+
+	t.Run("gives up after the configured attempts", func(t *testing.T) {
+		flaky := &flakyPublisher{failures: attempts}
+		publish := messaging.NewPublishFunc[publishTestEvent](flaky, "test.topic",
+			messaging.WithRetry(attempts, time.Millisecond))
+
+		err := publish(&publishTestEvent{ID: "123"})
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "after 3 attempts")
+		assert.Equal(t, attempts, flaky.attempts)
+	})
+
+	t.Run("publishes once without retry", func(t *testing.T) {
+		flaky := &flakyPublisher{failures: 1}
+		publish := messaging.NewPublishFunc[publishTestEvent](flaky, "test.topic")
+
+		err := publish(&publishTestEvent{ID: "123"})
+
+		require.Error(t, err)
+		assert.Equal(t, 1, flaky.attempts)
+	})
+}
@@ -0,0 +1,29 @@
+package messaging
+
+import "time"
+
+// Recorder records consumer lifecycle events for observability.
+type Recorder interface {
+	ConsumerStarted()
+	ConsumerStopped()
+}
+
+// NopRecorder is a Recorder that discards all events.
+type NopRecorder struct{}
+
+// ConsumerStarted implements Recorder.
+func (NopRecorder) ConsumerStarted() {}
+
+// ConsumerStopped implements Recorder.
+func (NopRecorder) ConsumerStopped() {}
+
+// HandlerRecorder records how long consumer handlers take to process an event.
+type HandlerRecorder interface {
+	ObserveHandlerDuration(topic string, duration time.Duration)
+}
+
+// NopHandlerRecorder is a HandlerRecorder that discards all observations.
+type NopHandlerRecorder struct{}
+
+// ObserveHandlerDuration implements HandlerRecorder.
+func (NopHandlerRecorder) ObserveHandlerDuration(string, time.Duration) {}
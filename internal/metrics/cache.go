@@ -0,0 +1,33 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/serroba/web-demo-go/internal/store"
+)
+
+// CacheRecorder counts failed Redis cache writes.
+type CacheRecorder struct {
+	writeErrors prometheus.Counter
+}
+
+// NewCacheRecorder creates a recorder and registers its counter with reg.
+func NewCacheRecorder(reg prometheus.Registerer) *CacheRecorder {
+	writeErrors := prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "cache",
+		Name:      "write_errors_total",
+		Help:      "Redis cache writes that failed and were discarded.",
+	})
+
+	reg.MustRegister(writeErrors)
+
+	return &CacheRecorder{writeErrors: writeErrors}
+}
+
+// RecordCacheWriteError implements store.CacheRecorder.
+func (r *CacheRecorder) RecordCacheWriteError() {
+	r.writeErrors.Inc()
+}
+
+// Compile-time check.
+var _ store.CacheRecorder = (*CacheRecorder)(nil)
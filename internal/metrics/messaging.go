@@ -0,0 +1,107 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/serroba/web-demo-go/internal/messaging"
+)
+
+// ConsumerRecorder tracks running messaging consumers as a Prometheus gauge.
+type ConsumerRecorder struct {
+	active prometheus.Gauge
+}
+
+// NewConsumerRecorder creates a recorder and registers its gauge with reg.
+func NewConsumerRecorder(reg prometheus.Registerer) *ConsumerRecorder {
+	active := prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: "messaging",
+		Name:      "active_consumers",
+		Help:      "Number of running messaging consumers.",
+	})
+
+	reg.MustRegister(active)
+
+	return &ConsumerRecorder{active: active}
+}
+
+// ConsumerStarted implements messaging.Recorder.
+func (r *ConsumerRecorder) ConsumerStarted() {
+	r.active.Inc()
+}
+
+// ConsumerStopped implements messaging.Recorder.
+func (r *ConsumerRecorder) ConsumerStopped() {
+	r.active.Dec()
+}
+
+// HandlerRecorder records consumer handler latency as a Prometheus histogram.
+type HandlerRecorder struct {
+	duration *prometheus.HistogramVec
+}
+
+// NewHandlerRecorder creates a recorder and registers its histogram with reg.
+func NewHandlerRecorder(reg prometheus.Registerer) *HandlerRecorder {
+	duration := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Subsystem: "messaging",
+		Name:      "handler_duration_seconds",
+		Help:      "Time consumer handlers take to process an event.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"topic"})
+
+	reg.MustRegister(duration)
+
+	return &HandlerRecorder{duration: duration}
+}
+
+// ObserveHandlerDuration implements messaging.HandlerRecorder.
+func (r *HandlerRecorder) ObserveHandlerDuration(topic string, duration time.Duration) {
+	r.duration.WithLabelValues(topic).Observe(duration.Seconds())
+}
+
+// PublishBufferRecorder exports the async publish buffer occupancy as a gauge
+// and the events it drops as a counter.
+type PublishBufferRecorder struct {
+	occupancy prometheus.Gauge
+	dropped   prometheus.Counter
+}
+
+// NewPublishBufferRecorder creates a recorder and registers its metrics with reg.
+func NewPublishBufferRecorder(reg prometheus.Registerer) *PublishBufferRecorder {
+	occupancy := prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: "messaging",
+		Name:      "publish_buffer_events",
+		Help:      "Events waiting in or being published from the async publish buffer.",
+	})
+
+	dropped := prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "messaging",
+		Name:      "publish_dropped_total",
+		Help:      "Events dropped because the async publish buffer was full or closed.",
+	})
+
+	reg.MustRegister(occupancy, dropped)
+
+	return &PublishBufferRecorder{occupancy: occupancy, dropped: dropped}
+}
+
+// PublishBufferOccupancy implements messaging.BufferRecorder.
+func (r *PublishBufferRecorder) PublishBufferOccupancy(events int) {
+	r.occupancy.Set(float64(events))
+}
+
+// PublishDropped implements messaging.BufferRecorder.
+func (r *PublishBufferRecorder) PublishDropped() {
+	r.dropped.Inc()
+}
+
+// Compile-time checks.
+var (
+	_ messaging.Recorder        = (*ConsumerRecorder)(nil)
+	_ messaging.HandlerRecorder = (*HandlerRecorder)(nil)
+	_ messaging.BufferRecorder  = (*PublishBufferRecorder)(nil)
+)
@@ -0,0 +1,103 @@
+package metrics_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/ThreeDotsLabs/watermill/pubsub/gochannel"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/serroba/web-demo-go/internal/messaging"
+	"github.com/serroba/web-demo-go/internal/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type idleRunnable struct{}
+
+func (idleRunnable) Start(_ context.Context) error { return nil }
+
+func (idleRunnable) Shutdown() error { return nil }
+
+func activeConsumers(t *testing.T, reg *prometheus.Registry) float64 {
+	t.Helper()
+
+	families, err := reg.Gather()
+	require.NoError(t, err)
+	require.Len(t, families, 1)
+	assert.Equal(t, "shortener_messaging_active_consumers", families[0].GetName())
+
+	return families[0].GetMetric()[0].GetGauge().GetValue()
+}
+
+func TestConsumerRecorder(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	sub := gochannel.NewGoChannel(gochannel.Config{}, watermill.NopLogger{})
+	group := messaging.NewConsumerGroup(sub, zap.NewNop(),
+		messaging.WithRecorder(metrics.NewConsumerRecorder(reg)))
+
+	group.Add(idleRunnable{})
+	group.Add(idleRunnable{})
+	group.Add(idleRunnable{})
+
+	assert.InDelta(t, 0, activeConsumers(t, reg), 0)
+
+	require.NoError(t, group.Start(context.Background()))
+	assert.InDelta(t, 3, activeConsumers(t, reg), 0)
+
+	require.NoError(t, group.Shutdown())
+	assert.InDelta(t, 0, activeConsumers(t, reg), 0)
+}
+
+func TestHandlerRecorder(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	recorder := metrics.NewHandlerRecorder(reg)
+
+	recorder.ObserveHandlerDuration("url.created", 10*time.Millisecond)
+	recorder.ObserveHandlerDuration("url.created", 30*time.Millisecond)
+	recorder.ObserveHandlerDuration("url.accessed", time.Millisecond)
+
+	families, err := reg.Gather()
+	require.NoError(t, err)
+	require.Len(t, families, 1)
+	assert.Equal(t, "shortener_messaging_handler_duration_seconds", families[0].GetName())
+
+	counts := map[string]uint64{}
+
+	for _, m := range families[0].GetMetric() {
+		require.Len(t, m.GetLabel(), 1)
+		counts[m.GetLabel()[0].GetValue()] = m.GetHistogram().GetSampleCount()
+	}
+
+	assert.Equal(t, map[string]uint64{"url.created": 2, "url.accessed": 1}, counts)
+}
+
+func TestPublishBufferRecorder(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	recorder := metrics.NewPublishBufferRecorder(reg)
+
+	recorder.PublishBufferOccupancy(3)
+	recorder.PublishDropped()
+	recorder.PublishDropped()
+
+	families, err := reg.Gather()
+	require.NoError(t, err)
+
+	values := map[string]float64{}
+
+	for _, family := range families {
+		m := family.GetMetric()[0]
+		if family.GetName() == "shortener_messaging_publish_buffer_events" {
+			values[family.GetName()] = m.GetGauge().GetValue()
+		} else {
+			values[family.GetName()] = m.GetCounter().GetValue()
+		}
+	}
+
+	assert.Equal(t, map[string]float64{
+		"shortener_messaging_publish_buffer_events": 3,
+		"shortener_messaging_publish_dropped_total": 2,
+	}, values)
+}
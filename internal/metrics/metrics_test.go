@@ -0,0 +1,25 @@
+package metrics_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/serroba/web-demo-go/internal/metrics"
+	"github.com/serroba/web-demo-go/internal/ratelimit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler(t *testing.T) {
+	reg := metrics.NewRegistry()
+	metrics.NewRateLimitRecorder(reg).RecordDecision(ratelimit.ScopeWrite, ratelimit.DecisionDenied)
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	w := httptest.NewRecorder()
+
+	metrics.Handler(reg).ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `shortener_ratelimit_decisions_total{decision="denied",scope="write"} 1`)
+	assert.Contains(t, w.Body.String(), "go_goroutines")
+}
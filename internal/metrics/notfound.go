@@ -0,0 +1,137 @@
+package metrics
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/serroba/web-demo-go/internal/clock"
+	"github.com/serroba/web-demo-go/internal/store"
+	"go.uber.org/zap"
+)
+
+// defaultMinLookups is how many code lookups a window needs before its
+// not-found ratio is trusted enough to warn about.
+const defaultMinLookups = 20
+
+// NotFoundRateOption configures optional NotFoundRate behavior.
+type NotFoundRateOption func(*NotFoundRate)
+
+// WithNotFoundRateClock sets the clock used to close windows.
+func WithNotFoundRateClock(c clock.Clock) NotFoundRateOption {
+	return func(n *NotFoundRate) {
+		n.clock = c
+	}
+}
+
+// WithMinLookups sets how many lookups a window needs before a ratio above the
+// threshold is logged, so a single miss on an idle instance does not warn.
+func WithMinLookups(minLookups int) NotFoundRateOption {
+	return func(n *NotFoundRate) {
+		n.minLookups = minLookups
+	}
+}
+
+// NotFoundRate tracks the share of code lookups that find nothing, per fixed
+// window, and warns when it exceeds a threshold. A sudden rise usually means
+// someone is enumerating codes. It implements store.Recorder and only counts
+// get_by_code calls.
+type NotFoundRate struct {
+	window     time.Duration
+	threshold  float64
+	minLookups int
+	ratio      prometheus.Gauge
+	logger     *zap.Logger
+	clock      clock.Clock
+
+	mu          sync.Mutex
+	windowStart time.Time
+	lookups     int
+	misses      int
+}
+
+// NewNotFoundRate creates a tracker that closes a window every window and
+// registers its gauge with reg.
+func NewNotFoundRate(
+	reg prometheus.Registerer,
+	window time.Duration,
+	threshold float64,
+	logger *zap.Logger,
+	opts ...NotFoundRateOption,
+) *NotFoundRate {
+	ratio := prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: "store",
+		Name:      "lookup_not_found_ratio",
+		Help:      "Share of code lookups that found no short URL in the last completed window.",
+	})
+
+	reg.MustRegister(ratio)
+
+	n := &NotFoundRate{
+		window:     window,
+		threshold:  threshold,
+		minLookups: defaultMinLookups,
+		ratio:      ratio,
+		logger:     logger,
+		clock:      clock.Real{},
+	}
+
+	for _, opt := range opts {
+		opt(n)
+	}
+
+	n.windowStart = n.clock.Now()
+
+	return n
+}
+
+// ObserveOperation implements store.Recorder.
+func (n *NotFoundRate) ObserveOperation(operation string, outcome store.Outcome, _ time.Duration) {
+	if operation != "get_by_code" {
+		return
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if now := n.clock.Now(); now.Sub(n.windowStart) >= n.window {
+		n.closeWindow()
+		n.windowStart = now
+	}
+
+	n.lookups++
+
+	if outcome == store.OutcomeMiss {
+		n.misses++
+	}
+}
+
+// closeWindow publishes the ratio of the finished window and resets the counts.
+// The caller must hold mu.
+func (n *NotFoundRate) closeWindow() {
+	if n.lookups == 0 {
+		n.ratio.Set(0)
+
+		return
+	}
+
+	ratio := float64(n.misses) / float64(n.lookups)
+	n.ratio.Set(ratio)
+
+	if ratio > n.threshold && n.lookups >= n.minLookups {
+		n.logger.Warn("high not-found rate for code lookups",
+			zap.Float64("ratio", ratio),
+			zap.Float64("threshold", n.threshold),
+			zap.Int("lookups", n.lookups),
+			zap.Int("not_found", n.misses),
+			zap.Duration("window", n.window),
+		)
+	}
+
+	n.lookups = 0
+	n.misses = 0
+}
+
+// Compile-time check.
+var _ store.Recorder = (*NotFoundRate)(nil)
@@ -0,0 +1,143 @@
+package metrics_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/serroba/web-demo-go/internal/clock"
+	"github.com/serroba/web-demo-go/internal/metrics"
+	"github.com/serroba/web-demo-go/internal/shortener"
+	"github.com/serroba/web-demo-go/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func notFoundRatio(t *testing.T, reg *prometheus.Registry) float64 {
+	t.Helper()
+
+	families, err := reg.Gather()
+	require.NoError(t, err)
+
+	for _, family := range families {
+		if family.GetName() == "shortener_store_lookup_not_found_ratio" {
+			return family.GetMetric()[0].GetGauge().GetValue()
+		}
+	}
+
+	t.Fatal("no not-found ratio gauge")
+
+	return 0
+}
+
+// lookup drives found and missing codes through an instrumented memory store.
+func lookup(t *testing.T, repo shortener.Repository, found, missing int) {
+	t.Helper()
+
+	ctx := context.Background()
+
+	for range found {
+		_, err := repo.GetByCode(ctx, "abc123")
+		require.NoError(t, err)
+	}
+
+	for range missing {
+		_, err := repo.GetByCode(ctx, "missing")
+		require.ErrorIs(t, err, shortener.ErrNotFound)
+	}
+}
+
+func TestNotFoundRate(t *testing.T) {
+	setup := func(t *testing.T) (*prometheus.Registry, shortener.Repository, *clock.Fake, *observer.ObservedLogs) {
+		t.Helper()
+
+		reg := prometheus.NewRegistry()
+		fake := clock.NewFake(time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC))
+		core, logs := observer.New(zap.WarnLevel)
+
+		memory := store.NewMemoryStore()
+		require.NoError(t, memory.Save(context.Background(),
+			&shortener.ShortURL{Code: "abc123", OriginalURL: "https://example.com"}))
+
+		rate := metrics.NewNotFoundRate(reg, time.Minute, 0.5, zap.New(core),
+			metrics.WithNotFoundRateClock(fake), metrics.WithMinLookups(10))
+
+		return reg, store.NewInstrumentedRepository(memory, rate), fake, logs
+	}
+
+	t.Run("publishes the ratio of the finished window", func(t *testing.T) {
+		reg, repo, fake, logs := setup(t)
+
+		lookup(t, repo, 15, 5)
+		assert.InDelta(t, 0, notFoundRatio(t, reg), 0.0001, "window still open")
+
+		fake.Advance(time.Minute)
+		lookup(t, repo, 1, 0)
+
+		assert.InDelta(t, 0.25, notFoundRatio(t, reg), 0.0001)
+		assert.Equal(t, 0, logs.Len())
+	})
+
+	t.Run("warns when the ratio exceeds the threshold", func(t *testing.T) {
+		reg, repo, fake, logs := setup(t)
+
+		lookup(t, repo, 2, 18)
+		fake.Advance(time.Minute)
+		lookup(t, repo, 1, 0)
+
+		assert.InDelta(t, 0.9, notFoundRatio(t, reg), 0.0001)
+		require.Equal(t, 1, logs.Len())
+
+		entry := logs.All()[0]
+		assert.Equal(t, "high not-found rate for code lookups", entry.Message)
+		assert.InDelta(t, 0.9, entry.ContextMap()["ratio"], 0.0001)
+		assert.Equal(t, int64(20), entry.ContextMap()["lookups"])
+	})
+
+	t.Run("does not warn below the minimum lookups", func(t *testing.T) {
+		reg, repo, fake, logs := setup(t)
+
+		lookup(t, repo, 0, 3)
+		fake.Advance(time.Minute)
+		lookup(t, repo, 1, 0)
+
+		assert.InDelta(t, 1, notFoundRatio(t, reg), 0.0001)
+		assert.Equal(t, 0, logs.Len())
+	})
+
+	t.Run("counts only code lookups", func(t *testing.T) {
+		reg := prometheus.NewRegistry()
+		fake := clock.NewFake(time.Now())
+		core, logs := observer.New(zap.WarnLevel)
+		rate := metrics.NewNotFoundRate(reg, time.Minute, 0.5, zap.New(core),
+			metrics.WithNotFoundRateClock(fake), metrics.WithMinLookups(1))
+
+		for range 5 {
+			rate.ObserveOperation("get_by_hash", store.OutcomeMiss, 0)
+		}
+
+		rate.ObserveOperation("get_by_code", store.OutcomeHit, 0)
+		fake.Advance(time.Minute)
+		rate.ObserveOperation("get_by_code", store.OutcomeHit, 0)
+
+		assert.InDelta(t, 0, notFoundRatio(t, reg), 0.0001)
+		assert.Equal(t, 0, logs.Len())
+	})
+
+	t.Run("errors count as lookups but not as misses", func(t *testing.T) {
+		reg := prometheus.NewRegistry()
+		fake := clock.NewFake(time.Now())
+		rate := metrics.NewNotFoundRate(reg, time.Minute, 0.5, zap.NewNop(),
+			metrics.WithNotFoundRateClock(fake))
+
+		rate.ObserveOperation("get_by_code", store.OutcomeError, 0)
+		rate.ObserveOperation("get_by_code", store.OutcomeMiss, 0)
+		fake.Advance(time.Minute)
+		rate.ObserveOperation("get_by_code", store.OutcomeHit, 0)
+
+		assert.InDelta(t, 0.5, notFoundRatio(t, reg), 0.0001)
+	})
+}
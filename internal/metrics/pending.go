@@ -0,0 +1,130 @@
+package metrics
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// PendingReader reads the pending-entries summary of a stream consumer group.
+// It is satisfied by *redis.Client.
+type PendingReader interface {
+	XPending(ctx context.Context, stream, group string) *redis.XPendingCmd
+}
+
+// PendingMonitor periodically exports the number of delivered but unacknowledged
+// messages of a consumer group per stream, from Redis XPENDING. A growing value
+// means consumers are falling behind or failing to ack.
+type PendingMonitor struct {
+	client   PendingReader
+	group    string
+	streams  []string
+	interval time.Duration
+	pending  *prometheus.GaugeVec
+	logger   *zap.Logger
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+// NewPendingMonitor creates a monitor that polls every interval and registers
+// its gauge with reg.
+func NewPendingMonitor(
+	reg prometheus.Registerer,
+	client PendingReader,
+	group string,
+	streams []string,
+	interval time.Duration,
+	logger *zap.Logger,
+) *PendingMonitor {
+	pending := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: "messaging",
+		Name:      "pending_messages",
+		Help:      "Messages delivered to the consumer group but not yet acknowledged, by stream.",
+	}, []string{"stream", "group"})
+
+	reg.MustRegister(pending)
+
+	return &PendingMonitor{
+		client:   client,
+		group:    group,
+		streams:  streams,
+		interval: interval,
+		pending:  pending,
+		logger:   logger,
+		done:     make(chan struct{}),
+	}
+}
+
+// Update reads the pending count of every stream and sets the gauge. Streams
+// or groups that do not exist yet report 0.
+func (m *PendingMonitor) Update(ctx context.Context) {
+	for _, stream := range m.streams {
+		count, err := m.pendingCount(ctx, stream)
+		if err != nil {
+			m.logger.Warn("failed to read pending messages",
+				zap.String("stream", stream),
+				zap.String("group", m.group),
+				zap.Error(err),
+			)
+
+			continue
+		}
+
+		m.pending.WithLabelValues(stream, m.group).Set(float64(count))
+	}
+}
+
+func (m *PendingMonitor) pendingCount(ctx context.Context, stream string) (int64, error) {
+	summary, err := m.client.XPending(ctx, stream, m.group).Result()
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "NOGROUP") {
+			return 0, nil
+		}
+
+		return 0, err
+	}
+
+	return summary.Count, nil
+}
+
+// Start updates the gauge once immediately and then on every interval until shut down.
+func (m *PendingMonitor) Start(ctx context.Context) error {
+	ctx, m.cancel = context.WithCancel(ctx)
+
+	go m.loop(ctx)
+
+	return nil
+}
+
+func (m *PendingMonitor) loop(ctx context.Context) {
+	defer close(m.done)
+
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		m.Update(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Shutdown stops polling and waits for an in-flight update to finish.
+func (m *PendingMonitor) Shutdown() error {
+	if m.cancel != nil {
+		m.cancel()
+	}
+
+	<-m.done
+
+	return nil
+}
@@ -0,0 +1,120 @@
+package metrics_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/redis/go-redis/v9"
+	"github.com/serroba/web-demo-go/internal/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakePending reports a fixed XPENDING result per stream.
+type fakePending struct {
+	counts map[string]int64
+	errs   map[string]error
+}
+
+func (f *fakePending) XPending(ctx context.Context, stream, _ string) *redis.XPendingCmd {
+	cmd := redis.NewXPendingCmd(ctx)
+
+	if err, ok := f.errs[stream]; ok {
+		cmd.SetErr(err)
+
+		return cmd
+	}
+
+	cmd.SetVal(&redis.XPending{Count: f.counts[stream]})
+
+	return cmd
+}
+
+func pendingGauge(t *testing.T, reg *prometheus.Registry, stream string) float64 {
+	t.Helper()
+
+	families, err := reg.Gather()
+	require.NoError(t, err)
+
+	for _, family := range families {
+		if family.GetName() != "shortener_messaging_pending_messages" {
+			continue
+		}
+
+		for _, m := range family.GetMetric() {
+			for _, label := range m.GetLabel() {
+				if label.GetName() == "stream" && label.GetValue() == stream {
+					return m.GetGauge().GetValue()
+				}
+			}
+		}
+	}
+
+	t.Fatalf("no pending gauge for stream %s", stream)
+
+	return 0
+}
+
+func TestPendingMonitor_Update(t *testing.T) {
+	t.Run("sets the gauge from the pending count", func(t *testing.T) {
+		reg := prometheus.NewRegistry()
+		client := &fakePending{counts: map[string]int64{"url.created": 3, "url.accessed": 42}}
+		monitor := metrics.NewPendingMonitor(reg, client, "analytics",
+			[]string{"url.created", "url.accessed"}, time.Minute, zap.NewNop())
+
+		monitor.Update(context.Background())
+
+		assert.InDelta(t, 3, pendingGauge(t, reg, "url.created"), 0)
+		assert.InDelta(t, 42, pendingGauge(t, reg, "url.accessed"), 0)
+
+		client.counts["url.accessed"] = 0
+		monitor.Update(context.Background())
+
+		assert.InDelta(t, 0, pendingGauge(t, reg, "url.accessed"), 0)
+	})
+
+	t.Run("reports zero for a missing group", func(t *testing.T) {
+		reg := prometheus.NewRegistry()
+		client := &fakePending{errs: map[string]error{
+			"url.created": errors.New("NOGROUP No such key 'url.created' or consumer group 'analytics'"),
+		}}
+		monitor := metrics.NewPendingMonitor(reg, client, "analytics", []string{"url.created"}, time.Minute, zap.NewNop())
+
+		monitor.Update(context.Background())
+
+		assert.InDelta(t, 0, pendingGauge(t, reg, "url.created"), 0)
+	})
+
+	t.Run("keeps the last value when reading fails", func(t *testing.T) {
+		reg := prometheus.NewRegistry()
+		client := &fakePending{counts: map[string]int64{"url.created": 5}}
+		monitor := metrics.NewPendingMonitor(reg, client, "analytics", []string{"url.created"}, time.Minute, zap.NewNop())
+
+		monitor.Update(context.Background())
+
+		client.errs = map[string]error{"url.created": errors.New("connection refused")}
+		monitor.Update(context.Background())
+
+		assert.InDelta(t, 5, pendingGauge(t, reg, "url.created"), 0)
+	})
+}
+
+func TestPendingMonitor_StartAndShutdown(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	client := &fakePending{counts: map[string]int64{"url.created": 7}}
+	monitor := metrics.NewPendingMonitor(reg, client, "analytics", []string{"url.created"}, time.Hour, zap.NewNop())
+
+	require.NoError(t, monitor.Start(context.Background()))
+
+	assert.Eventually(t, func() bool {
+		return testutil.CollectAndCount(reg, "shortener_messaging_pending_messages") == 1
+	}, time.Second, 5*time.Millisecond)
+
+	require.NoError(t, monitor.Shutdown())
+	assert.InDelta(t, 7, pendingGauge(t, reg, "url.created"), 0)
+}
@@ -0,0 +1,33 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/serroba/web-demo-go/internal/ratelimit"
+)
+
+// RateLimitRecorder records rate limit decisions as Prometheus counters.
+type RateLimitRecorder struct {
+	decisions *prometheus.CounterVec
+}
+
+// NewRateLimitRecorder creates a recorder and registers its counters with reg.
+func NewRateLimitRecorder(reg prometheus.Registerer) *RateLimitRecorder {
+	decisions := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "ratelimit",
+		Name:      "decisions_total",
+		Help:      "Rate limit decisions by scope and decision.",
+	}, []string{"scope", "decision"})
+
+	reg.MustRegister(decisions)
+
+	return &RateLimitRecorder{decisions: decisions}
+}
+
+// RecordDecision implements ratelimit.Recorder.
+func (r *RateLimitRecorder) RecordDecision(scope ratelimit.Scope, decision ratelimit.Decision) {
+	r.decisions.WithLabelValues(string(scope), string(decision)).Inc()
+}
+
+// Compile-time check.
+var _ ratelimit.Recorder = (*RateLimitRecorder)(nil)
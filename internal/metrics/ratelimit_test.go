@@ -0,0 +1,39 @@
+package metrics_test
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/serroba/web-demo-go/internal/metrics"
+	"github.com/serroba/web-demo-go/internal/ratelimit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimitRecorder(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	recorder := metrics.NewRateLimitRecorder(reg)
+
+	recorder.RecordDecision(ratelimit.ScopeWrite, ratelimit.DecisionDenied)
+	recorder.RecordDecision(ratelimit.ScopeWrite, ratelimit.DecisionDenied)
+	recorder.RecordDecision(ratelimit.ScopeRead, ratelimit.DecisionAllowed)
+
+	families, err := reg.Gather()
+	require.NoError(t, err)
+	require.Len(t, families, 1)
+	assert.Equal(t, "shortener_ratelimit_decisions_total", families[0].GetName())
+
+	values := make(map[string]float64)
+
+	for _, m := range families[0].GetMetric() {
+		labels := make(map[string]string)
+		for _, l := range m.GetLabel() {
+			labels[l.GetName()] = l.GetValue()
+		}
+
+		values[labels["scope"]+"/"+labels["decision"]] = m.GetCounter().GetValue()
+	}
+
+	assert.InDelta(t, 2, values["write/denied"], 0)
+	assert.InDelta(t, 1, values["read/allowed"], 0)
+}
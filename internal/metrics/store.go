@@ -0,0 +1,36 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/serroba/web-demo-go/internal/store"
+)
+
+// RepositoryRecorder records repository operation latency as a Prometheus histogram.
+type RepositoryRecorder struct {
+	duration *prometheus.HistogramVec
+}
+
+// NewRepositoryRecorder creates a recorder and registers its histogram with reg.
+func NewRepositoryRecorder(reg prometheus.Registerer) *RepositoryRecorder {
+	duration := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Subsystem: "store",
+		Name:      "operation_duration_seconds",
+		Help:      "Repository operation latency by operation and outcome.",
+		Buckets:   prometheus.ExponentialBuckets(0.0005, 2, 14),
+	}, []string{"operation", "outcome"})
+
+	reg.MustRegister(duration)
+
+	return &RepositoryRecorder{duration: duration}
+}
+
+// ObserveOperation implements store.Recorder.
+func (r *RepositoryRecorder) ObserveOperation(operation string, outcome store.Outcome, duration time.Duration) {
+	r.duration.WithLabelValues(operation, string(outcome)).Observe(duration.Seconds())
+}
+
+// Compile-time check.
+var _ store.Recorder = (*RepositoryRecorder)(nil)
@@ -0,0 +1,40 @@
+package metrics_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/serroba/web-demo-go/internal/metrics"
+	"github.com/serroba/web-demo-go/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepositoryRecorder(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	recorder := metrics.NewRepositoryRecorder(reg)
+
+	recorder.ObserveOperation("get_by_code", store.OutcomeHit, 2*time.Millisecond)
+	recorder.ObserveOperation("get_by_code", store.OutcomeHit, 4*time.Millisecond)
+	recorder.ObserveOperation("get_by_code", store.OutcomeMiss, time.Millisecond)
+
+	families, err := reg.Gather()
+	require.NoError(t, err)
+	require.Len(t, families, 1)
+	assert.Equal(t, "shortener_store_operation_duration_seconds", families[0].GetName())
+
+	counts := make(map[string]uint64)
+
+	for _, m := range families[0].GetMetric() {
+		labels := make(map[string]string)
+		for _, l := range m.GetLabel() {
+			labels[l.GetName()] = l.GetValue()
+		}
+
+		counts[labels["operation"]+"/"+labels["outcome"]] = m.GetHistogram().GetSampleCount()
+	}
+
+	assert.Equal(t, uint64(2), counts["get_by_code/hit"])
+	assert.Equal(t, uint64(1), counts["get_by_code/miss"])
+}
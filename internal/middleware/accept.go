@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// NotAcceptable is a middleware that rejects requests whose Accept header
+// matches none of the given media types with a 406 listing the supported ones.
+// Without it Huma silently falls back to the default format. Requests without
+// an Accept header, or accepting */*, always pass.
+func NotAcceptable(api huma.API, mediaTypes []string) func(ctx huma.Context, next func(huma.Context)) {
+	supported := strings.Join(mediaTypes, ", ")
+
+	return func(ctx huma.Context, next func(huma.Context)) {
+		accept := ctx.Header("Accept")
+		if accept == "" || accepts(accept, mediaTypes) {
+			next(ctx)
+
+			return
+		}
+
+		// Huma falls back to the default format to encode the error itself.
+		_ = huma.WriteErr(api, ctx, http.StatusNotAcceptable,
+			"unsupported Accept header; supported media types: "+supported)
+	}
+}
+
+// accepts reports whether any media range in the Accept header matches one of
+// the media types. Ranges with q=0 are explicitly refused and never match.
+func accepts(accept string, mediaTypes []string) bool {
+	for _, part := range strings.Split(accept, ",") {
+		mediaRange, params, _ := strings.Cut(part, ";")
+		mediaRange = strings.ToLower(strings.TrimSpace(mediaRange))
+
+		if refused(params) {
+			continue
+		}
+
+		for _, mediaType := range mediaTypes {
+			if matchesRange(mediaRange, mediaType) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func matchesRange(mediaRange, mediaType string) bool {
+	if mediaRange == "*/*" || mediaRange == mediaType {
+		return true
+	}
+
+	prefix, ok := strings.CutSuffix(mediaRange, "/*")
+
+	return ok && strings.HasPrefix(mediaType, prefix+"/")
+}
+
+func refused(params string) bool {
+	for _, param := range strings.Split(params, ";") {
+		if q, ok := strings.CutPrefix(strings.TrimSpace(param), "q="); ok {
+			return strings.Trim(q, "0.") == ""
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,75 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/adapters/humachi"
+	"github.com/go-chi/chi/v5"
+	"github.com/serroba/web-demo-go/internal/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupNotAcceptableAPI(t *testing.T) *chi.Mux {
+	t.Helper()
+
+	router := chi.NewMux()
+	api := humachi.New(router, huma.DefaultConfig("Test", "1.0.0"))
+	api.UseMiddleware(middleware.NotAcceptable(api, []string{"application/cbor", "application/json"}))
+
+	huma.Get(api, "/test", func(_ context.Context, _ *struct{}) (*testOutput, error) {
+		return &testOutput{Body: "ok"}, nil
+	})
+
+	return router
+}
+
+func TestNotAcceptable(t *testing.T) {
+	tests := []struct {
+		name   string
+		accept string
+		want   int
+	}{
+		{name: "no accept header passes", accept: "", want: http.StatusOK},
+		{name: "supported type passes", accept: "application/json", want: http.StatusOK},
+		{name: "supported type among others passes", accept: "application/xml, application/cbor;q=0.5", want: http.StatusOK},
+		{name: "wildcard passes", accept: "text/html, */*;q=0.8", want: http.StatusOK},
+		{name: "type wildcard passes", accept: "application/*", want: http.StatusOK},
+		{name: "unsupported type is rejected", accept: "application/xml", want: http.StatusNotAcceptable},
+		{name: "other type wildcard is rejected", accept: "text/*", want: http.StatusNotAcceptable},
+		{name: "refused supported type is rejected", accept: "application/xml, application/json;q=0", want: http.StatusNotAcceptable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := setupNotAcceptableAPI(t)
+
+			req := httptest.NewRequest(http.MethodGet, "/test", nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.want, w.Code)
+		})
+	}
+
+	t.Run("406 lists the supported media types", func(t *testing.T) {
+		router := setupNotAcceptableAPI(t)
+
+		req := httptest.NewRequest(http.MethodGet, "/test", nil)
+		req.Header.Set("Accept", "application/xml")
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotAcceptable, w.Code)
+		assert.Contains(t, w.Header().Get("Content-Type"), "json")
+		assert.Contains(t, w.Body.String(), "supported media types: application/cbor, application/json")
+	})
+}
@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/serroba/web-demo-go/internal/audit"
+	"github.com/serroba/web-demo-go/internal/handlers"
+)
+
+// AdminAuth is a middleware that requires "Authorization: Bearer <token>" on
+// operations marked with handlers.AdminMetadataKey. When no token is configured,
+// admin operations are rejected. Authenticated requests carry an audit actor
+// derived from the token.
+func AdminAuth(api huma.API, token string) func(ctx huma.Context, next func(huma.Context)) {
+	actor := audit.TokenActor(token)
+
+	return func(ctx huma.Context, next func(huma.Context)) {
+		if !isAdminOperation(ctx) {
+			next(ctx)
+
+			return
+		}
+
+		if token == "" {
+			_ = huma.WriteErr(api, ctx, http.StatusUnauthorized, "admin API is disabled")
+
+			return
+		}
+
+		given, ok := strings.CutPrefix(ctx.Header("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			_ = huma.WriteErr(api, ctx, http.StatusUnauthorized, "invalid admin token")
+
+			return
+		}
+
+		next(huma.WithContext(ctx, audit.ContextWithActor(ctx.Context(), actor)))
+	}
+}
+
+func isAdminOperation(ctx huma.Context) bool {
+	op := ctx.Operation()
+	if op == nil || op.Metadata == nil {
+		return false
+	}
+
+	admin, _ := op.Metadata[handlers.AdminMetadataKey].(bool)
+
+	return admin
+}
@@ -0,0 +1,98 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/adapters/humachi"
+	"github.com/go-chi/chi/v5"
+	"github.com/serroba/web-demo-go/internal/audit"
+	"github.com/serroba/web-demo-go/internal/handlers"
+	"github.com/serroba/web-demo-go/internal/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupAdminAuthAPI(t *testing.T, token string) *chi.Mux {
+	t.Helper()
+
+	router := chi.NewMux()
+	api := humachi.New(router, huma.DefaultConfig("Test", "1.0.0"))
+	api.UseMiddleware(middleware.AdminAuth(api, token))
+
+	huma.Get(api, "/test", func(_ context.Context, _ *struct{}) (*testOutput, error) {
+		return &testOutput{Body: "ok"}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		Method:   http.MethodGet,
+		Path:     "/admin",
+		Metadata: map[string]any{handlers.AdminMetadataKey: true},
+	}, func(_ context.Context, _ *struct{}) (*testOutput, error) {
+		return &testOutput{Body: "ok"}, nil
+	})
+
+	return router
+}
+
+func TestAdminAuth(t *testing.T) {
+	tests := []struct {
+		name   string
+		token  string
+		path   string
+		header string
+		want   int
+	}{
+		{name: "non-admin route needs no token", token: "secret", path: "/test", want: http.StatusOK},
+		{name: "valid token passes", token: "secret", path: "/admin", header: "Bearer secret", want: http.StatusOK},
+		{name: "missing token is rejected", token: "secret", path: "/admin", want: http.StatusUnauthorized},
+		{name: "wrong token is rejected", token: "secret", path: "/admin", header: "Bearer nope", want: http.StatusUnauthorized},
+		{name: "non-bearer scheme is rejected", token: "secret", path: "/admin", header: "secret", want: http.StatusUnauthorized},
+		{name: "unconfigured token rejects admin", token: "", path: "/admin", header: "Bearer ", want: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := setupAdminAuthAPI(t, tt.token)
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.want, rec.Code)
+		})
+	}
+}
+
+func TestAdminAuth_SetsAuditActor(t *testing.T) {
+	router := chi.NewMux()
+	api := humachi.New(router, huma.DefaultConfig("Test", "1.0.0"))
+	api.UseMiddleware(middleware.AdminAuth(api, "secret"))
+
+	var actor string
+
+	huma.Register(api, huma.Operation{
+		Method:   http.MethodGet,
+		Path:     "/admin",
+		Metadata: map[string]any{handlers.AdminMetadataKey: true},
+	}, func(ctx context.Context, _ *struct{}) (*testOutput, error) {
+		actor = audit.ActorFromContext(ctx)
+
+		return &testOutput{Body: "ok"}, nil
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, audit.TokenActor("secret"), actor)
+}
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// AllowedHosts is a middleware that rejects requests whose Host (or
+// X-Forwarded-Host, which is used to build short URLs) is not in the allowlist.
+// Entries match with or without a port. An empty list disables the check.
+func AllowedHosts(api huma.API, hosts []string) func(ctx huma.Context, next func(huma.Context)) {
+	allowed := make(map[string]bool, len(hosts))
+	for _, h := range hosts {
+		allowed[strings.ToLower(h)] = true
+	}
+
+	return func(ctx huma.Context, next func(huma.Context)) {
+		if len(allowed) == 0 {
+			next(ctx)
+
+			return
+		}
+
+		if !isAllowedHost(allowed, ctx.Host()) {
+			_ = huma.WriteErr(api, ctx, http.StatusBadRequest, "host not allowed")
+
+			return
+		}
+
+		if fwd := firstHeaderValue(ctx.Header("X-Forwarded-Host")); fwd != "" && !isAllowedHost(allowed, fwd) {
+			_ = huma.WriteErr(api, ctx, http.StatusBadRequest, "forwarded host not allowed")
+
+			return
+		}
+
+		next(ctx)
+	}
+}
+
+func isAllowedHost(allowed map[string]bool, host string) bool {
+	host = strings.ToLower(host)
+	if allowed[host] {
+		return true
+	}
+
+	if name, _, err := net.SplitHostPort(host); err == nil {
+		return allowed[name]
+	}
+
+	return false
+}
+
+// firstHeaderValue returns the first entry of a comma-separated header value.
+func firstHeaderValue(value string) string {
+	if idx := strings.Index(value, ","); idx != -1 {
+		value = value[:idx]
+	}
+
+	return strings.TrimSpace(value)
+}
@@ -0,0 +1,69 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/adapters/humachi"
+	"github.com/go-chi/chi/v5"
+	"github.com/serroba/web-demo-go/internal/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupAllowedHostsAPI(t *testing.T, hosts []string) *chi.Mux {
+	t.Helper()
+
+	router := chi.NewMux()
+	api := humachi.New(router, huma.DefaultConfig("Test", "1.0.0"))
+	api.UseMiddleware(middleware.AllowedHosts(api, hosts))
+
+	huma.Get(api, "/test", func(_ context.Context, _ *struct{}) (*testOutput, error) {
+		return &testOutput{Body: "ok"}, nil
+	})
+
+	return router
+}
+
+func TestAllowedHosts(t *testing.T) {
+	tests := []struct {
+		name          string
+		hosts         []string
+		host          string
+		forwardedHost string
+		want          int
+	}{
+		{name: "allowed host passes", hosts: []string{"sho.rt"}, host: "sho.rt", want: http.StatusOK},
+		{name: "allowed host with port passes", hosts: []string{"sho.rt"}, host: "sho.rt:8888", want: http.StatusOK},
+		{name: "host match is case-insensitive", hosts: []string{"sho.rt"}, host: "SHO.RT", want: http.StatusOK},
+		{name: "spoofed host is rejected", hosts: []string{"sho.rt"}, host: "evil.example", want: http.StatusBadRequest},
+		{
+			name:          "spoofed forwarded host is rejected",
+			hosts:         []string{"sho.rt"},
+			host:          "sho.rt",
+			forwardedHost: "evil.example",
+			want:          http.StatusBadRequest,
+		},
+		{name: "empty list allows any host", hosts: nil, host: "anything.example", want: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := setupAllowedHostsAPI(t, tt.hosts)
+
+			req := httptest.NewRequest(http.MethodGet, "/test", nil)
+			req.Host = tt.host
+
+			if tt.forwardedHost != "" {
+				req.Header.Set("X-Forwarded-Host", tt.forwardedHost)
+			}
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.want, w.Code)
+		})
+	}
+}
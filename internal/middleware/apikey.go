@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/serroba/web-demo-go/internal/ratelimit"
+	"github.com/serroba/web-demo-go/internal/shortener"
+)
+
+// APIKeyHeader carries the client API key.
+const APIKeyHeader = "X-API-Key"
+
+// ErrInvalidAPIKeys is returned by ParseAPIKeys for malformed key lists.
+var ErrInvalidAPIKeys = errors.New("invalid api keys")
+
+// APIKey is a client API key. Its name identifies the key in stored data, so
+// the secret can be rotated without losing the key's short URLs. Keys with a
+// tier are rate limited with that tier's limits.
+type APIKey struct {
+	Name   string
+	Secret string
+	Tier   ratelimit.Tier
+}
+
+// Creator returns the identity stored as CreatedBy for short URLs created
+// with the key.
+func (k APIKey) Creator() string {
+	return "key:" + k.Name
+}
+
+// ParseAPIKeys parses comma-separated API keys written as name:secret or
+// name:secret:tier. Names and secrets must be unique. An empty spec yields no
+// keys.
+func ParseAPIKeys(spec string) ([]APIKey, error) {
+	var keys []APIKey
+
+	names := map[string]bool{}
+	secrets := map[string]bool{}
+
+	for entry := range strings.SplitSeq(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		name, rest, ok := strings.Cut(entry, ":")
+		secret, tier, hasTier := strings.Cut(rest, ":")
+
+		if !ok || name == "" || secret == "" || (hasTier && tier == "") {
+			return nil, fmt.Errorf("%w: %q must be name:secret or name:secret:tier", ErrInvalidAPIKeys, name)
+		}
+
+		if names[name] || secrets[secret] {
+			return nil, fmt.Errorf("%w: key %q is not unique", ErrInvalidAPIKeys, name)
+		}
+
+		names[name] = true
+		secrets[secret] = true
+
+		keys = append(keys, APIKey{Name: name, Secret: secret, Tier: ratelimit.Tier(tier)})
+	}
+
+	return keys, nil
+}
+
+// APIKeyAuth is a middleware that authenticates the API key sent in the
+// X-API-Key header and records its creator identity and tier in the request
+// context. Requests without the header continue anonymously with the base
+// rate limits; unknown keys get 401.
+func APIKeyAuth(api huma.API, keys []APIKey) func(ctx huma.Context, next func(huma.Context)) {
+	return func(ctx huma.Context, next func(huma.Context)) {
+		given := ctx.Header(APIKeyHeader)
+		if given == "" {
+			next(ctx)
+
+			return
+		}
+
+		key, ok := matchAPIKey(keys, given)
+		if !ok {
+			_ = huma.WriteErr(api, ctx, http.StatusUnauthorized, "invalid API key")
+
+			return
+		}
+
+		keyCtx := shortener.WithCreator(ctx.Context(), key.Creator())
+		if key.Tier != "" {
+			keyCtx = ratelimit.WithTier(keyCtx, key.Tier)
+		}
+
+		next(huma.WithContext(ctx, keyCtx))
+	}
+}
+
+// matchAPIKey returns the key whose secret is given, comparing every secret
+// in constant time.
+func matchAPIKey(keys []APIKey, given string) (APIKey, bool) {
+	var (
+		match APIKey
+		found bool
+	)
+
+	for _, key := range keys {
+		if subtle.ConstantTimeCompare([]byte(given), []byte(key.Secret)) == 1 {
+			match, found = key, true
+		}
+	}
+
+	return match, found
+}
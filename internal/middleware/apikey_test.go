@@ -0,0 +1,99 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/adapters/humachi"
+	"github.com/go-chi/chi/v5"
+	"github.com/serroba/web-demo-go/internal/middleware"
+	"github.com/serroba/web-demo-go/internal/ratelimit"
+	"github.com/serroba/web-demo-go/internal/shortener"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAPIKeys(t *testing.T) {
+	t.Run("parses keys with optional tiers", func(t *testing.T) {
+		keys, err := middleware.ParseAPIKeys("team-a:s3cret, team-b:other:pro")
+
+		require.NoError(t, err)
+		assert.Equal(t, []middleware.APIKey{
+			{Name: "team-a", Secret: "s3cret"},
+			{Name: "team-b", Secret: "other", Tier: "pro"},
+		}, keys)
+	})
+
+	t.Run("empty spec yields no keys", func(t *testing.T) {
+		keys, err := middleware.ParseAPIKeys("")
+
+		require.NoError(t, err)
+		assert.Empty(t, keys)
+	})
+
+	for _, spec := range []string{"team-a", "team-a:", ":s3cret", "a:s3cret:", "a:same,b:same", "a:one,a:two"} {
+		t.Run("rejects "+spec, func(t *testing.T) {
+			_, err := middleware.ParseAPIKeys(spec)
+
+			assert.ErrorIs(t, err, middleware.ErrInvalidAPIKeys)
+		})
+	}
+}
+
+func TestAPIKeyAuth(t *testing.T) {
+	router := chi.NewMux()
+	api := humachi.New(router, huma.DefaultConfig("Test", "1.0.0"))
+	api.UseMiddleware(middleware.APIKeyAuth(api, []middleware.APIKey{
+		{Name: "team-a", Secret: "s3cret"},
+		{Name: "team-b", Secret: "other", Tier: "pro"},
+	}))
+
+	var (
+		creator string
+		tier    ratelimit.Tier
+	)
+
+	huma.Get(api, "/test", func(ctx context.Context, _ *struct{}) (*testOutput, error) {
+		creator = shortener.CreatorFromContext(ctx)
+		tier, _ = ratelimit.TierFromContext(ctx)
+
+		return &testOutput{Body: "ok"}, nil
+	})
+
+	tests := []struct {
+		name        string
+		key         string
+		wantStatus  int
+		wantCreator string
+		wantTier    ratelimit.Tier
+	}{
+		{name: "valid key sets the creator", key: "s3cret", wantStatus: http.StatusOK, wantCreator: "key:team-a"},
+		{
+			name: "key with a tier sets the tier", key: "other",
+			wantStatus: http.StatusOK, wantCreator: "key:team-b", wantTier: "pro",
+		},
+		{name: "missing key continues anonymously", key: "", wantStatus: http.StatusOK},
+		{name: "unknown key is rejected", key: "nope", wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			creator, tier = "", ""
+
+			req := httptest.NewRequest(http.MethodGet, "/test", nil)
+			if tt.key != "" {
+				req.Header.Set(middleware.APIKeyHeader, tt.key)
+			}
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			assert.Equal(t, tt.wantCreator, creator)
+			assert.Equal(t, tt.wantTier, tier)
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"net"
+	"strings"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// clientIP returns the IP of the client that sent the request, considering
+// proxies. See forwardedClientIP for how trustDepth selects the X-Forwarded-For
+// entry; a chain too short to trust falls back to the peer address. X-Real-IP
+// is only used with a trustDepth of zero, since trusted proxies are described
+// by the X-Forwarded-For chain alone and any client can send the header.
+func clientIP(ctx huma.Context, trustDepth int) string {
+	if xff := ctx.Header("X-Forwarded-For"); xff != "" {
+		if ip, ok := forwardedClientIP(xff, trustDepth); ok {
+			return ip
+		}
+
+		return remoteIP(ctx)
+	}
+
+	if trustDepth == 0 {
+		if xri := ctx.Header("X-Real-IP"); xri != "" {
+			return xri
+		}
+	}
+
+	return remoteIP(ctx)
+}
+
+// forwardedClientIP picks the client IP from an X-Forwarded-For chain. Each
+// proxy appends the address it received the request from, so behind
+// trustDepth trusted proxies the entry trustDepth hops from the right is the
+// address the outermost trusted proxy saw; everything left of it is supplied
+// by the client and can be forged. A trustDepth of zero keeps the leftmost
+// entry. Chains shorter than trustDepth did not pass through every trusted
+// proxy, so none of their entries can be trusted and ok is false.
+func forwardedClientIP(xff string, trustDepth int) (ip string, ok bool) {
+	hops := strings.Split(xff, ",")
+	if trustDepth > len(hops) {
+		return "", false
+	}
+
+	idx := 0
+	if trustDepth > 0 {
+		idx = len(hops) - trustDepth
+	}
+
+	return strings.TrimSpace(hops[idx]), true
+}
+
+// remoteIP returns the IP of the connection's peer address.
+func remoteIP(ctx huma.Context) string {
+	peer := ctx.RemoteAddr()
+	if ip, _, err := net.SplitHostPort(peer); err == nil {
+		return ip
+	}
+
+	return peer
+}
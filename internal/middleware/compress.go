@@ -0,0 +1,165 @@
+package middleware
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// incompressibleTypes are content type prefixes that are already compressed,
+// such as the QR code PNG, and gain nothing from another pass.
+var incompressibleTypes = []string{"image/", "audio/", "video/", "application/zip", "application/gzip"}
+
+// Compress is a router middleware that gzip- or deflate-encodes responses for
+// clients that accept it. Bodies are buffered until they reach minSize bytes, so
+// small responses are sent unencoded. It wraps the router rather than the Huma
+// API because it has to replace the response writer.
+func Compress(minSize int) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Accept-Encoding")
+
+			encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"))
+			if encoding == "" || r.Method == http.MethodHead {
+				next.ServeHTTP(w, r)
+
+				return
+			}
+
+			cw := &compressWriter{ResponseWriter: w, encoding: encoding, minSize: minSize, status: http.StatusOK}
+			defer cw.finish()
+
+			next.ServeHTTP(cw, r)
+		})
+	}
+}
+
+// negotiateEncoding picks gzip or deflate from an Accept-Encoding header, preferring gzip.
+// Codings listed with q=0 are refused; anything else is treated as acceptable.
+func negotiateEncoding(header string) string {
+	accepted := map[string]bool{}
+
+	for part := range strings.SplitSeq(header, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		accepted[strings.ToLower(strings.TrimSpace(coding))] = !refused(params)
+	}
+
+	for _, encoding := range []string{"gzip", "deflate"} {
+		if ok, listed := accepted[encoding]; ok || (!listed && accepted["*"]) {
+			return encoding
+		}
+	}
+
+	return ""
+}
+
+// compressWriter buffers the start of a response to decide whether to encode it.
+type compressWriter struct {
+	http.ResponseWriter
+
+	encoding string
+	minSize  int
+	status   int
+	buf      []byte
+	decided  bool
+	encoder  io.WriteCloser
+}
+
+// WriteHeader defers the status until the encoding decision is made.
+func (cw *compressWriter) WriteHeader(status int) {
+	if !cw.decided {
+		cw.status = status
+	}
+}
+
+func (cw *compressWriter) Write(p []byte) (int, error) {
+	if cw.decided {
+		if cw.encoder != nil {
+			return cw.encoder.Write(p)
+		}
+
+		return cw.ResponseWriter.Write(p)
+	}
+
+	cw.buf = append(cw.buf, p...)
+	if len(cw.buf) >= cw.minSize {
+		if err := cw.decide(); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(p), nil
+}
+
+// decide writes the deferred status and buffered bytes, starting an encoder when
+// the response is large enough and worth compressing.
+func (cw *compressWriter) decide() error {
+	cw.decided = true
+
+	if len(cw.buf) >= cw.minSize && cw.compressible() {
+		header := cw.Header()
+		header.Set("Content-Encoding", cw.encoding)
+		header.Del("Content-Length")
+
+		if cw.encoding == "gzip" {
+			cw.encoder = gzip.NewWriter(cw.ResponseWriter)
+		} else {
+			cw.encoder, _ = flate.NewWriter(cw.ResponseWriter, flate.DefaultCompression)
+		}
+	}
+
+	cw.ResponseWriter.WriteHeader(cw.status)
+
+	if len(cw.buf) == 0 {
+		return nil
+	}
+
+	var err error
+	if cw.encoder != nil {
+		_, err = cw.encoder.Write(cw.buf)
+	} else {
+		_, err = cw.ResponseWriter.Write(cw.buf)
+	}
+
+	cw.buf = nil
+
+	return err
+}
+
+func (cw *compressWriter) compressible() bool {
+	if cw.status < http.StatusOK || cw.status == http.StatusNoContent || cw.status == http.StatusNotModified {
+		return false
+	}
+
+	header := cw.Header()
+	if header.Get("Content-Encoding") != "" {
+		return false
+	}
+
+	contentType := header.Get("Content-Type")
+	for _, prefix := range incompressibleTypes {
+		if strings.HasPrefix(contentType, prefix) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// finish flushes a response that stayed below minSize and closes the encoder.
+func (cw *compressWriter) finish() {
+	if !cw.decided {
+		if len(cw.buf) > 0 {
+			cw.Header().Set("Content-Length", strconv.Itoa(len(cw.buf)))
+		}
+
+		_ = cw.decide()
+	}
+
+	if cw.encoder != nil {
+		_ = cw.encoder.Close()
+	}
+}
@@ -0,0 +1,135 @@
+package middleware_test
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/adapters/humachi"
+	"github.com/go-chi/chi/v5"
+	"github.com/serroba/web-demo-go/internal/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const compressMinSize = 1024
+
+type itemsOutput struct {
+	Body struct {
+		Items []string `json:"items"`
+	}
+}
+
+type imageOutput struct {
+	ContentType string `header:"Content-Type"`
+	Body        []byte
+}
+
+func setupCompressAPI(t *testing.T) *chi.Mux {
+	t.Helper()
+
+	router := chi.NewMux()
+	router.Use(middleware.Compress(compressMinSize))
+
+	api := humachi.New(router, huma.DefaultConfig("Test", "1.0.0"))
+
+	huma.Get(api, "/items", func(_ context.Context, input *struct {
+		Count int `query:"count"`
+	}) (*itemsOutput, error) {
+		out := &itemsOutput{}
+		for range input.Count {
+			out.Body.Items = append(out.Body.Items, "https://example.com/some/long/path")
+		}
+
+		return out, nil
+	})
+
+	huma.Get(api, "/image", func(_ context.Context, _ *struct{}) (*imageOutput, error) {
+		return &imageOutput{ContentType: "image/png", Body: make([]byte, 4*compressMinSize)}, nil
+	})
+
+	return router
+}
+
+func getWithEncoding(router http.Handler, target, acceptEncoding string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	if acceptEncoding != "" {
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	return w
+}
+
+func TestCompress(t *testing.T) {
+	router := setupCompressAPI(t)
+
+	t.Run("gzips large json responses", func(t *testing.T) {
+		w := getWithEncoding(router, "/items?count=200", "gzip, deflate")
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+		assert.Contains(t, w.Header().Values("Vary"), "Accept-Encoding")
+
+		reader, err := gzip.NewReader(w.Body)
+		require.NoError(t, err)
+
+		var body struct {
+			Items []string `json:"items"`
+		}
+		require.NoError(t, json.NewDecoder(reader).Decode(&body))
+		assert.Len(t, body.Items, 200)
+	})
+
+	t.Run("uses deflate when gzip is not accepted", func(t *testing.T) {
+		w := getWithEncoding(router, "/items?count=200", "gzip;q=0, deflate")
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "deflate", w.Header().Get("Content-Encoding"))
+
+		raw, err := io.ReadAll(flate.NewReader(w.Body))
+		require.NoError(t, err)
+		assert.Contains(t, string(raw), `"items"`)
+	})
+
+	t.Run("leaves small responses uncompressed", func(t *testing.T) {
+		w := getWithEncoding(router, "/items?count=1", "gzip")
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("Content-Encoding"))
+		assert.Less(t, w.Body.Len(), compressMinSize)
+		assert.Contains(t, w.Body.String(), "https://example.com/some/long/path")
+	})
+
+	t.Run("leaves responses uncompressed without accept-encoding", func(t *testing.T) {
+		w := getWithEncoding(router, "/items?count=200", "")
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("Content-Encoding"))
+		assert.True(t, strings.HasPrefix(w.Body.String(), "{"))
+	})
+
+	t.Run("does not recompress images", func(t *testing.T) {
+		w := getWithEncoding(router, "/image", "gzip")
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "image/png", w.Header().Get("Content-Type"))
+		assert.Empty(t, w.Header().Get("Content-Encoding"))
+		assert.Equal(t, 4*compressMinSize, w.Body.Len())
+	})
+
+	t.Run("keeps error statuses", func(t *testing.T) {
+		w := getWithEncoding(router, "/missing", "gzip")
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/serroba/web-demo-go/internal/handlers"
+	"github.com/serroba/web-demo-go/internal/ratelimit"
+)
+
+// GlobalThrottle is a middleware that shares bucket across every request to
+// operations marked with handlers.GlobalThrottleMetadataKey, regardless of
+// client. Once the bucket is empty it answers 503 with Retry-After, shielding
+// the store from a herd of requests such as a viral link missing the cache.
+func GlobalThrottle(api huma.API, bucket *ratelimit.TokenBucket) func(ctx huma.Context, next func(huma.Context)) {
+	return func(ctx huma.Context, next func(huma.Context)) {
+		if !isGloballyThrottled(ctx) {
+			next(ctx)
+
+			return
+		}
+
+		if ok, wait := bucket.Take(); !ok {
+			retryAfter := max(1, int(math.Ceil(wait.Seconds())))
+			ctx.SetHeader("Retry-After", strconv.Itoa(retryAfter))
+			_ = huma.WriteErr(api, ctx, http.StatusServiceUnavailable, "too many redirects in flight, retry later")
+
+			return
+		}
+
+		next(ctx)
+	}
+}
+
+func isGloballyThrottled(ctx huma.Context) bool {
+	op := ctx.Operation()
+	if op == nil || op.Metadata == nil {
+		return false
+	}
+
+	throttled, _ := op.Metadata[handlers.GlobalThrottleMetadataKey].(bool)
+
+	return throttled
+}
@@ -0,0 +1,83 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/adapters/humachi"
+	"github.com/go-chi/chi/v5"
+	"github.com/serroba/web-demo-go/internal/clock"
+	"github.com/serroba/web-demo-go/internal/handlers"
+	"github.com/serroba/web-demo-go/internal/middleware"
+	"github.com/serroba/web-demo-go/internal/ratelimit"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupGlobalThrottleAPI(t *testing.T, bucket *ratelimit.TokenBucket) *chi.Mux {
+	t.Helper()
+
+	router := chi.NewMux()
+	api := humachi.New(router, huma.DefaultConfig("Test", "1.0.0"))
+	api.UseMiddleware(middleware.GlobalThrottle(api, bucket))
+
+	ok := func(_ context.Context, _ *struct{}) (*testOutput, error) {
+		return &testOutput{Body: "ok"}, nil
+	}
+
+	huma.Register(api, huma.Operation{
+		Method:   http.MethodGet,
+		Path:     "/redirect",
+		Metadata: map[string]any{handlers.GlobalThrottleMetadataKey: true},
+	}, ok)
+	huma.Get(api, "/other", ok)
+
+	return router
+}
+
+func TestGlobalThrottle(t *testing.T) {
+	get := func(router http.Handler, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+
+		return w
+	}
+
+	t.Run("throttles bursts beyond the global rate", func(t *testing.T) {
+		c := clock.NewFake(time.Now())
+		router := setupGlobalThrottleAPI(t, ratelimit.NewTokenBucket(1, 3, ratelimit.WithBucketClock(c)))
+
+		for range 3 {
+			assert.Equal(t, http.StatusOK, get(router, "/redirect").Code)
+		}
+
+		w := get(router, "/redirect")
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Equal(t, "1", w.Header().Get("Retry-After"))
+	})
+
+	t.Run("lets traffic within the rate through", func(t *testing.T) {
+		c := clock.NewFake(time.Now())
+		router := setupGlobalThrottleAPI(t, ratelimit.NewTokenBucket(10, 1, ratelimit.WithBucketClock(c)))
+
+		for range 20 {
+			assert.Equal(t, http.StatusOK, get(router, "/redirect").Code)
+			c.Advance(100 * time.Millisecond)
+		}
+	})
+
+	t.Run("ignores unmarked operations", func(t *testing.T) {
+		c := clock.NewFake(time.Now())
+		router := setupGlobalThrottleAPI(t, ratelimit.NewTokenBucket(1, 1, ratelimit.WithBucketClock(c)))
+
+		assert.Equal(t, http.StatusOK, get(router, "/redirect").Code)
+		assert.Equal(t, http.StatusServiceUnavailable, get(router, "/redirect").Code)
+
+		for range 5 {
+			assert.Equal(t, http.StatusOK, get(router, "/other").Code)
+		}
+	})
+}
@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// loadShedRetryAfter is the Retry-After value, in seconds, sent with shed requests.
+const loadShedRetryAfter = "1"
+
+// MaxConcurrentRequests is a middleware that sheds load once limit requests are
+// in flight, answering 503 with Retry-After instead of queueing more work.
+// A limit of zero or less disables the check.
+func MaxConcurrentRequests(api huma.API, limit int) func(ctx huma.Context, next func(huma.Context)) {
+	if limit <= 0 {
+		return func(ctx huma.Context, next func(huma.Context)) {
+			next(ctx)
+		}
+	}
+
+	slots := make(chan struct{}, limit)
+
+	return func(ctx huma.Context, next func(huma.Context)) {
+		select {
+		case slots <- struct{}{}:
+		default:
+			ctx.SetHeader("Retry-After", loadShedRetryAfter)
+			_ = huma.WriteErr(api, ctx, http.StatusServiceUnavailable, "server is at capacity, retry later")
+
+			return
+		}
+
+		defer func() { <-slots }()
+
+		next(ctx)
+	}
+}
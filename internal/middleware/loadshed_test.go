@@ -0,0 +1,105 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/adapters/humachi"
+	"github.com/go-chi/chi/v5"
+	"github.com/serroba/web-demo-go/internal/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupLoadShedAPI(t *testing.T, limit int, started chan<- struct{}, release <-chan struct{}) *chi.Mux {
+	t.Helper()
+
+	router := chi.NewMux()
+	api := humachi.New(router, huma.DefaultConfig("Test", "1.0.0"))
+	api.UseMiddleware(middleware.MaxConcurrentRequests(api, limit))
+
+	huma.Get(api, "/test", func(_ context.Context, _ *struct{}) (*testOutput, error) {
+		started <- struct{}{}
+		<-release
+
+		return &testOutput{Body: "ok"}, nil
+	})
+
+	return router
+}
+
+func TestMaxConcurrentRequests(t *testing.T) {
+	t.Run("sheds requests beyond the limit", func(t *testing.T) {
+		const limit = 2
+
+		started := make(chan struct{}, limit)
+		release := make(chan struct{})
+		router := setupLoadShedAPI(t, limit, started, release)
+
+		var wg sync.WaitGroup
+
+		codes := make([]int, limit)
+
+		for i := range limit {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/test", nil))
+				codes[i] = w.Code
+			}()
+		}
+
+		// Wait until every slot is held by a blocked handler.
+		for range limit {
+			<-started
+		}
+
+		for range 3 {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/test", nil))
+
+			assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+			assert.Equal(t, "1", w.Header().Get("Retry-After"))
+		}
+
+		close(release)
+		wg.Wait()
+
+		assert.Equal(t, []int{http.StatusOK, http.StatusOK}, codes)
+	})
+
+	t.Run("frees slots when requests finish", func(t *testing.T) {
+		started := make(chan struct{}, 2)
+		release := make(chan struct{})
+		close(release)
+
+		router := setupLoadShedAPI(t, 1, started, release)
+
+		for range 2 {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/test", nil))
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			<-started
+		}
+	})
+
+	t.Run("zero limit disables shedding", func(t *testing.T) {
+		started := make(chan struct{}, 1)
+		release := make(chan struct{})
+		close(release)
+
+		router := setupLoadShedAPI(t, 0, started, release)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/test", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
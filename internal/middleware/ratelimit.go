@@ -1,15 +1,16 @@
 package middleware
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"errors"
 	"fmt"
-	"net"
 	"net/http"
 	"strings"
 
 	"github.com/danielgtaylor/huma/v2"
+	"github.com/serroba/web-demo-go/internal/logging"
 	"github.com/serroba/web-demo-go/internal/ratelimit"
 	"go.uber.org/zap"
 )
@@ -17,7 +18,7 @@ import (
 // RateLimiter returns a Huma middleware that limits requests based on client IP and User-Agent.
 func RateLimiter(api huma.API, limiter ratelimit.Limiter) func(ctx huma.Context, next func(huma.Context)) {
 	return func(ctx huma.Context, next func(huma.Context)) {
-		key := clientKey(ctx)
+		key := clientKey(ctx, 0)
 
 		allowed, err := limiter.Allow(ctx.Context(), key)
 		if err != nil {
@@ -37,8 +38,9 @@ func RateLimiter(api huma.API, limiter ratelimit.Limiter) func(ctx huma.Context,
 }
 
 // clientKey generates a unique key for rate limiting based on IP and User-Agent.
-func clientKey(ctx huma.Context) string {
-	ip := clientIP(ctx)
+// trustDepth is passed to clientIP.
+func clientKey(ctx huma.Context, trustDepth int) string {
+	ip := clientIP(ctx, trustDepth)
 	ua := ctx.Header("User-Agent")
 
 	hash := sha256.Sum256([]byte(ip + "|" + ua))
@@ -46,32 +48,89 @@ func clientKey(ctx huma.Context) string {
 	return hex.EncodeToString(hash[:])
 }
 
-// clientIP extracts the client IP from the request, considering proxies.
-func clientIP(ctx huma.Context) string {
-	// Check X-Forwarded-For header (may contain multiple IPs)
-	if xff := ctx.Header("X-Forwarded-For"); xff != "" {
-		// Take the first IP (original client)
-		if idx := strings.Index(xff, ","); idx != -1 {
-			return strings.TrimSpace(xff[:idx])
-		}
+// peerKey generates a rate limit key from the connection's peer address only.
+func peerKey(ctx huma.Context) string {
+	hash := sha256.Sum256([]byte("peer|" + remoteIP(ctx)))
+
+	return hex.EncodeToString(hash[:])
+}
 
-		return strings.TrimSpace(xff)
+// PolicyRateLimiterOption configures optional PolicyRateLimiter behavior.
+type PolicyRateLimiterOption func(*policyRateLimiter)
+
+// WithDecisionRecorder sets the recorder notified of every allow/deny decision.
+func WithDecisionRecorder(recorder ratelimit.Recorder) PolicyRateLimiterOption {
+	return func(p *policyRateLimiter) {
+		p.recorder = recorder
 	}
+}
 
-	// Check X-Real-IP header
-	if xri := ctx.Header("X-Real-IP"); xri != "" {
-		return xri
+// WithPeerKeyWithoutUserAgent keys requests without a User-Agent by the
+// connection's peer address instead of the forwarded client IP.
+func WithPeerKeyWithoutUserAgent() PolicyRateLimiterOption {
+	return func(p *policyRateLimiter) {
+		p.peerKeyWithoutUA = true
 	}
+}
 
-	// Fall back to Host (which contains remote addr in Huma context)
-	host := ctx.Host()
+// WithXFFTrustDepth keys requests by the X-Forwarded-For entry trustDepth hops
+// from the right, the address seen by the outermost of that many trusted
+// proxies, instead of the client-controlled leftmost entry.
+func WithXFFTrustDepth(trustDepth int) PolicyRateLimiterOption {
+	return func(p *policyRateLimiter) {
+		p.trustDepth = trustDepth
+	}
+}
 
-	ip, _, err := net.SplitHostPort(host)
-	if err != nil {
-		return host
+// WithBreachNotifier notifies notifier when tracker reports a client denied
+// more often than its threshold allows.
+func WithBreachNotifier(tracker *ratelimit.BreachTracker, notifier ratelimit.BreachNotifier) PolicyRateLimiterOption {
+	return func(p *policyRateLimiter) {
+		p.breaches = tracker
+		p.notifier = notifier
+	}
+}
+
+// logBreachNotifier logs breaches at error level so log-based alerting can
+// pick them up.
+type logBreachNotifier struct {
+	logger *zap.Logger
+}
+
+// NewLogBreachNotifier returns a BreachNotifier that logs each breach to logger.
+func NewLogBreachNotifier(logger *zap.Logger) ratelimit.BreachNotifier {
+	return logBreachNotifier{logger: logger}
+}
+
+// NotifyBreach implements ratelimit.BreachNotifier.
+func (n logBreachNotifier) NotifyBreach(ctx context.Context, breach ratelimit.Breach) {
+	logging.FromContext(ctx, n.logger).Error("client repeatedly rate limited",
+		zap.String("client_ip", breach.ClientIP),
+		zap.String("path", breach.Path),
+		zap.Int("threshold", breach.Threshold),
+		zap.Duration("window", breach.Window),
+	)
+}
+
+// WithMonitorOnly logs would-be-denied requests for every endpoint instead of rejecting them.
+func WithMonitorOnly() PolicyRateLimiterOption {
+	return func(p *policyRateLimiter) {
+		p.monitorOnly = true
 	}
+}
 
-	return ip
+// policyRateLimiter holds the dependencies shared by the policy middleware helpers.
+type policyRateLimiter struct {
+	api              huma.API
+	limiter          *ratelimit.PolicyLimiter
+	resolver         ratelimit.ScopeResolver
+	logger           *zap.Logger
+	recorder         ratelimit.Recorder
+	monitorOnly      bool
+	peerKeyWithoutUA bool
+	trustDepth       int
+	breaches         *ratelimit.BreachTracker
+	notifier         ratelimit.BreachNotifier
 }
 
 // PolicyRateLimiter returns a Huma middleware that applies policy-based rate limiting.
@@ -88,37 +147,112 @@ func PolicyRateLimiter(
 	limiter *ratelimit.PolicyLimiter,
 	resolver ratelimit.ScopeResolver,
 	logger *zap.Logger,
+	opts ...PolicyRateLimiterOption,
 ) func(ctx huma.Context, next func(huma.Context)) {
-	return func(ctx huma.Context, next func(huma.Context)) {
-		path := getOperationPath(ctx)
+	p := &policyRateLimiter{
+		api:      api,
+		limiter:  limiter,
+		resolver: resolver,
+		logger:   logger,
+		recorder: ratelimit.NopRecorder{},
+		notifier: ratelimit.NopBreachNotifier{},
+	}
 
-		// Check for per-endpoint configuration
-		if cfg := ratelimit.GetEndpointConfig(ctx); cfg != nil {
-			if handleEndpointConfig(api, ctx, limiter, cfg, path, logger, next) {
-				return
-			}
-		}
+	for _, opt := range opts {
+		opt(p)
+	}
 
-		// Default behavior: use policy-based rate limiting
-		key := clientKey(ctx)
-		scopes := resolver.Resolve(ctx)
+	return p.handle
+}
 
-		allowed, exceeded, err := limiter.Allow(ctx.Context(), key, scopes)
-		if err != nil {
-			logger.Error("rate limit check failed", zap.String("path", path), zap.Error(err))
-			_ = huma.WriteErr(api, ctx, http.StatusInternalServerError, "internal server error", err)
+func (p *policyRateLimiter) handle(ctx huma.Context, next func(huma.Context)) {
+	path := getOperationPath(ctx)
+
+	limitCtx := ctx.Context()
 
+	// Check for per-endpoint configuration
+	if cfg := ratelimit.GetEndpointConfig(ctx); cfg != nil {
+		if p.handleEndpointConfig(ctx, cfg, path, next) {
 			return
 		}
 
-		if !allowed {
-			handleRateLimitExceeded(api, ctx, exceeded, path, logger)
+		if operationID := keyOperation(ctx, cfg); operationID != "" {
+			limitCtx = ratelimit.WithOperation(limitCtx, operationID)
+		}
+	}
+
+	// Default behavior: use policy-based rate limiting
+	key := p.clientKey(ctx)
+	scopes := p.resolver.Resolve(ctx)
+
+	allowed, exceeded, err := p.limiter.Allow(limitCtx, key, scopes)
+	if err != nil {
+		p.log(ctx).Error("rate limit check failed", zap.String("path", path), zap.Error(err))
+		_ = huma.WriteErr(p.api, ctx, http.StatusInternalServerError, "internal server error", err)
+
+		return
+	}
+
+	if !allowed {
+		if !p.isMonitorOnly(ctx) {
+			p.handleRateLimitExceeded(ctx, exceeded, path)
 
 			return
 		}
 
-		next(ctx)
+		p.logMonitored(ctx, exceeded, path)
+	}
+
+	for _, scope := range scopes {
+		p.recorder.RecordDecision(scope, ratelimit.DecisionAllowed)
+	}
+
+	next(ctx)
+}
+
+// clientKey returns the rate limit key of the request.
+func (p *policyRateLimiter) clientKey(ctx huma.Context) string {
+	if p.peerKeyWithoutUA && strings.TrimSpace(ctx.Header("User-Agent")) == "" {
+		return peerKey(ctx)
+	}
+
+	return clientKey(ctx, p.trustDepth)
+}
+
+// log returns the request-scoped logger, falling back to the limiter's logger.
+func (p *policyRateLimiter) log(ctx huma.Context) *zap.Logger {
+	return logging.FromContext(ctx.Context(), p.logger)
+}
+
+// isMonitorOnly reports whether limits are only logged, globally or for this endpoint.
+func (p *policyRateLimiter) isMonitorOnly(ctx huma.Context) bool {
+	if p.monitorOnly {
+		return true
+	}
+
+	cfg := ratelimit.GetEndpointConfig(ctx)
+
+	return cfg != nil && cfg.MonitorOnly
+}
+
+// logMonitored logs a request that would have been rate limited outside monitor mode.
+func (p *policyRateLimiter) logMonitored(ctx huma.Context, exceeded *ratelimit.LimitExceeded, path string) {
+	fields := []zap.Field{
+		zap.String("path", path),
+		zap.String("method", ctx.Method()),
+		zap.String("client_ip", clientIP(ctx, p.trustDepth)),
 	}
+
+	if exceeded != nil {
+		fields = append(fields,
+			zap.String("scope", string(exceeded.Scope)),
+			zap.Int64("count", exceeded.Count),
+			zap.Int64("max", exceeded.Config.Max),
+			zap.Duration("window", exceeded.Config.Window),
+		)
+	}
+
+	p.log(ctx).Warn("rate limit would be exceeded (monitor only)", fields...)
 }
 
 // getOperationPath extracts the path from the operation, if available.
@@ -130,19 +264,30 @@ func getOperationPath(ctx huma.Context) string {
 	return ""
 }
 
+// keyOperation returns the OperationID limits are keyed by, or "" when the
+// endpoint keys by route template or its operation has no ID.
+func keyOperation(ctx huma.Context, cfg *ratelimit.EndpointConfig) string {
+	if !cfg.KeyByOperation {
+		return ""
+	}
+
+	if op := ctx.Operation(); op != nil {
+		return op.OperationID
+	}
+
+	return ""
+}
+
 // handleEndpointConfig processes per-endpoint rate limit configuration.
 // Returns true if the request was handled (should return early), false to continue.
-func handleEndpointConfig(
-	api huma.API,
+func (p *policyRateLimiter) handleEndpointConfig(
 	ctx huma.Context,
-	limiter *ratelimit.PolicyLimiter,
 	cfg *ratelimit.EndpointConfig,
 	path string,
-	logger *zap.Logger,
 	next func(huma.Context),
 ) bool {
 	if cfg.Disabled {
-		logger.Debug("rate limiting disabled for endpoint",
+		p.log(ctx).Debug("rate limiting disabled for endpoint",
 			zap.String("path", path), zap.String("method", ctx.Method()))
 		next(ctx)
 
@@ -150,7 +295,7 @@ func handleEndpointConfig(
 	}
 
 	if len(cfg.Limits) > 0 {
-		if !checkCustomLimits(api, ctx, limiter.Store(), cfg.Limits, logger) {
+		if !p.checkCustomLimits(ctx, cfg) {
 			return true
 		}
 
@@ -163,29 +308,52 @@ func handleEndpointConfig(
 }
 
 // handleRateLimitExceeded logs and responds to a rate limit exceeded condition.
-func handleRateLimitExceeded(
-	api huma.API,
+func (p *policyRateLimiter) handleRateLimitExceeded(
 	ctx huma.Context,
 	exceeded *ratelimit.LimitExceeded,
 	path string,
-	logger *zap.Logger,
 ) {
 	msg := "rate limit exceeded"
 	if exceeded != nil {
+		p.recorder.RecordDecision(exceeded.Scope, ratelimit.DecisionDenied)
+
 		msg = fmt.Sprintf("rate limit exceeded: %s scope, %d/%d requests in %s",
 			exceeded.Scope, exceeded.Count, exceeded.Config.Max, exceeded.Config.Window)
-		logger.Warn("rate limit exceeded",
+		p.log(ctx).Warn("rate limit exceeded",
 			zap.String("path", path),
 			zap.String("method", ctx.Method()),
 			zap.String("scope", string(exceeded.Scope)),
 			zap.Int64("count", exceeded.Count),
 			zap.Int64("max", exceeded.Config.Max),
 			zap.Duration("window", exceeded.Config.Window),
-			zap.String("client_ip", clientIP(ctx)),
+			zap.String("client_ip", clientIP(ctx, p.trustDepth)),
 		)
 	}
 
-	_ = huma.WriteErr(api, ctx, http.StatusTooManyRequests, msg)
+	p.recordBreach(ctx, path)
+
+	_ = huma.WriteErr(p.api, ctx, http.StatusTooManyRequests, msg)
+}
+
+// recordBreach counts a denied request and notifies the breach notifier when
+// the client crosses the breach threshold.
+func (p *policyRateLimiter) recordBreach(ctx huma.Context, path string) {
+	if p.breaches == nil {
+		return
+	}
+
+	key := p.clientKey(ctx)
+	if !p.breaches.Record(key) {
+		return
+	}
+
+	p.notifier.NotifyBreach(ctx.Context(), ratelimit.Breach{
+		ClientKey: key,
+		ClientIP:  clientIP(ctx, p.trustDepth),
+		Path:      path,
+		Threshold: p.breaches.Threshold(),
+		Window:    p.breaches.Window(),
+	})
 }
 
 // checkCustomLimits applies custom rate limits defined in endpoint config.
@@ -194,58 +362,77 @@ func handleRateLimitExceeded(
 // Note: The rate limit key uses the operation's route template (e.g., "/{code}"),
 // not the actual request path. This means all requests matching the same route
 // pattern share rate limit counters per client, regardless of specific path values.
-func checkCustomLimits(
-	api huma.API,
-	ctx huma.Context,
-	store ratelimit.Store,
-	limits []ratelimit.LimitConfig,
-	logger *zap.Logger,
-) bool {
-	clientK := clientKey(ctx)
+// With cfg.KeyByOperation the key uses the OperationID instead.
+func (p *policyRateLimiter) checkCustomLimits(ctx huma.Context, cfg *ratelimit.EndpointConfig) bool {
+	clientK := p.clientKey(ctx)
 
 	op := ctx.Operation()
 	if op == nil {
-		logger.Error("missing operation in context for rate limiting")
+		p.log(ctx).Error("missing operation in context for rate limiting")
 
-		_ = huma.WriteErr(api, ctx, http.StatusInternalServerError, "internal server error",
+		_ = huma.WriteErr(p.api, ctx, http.StatusInternalServerError, "internal server error",
 			errors.New("missing operation in context"))
 
 		return false
 	}
 
 	path := op.Path
+	store := p.limiter.Store()
+
+	route := path
+	if operationID := keyOperation(ctx, cfg); operationID != "" {
+		route = "op:" + operationID
+	}
 
-	for _, limit := range limits {
-		// Build key combining client, route template, and window for unique tracking
-		key := fmt.Sprintf("%s:custom:%s:%d", clientK, path, limit.Window.Milliseconds())
+	for _, limit := range cfg.Limits {
+		// Build key combining client, route template (or operation), and window for unique tracking
+		key := fmt.Sprintf("%s:custom:%s:%d", clientK, route, limit.Window.Milliseconds())
 
 		count, err := store.Record(ctx.Context(), key, limit.Window)
 		if err != nil {
-			logger.Error("custom rate limit check failed",
+			p.log(ctx).Error("custom rate limit check failed",
 				zap.String("path", path),
 				zap.Error(err),
 			)
-			_ = huma.WriteErr(api, ctx, http.StatusInternalServerError, "internal server error", err)
+			_ = huma.WriteErr(p.api, ctx, http.StatusInternalServerError, "internal server error", err)
 
 			return false
 		}
 
+		if count > limit.Max && p.isMonitorOnly(ctx) {
+			p.log(ctx).Warn("custom rate limit would be exceeded (monitor only)",
+				zap.String("path", path),
+				zap.String("method", ctx.Method()),
+				zap.Int64("count", count),
+				zap.Int64("max", limit.Max),
+				zap.Duration("window", limit.Window),
+				zap.String("client_ip", clientIP(ctx, p.trustDepth)),
+			)
+
+			continue
+		}
+
 		if count > limit.Max {
-			logger.Warn("custom rate limit exceeded",
+			p.recorder.RecordDecision(ratelimit.ScopeCustom, ratelimit.DecisionDenied)
+			p.log(ctx).Warn("custom rate limit exceeded",
 				zap.String("path", path),
 				zap.String("method", ctx.Method()),
 				zap.Int64("count", count),
 				zap.Int64("max", limit.Max),
 				zap.Duration("window", limit.Window),
-				zap.String("client_ip", clientIP(ctx)),
+				zap.String("client_ip", clientIP(ctx, p.trustDepth)),
 			)
+			p.recordBreach(ctx, path)
+
 			msg := fmt.Sprintf("rate limit exceeded: %d/%d requests in %s",
 				count, limit.Max, limit.Window)
-			_ = huma.WriteErr(api, ctx, http.StatusTooManyRequests, msg)
+			_ = huma.WriteErr(p.api, ctx, http.StatusTooManyRequests, msg)
 
 			return false
 		}
 	}
 
+	p.recorder.RecordDecision(ratelimit.ScopeCustom, ratelimit.DecisionAllowed)
+
 	return true
 }
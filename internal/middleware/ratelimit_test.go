@@ -16,7 +16,10 @@ import (
 	"github.com/serroba/web-demo-go/internal/middleware"
 	"github.com/serroba/web-demo-go/internal/ratelimit"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 const (
@@ -100,7 +103,7 @@ func TestRateLimiter(t *testing.T) {
 		mw := middleware.RateLimiter(api, limiter)
 
 		ctx := newMockHumaContext()
-		ctx.host = testHostAddr
+		ctx.remoteAddr = testHostAddr
 		ctx.headers["User-Agent"] = testUserAgent
 
 		nextCalled := false
@@ -118,7 +121,7 @@ func TestRateLimiter(t *testing.T) {
 		mw := middleware.RateLimiter(api, limiter)
 
 		ctx := newMockHumaContext()
-		ctx.host = testHostAddr
+		ctx.remoteAddr = testHostAddr
 		ctx.headers["User-Agent"] = testUserAgent
 
 		nextCalled := false
@@ -144,7 +147,7 @@ func TestRateLimiter(t *testing.T) {
 		mw := middleware.RateLimiter(api, limiter)
 
 		ctx1 := newMockHumaContext()
-		ctx1.host = testHostAddr
+		ctx1.remoteAddr = testHostAddr
 		ctx1.headers["User-Agent"] = testUserAgent
 
 		mw(ctx1, func(_ huma.Context) {})
@@ -152,7 +155,7 @@ func TestRateLimiter(t *testing.T) {
 		key1 := capturedKey
 
 		ctx2 := newMockHumaContext()
-		ctx2.host = testHostAddr
+		ctx2.remoteAddr = testHostAddr
 		ctx2.headers["User-Agent"] = testUserAgent
 
 		mw(ctx2, func(_ huma.Context) {})
@@ -163,7 +166,7 @@ func TestRateLimiter(t *testing.T) {
 
 		// Different User-Agent should produce different key
 		ctx3 := newMockHumaContext()
-		ctx3.host = testHostAddr
+		ctx3.remoteAddr = testHostAddr
 		ctx3.headers["User-Agent"] = "DifferentAgent/2.0"
 
 		mw(ctx3, func(_ huma.Context) {})
@@ -185,7 +188,7 @@ func TestRateLimiter(t *testing.T) {
 		mw := middleware.RateLimiter(api, limiter)
 
 		ctx := newMockHumaContext()
-		ctx.host = "10.0.0.1:12345"
+		ctx.remoteAddr = "10.0.0.1:12345"
 		ctx.headers["X-Forwarded-For"] = "203.0.113.195, 70.41.3.18, 150.172.238.178"
 		ctx.headers["User-Agent"] = testUserAgentShort
 
@@ -195,7 +198,7 @@ func TestRateLimiter(t *testing.T) {
 
 		// Request with same first XFF IP should have same key
 		ctx2 := newMockHumaContext()
-		ctx2.host = "10.0.0.2:54321"
+		ctx2.remoteAddr = "10.0.0.2:54321"
 		ctx2.headers["X-Forwarded-For"] = "203.0.113.195"
 		ctx2.headers["User-Agent"] = testUserAgentShort
 
@@ -222,7 +225,7 @@ func TestRateLimiter_LimiterError(t *testing.T) {
 	mw := middleware.RateLimiter(api, limiter)
 
 	ctx := newMockHumaContext()
-	ctx.host = testHostAddr
+	ctx.remoteAddr = testHostAddr
 	ctx.headers["User-Agent"] = testUserAgent
 
 	nextCalled := false
@@ -247,7 +250,7 @@ func TestClientIP_XRealIP(t *testing.T) {
 	mw := middleware.RateLimiter(api, limiter)
 
 	ctx := newMockHumaContext()
-	ctx.host = "10.0.0.1:12345"
+	ctx.remoteAddr = "10.0.0.1:12345"
 	ctx.headers["X-Real-IP"] = "203.0.113.100"
 	ctx.headers["User-Agent"] = testUserAgentShort
 
@@ -257,7 +260,7 @@ func TestClientIP_XRealIP(t *testing.T) {
 
 	// Request with same X-Real-IP should have same key
 	ctx2 := newMockHumaContext()
-	ctx2.host = "10.0.0.2:54321"
+	ctx2.remoteAddr = "10.0.0.2:54321"
 	ctx2.headers["X-Real-IP"] = "203.0.113.100"
 	ctx2.headers["User-Agent"] = testUserAgentShort
 
@@ -266,7 +269,7 @@ func TestClientIP_XRealIP(t *testing.T) {
 	assert.Equal(t, keyWithXRI, capturedKey, "should use X-Real-IP when present")
 }
 
-func TestClientIP_HostWithoutPort(t *testing.T) {
+func TestClientIP_PeerWithoutPort(t *testing.T) {
 	api := newTestAPI()
 
 	var capturedKey string
@@ -277,23 +280,23 @@ func TestClientIP_HostWithoutPort(t *testing.T) {
 	}
 	mw := middleware.RateLimiter(api, limiter)
 
-	// Host without port (SplitHostPort will fail)
+	// Peer address without port (SplitHostPort will fail)
 	ctx := newMockHumaContext()
-	ctx.host = "192.168.1.1"
+	ctx.remoteAddr = "192.168.1.1"
 	ctx.headers["User-Agent"] = testUserAgentShort
 
 	mw(ctx, func(_ huma.Context) {})
 
 	key1 := capturedKey
 
-	// Same host should produce same key
+	// Same peer should produce same key
 	ctx2 := newMockHumaContext()
-	ctx2.host = "192.168.1.1"
+	ctx2.remoteAddr = "192.168.1.1"
 	ctx2.headers["User-Agent"] = testUserAgentShort
 
 	mw(ctx2, func(_ huma.Context) {})
 
-	assert.Equal(t, key1, capturedKey, "should use host as-is when SplitHostPort fails")
+	assert.Equal(t, key1, capturedKey, "should use the peer address as-is when SplitHostPort fails")
 }
 
 // mockPolicyStore is a mock store for testing PolicyRateLimiter.
@@ -316,6 +319,10 @@ func (m *mockPolicyStore) Record(_ context.Context, key string, _ time.Duration)
 	return m.counts[key], nil
 }
 
+func (m *mockPolicyStore) Peek(_ context.Context, key string, _ time.Duration) (int64, error) {
+	return m.counts[key], m.err
+}
+
 // mockScopeResolver is a mock resolver for testing.
 type mockScopeResolver struct {
 	scopes []ratelimit.Scope
@@ -340,7 +347,7 @@ func TestPolicyRateLimiter(t *testing.T) {
 		mw := middleware.PolicyRateLimiter(api, limiter, resolver, logger)
 
 		ctx := newMockHumaContext()
-		ctx.host = testHostAddr
+		ctx.remoteAddr = testHostAddr
 		ctx.headers["User-Agent"] = testUserAgent
 
 		nextCalled := false
@@ -365,7 +372,7 @@ func TestPolicyRateLimiter(t *testing.T) {
 		mw := middleware.PolicyRateLimiter(api, limiter, resolver, logger)
 
 		ctx := newMockHumaContext()
-		ctx.host = testHostAddr
+		ctx.remoteAddr = testHostAddr
 		ctx.headers["User-Agent"] = testUserAgent
 
 		// First request allowed
@@ -373,7 +380,7 @@ func TestPolicyRateLimiter(t *testing.T) {
 
 		// Second request should be denied
 		ctx2 := newMockHumaContext()
-		ctx2.host = testHostAddr
+		ctx2.remoteAddr = testHostAddr
 		ctx2.headers["User-Agent"] = testUserAgent
 
 		nextCalled := false
@@ -400,13 +407,13 @@ func TestPolicyRateLimiter(t *testing.T) {
 		mw := middleware.PolicyRateLimiter(api, limiter, resolver, logger)
 
 		ctx := newMockHumaContext()
-		ctx.host = testHostAddr
+		ctx.remoteAddr = testHostAddr
 		ctx.headers["User-Agent"] = testUserAgent
 
 		mw(ctx, func(_ huma.Context) {})
 
 		ctx2 := newMockHumaContext()
-		ctx2.host = testHostAddr
+		ctx2.remoteAddr = testHostAddr
 		ctx2.headers["User-Agent"] = testUserAgent
 
 		mw(ctx2, func(_ huma.Context) {})
@@ -434,7 +441,7 @@ func TestPolicyRateLimiter(t *testing.T) {
 		// Read requests - should allow 5
 		for i := range 5 {
 			ctx := newMockHumaContext()
-			ctx.host = testHostAddr
+			ctx.remoteAddr = testHostAddr
 			ctx.headers["User-Agent"] = testUserAgent
 
 			nextCalled := false
@@ -449,7 +456,7 @@ func TestPolicyRateLimiter(t *testing.T) {
 		// Write requests - should only allow 2
 		for i := range 2 {
 			ctx := newMockHumaContext()
-			ctx.host = testHostAddr
+			ctx.remoteAddr = testHostAddr
 			ctx.headers["User-Agent"] = testUserAgent
 
 			nextCalled := false
@@ -463,7 +470,7 @@ func TestPolicyRateLimiter(t *testing.T) {
 
 		// 3rd write should be denied
 		ctx := newMockHumaContext()
-		ctx.host = testHostAddr
+		ctx.remoteAddr = testHostAddr
 		ctx.headers["User-Agent"] = testUserAgent
 
 		nextCalled := false
@@ -490,7 +497,7 @@ func TestPolicyRateLimiter(t *testing.T) {
 		mw := middleware.PolicyRateLimiter(api, limiter, resolver, logger)
 
 		ctx := newMockHumaContext()
-		ctx.host = testHostAddr
+		ctx.remoteAddr = testHostAddr
 		ctx.headers["User-Agent"] = testUserAgent
 
 		nextCalled := false
@@ -517,7 +524,7 @@ func TestPolicyRateLimiter(t *testing.T) {
 
 		// First request with disabled rate limiting
 		ctx := newMockHumaContext()
-		ctx.host = testHostAddr
+		ctx.remoteAddr = testHostAddr
 		ctx.headers["User-Agent"] = testUserAgent
 		ctx.operation = &huma.Operation{
 			Path: "/test",
@@ -538,7 +545,7 @@ func TestPolicyRateLimiter(t *testing.T) {
 
 		// Second request should also be allowed (disabled means no limit)
 		ctx2 := newMockHumaContext()
-		ctx2.host = testHostAddr
+		ctx2.remoteAddr = testHostAddr
 		ctx2.headers["User-Agent"] = testUserAgent
 		ctx2.operation = ctx.operation
 
@@ -578,7 +585,7 @@ func TestPolicyRateLimiter(t *testing.T) {
 		// First two requests should succeed
 		for i := range 2 {
 			ctx := newMockHumaContext()
-			ctx.host = testHostAddr
+			ctx.remoteAddr = testHostAddr
 			ctx.headers["User-Agent"] = testUserAgent
 			ctx.operation = operation
 
@@ -593,7 +600,7 @@ func TestPolicyRateLimiter(t *testing.T) {
 
 		// Third request should be rate limited
 		ctx := newMockHumaContext()
-		ctx.host = testHostAddr
+		ctx.remoteAddr = testHostAddr
 		ctx.headers["User-Agent"] = testUserAgent
 		ctx.operation = operation
 
@@ -620,7 +627,7 @@ func TestPolicyRateLimiter(t *testing.T) {
 		mw := middleware.PolicyRateLimiter(api, limiter, resolver, logger)
 
 		ctx := newMockHumaContext()
-		ctx.host = testHostAddr
+		ctx.remoteAddr = testHostAddr
 		ctx.headers["User-Agent"] = testUserAgent
 		ctx.operation = &huma.Operation{
 			Path: "/api/v1/test",
@@ -647,7 +654,7 @@ func TestPolicyRateLimiter(t *testing.T) {
 		mw := middleware.PolicyRateLimiter(api, limiter, resolver, logger)
 
 		ctx := newMockHumaContext()
-		ctx.host = testHostAddr
+		ctx.remoteAddr = testHostAddr
 		ctx.headers["User-Agent"] = testUserAgent
 		ctx.operation = &huma.Operation{
 			Path: "/custom-error",
@@ -670,3 +677,409 @@ func TestPolicyRateLimiter(t *testing.T) {
 		assert.Equal(t, 500, ctx.statusCode)
 	})
 }
+
+type decisionKey struct {
+	scope    ratelimit.Scope
+	decision ratelimit.Decision
+}
+
+type mockRecorder struct {
+	counts map[decisionKey]int
+}
+
+func newMockRecorder() *mockRecorder {
+	return &mockRecorder{counts: make(map[decisionKey]int)}
+}
+
+func (m *mockRecorder) RecordDecision(scope ratelimit.Scope, decision ratelimit.Decision) {
+	m.counts[decisionKey{scope: scope, decision: decision}]++
+}
+
+func TestPolicyRateLimiter_RecordsDecisions(t *testing.T) {
+	t.Run("denied write increments denied write counter", func(t *testing.T) {
+		api := newTestAPI()
+		store := newMockPolicyStore()
+		policy := ratelimit.NewPolicyBuilder().
+			AddLimit(ratelimit.ScopeGlobal, 100, time.Minute).
+			AddLimit(ratelimit.ScopeWrite, 1, time.Minute).
+			Build()
+		limiter := ratelimit.NewPolicyLimiter(store, policy)
+		resolver := &mockScopeResolver{scopes: []ratelimit.Scope{ratelimit.ScopeGlobal, ratelimit.ScopeWrite}}
+		recorder := newMockRecorder()
+
+		mw := middleware.PolicyRateLimiter(api, limiter, resolver, zap.NewNop(),
+			middleware.WithDecisionRecorder(recorder))
+
+		for range 2 {
+			ctx := newMockHumaContext()
+			ctx.remoteAddr = testHostAddr
+			ctx.headers["User-Agent"] = testUserAgent
+
+			mw(ctx, func(_ huma.Context) {})
+		}
+
+		assert.Equal(t, 1, recorder.counts[decisionKey{ratelimit.ScopeWrite, ratelimit.DecisionAllowed}])
+		assert.Equal(t, 1, recorder.counts[decisionKey{ratelimit.ScopeGlobal, ratelimit.DecisionAllowed}])
+		assert.Equal(t, 1, recorder.counts[decisionKey{ratelimit.ScopeWrite, ratelimit.DecisionDenied}])
+		assert.Zero(t, recorder.counts[decisionKey{ratelimit.ScopeGlobal, ratelimit.DecisionDenied}])
+	})
+
+	t.Run("custom limits record under custom scope", func(t *testing.T) {
+		api := newTestAPI()
+		store := newMockPolicyStore()
+		limiter := ratelimit.NewPolicyLimiter(store, ratelimit.NewPolicyBuilder().Build())
+		resolver := &mockScopeResolver{}
+		recorder := newMockRecorder()
+
+		mw := middleware.PolicyRateLimiter(api, limiter, resolver, zap.NewNop(),
+			middleware.WithDecisionRecorder(recorder))
+
+		operation := &huma.Operation{
+			Path: "/custom",
+			Metadata: map[string]any{
+				ratelimit.MetadataKey: ratelimit.EndpointConfig{
+					Limits: []ratelimit.LimitConfig{{Window: time.Minute, Max: 1}},
+				},
+			},
+		}
+
+		for range 2 {
+			ctx := newMockHumaContext()
+			ctx.remoteAddr = testHostAddr
+			ctx.headers["User-Agent"] = testUserAgent
+			ctx.operation = operation
+
+			mw(ctx, func(_ huma.Context) {})
+		}
+
+		assert.Equal(t, 1, recorder.counts[decisionKey{ratelimit.ScopeCustom, ratelimit.DecisionAllowed}])
+		assert.Equal(t, 1, recorder.counts[decisionKey{ratelimit.ScopeCustom, ratelimit.DecisionDenied}])
+	})
+}
+
+func TestPolicyRateLimiter_MonitorOnly(t *testing.T) {
+	newLimiter := func() *ratelimit.PolicyLimiter {
+		policy := ratelimit.NewPolicyBuilder().
+			AddLimit(ratelimit.ScopeWrite, 1, time.Minute).
+			Build()
+
+		return ratelimit.NewPolicyLimiter(newMockPolicyStore(), policy)
+	}
+	resolver := &mockScopeResolver{scopes: []ratelimit.Scope{ratelimit.ScopeWrite}}
+
+	send := func(mw func(huma.Context, func(huma.Context)), operation *huma.Operation) (*mockHumaContext, bool) {
+		ctx := newMockHumaContext()
+		ctx.remoteAddr = testHostAddr
+		ctx.headers["User-Agent"] = testUserAgent
+		ctx.operation = operation
+
+		called := false
+		mw(ctx, func(_ huma.Context) { called = true })
+
+		return ctx, called
+	}
+
+	t.Run("global monitor mode logs and allows requests over the limit", func(t *testing.T) {
+		core, logs := observer.New(zapcore.WarnLevel)
+		mw := middleware.PolicyRateLimiter(newTestAPI(), newLimiter(), resolver, zap.New(core),
+			middleware.WithMonitorOnly())
+
+		for range 2 {
+			ctx, called := send(mw, nil)
+
+			assert.True(t, called)
+			assert.NotEqual(t, 429, ctx.statusCode)
+		}
+
+		assert.Equal(t, 1, logs.FilterMessage("rate limit would be exceeded (monitor only)").Len())
+	})
+
+	t.Run("endpoint monitor mode logs and allows requests over the limit", func(t *testing.T) {
+		core, logs := observer.New(zapcore.WarnLevel)
+		mw := middleware.PolicyRateLimiter(newTestAPI(), newLimiter(), resolver, zap.New(core))
+		operation := &huma.Operation{
+			Path: "/monitored",
+			Metadata: map[string]any{
+				ratelimit.MetadataKey: ratelimit.EndpointConfig{MonitorOnly: true},
+			},
+		}
+
+		for range 2 {
+			_, called := send(mw, operation)
+
+			assert.True(t, called)
+		}
+
+		assert.Equal(t, 1, logs.FilterMessage("rate limit would be exceeded (monitor only)").Len())
+	})
+
+	t.Run("custom limits in monitor mode log and allow", func(t *testing.T) {
+		core, logs := observer.New(zapcore.WarnLevel)
+		mw := middleware.PolicyRateLimiter(newTestAPI(), newLimiter(), resolver, zap.New(core))
+		operation := &huma.Operation{
+			Path: "/custom-monitored",
+			Metadata: map[string]any{
+				ratelimit.MetadataKey: ratelimit.EndpointConfig{
+					Limits:      []ratelimit.LimitConfig{{Window: time.Minute, Max: 1}},
+					MonitorOnly: true,
+				},
+			},
+		}
+
+		for range 2 {
+			_, called := send(mw, operation)
+
+			assert.True(t, called)
+		}
+
+		assert.Equal(t, 1, logs.FilterMessage("custom rate limit would be exceeded (monitor only)").Len())
+	})
+
+	t.Run("normal mode denies requests over the limit", func(t *testing.T) {
+		mw := middleware.PolicyRateLimiter(newTestAPI(), newLimiter(), resolver, zap.NewNop())
+
+		_, called := send(mw, nil)
+		assert.True(t, called)
+
+		ctx, called := send(mw, nil)
+		assert.False(t, called)
+		assert.Equal(t, 429, ctx.statusCode)
+	})
+}
+
+func TestPolicyRateLimiter_PeerKeyWithoutUserAgent(t *testing.T) {
+	newMiddleware := func(opts ...middleware.PolicyRateLimiterOption) func(huma.Context, func(huma.Context)) {
+		policy := ratelimit.NewPolicyBuilder().
+			AddLimit(ratelimit.ScopeGlobal, 1, time.Minute).
+			Build()
+		limiter := ratelimit.NewPolicyLimiter(newMockPolicyStore(), policy)
+		resolver := &mockScopeResolver{scopes: []ratelimit.Scope{ratelimit.ScopeGlobal}}
+
+		return middleware.PolicyRateLimiter(newTestAPI(), limiter, resolver, zap.NewNop(), opts...)
+	}
+
+	// send issues a request from the same peer with a rotated forwarded IP.
+	send := func(mw func(huma.Context, func(huma.Context)), forwardedFor, userAgent string) int {
+		ctx := newMockHumaContext()
+		ctx.remoteAddr = testHostAddr
+		ctx.headers["X-Forwarded-For"] = forwardedFor
+		ctx.headers["User-Agent"] = userAgent
+
+		mw(ctx, func(_ huma.Context) { ctx.statusCode = 200 })
+
+		return ctx.statusCode
+	}
+
+	t.Run("keys requests without a user agent by peer address", func(t *testing.T) {
+		mw := newMiddleware(middleware.WithPeerKeyWithoutUserAgent())
+
+		assert.Equal(t, 200, send(mw, "10.0.0.1", ""))
+		assert.Equal(t, 429, send(mw, "10.0.0.2", ""), "rotating X-Forwarded-For must not reset the limit")
+	})
+
+	t.Run("keeps forwarded keys for requests with a user agent", func(t *testing.T) {
+		mw := newMiddleware(middleware.WithPeerKeyWithoutUserAgent())
+
+		assert.Equal(t, 200, send(mw, "10.0.0.1", testUserAgent))
+		assert.Equal(t, 200, send(mw, "10.0.0.2", testUserAgent))
+	})
+
+	t.Run("uses forwarded keys without the option", func(t *testing.T) {
+		mw := newMiddleware()
+
+		assert.Equal(t, 200, send(mw, "10.0.0.1", ""))
+		assert.Equal(t, 200, send(mw, "10.0.0.2", ""))
+	})
+}
+
+func TestPolicyRateLimiter_XFFTrustDepth(t *testing.T) {
+	newMiddleware := func(opts ...middleware.PolicyRateLimiterOption) func(huma.Context, func(huma.Context)) {
+		policy := ratelimit.NewPolicyBuilder().
+			AddLimit(ratelimit.ScopeGlobal, 1, time.Minute).
+			Build()
+		limiter := ratelimit.NewPolicyLimiter(newMockPolicyStore(), policy)
+		resolver := &mockScopeResolver{scopes: []ratelimit.Scope{ratelimit.ScopeGlobal}}
+
+		return middleware.PolicyRateLimiter(newTestAPI(), limiter, resolver, zap.NewNop(), opts...)
+	}
+
+	send := func(mw func(huma.Context, func(huma.Context)), forwardedFor string) int {
+		ctx := newMockHumaContext()
+		ctx.remoteAddr = testHostAddr
+		ctx.headers["X-Forwarded-For"] = forwardedFor
+		ctx.headers["User-Agent"] = testUserAgentShort
+
+		mw(ctx, func(_ huma.Context) { ctx.statusCode = 200 })
+
+		return ctx.statusCode
+	}
+
+	t.Run("forged leftmost entries do not reset the limit behind trusted proxies", func(t *testing.T) {
+		mw := newMiddleware(middleware.WithXFFTrustDepth(1))
+
+		assert.Equal(t, 200, send(mw, "198.51.100.1, 203.0.113.10"))
+		assert.Equal(t, 429, send(mw, "198.51.100.2, 203.0.113.10"))
+		assert.Equal(t, 200, send(mw, "198.51.100.2, 203.0.113.11"), "other clients keep their own limit")
+	})
+
+	t.Run("forged chains shorter than the trust depth are keyed by the peer", func(t *testing.T) {
+		mw := newMiddleware(middleware.WithXFFTrustDepth(2))
+
+		assert.Equal(t, 200, send(mw, "198.51.100.1"))
+		assert.Equal(t, 429, send(mw, "198.51.100.2"))
+	})
+
+	t.Run("keys by the leftmost entry by default", func(t *testing.T) {
+		mw := newMiddleware()
+
+		assert.Equal(t, 200, send(mw, "198.51.100.1, 203.0.113.10"))
+		assert.Equal(t, 200, send(mw, "198.51.100.2, 203.0.113.10"))
+	})
+
+	sendRealIP := func(mw func(huma.Context, func(huma.Context)), realIP string) int {
+		ctx := newMockHumaContext()
+		ctx.remoteAddr = testHostAddr
+		ctx.headers["X-Real-IP"] = realIP
+		ctx.headers["User-Agent"] = testUserAgentShort
+
+		mw(ctx, func(_ huma.Context) { ctx.statusCode = 200 })
+
+		return ctx.statusCode
+	}
+
+	t.Run("ignores X-Real-IP behind trusted proxies", func(t *testing.T) {
+		mw := newMiddleware(middleware.WithXFFTrustDepth(1))
+
+		assert.Equal(t, 200, sendRealIP(mw, "198.51.100.1"))
+		assert.Equal(t, 429, sendRealIP(mw, "198.51.100.2"), "rotating X-Real-IP must not reset the limit")
+	})
+
+	t.Run("keys by X-Real-IP by default", func(t *testing.T) {
+		mw := newMiddleware()
+
+		assert.Equal(t, 200, sendRealIP(mw, "198.51.100.1"))
+		assert.Equal(t, 200, sendRealIP(mw, "198.51.100.2"))
+	})
+}
+
+type fakeBreachNotifier struct {
+	breaches []ratelimit.Breach
+}
+
+func (n *fakeBreachNotifier) NotifyBreach(_ context.Context, breach ratelimit.Breach) {
+	n.breaches = append(n.breaches, breach)
+}
+
+func TestPolicyRateLimiter_BreachNotifier(t *testing.T) {
+	newMiddleware := func(notifier ratelimit.BreachNotifier) func(huma.Context, func(huma.Context)) {
+		policy := ratelimit.NewPolicyBuilder().
+			AddLimit(ratelimit.ScopeGlobal, 1, time.Minute).
+			Build()
+		limiter := ratelimit.NewPolicyLimiter(newMockPolicyStore(), policy)
+		resolver := &mockScopeResolver{scopes: []ratelimit.Scope{ratelimit.ScopeGlobal}}
+		tracker := ratelimit.NewBreachTracker(2, time.Minute)
+
+		return middleware.PolicyRateLimiter(newTestAPI(), limiter, resolver, zap.NewNop(),
+			middleware.WithBreachNotifier(tracker, notifier))
+	}
+
+	send := func(mw func(huma.Context, func(huma.Context)), ip string) int {
+		ctx := newMockHumaContext()
+		ctx.headers["X-Forwarded-For"] = ip
+		ctx.headers["User-Agent"] = testUserAgentShort
+
+		mw(ctx, func(_ huma.Context) { ctx.statusCode = 200 })
+
+		return ctx.statusCode
+	}
+
+	t.Run("fires after repeated breaches from the same client", func(t *testing.T) {
+		notifier := &fakeBreachNotifier{}
+		mw := newMiddleware(notifier)
+
+		assert.Equal(t, 200, send(mw, "203.0.113.1"))
+
+		for range 2 {
+			assert.Equal(t, 429, send(mw, "203.0.113.1"))
+		}
+
+		assert.Empty(t, notifier.breaches)
+
+		assert.Equal(t, 429, send(mw, "203.0.113.1"))
+		require.Len(t, notifier.breaches, 1)
+		assert.Equal(t, "203.0.113.1", notifier.breaches[0].ClientIP)
+		assert.Equal(t, 2, notifier.breaches[0].Threshold)
+		assert.Equal(t, time.Minute, notifier.breaches[0].Window)
+	})
+
+	t.Run("does not fire for isolated breaches", func(t *testing.T) {
+		notifier := &fakeBreachNotifier{}
+		mw := newMiddleware(notifier)
+
+		for _, ip := range []string{"203.0.113.1", "203.0.113.2", "203.0.113.3"} {
+			assert.Equal(t, 200, send(mw, ip))
+			assert.Equal(t, 429, send(mw, ip))
+		}
+
+		assert.Empty(t, notifier.breaches)
+	})
+}
+
+func TestPolicyRateLimiter_KeyByOperation(t *testing.T) {
+	newMiddleware := func() func(huma.Context, func(huma.Context)) {
+		policy := ratelimit.NewPolicyBuilder().
+			AddLimit(ratelimit.ScopeWrite, 1, time.Minute).
+			Build()
+		limiter := ratelimit.NewPolicyLimiter(newMockPolicyStore(), policy)
+		resolver := &mockScopeResolver{scopes: []ratelimit.Scope{ratelimit.ScopeWrite}}
+
+		return middleware.PolicyRateLimiter(newTestAPI(), limiter, resolver, zap.NewNop())
+	}
+
+	operation := func(id string, cfg ratelimit.EndpointConfig) *huma.Operation {
+		return &huma.Operation{
+			OperationID: id,
+			Path:        "/{code}",
+			Metadata:    map[string]any{ratelimit.MetadataKey: cfg},
+		}
+	}
+
+	send := func(mw func(huma.Context, func(huma.Context)), op *huma.Operation) int {
+		ctx := newMockHumaContext()
+		ctx.remoteAddr = testHostAddr
+		ctx.headers["User-Agent"] = testUserAgent
+		ctx.operation = op
+
+		mw(ctx, func(_ huma.Context) { ctx.statusCode = 200 })
+
+		return ctx.statusCode
+	}
+
+	custom := []ratelimit.LimitConfig{{Window: time.Minute, Max: 1}}
+
+	t.Run("custom limits of operations sharing a path are isolated", func(t *testing.T) {
+		mw := newMiddleware()
+		cfg := ratelimit.EndpointConfig{Limits: custom, KeyByOperation: true}
+
+		assert.Equal(t, 200, send(mw, operation("update-url", cfg)))
+		assert.Equal(t, 429, send(mw, operation("update-url", cfg)))
+		assert.Equal(t, 200, send(mw, operation("delete-url", cfg)))
+	})
+
+	t.Run("custom limits of operations sharing a path share buckets by default", func(t *testing.T) {
+		mw := newMiddleware()
+		cfg := ratelimit.EndpointConfig{Limits: custom}
+
+		assert.Equal(t, 200, send(mw, operation("update-url", cfg)))
+		assert.Equal(t, 429, send(mw, operation("delete-url", cfg)))
+	})
+
+	t.Run("scope limits are counted per operation", func(t *testing.T) {
+		mw := newMiddleware()
+		cfg := ratelimit.EndpointConfig{KeyByOperation: true}
+
+		assert.Equal(t, 200, send(mw, operation("update-url", cfg)))
+		assert.Equal(t, 429, send(mw, operation("update-url", cfg)))
+		assert.Equal(t, 200, send(mw, operation("delete-url", cfg)))
+	})
+}
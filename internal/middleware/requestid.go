@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/jaevor/go-nanoid"
+	"github.com/serroba/web-demo-go/internal/handlers"
+	"github.com/serroba/web-demo-go/internal/logging"
+	"go.uber.org/zap"
+)
+
+// RequestIDHeader is the header used to read and echo the request correlation ID.
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds client-supplied IDs so they cannot bloat logs.
+const maxRequestIDLength = 128
+
+// RequestID is a middleware that reads X-Request-ID or generates one, echoes it
+// in the response, records it in RequestMeta, and stores a child logger tagged
+// with the ID in the request context.
+// It must run after RequestMeta so the ID is added to the existing metadata.
+func RequestID(_ huma.API, logger *zap.Logger) func(ctx huma.Context, next func(huma.Context)) {
+	generate, _ := nanoid.Standard(21)
+
+	return func(ctx huma.Context, next func(huma.Context)) {
+		id := ctx.Header(RequestIDHeader)
+		if !isValidRequestID(id) {
+			id = generate()
+		}
+
+		ctx.SetHeader(RequestIDHeader, id)
+
+		meta := handlers.RequestMetaFromContext(ctx.Context())
+		meta.RequestID = id
+
+		newCtx := handlers.ContextWithRequestMeta(ctx.Context(), meta)
+		newCtx = logging.WithLogger(newCtx, logger.With(zap.String("request_id", id)))
+		ctx = huma.WithContext(ctx, newCtx)
+
+		next(ctx)
+	}
+}
+
+// isValidRequestID accepts non-empty, bounded IDs made of printable ASCII.
+func isValidRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+
+	for i := range len(id) {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,111 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/adapters/humachi"
+	"github.com/go-chi/chi/v5"
+	"github.com/serroba/web-demo-go/internal/handlers"
+	"github.com/serroba/web-demo-go/internal/logging"
+	"github.com/serroba/web-demo-go/internal/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func setupRequestIDAPI(t *testing.T, logger *zap.Logger) (*chi.Mux, huma.API) {
+	t.Helper()
+
+	router := chi.NewMux()
+	api := humachi.New(router, huma.DefaultConfig("Test", "1.0.0"))
+	api.UseMiddleware(middleware.RequestMeta(api))
+	api.UseMiddleware(middleware.RequestID(api, logger))
+
+	return router, api
+}
+
+func TestRequestID(t *testing.T) {
+	t.Run("echoes incoming request id", func(t *testing.T) {
+		router, api := setupRequestIDAPI(t, zap.NewNop())
+
+		ctxChan := make(chan context.Context, 1)
+
+		huma.Get(api, "/test", func(ctx context.Context, _ *struct{}) (*testOutput, error) {
+			ctxChan <- ctx
+
+			return &testOutput{Body: "ok"}, nil
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/test", nil)
+		req.Header.Set(middleware.RequestIDHeader, "req-abc-123")
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, "req-abc-123", w.Header().Get(middleware.RequestIDHeader))
+
+		meta := handlers.RequestMetaFromContext(<-ctxChan)
+		assert.Equal(t, "req-abc-123", meta.RequestID)
+	})
+
+	t.Run("generates request id when missing", func(t *testing.T) {
+		router, api := setupRequestIDAPI(t, zap.NewNop())
+
+		huma.Get(api, "/test", func(_ context.Context, _ *struct{}) (*testOutput, error) {
+			return &testOutput{Body: "ok"}, nil
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/test", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.NotEmpty(t, w.Header().Get(middleware.RequestIDHeader))
+	})
+
+	t.Run("replaces oversized request id", func(t *testing.T) {
+		router, api := setupRequestIDAPI(t, zap.NewNop())
+
+		huma.Get(api, "/test", func(_ context.Context, _ *struct{}) (*testOutput, error) {
+			return &testOutput{Body: "ok"}, nil
+		})
+
+		oversized := strings.Repeat("a", 200)
+		req := httptest.NewRequest(http.MethodGet, "/test", nil)
+		req.Header.Set(middleware.RequestIDHeader, oversized)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		got := w.Header().Get(middleware.RequestIDHeader)
+		assert.NotEmpty(t, got)
+		assert.NotEqual(t, oversized, got)
+	})
+
+	t.Run("context logger includes request id", func(t *testing.T) {
+		core, logs := observer.New(zapcore.InfoLevel)
+		router, api := setupRequestIDAPI(t, zap.New(core))
+
+		huma.Get(api, "/test", func(ctx context.Context, _ *struct{}) (*testOutput, error) {
+			logging.FromContext(ctx, zap.NewNop()).Info("handling request")
+
+			return &testOutput{Body: "ok"}, nil
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/test", nil)
+		req.Header.Set(middleware.RequestIDHeader, "req-log-1")
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		entries := logs.FilterMessage("handling request").All()
+		require.Len(t, entries, 1)
+		assert.Equal(t, "req-log-1", entries[0].ContextMap()["request_id"])
+	})
+}
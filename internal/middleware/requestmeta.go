@@ -1,19 +1,48 @@
 package middleware
 
 import (
-	"strings"
-
 	"github.com/danielgtaylor/huma/v2"
 	"github.com/serroba/web-demo-go/internal/handlers"
 )
 
-// RequestMeta is a middleware that adds client IP, user-agent, and referrer to the request context.
-func RequestMeta(_ huma.API) func(ctx huma.Context, next func(huma.Context)) {
+// RequestMetaOption configures optional RequestMeta behavior.
+type RequestMetaOption func(*requestMetaConfig)
+
+type requestMetaConfig struct {
+	trustDepth int
+}
+
+// WithRequestMetaXFFTrustDepth takes the client IP from the X-Forwarded-For
+// entry trustDepth hops from the right, for deployments behind that many
+// trusted proxies. Zero keeps the leftmost entry; shorter chains use the peer
+// address. A positive depth also honors X-Forwarded-Proto and X-Forwarded-Host,
+// which are ignored without trusted proxies since any client can send them.
+func WithRequestMetaXFFTrustDepth(trustDepth int) RequestMetaOption {
+	return func(c *requestMetaConfig) {
+		c.trustDepth = trustDepth
+	}
+}
+
+// RequestMeta is a middleware that adds client IP, user-agent, referrer, and,
+// behind trusted proxies, forwarded proto/host to the request context. Operations marked with
+// handlers.NoAnalyticsMetadataKey get SkipAnalytics set so they publish no events.
+func RequestMeta(_ huma.API, opts ...RequestMetaOption) func(ctx huma.Context, next func(huma.Context)) {
+	var cfg requestMetaConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
 	return func(ctx huma.Context, next func(huma.Context)) {
 		meta := handlers.RequestMeta{
-			ClientIP:  extractClientIP(ctx),
-			UserAgent: ctx.Header("User-Agent"),
-			Referrer:  ctx.Header("Referer"),
+			ClientIP:      clientIP(ctx, cfg.trustDepth),
+			UserAgent:     ctx.Header("User-Agent"),
+			Referrer:      ctx.Header("Referer"),
+			SkipAnalytics: skipsAnalytics(ctx),
+		}
+
+		if cfg.trustDepth > 0 {
+			meta.ForwardedProto = ctx.Header("X-Forwarded-Proto")
+			meta.ForwardedHost = ctx.Header("X-Forwarded-Host")
 		}
 
 		newCtx := handlers.ContextWithRequestMeta(ctx.Context(), meta)
@@ -23,27 +52,13 @@ func RequestMeta(_ huma.API) func(ctx huma.Context, next func(huma.Context)) {
 	}
 }
 
-func extractClientIP(ctx huma.Context) string {
-	// Check X-Forwarded-For first (may contain multiple IPs)
-	if xff := ctx.Header("X-Forwarded-For"); xff != "" {
-		// Take the first IP (original client)
-		if idx := strings.Index(xff, ","); idx != -1 {
-			return strings.TrimSpace(xff[:idx])
-		}
-
-		return strings.TrimSpace(xff)
-	}
-
-	// Check X-Real-IP
-	if xri := ctx.Header("X-Real-IP"); xri != "" {
-		return xri
+func skipsAnalytics(ctx huma.Context) bool {
+	op := ctx.Operation()
+	if op == nil || op.Metadata == nil {
+		return false
 	}
 
-	// Fall back to remote addr
-	host := ctx.Host()
-	if idx := strings.LastIndex(host, ":"); idx != -1 {
-		return host[:idx]
-	}
+	skip, _ := op.Metadata[handlers.NoAnalyticsMetadataKey].(bool)
 
-	return host
+	return skip
 }
@@ -56,6 +56,54 @@ func TestRequestMeta(t *testing.T) {
 		assert.Equal(t, "TestAgent/1.0", meta.UserAgent)
 	})
 
+	t.Run("extracts forwarded proto and host behind trusted proxies", func(t *testing.T) {
+		router := chi.NewMux()
+		api := humachi.New(router, huma.DefaultConfig("Test", "1.0.0"))
+		api.UseMiddleware(middleware.RequestMeta(api, middleware.WithRequestMetaXFFTrustDepth(1)))
+
+		ctxChan := make(chan context.Context, 1)
+
+		huma.Get(api, "/test", func(ctx context.Context, _ *struct{}) (*testOutput, error) {
+			ctxChan <- ctx
+
+			return &testOutput{Body: "ok"}, nil
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/test", nil)
+		req.Header.Set("X-Forwarded-Proto", "https")
+		req.Header.Set("X-Forwarded-Host", "sho.rt")
+
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		meta := handlers.RequestMetaFromContext(<-ctxChan)
+		assert.Equal(t, "https", meta.ForwardedProto)
+		assert.Equal(t, "sho.rt", meta.ForwardedHost)
+	})
+
+	t.Run("ignores forwarded proto and host without trusted proxies", func(t *testing.T) {
+		router, api := setupTestAPI(t)
+
+		ctxChan := make(chan context.Context, 1)
+
+		huma.Get(api, "/test", func(ctx context.Context, _ *struct{}) (*testOutput, error) {
+			ctxChan <- ctx
+
+			return &testOutput{Body: "ok"}, nil
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/test", nil)
+		req.Header.Set("X-Forwarded-Proto", "https")
+		req.Header.Set("X-Forwarded-Host", "evil.example")
+
+		router.ServeHTTP(httptest.NewRecorder(), req)
+
+		meta := handlers.RequestMetaFromContext(<-ctxChan)
+		assert.Empty(t, meta.ForwardedProto)
+		assert.Empty(t, meta.ForwardedHost)
+	})
+
 	t.Run("extracts IP from X-Forwarded-For with single IP", func(t *testing.T) {
 		router, api := setupTestAPI(t)
 
@@ -107,10 +155,14 @@ func TestRequestMeta(t *testing.T) {
 		assert.Equal(t, http.StatusOK, w.Code)
 	})
 
-	t.Run("falls back to host when no IP headers present", func(t *testing.T) {
+	t.Run("falls back to the peer address when no IP headers present", func(t *testing.T) {
 		router, api := setupTestAPI(t)
 
-		huma.Get(api, "/test", func(_ context.Context, _ *struct{}) (*testOutput, error) {
+		ctxChan := make(chan context.Context, 1)
+
+		huma.Get(api, "/test", func(ctx context.Context, _ *struct{}) (*testOutput, error) {
+			ctxChan <- ctx
+
 			return &testOutput{Body: "ok"}, nil
 		})
 
@@ -121,5 +173,95 @@ func TestRequestMeta(t *testing.T) {
 		router.ServeHTTP(w, req)
 
 		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "192.0.2.1", handlers.RequestMetaFromContext(<-ctxChan).ClientIP, "not the Host header")
+	})
+
+	t.Run("skips analytics only for marked operations", func(t *testing.T) {
+		router, api := setupTestAPI(t)
+
+		captured := map[string]handlers.RequestMeta{}
+		capture := func(path string) func(context.Context, *struct{}) (*testOutput, error) {
+			return func(ctx context.Context, _ *struct{}) (*testOutput, error) {
+				captured[path] = handlers.RequestMetaFromContext(ctx)
+
+				return &testOutput{Body: "ok"}, nil
+			}
+		}
+
+		huma.Get(api, "/tracked", capture("/tracked"))
+		huma.Register(api, huma.Operation{
+			Method:   http.MethodGet,
+			Path:     "/quiet",
+			Metadata: map[string]any{handlers.NoAnalyticsMetadataKey: true},
+		}, capture("/quiet"))
+
+		for _, path := range []string{"/tracked", "/quiet"} {
+			router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+		}
+
+		assert.False(t, captured["/tracked"].SkipAnalytics)
+		assert.True(t, captured["/quiet"].SkipAnalytics)
+	})
+}
+
+func TestRequestMeta_XFFTrustDepth(t *testing.T) {
+	// A forged entry, the real client, then two trusted proxies.
+	const chain = "198.51.100.7, 203.0.113.10, 10.0.0.2, 10.0.0.3"
+
+	tests := []struct {
+		name  string
+		depth int
+		want  string
+	}{
+		{name: "zero keeps the leftmost entry", depth: 0, want: "198.51.100.7"},
+		{name: "one trusted proxy", depth: 1, want: "10.0.0.3"},
+		{name: "two trusted proxies", depth: 2, want: "10.0.0.2"},
+		{name: "three trusted proxies", depth: 3, want: "203.0.113.10"},
+		{name: "depth equal to the chain length", depth: 4, want: "198.51.100.7"},
+		{name: "chain shorter than the depth uses the peer address", depth: 10, want: "192.0.2.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := chi.NewMux()
+			api := humachi.New(router, huma.DefaultConfig("Test", "1.0.0"))
+			api.UseMiddleware(middleware.RequestMeta(api, middleware.WithRequestMetaXFFTrustDepth(tt.depth)))
+
+			ctxChan := make(chan context.Context, 1)
+
+			huma.Get(api, "/test", func(ctx context.Context, _ *struct{}) (*testOutput, error) {
+				ctxChan <- ctx
+
+				return &testOutput{Body: "ok"}, nil
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/test", nil)
+			req.Header.Set("X-Forwarded-For", chain)
+
+			router.ServeHTTP(httptest.NewRecorder(), req)
+
+			assert.Equal(t, tt.want, handlers.RequestMetaFromContext(<-ctxChan).ClientIP)
+		})
+	}
+
+	t.Run("ignores X-Real-IP behind trusted proxies", func(t *testing.T) {
+		router := chi.NewMux()
+		api := humachi.New(router, huma.DefaultConfig("Test", "1.0.0"))
+		api.UseMiddleware(middleware.RequestMeta(api, middleware.WithRequestMetaXFFTrustDepth(1)))
+
+		ctxChan := make(chan context.Context, 1)
+
+		huma.Get(api, "/test", func(ctx context.Context, _ *struct{}) (*testOutput, error) {
+			ctxChan <- ctx
+
+			return &testOutput{Body: "ok"}, nil
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/test", nil)
+		req.Header.Set("X-Real-IP", "198.51.100.7")
+
+		router.ServeHTTP(httptest.NewRecorder(), req)
+
+		assert.Equal(t, "192.0.2.1", handlers.RequestMetaFromContext(<-ctxChan).ClientIP)
 	})
 }
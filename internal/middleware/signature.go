@@ -0,0 +1,146 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/serroba/web-demo-go/internal/clock"
+)
+
+// Request signing headers.
+const (
+	SignatureHeader          = "X-Signature"
+	SignatureTimestampHeader = "X-Signature-Timestamp"
+)
+
+// maxSignedBodyBytes bounds how much of a request body is buffered for verification.
+const maxSignedBodyBytes = 8 << 20
+
+// SignatureOption configures optional VerifySignature behavior.
+type SignatureOption func(*signatureVerifier)
+
+// WithSignatureClock sets the clock used to check signature timestamps.
+func WithSignatureClock(c clock.Clock) SignatureOption {
+	return func(v *signatureVerifier) {
+		v.clock = c
+	}
+}
+
+type signatureVerifier struct {
+	secret []byte
+	window time.Duration
+	clock  clock.Clock
+}
+
+// VerifySignature is a router middleware that requires an HMAC-SHA256 signature
+// on requests that carry a body (every method but GET, HEAD and OPTIONS), so only
+// callers holding secret can make changes. Callers send the Unix timestamp in
+// X-Signature-Timestamp and the hex HMAC of "<timestamp>.<body>" in X-Signature,
+// optionally prefixed with "sha256=". Timestamps further than window from now
+// are rejected to limit replays. It wraps the router rather than the Huma API
+// because it has to buffer and restore the body, including multipart uploads.
+func VerifySignature(secret string, window time.Duration, opts ...SignatureOption) func(http.Handler) http.Handler {
+	v := &signatureVerifier{
+		secret: []byte(secret),
+		window: window,
+		clock:  clock.Real{},
+	}
+
+	for _, opt := range opts {
+		opt(v)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				next.ServeHTTP(w, r)
+
+				return
+			}
+
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxSignedBodyBytes))
+			if err != nil {
+				var tooLarge *http.MaxBytesError
+				if errors.As(err, &tooLarge) {
+					writeProblem(w, http.StatusRequestEntityTooLarge, "request body too large to verify")
+
+					return
+				}
+
+				writeProblem(w, http.StatusBadRequest, "failed to read request body")
+
+				return
+			}
+
+			if msg := v.verify(r.Header, body); msg != "" {
+				writeProblem(w, http.StatusUnauthorized, msg)
+
+				return
+			}
+
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// verify returns why the signature is not acceptable, or "" when it is.
+func (v *signatureVerifier) verify(header http.Header, body []byte) string {
+	timestamp := header.Get(SignatureTimestampHeader)
+	signature := strings.TrimPrefix(header.Get(SignatureHeader), "sha256=")
+
+	if timestamp == "" || signature == "" {
+		return "missing request signature"
+	}
+
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return "invalid signature timestamp"
+	}
+
+	if age := v.clock.Now().Sub(time.Unix(seconds, 0)); age > v.window || age < -v.window {
+		return "signature timestamp outside the allowed window"
+	}
+
+	given, err := hex.DecodeString(signature)
+	if err != nil || !hmac.Equal(given, Sign(v.secret, timestamp, body)) {
+		return "invalid request signature"
+	}
+
+	return ""
+}
+
+// Sign returns the HMAC-SHA256 of "<timestamp>.<body>" under secret, the value
+// callers hex-encode into X-Signature.
+func Sign(secret []byte, timestamp string, body []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return mac.Sum(nil)
+}
+
+// writeProblem writes an RFC 9457 error in the shape Huma uses, for router
+// middleware that runs before the API.
+func writeProblem(w http.ResponseWriter, status int, detail string) {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+
+	_ = json.NewEncoder(w).Encode(&huma.ErrorModel{
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+	})
+}
@@ -0,0 +1,118 @@
+package middleware_test
+
+import (
+	"context"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/adapters/humachi"
+	"github.com/go-chi/chi/v5"
+	"github.com/serroba/web-demo-go/internal/clock"
+	"github.com/serroba/web-demo-go/internal/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+const signingSecret = "s3cret"
+
+type echoInput struct {
+	Body struct {
+		URL string `json:"url"`
+	}
+}
+
+func setupSignatureAPI(t *testing.T, now time.Time) *chi.Mux {
+	t.Helper()
+
+	router := chi.NewMux()
+	router.Use(middleware.VerifySignature(signingSecret, 5*time.Minute,
+		middleware.WithSignatureClock(clock.NewFake(now))))
+
+	api := humachi.New(router, huma.DefaultConfig("Test", "1.0.0"))
+
+	huma.Get(api, "/test", func(_ context.Context, _ *struct{}) (*testOutput, error) {
+		return &testOutput{Body: "ok"}, nil
+	})
+
+	huma.Post(api, "/echo", func(_ context.Context, input *echoInput) (*testOutput, error) {
+		return &testOutput{Body: input.Body.URL}, nil
+	})
+
+	return router
+}
+
+func signedRequest(body string, timestamp time.Time, secret string) *http.Request {
+	ts := strconv.FormatInt(timestamp.Unix(), 10)
+
+	req := httptest.NewRequest(http.MethodPost, "/echo", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(middleware.SignatureTimestampHeader, ts)
+	req.Header.Set(middleware.SignatureHeader,
+		"sha256="+hex.EncodeToString(middleware.Sign([]byte(secret), ts, []byte(body))))
+
+	return req
+}
+
+func TestVerifySignature(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	router := setupSignatureAPI(t, now)
+	body := `{"url":"https://example.com"}`
+
+	serve := func(req *http.Request) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	t.Run("valid signature passes with the body intact", func(t *testing.T) {
+		rec := serve(signedRequest(body, now.Add(-time.Minute), signingSecret))
+
+		assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.Contains(t, rec.Body.String(), "https://example.com")
+	})
+
+	t.Run("invalid signature is rejected", func(t *testing.T) {
+		rec := serve(signedRequest(body, now, "wrong"))
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Contains(t, rec.Body.String(), "invalid request signature")
+	})
+
+	t.Run("tampered body is rejected", func(t *testing.T) {
+		req := signedRequest(body, now, signingSecret)
+		req.Body = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"url":"https://evil.com"}`)).Body
+
+		assert.Equal(t, http.StatusUnauthorized, serve(req).Code)
+	})
+
+	t.Run("expired timestamp is rejected", func(t *testing.T) {
+		rec := serve(signedRequest(body, now.Add(-6*time.Minute), signingSecret))
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Contains(t, rec.Body.String(), "outside the allowed window")
+	})
+
+	t.Run("future timestamp beyond the window is rejected", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, serve(signedRequest(body, now.Add(6*time.Minute), signingSecret)).Code)
+	})
+
+	t.Run("missing signature is rejected", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/echo", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+
+		rec := serve(req)
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+	})
+
+	t.Run("safe methods need no signature", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, serve(httptest.NewRequest(http.MethodGet, "/test", nil)).Code)
+	})
+}
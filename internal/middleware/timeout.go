@@ -0,0 +1,160 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RequestTimeoutHeader carries the client's deadline for a request, in milliseconds.
+const RequestTimeoutHeader = "X-Request-Timeout-Ms"
+
+// RequestTimeout is a router middleware that applies the deadline a client sends
+// in X-Request-Timeout-Ms to the request context, capped at maxTimeout, so
+// store and cache calls give up once the client has stopped waiting. When the
+// deadline passes before the handler responds, the client gets 504 and later
+// writes by the handler are discarded. Requests without the header are not
+// affected, and a maxTimeout of zero or less ignores the header.
+//
+// Like http.TimeoutHandler, it buffers the response of requests with a deadline,
+// which is why it wraps the router rather than the Huma API.
+func RequestTimeout(maxTimeout time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if maxTimeout <= 0 {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			raw := r.Header.Get(RequestTimeoutHeader)
+			if raw == "" {
+				next.ServeHTTP(w, r)
+
+				return
+			}
+
+			ms, err := strconv.ParseInt(raw, 10, 64)
+			if err != nil || ms <= 0 {
+				writeProblem(w, http.StatusBadRequest, RequestTimeoutHeader+" must be a positive number of milliseconds")
+
+				return
+			}
+
+			// Compare in milliseconds so huge values cannot overflow the Duration
+			timeout := maxTimeout
+			if ms < maxTimeout.Milliseconds() {
+				timeout = time.Duration(ms) * time.Millisecond
+			}
+
+			serveWithTimeout(w, r, next, timeout)
+		})
+	}
+}
+
+// serveWithTimeout runs next with a deadline and answers 504 if it passes first.
+func serveWithTimeout(w http.ResponseWriter, r *http.Request, next http.Handler, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	tw := &timeoutWriter{header: make(http.Header)}
+	done := make(chan struct{})
+	panicked := make(chan any, 1)
+
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				panicked <- p
+
+				return
+			}
+
+			close(done)
+		}()
+
+		next.ServeHTTP(tw, r.WithContext(ctx))
+	}()
+
+	select {
+	case p := <-panicked:
+		panic(p)
+	case <-done:
+	case <-ctx.Done():
+	}
+
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	// Handlers that notice the deadline answer with their own error; the client
+	// gets the 504 either way
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		tw.timedOut = true
+		writeProblem(w, http.StatusGatewayTimeout, fmt.Sprintf("request did not complete within %s", timeout))
+
+		return
+	}
+
+	if ctx.Err() != nil {
+		// The client went away; nobody is left to answer
+		tw.timedOut = true
+
+		return
+	}
+
+	tw.writeTo(w)
+}
+
+// timeoutWriter buffers a response until the handler finishes in time.
+type timeoutWriter struct {
+	mu       sync.Mutex
+	header   http.Header
+	buf      bytes.Buffer
+	status   int
+	timedOut bool
+}
+
+func (tw *timeoutWriter) Header() http.Header {
+	return tw.header
+}
+
+func (tw *timeoutWriter) WriteHeader(status int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.timedOut || tw.status != 0 {
+		return
+	}
+
+	tw.status = status
+}
+
+func (tw *timeoutWriter) Write(p []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+
+	if tw.status == 0 {
+		tw.status = http.StatusOK
+	}
+
+	return tw.buf.Write(p)
+}
+
+// writeTo copies the buffered response to w. The caller must hold mu.
+func (tw *timeoutWriter) writeTo(w http.ResponseWriter) {
+	maps.Copy(w.Header(), tw.header)
+
+	if tw.status == 0 {
+		tw.status = http.StatusOK
+	}
+
+	w.WriteHeader(tw.status)
+	_, _ = w.Write(tw.buf.Bytes())
+}
@@ -0,0 +1,128 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/adapters/humachi"
+	"github.com/go-chi/chi/v5"
+	"github.com/serroba/web-demo-go/internal/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type taggedOutput struct {
+	Tag  string `header:"X-Tag"`
+	Body string
+}
+
+func setupTimeoutAPI(maxTimeout time.Duration) (*chi.Mux, chan time.Duration) {
+	deadlines := make(chan time.Duration, 1)
+
+	router := chi.NewMux()
+	router.Use(middleware.RequestTimeout(maxTimeout))
+
+	api := humachi.New(router, huma.DefaultConfig("Test", "1.0.0"))
+
+	huma.Get(api, "/fast", func(ctx context.Context, _ *struct{}) (*taggedOutput, error) {
+		if deadline, ok := ctx.Deadline(); ok {
+			deadlines <- time.Until(deadline)
+		}
+
+		return &taggedOutput{Tag: "fast", Body: "ok"}, nil
+	})
+
+	// slow waits for its context like a store call would, or gives up after a second
+	huma.Get(api, "/slow", func(ctx context.Context, _ *struct{}) (*testOutput, error) {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(time.Second):
+			return &testOutput{Body: "late"}, nil
+		}
+	})
+
+	return router, deadlines
+}
+
+func serveWithTimeoutHeader(router http.Handler, path, timeout string) (*httptest.ResponseRecorder, time.Duration) {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if timeout != "" {
+		req.Header.Set(middleware.RequestTimeoutHeader, timeout)
+	}
+
+	rec := httptest.NewRecorder()
+	start := time.Now()
+	router.ServeHTTP(rec, req)
+
+	return rec, time.Since(start)
+}
+
+func TestRequestTimeout(t *testing.T) {
+	t.Run("header timeout fires with 504", func(t *testing.T) {
+		router, _ := setupTimeoutAPI(time.Minute)
+
+		rec, elapsed := serveWithTimeoutHeader(router, "/slow", "20")
+
+		assert.Equal(t, http.StatusGatewayTimeout, rec.Code)
+		assert.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+		assert.Contains(t, rec.Body.String(), "did not complete within 20ms")
+		assert.Less(t, elapsed, 500*time.Millisecond)
+	})
+
+	t.Run("header timeout is capped by the server maximum", func(t *testing.T) {
+		router, _ := setupTimeoutAPI(30 * time.Millisecond)
+
+		rec, elapsed := serveWithTimeoutHeader(router, "/slow", "60000")
+
+		assert.Equal(t, http.StatusGatewayTimeout, rec.Code)
+		assert.Contains(t, rec.Body.String(), "did not complete within 30ms")
+		assert.Less(t, elapsed, 500*time.Millisecond)
+	})
+
+	t.Run("handler sees the deadline on its context", func(t *testing.T) {
+		router, deadlines := setupTimeoutAPI(time.Minute)
+
+		rec, _ := serveWithTimeoutHeader(router, "/fast", "5000")
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "fast", rec.Header().Get("X-Tag"))
+		assert.Contains(t, rec.Body.String(), "ok")
+
+		remaining := <-deadlines
+		assert.LessOrEqual(t, remaining, 5*time.Second)
+		assert.Greater(t, remaining, 4*time.Second)
+	})
+
+	t.Run("requests without the header have no deadline", func(t *testing.T) {
+		router, deadlines := setupTimeoutAPI(time.Minute)
+
+		rec, _ := serveWithTimeoutHeader(router, "/fast", "")
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, deadlines)
+	})
+
+	t.Run("malformed header is rejected", func(t *testing.T) {
+		router, _ := setupTimeoutAPI(time.Minute)
+
+		for _, value := range []string{"soon", "0", "-5"} {
+			rec, _ := serveWithTimeoutHeader(router, "/fast", value)
+
+			assert.Equal(t, http.StatusBadRequest, rec.Code, value)
+		}
+	})
+
+	t.Run("zero maximum ignores the header", func(t *testing.T) {
+		router, deadlines := setupTimeoutAPI(0)
+
+		rec, _ := serveWithTimeoutHeader(router, "/fast", "soon")
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, deadlines)
+	})
+}
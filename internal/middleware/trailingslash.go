@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+)
+
+// TrailingSlashPolicy decides how a short code requested with a trailing slash,
+// such as /abc123/, is handled. Chat clients and hand-typed links often add one,
+// and without a policy the path matches no route.
+type TrailingSlashPolicy string
+
+const (
+	// TrailingSlashStrip serves /abc123/ as if /abc123 had been requested (default).
+	TrailingSlashStrip TrailingSlashPolicy = "strip"
+	// TrailingSlashRedirect answers 308 with the slash removed.
+	TrailingSlashRedirect TrailingSlashPolicy = "redirect"
+	// TrailingSlashOff leaves the path alone, so /abc123/ is not found.
+	TrailingSlashOff TrailingSlashPolicy = "off"
+)
+
+// IsValidTrailingSlashPolicy reports whether policy is a known trailing slash policy.
+func IsValidTrailingSlashPolicy(policy TrailingSlashPolicy) bool {
+	switch policy {
+	case TrailingSlashStrip, TrailingSlashRedirect, TrailingSlashOff:
+		return true
+	default:
+		return false
+	}
+}
+
+// TrailingSlash is a router middleware that applies policy to single-segment
+// paths ending in one slash, the shape of a short code. Deeper paths, such as
+// /api/urls/recent/, and the root are never changed. It wraps the router rather
+// than the Huma API because the path has to change before routing.
+func TrailingSlash(policy TrailingSlashPolicy) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if policy == TrailingSlashOff {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			trimmed, ok := trimCodeSlash(r.URL.Path)
+			if !ok {
+				next.ServeHTTP(w, r)
+
+				return
+			}
+
+			if policy == TrailingSlashRedirect {
+				target := *r.URL
+				target.Path = trimmed
+				target.RawPath = ""
+
+				http.Redirect(w, r, target.RequestURI(), http.StatusPermanentRedirect)
+
+				return
+			}
+
+			r2 := r.Clone(r.Context())
+			r2.URL.Path = trimmed
+			r2.URL.RawPath = ""
+			r2.RequestURI = r2.URL.RequestURI()
+
+			next.ServeHTTP(w, r2)
+		})
+	}
+}
+
+// trimCodeSlash returns path without its trailing slash when it is a single
+// non-empty segment followed by exactly one slash.
+func trimCodeSlash(path string) (string, bool) {
+	segment, ok := strings.CutSuffix(strings.TrimPrefix(path, "/"), "/")
+	if !ok || segment == "" || strings.Contains(segment, "/") {
+		return "", false
+	}
+
+	return "/" + segment, true
+}
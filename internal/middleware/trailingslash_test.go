@@ -0,0 +1,108 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/adapters/humachi"
+	"github.com/go-chi/chi/v5"
+	"github.com/serroba/web-demo-go/internal/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+type codeInput struct {
+	Code string `path:"code"`
+}
+
+func setupTrailingSlashAPI(policy middleware.TrailingSlashPolicy) *chi.Mux {
+	router := chi.NewMux()
+	router.Use(middleware.TrailingSlash(policy))
+
+	api := humachi.New(router, huma.DefaultConfig("Test", "1.0.0"))
+
+	huma.Get(api, "/health", func(_ context.Context, _ *struct{}) (*testOutput, error) {
+		return &testOutput{Body: "healthy"}, nil
+	})
+
+	huma.Get(api, "/api/urls/recent", func(_ context.Context, _ *struct{}) (*testOutput, error) {
+		return &testOutput{Body: "recent"}, nil
+	})
+
+	huma.Get(api, "/{code}", func(_ context.Context, input *codeInput) (*testOutput, error) {
+		return &testOutput{Body: "code:" + input.Code}, nil
+	})
+
+	return router
+}
+
+func serveTrailingSlash(router http.Handler, target string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+
+	return rec
+}
+
+func TestTrailingSlash_Strip(t *testing.T) {
+	router := setupTrailingSlashAPI(middleware.TrailingSlashStrip)
+
+	t.Run("code with trailing slash resolves like the code", func(t *testing.T) {
+		rec := serveTrailingSlash(router, "/abc123/")
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "code:abc123")
+	})
+
+	t.Run("query string is kept", func(t *testing.T) {
+		rec := serveTrailingSlash(router, "/abc123/?utm=x")
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "code:abc123")
+	})
+
+	t.Run("code without trailing slash is unchanged", func(t *testing.T) {
+		assert.Contains(t, serveTrailingSlash(router, "/abc123").Body.String(), "code:abc123")
+	})
+
+	t.Run("health is unaffected", func(t *testing.T) {
+		rec := serveTrailingSlash(router, "/health")
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "healthy")
+	})
+
+	t.Run("deeper paths are not rewritten", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, serveTrailingSlash(router, "/api/urls/recent").Code)
+		assert.Equal(t, http.StatusNotFound, serveTrailingSlash(router, "/api/urls/recent/").Code)
+	})
+
+	t.Run("double slash is not rewritten", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, serveTrailingSlash(router, "/abc123//").Code)
+	})
+}
+
+func TestTrailingSlash_Redirect(t *testing.T) {
+	router := setupTrailingSlashAPI(middleware.TrailingSlashRedirect)
+
+	rec := serveTrailingSlash(router, "/abc123/?utm=x")
+
+	assert.Equal(t, http.StatusPermanentRedirect, rec.Code)
+	assert.Equal(t, "/abc123?utm=x", rec.Header().Get("Location"))
+	assert.Equal(t, http.StatusOK, serveTrailingSlash(router, "/health").Code)
+}
+
+func TestTrailingSlash_Off(t *testing.T) {
+	router := setupTrailingSlashAPI(middleware.TrailingSlashOff)
+
+	assert.Equal(t, http.StatusNotFound, serveTrailingSlash(router, "/abc123/").Code)
+	assert.Equal(t, http.StatusOK, serveTrailingSlash(router, "/abc123").Code)
+}
+
+func TestIsValidTrailingSlashPolicy(t *testing.T) {
+	assert.True(t, middleware.IsValidTrailingSlashPolicy(middleware.TrailingSlashStrip))
+	assert.True(t, middleware.IsValidTrailingSlashPolicy(middleware.TrailingSlashRedirect))
+	assert.True(t, middleware.IsValidTrailingSlashPolicy(middleware.TrailingSlashOff))
+	assert.False(t, middleware.IsValidTrailingSlashPolicy("keep"))
+}
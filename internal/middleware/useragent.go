@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// MissingUserAgentPolicy decides how requests without a User-Agent are treated.
+// Rate limit keys combine the client IP with the User-Agent, so clients that
+// omit it are only told apart by a (possibly forwarded) IP.
+type MissingUserAgentPolicy string
+
+const (
+	// MissingUserAgentAllow rate limits them by client IP like any other request (default).
+	MissingUserAgentAllow MissingUserAgentPolicy = "allow"
+	// MissingUserAgentReject answers 400 before rate limiting.
+	MissingUserAgentReject MissingUserAgentPolicy = "reject"
+	// MissingUserAgentPeer rate limits them by the connection's peer address,
+	// ignoring X-Forwarded-For and X-Real-IP, which clients can rotate freely.
+	MissingUserAgentPeer MissingUserAgentPolicy = "peer"
+)
+
+// IsValidMissingUserAgentPolicy reports whether policy is a known missing User-Agent policy.
+func IsValidMissingUserAgentPolicy(policy MissingUserAgentPolicy) bool {
+	switch policy {
+	case MissingUserAgentAllow, MissingUserAgentReject, MissingUserAgentPeer:
+		return true
+	default:
+		return false
+	}
+}
+
+// RequireUserAgent is a middleware that rejects requests with an empty or
+// blank User-Agent header with 400.
+func RequireUserAgent(api huma.API) func(ctx huma.Context, next func(huma.Context)) {
+	return func(ctx huma.Context, next func(huma.Context)) {
+		if strings.TrimSpace(ctx.Header("User-Agent")) == "" {
+			_ = huma.WriteErr(api, ctx, http.StatusBadRequest, "User-Agent header is required")
+
+			return
+		}
+
+		next(ctx)
+	}
+}
@@ -0,0 +1,53 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/adapters/humachi"
+	"github.com/go-chi/chi/v5"
+	"github.com/serroba/web-demo-go/internal/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequireUserAgent(t *testing.T) {
+	router := chi.NewMux()
+	api := humachi.New(router, huma.DefaultConfig("Test", "1.0.0"))
+	api.UseMiddleware(middleware.RequireUserAgent(api))
+
+	huma.Get(api, "/test", func(_ context.Context, _ *struct{}) (*testOutput, error) {
+		return &testOutput{Body: "ok"}, nil
+	})
+
+	tests := []struct {
+		name      string
+		userAgent string
+		want      int
+	}{
+		{name: "user agent passes", userAgent: testUserAgent, want: http.StatusOK},
+		{name: "missing user agent is rejected", userAgent: "", want: http.StatusBadRequest},
+		{name: "blank user agent is rejected", userAgent: "   ", want: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/test", nil)
+			req.Header.Set("User-Agent", tt.userAgent)
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.want, rec.Code)
+		})
+	}
+}
+
+func TestIsValidMissingUserAgentPolicy(t *testing.T) {
+	assert.True(t, middleware.IsValidMissingUserAgentPolicy(middleware.MissingUserAgentAllow))
+	assert.True(t, middleware.IsValidMissingUserAgentPolicy(middleware.MissingUserAgentReject))
+	assert.True(t, middleware.IsValidMissingUserAgentPolicy(middleware.MissingUserAgentPeer))
+	assert.False(t, middleware.IsValidMissingUserAgentPolicy("block"))
+}
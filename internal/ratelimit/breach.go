@@ -0,0 +1,155 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/serroba/web-demo-go/internal/clock"
+)
+
+// Breach describes a client that kept exceeding its rate limits.
+type Breach struct {
+	// ClientKey is the rate limit key of the client.
+	ClientKey string
+	// ClientIP is the client IP the key was derived from.
+	ClientIP string
+	// Path is the route template of the request that crossed the threshold.
+	Path string
+	// Threshold is the number of denials within Window the client exceeded.
+	Threshold int
+	// Window is the period the denials were counted over.
+	Window time.Duration
+}
+
+// BreachNotifier is told when a client is denied more often than the
+// configured threshold, e.g. to alert a security team.
+type BreachNotifier interface {
+	NotifyBreach(ctx context.Context, breach Breach)
+}
+
+// NopBreachNotifier is a BreachNotifier that discards all breaches.
+type NopBreachNotifier struct{}
+
+// NotifyBreach implements BreachNotifier.
+func (NopBreachNotifier) NotifyBreach(context.Context, Breach) {}
+
+// BreachTracker counts denied requests per client over a sliding window and
+// reports when a client crosses the threshold. A client is reported once per
+// crossing; it is reported again only after its denials have dropped back to
+// the threshold and climbed past it.
+type BreachTracker struct {
+	mu        sync.Mutex
+	threshold int
+	window    time.Duration
+	clock     clock.Clock
+	clients   map[string]*breachClient
+	lastSweep time.Time
+}
+
+// breachClient holds the most recent denials of one client, at most
+// threshold+1 of them, and whether its current crossing was reported.
+type breachClient struct {
+	denials  []time.Time
+	reported bool
+}
+
+// BreachTrackerOption configures optional BreachTracker behavior.
+type BreachTrackerOption func(*BreachTracker)
+
+// WithBreachClock sets the clock used to age out denials.
+func WithBreachClock(c clock.Clock) BreachTrackerOption {
+	return func(t *BreachTracker) {
+		t.clock = c
+	}
+}
+
+// NewBreachTracker creates a tracker reporting clients denied more than
+// threshold times within window.
+func NewBreachTracker(threshold int, window time.Duration, opts ...BreachTrackerOption) *BreachTracker {
+	t := &BreachTracker{
+		threshold: threshold,
+		window:    window,
+		clock:     clock.Real{},
+		clients:   make(map[string]*breachClient),
+	}
+
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	t.lastSweep = t.clock.Now()
+
+	return t
+}
+
+// Threshold returns the number of denials within the window a client may
+// have before it is reported.
+func (t *BreachTracker) Threshold() int {
+	return t.threshold
+}
+
+// Window returns the period denials are counted over.
+func (t *BreachTracker) Window() time.Duration {
+	return t.window
+}
+
+// Record counts a denied request for key and reports whether it crossed the
+// threshold.
+func (t *BreachTracker) Record(key string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.clock.Now()
+	cutoff := now.Add(-t.window)
+
+	if now.Sub(t.lastSweep) >= t.window {
+		t.sweep(cutoff)
+		t.lastSweep = now
+	}
+
+	client, ok := t.clients[key]
+	if !ok {
+		client = &breachClient{}
+		t.clients[key] = client
+	}
+
+	client.denials = prune(client.denials, cutoff)
+	if len(client.denials) <= t.threshold {
+		client.reported = false
+	}
+
+	client.denials = append(client.denials, now)
+	if len(client.denials) > t.threshold+1 {
+		client.denials = client.denials[len(client.denials)-t.threshold-1:]
+	}
+
+	if len(client.denials) <= t.threshold || client.reported {
+		return false
+	}
+
+	client.reported = true
+
+	return true
+}
+
+// sweep forgets clients with no denials after cutoff, bounding memory to the
+// clients denied within the last window.
+func (t *BreachTracker) sweep(cutoff time.Time) {
+	for key, client := range t.clients {
+		if len(prune(client.denials, cutoff)) == 0 {
+			delete(t.clients, key)
+		}
+	}
+}
+
+// prune drops the denials at or before cutoff. Denials are kept in order, so
+// the expired ones form a prefix.
+func prune(denials []time.Time, cutoff time.Time) []time.Time {
+	i := 0
+	for i < len(denials) && !denials[i].After(cutoff) {
+		i++
+	}
+
+	return denials[i:]
+}
@@ -0,0 +1,54 @@
+package ratelimit_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/serroba/web-demo-go/internal/clock"
+	"github.com/serroba/web-demo-go/internal/ratelimit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreachTracker(t *testing.T) {
+	t.Run("reports once when a client passes the threshold", func(t *testing.T) {
+		tracker := ratelimit.NewBreachTracker(3, time.Minute, ratelimit.WithBreachClock(clock.NewFake(time.Now())))
+
+		for range 3 {
+			assert.False(t, tracker.Record("client"))
+		}
+
+		assert.True(t, tracker.Record("client"))
+		assert.False(t, tracker.Record("client"), "the same crossing is reported once")
+	})
+
+	t.Run("counts clients separately", func(t *testing.T) {
+		tracker := ratelimit.NewBreachTracker(1, time.Minute, ratelimit.WithBreachClock(clock.NewFake(time.Now())))
+
+		assert.False(t, tracker.Record("a"))
+		assert.False(t, tracker.Record("b"))
+		assert.True(t, tracker.Record("a"))
+	})
+
+	t.Run("ignores denials spread beyond the window", func(t *testing.T) {
+		c := clock.NewFake(time.Now())
+		tracker := ratelimit.NewBreachTracker(1, time.Minute, ratelimit.WithBreachClock(c))
+
+		for range 5 {
+			assert.False(t, tracker.Record("client"))
+			c.Advance(time.Minute)
+		}
+	})
+
+	t.Run("reports again after the client calms down", func(t *testing.T) {
+		c := clock.NewFake(time.Now())
+		tracker := ratelimit.NewBreachTracker(1, time.Minute, ratelimit.WithBreachClock(c))
+
+		assert.False(t, tracker.Record("client"))
+		assert.True(t, tracker.Record("client"))
+
+		c.Advance(2 * time.Minute)
+
+		assert.False(t, tracker.Record("client"))
+		assert.True(t, tracker.Record("client"))
+	})
+}
@@ -0,0 +1,67 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+
+	"github.com/serroba/web-demo-go/internal/clock"
+)
+
+// TokenBucket is an in-process token bucket shared by every caller, for global
+// limits that protect a backend rather than limit individual clients.
+type TokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	clock  clock.Clock
+}
+
+// TokenBucketOption configures optional TokenBucket behavior.
+type TokenBucketOption func(*TokenBucket)
+
+// WithBucketClock sets the clock used to refill the bucket.
+func WithBucketClock(c clock.Clock) TokenBucketOption {
+	return func(b *TokenBucket) {
+		b.clock = c
+	}
+}
+
+// NewTokenBucket creates a full bucket refilled at rate tokens per second and
+// holding at most burst tokens. A burst below one is raised to one.
+func NewTokenBucket(rate float64, burst int, opts ...TokenBucketOption) *TokenBucket {
+	b := &TokenBucket{
+		rate:  rate,
+		burst: float64(max(burst, 1)),
+		clock: clock.Real{},
+	}
+
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	b.tokens = b.burst
+	b.last = b.clock.Now()
+
+	return b
+}
+
+// Take removes a token if one is available. Otherwise it reports how long until
+// the next token is due.
+func (b *TokenBucket) Take() (bool, time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.clock.Now()
+	b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+
+		return true, 0
+	}
+
+	return false, time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+}
@@ -0,0 +1,61 @@
+package ratelimit_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/serroba/web-demo-go/internal/clock"
+	"github.com/serroba/web-demo-go/internal/ratelimit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenBucket(t *testing.T) {
+	t.Run("allows the burst then throttles", func(t *testing.T) {
+		b := ratelimit.NewTokenBucket(10, 3, ratelimit.WithBucketClock(clock.NewFake(time.Now())))
+
+		for range 3 {
+			ok, _ := b.Take()
+			assert.True(t, ok)
+		}
+
+		ok, wait := b.Take()
+		assert.False(t, ok)
+		assert.Equal(t, 100*time.Millisecond, wait)
+	})
+
+	t.Run("refills at the configured rate", func(t *testing.T) {
+		c := clock.NewFake(time.Now())
+		b := ratelimit.NewTokenBucket(10, 1, ratelimit.WithBucketClock(c))
+
+		ok, _ := b.Take()
+		assert.True(t, ok)
+
+		c.Advance(50 * time.Millisecond)
+
+		ok, wait := b.Take()
+		assert.False(t, ok)
+		assert.Equal(t, 50*time.Millisecond, wait)
+
+		c.Advance(50 * time.Millisecond)
+
+		ok, _ = b.Take()
+		assert.True(t, ok)
+	})
+
+	t.Run("never holds more than the burst", func(t *testing.T) {
+		c := clock.NewFake(time.Now())
+		b := ratelimit.NewTokenBucket(10, 2, ratelimit.WithBucketClock(c))
+
+		c.Advance(time.Hour)
+
+		allowed := 0
+
+		for range 5 {
+			if ok, _ := b.Take(); ok {
+				allowed++
+			}
+		}
+
+		assert.Equal(t, 2, allowed)
+	})
+}
@@ -13,13 +13,13 @@ type Limiter interface {
 
 // SlidingWindowLimiter implements rate limiting using a sliding window algorithm.
 type SlidingWindowLimiter struct {
-	store  Store
+	store  SlidingWindowStore
 	limit  int64
 	window time.Duration
 }
 
 // NewSlidingWindowLimiter creates a new sliding window rate limiter.
-func NewSlidingWindowLimiter(store Store, limit int64, window time.Duration) *SlidingWindowLimiter {
+func NewSlidingWindowLimiter(store SlidingWindowStore, limit int64, window time.Duration) *SlidingWindowLimiter {
 	return &SlidingWindowLimiter{
 		store:  store,
 		limit:  limit,
@@ -35,3 +35,28 @@ func (l *SlidingWindowLimiter) Allow(ctx context.Context, key string) (bool, err
 
 	return count <= l.limit, nil
 }
+
+// FixedWindowLimiter implements rate limiting with one counter per aligned window.
+type FixedWindowLimiter struct {
+	store  FixedWindowStore
+	limit  int64
+	window time.Duration
+}
+
+// NewFixedWindowLimiter creates a new fixed window rate limiter.
+func NewFixedWindowLimiter(store FixedWindowStore, limit int64, window time.Duration) *FixedWindowLimiter {
+	return &FixedWindowLimiter{
+		store:  store,
+		limit:  limit,
+		window: window,
+	}
+}
+
+func (l *FixedWindowLimiter) Allow(ctx context.Context, key string) (bool, error) {
+	count, err := l.store.Record(ctx, key, l.window)
+	if err != nil {
+		return false, err
+	}
+
+	return count <= l.limit, nil
+}
@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/serroba/web-demo-go/internal/clock"
 	"github.com/serroba/web-demo-go/internal/ratelimit"
 	"github.com/serroba/web-demo-go/internal/ratelimit/store"
 	"github.com/stretchr/testify/assert"
@@ -65,8 +66,9 @@ func TestSlidingWindowLimiter(t *testing.T) {
 	})
 
 	t.Run("allows requests after window expires", func(t *testing.T) {
-		memStore := store.NewMemory()
-		limiter := ratelimit.NewSlidingWindowLimiter(memStore, 2, 50*time.Millisecond)
+		c := clock.NewFake(time.Now())
+		memStore := store.NewMemory(store.WithClock(c))
+		limiter := ratelimit.NewSlidingWindowLimiter(memStore, 2, time.Minute)
 
 		// Use up the limit
 		for range 2 {
@@ -77,8 +79,8 @@ func TestSlidingWindowLimiter(t *testing.T) {
 		allowed, _ := limiter.Allow(context.Background(), "client1")
 		assert.False(t, allowed, "should be rate limited")
 
-		// Wait for window to expire
-		time.Sleep(60 * time.Millisecond)
+		// Move past the window
+		c.Advance(time.Minute + time.Second)
 
 		// Should be allowed again
 		allowed, err := limiter.Allow(context.Background(), "client1")
@@ -107,3 +109,73 @@ type mockRateLimitStore struct {
 func (m *mockRateLimitStore) Record(_ context.Context, _ string, _ time.Duration) (int64, error) {
 	return m.count, m.err
 }
+
+func (m *mockRateLimitStore) Peek(_ context.Context, _ string, _ time.Duration) (int64, error) {
+	return m.count, m.err
+}
+
+func (m *mockRateLimitStore) SlidingWindow() {}
+
+// fixedWindowStore counts requests per key and aligned window, like the Postgres store.
+type fixedWindowStore struct {
+	clock  clock.Clock
+	counts map[string]int64
+}
+
+func (f *fixedWindowStore) Record(ctx context.Context, key string, window time.Duration) (int64, error) {
+	count, _ := f.Peek(ctx, key, window)
+	f.counts[f.bucket(key, window)] = count + 1
+
+	return count + 1, nil
+}
+
+func (f *fixedWindowStore) Peek(_ context.Context, key string, window time.Duration) (int64, error) {
+	return f.counts[f.bucket(key, window)], nil
+}
+
+func (f *fixedWindowStore) FixedWindow() {}
+
+func (f *fixedWindowStore) bucket(key string, window time.Duration) string {
+	return key + "@" + f.clock.Now().Truncate(window).String()
+}
+
+func TestFixedWindowLimiter(t *testing.T) {
+	t.Run("resets at the window boundary", func(t *testing.T) {
+		fake := clock.NewFake(time.Date(2026, 10, 16, 12, 0, 59, 0, time.UTC))
+		limiter := ratelimit.NewFixedWindowLimiter(
+			&fixedWindowStore{clock: fake, counts: map[string]int64{}}, 2, time.Minute)
+
+		for range 2 {
+			allowed, err := limiter.Allow(context.Background(), "client1")
+			require.NoError(t, err)
+			assert.True(t, allowed)
+		}
+
+		allowed, err := limiter.Allow(context.Background(), "client1")
+		require.NoError(t, err)
+		assert.False(t, allowed)
+
+		// One second later a new fixed window starts, unlike a sliding window
+		fake.Advance(time.Second)
+
+		allowed, err = limiter.Allow(context.Background(), "client1")
+		require.NoError(t, err)
+		assert.True(t, allowed)
+	})
+}
+
+func TestStoreAlgorithms(t *testing.T) {
+	stores := map[string]ratelimit.Store{
+		"memory":   store.NewMemory(),
+		"redis":    store.NewRedis(nil),
+		"postgres": store.NewPostgres(nil),
+	}
+
+	for name, s := range stores {
+		_, sliding := s.(ratelimit.SlidingWindowStore)
+		_, fixed := s.(ratelimit.FixedWindowStore)
+
+		assert.Equal(t, name != "postgres", sliding, name)
+		assert.Equal(t, name == "postgres", fixed, name)
+	}
+}
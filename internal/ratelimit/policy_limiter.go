@@ -3,6 +3,8 @@ package ratelimit
 import (
 	"context"
 	"fmt"
+	"slices"
+	"sync/atomic"
 )
 
 // LimitExceeded contains information about which limit was exceeded.
@@ -12,33 +14,69 @@ type LimitExceeded struct {
 	Count  int64
 }
 
+type operationKey struct{}
+
+// WithOperation returns a copy of ctx whose requests PolicyLimiter counts in
+// buckets of their own for operationID, instead of sharing the client's
+// scope buckets with every other operation.
+func WithOperation(ctx context.Context, operationID string) context.Context {
+	return context.WithValue(ctx, operationKey{}, operationID)
+}
+
+// OperationFromContext returns the operation stored in ctx by WithOperation, if any.
+func OperationFromContext(ctx context.Context) (string, bool) {
+	operationID, ok := ctx.Value(operationKey{}).(string)
+
+	return operationID, ok && operationID != ""
+}
+
 // PolicyLimiter enforces rate limits based on a policy and resolved scopes.
+// The policy can be replaced at runtime with SetPolicy.
 type PolicyLimiter struct {
 	store  Store
-	policy *Policy
+	policy atomic.Pointer[Policy]
 }
 
 // NewPolicyLimiter creates a new policy-based rate limiter.
 func NewPolicyLimiter(store Store, policy *Policy) *PolicyLimiter {
-	return &PolicyLimiter{
-		store:  store,
-		policy: policy,
-	}
+	l := &PolicyLimiter{store: store}
+	l.policy.Store(policy)
+
+	return l
+}
+
+// Policy returns the policy currently enforced.
+func (l *PolicyLimiter) Policy() *Policy {
+	return l.policy.Load()
+}
+
+// SetPolicy atomically replaces the enforced policy. Requests already being
+// checked finish against the old policy. Counts in windows that both policies
+// define carry over, since bucket keys only depend on scope and window.
+// Callers should validate the policy with ValidatePolicy first.
+func (l *PolicyLimiter) SetPolicy(policy *Policy) {
+	l.policy.Store(policy)
 }
 
 // Allow checks if a request should be allowed based on the client key and applicable scopes.
 // It returns true if the request is allowed, false if any limit is exceeded.
 // The LimitExceeded return value provides details about which limit was hit (nil if allowed).
 func (l *PolicyLimiter) Allow(ctx context.Context, clientKey string, scopes []Scope) (bool, *LimitExceeded, error) {
+	if batch, ok := l.store.(BatchStore); ok {
+		return l.allowBatch(ctx, batch, clientKey, scopes)
+	}
+
+	policy := l.policy.Load()
+
 	for _, scope := range scopes {
-		limits, ok := l.policy.Limits[scope]
+		limits, ok := policy.Limits[scope]
 		if !ok {
 			continue
 		}
 
 		for _, limit := range limits {
 			// Key combines client + scope + window for independent tracking
-			key := l.buildKey(clientKey, scope, limit)
+			key := l.buildKey(ctx, clientKey, scope, limit)
 
 			count, err := l.store.Record(ctx, key, limit.Window)
 			if err != nil {
@@ -58,8 +96,95 @@ func (l *PolicyLimiter) Allow(ctx context.Context, clientKey string, scopes []Sc
 	return true, nil, nil
 }
 
-// buildKey creates a unique rate limit key for the client, scope, and window combination.
-func (l *PolicyLimiter) buildKey(clientKey string, scope Scope, limit LimitConfig) string {
+// allowBatch records every applicable window in one RecordBatch call and then
+// reports the first exceeded limit. Unlike the sequential path, all windows are
+// recorded even when an earlier one is already over its limit.
+func (l *PolicyLimiter) allowBatch(
+	ctx context.Context,
+	batch BatchStore,
+	clientKey string,
+	scopes []Scope,
+) (bool, *LimitExceeded, error) {
+	var (
+		reqs    []RecordRequest
+		applied []LimitExceeded
+	)
+
+	policy := l.policy.Load()
+
+	for _, scope := range scopes {
+		for _, limit := range policy.Limits[scope] {
+			reqs = append(reqs, RecordRequest{Key: l.buildKey(ctx, clientKey, scope, limit), Window: limit.Window})
+			applied = append(applied, LimitExceeded{Scope: scope, Config: limit})
+		}
+	}
+
+	if len(reqs) == 0 {
+		return true, nil, nil
+	}
+
+	counts, err := batch.RecordBatch(ctx, reqs)
+	if err != nil {
+		return false, nil, err
+	}
+
+	for i, count := range counts {
+		if count > applied[i].Config.Max {
+			exceeded := applied[i]
+			exceeded.Count = count
+
+			return false, &exceeded, nil
+		}
+	}
+
+	return true, nil, nil
+}
+
+// BucketCount reports the current usage of one scope/window bucket for a client.
+type BucketCount struct {
+	Scope  Scope
+	Config LimitConfig
+	Key    string
+	Count  int64
+}
+
+// Inspect returns the current count for every bucket in the policy for clientKey
+// without recording a request. Buckets are ordered by scope name, then policy order.
+func (l *PolicyLimiter) Inspect(ctx context.Context, clientKey string) ([]BucketCount, error) {
+	policy := l.policy.Load()
+
+	scopes := make([]Scope, 0, len(policy.Limits))
+	for scope := range policy.Limits {
+		scopes = append(scopes, scope)
+	}
+
+	slices.Sort(scopes)
+
+	var buckets []BucketCount
+
+	for _, scope := range scopes {
+		for _, limit := range policy.Limits[scope] {
+			key := l.buildKey(ctx, clientKey, scope, limit)
+
+			count, err := l.store.Peek(ctx, key, limit.Window)
+			if err != nil {
+				return nil, err
+			}
+
+			buckets = append(buckets, BucketCount{Scope: scope, Config: limit, Key: key, Count: count})
+		}
+	}
+
+	return buckets, nil
+}
+
+// buildKey creates a unique rate limit key for the client, scope, and window
+// combination, narrowed to the operation set by WithOperation, if any.
+func (l *PolicyLimiter) buildKey(ctx context.Context, clientKey string, scope Scope, limit LimitConfig) string {
+	if operationID, ok := OperationFromContext(ctx); ok {
+		return fmt.Sprintf("%s:op:%s:%s:%d", clientKey, operationID, scope, limit.Window.Milliseconds())
+	}
+
 	return fmt.Sprintf("%s:%s:%d", clientKey, scope, limit.Window.Milliseconds())
 }
 
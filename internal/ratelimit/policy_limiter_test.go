@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/serroba/web-demo-go/internal/ratelimit"
+	"github.com/serroba/web-demo-go/internal/ratelimit/store"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -30,6 +31,14 @@ func (m *mockStore) Record(_ context.Context, key string, _ time.Duration) (int6
 	return m.counts[key], nil
 }
 
+func (m *mockStore) Peek(_ context.Context, key string, _ time.Duration) (int64, error) {
+	if m.err != nil {
+		return 0, m.err
+	}
+
+	return m.counts[key], nil
+}
+
 func TestPolicyLimiter_AllowsRequestsUnderLimit(t *testing.T) {
 	t.Parallel()
 
@@ -128,6 +137,34 @@ func TestPolicyLimiter_IndependentClientTracking(t *testing.T) {
 	}
 }
 
+func TestPolicyLimiter_OperationKeyedTracking(t *testing.T) {
+	t.Parallel()
+
+	store := newMockStore()
+	policy := ratelimit.NewPolicyBuilder().
+		AddLimit(ratelimit.ScopeGlobal, 1, time.Minute).
+		Build()
+
+	limiter := ratelimit.NewPolicyLimiter(store, policy)
+	scopes := []ratelimit.Scope{ratelimit.ScopeGlobal}
+	getCtx := ratelimit.WithOperation(context.Background(), "get-url")
+	deleteCtx := ratelimit.WithOperation(context.Background(), "delete-url")
+
+	allowed, _, _ := limiter.Allow(getCtx, "client1", scopes)
+	assert.True(t, allowed)
+
+	allowed, _, _ = limiter.Allow(getCtx, "client1", scopes)
+	assert.False(t, allowed)
+
+	// Another operation has a bucket of its own
+	allowed, _, _ = limiter.Allow(deleteCtx, "client1", scopes)
+	assert.True(t, allowed)
+
+	// So do requests without an operation
+	allowed, _, _ = limiter.Allow(context.Background(), "client1", scopes)
+	assert.True(t, allowed)
+}
+
 func TestPolicyLimiter_SkipsUndefinedScopes(t *testing.T) {
 	t.Parallel()
 
@@ -204,3 +241,195 @@ func TestPolicyLimiter_EmptyScopes(t *testing.T) {
 	assert.True(t, allowed)
 	assert.Nil(t, exceeded)
 }
+
+type mockBatchStore struct {
+	mockStore
+	batchCalls int
+	batchErr   error
+}
+
+func (m *mockBatchStore) RecordBatch(_ context.Context, reqs []ratelimit.RecordRequest) ([]int64, error) {
+	m.batchCalls++
+
+	if m.batchErr != nil {
+		return nil, m.batchErr
+	}
+
+	counts := make([]int64, len(reqs))
+	for i, req := range reqs {
+		m.counts[req.Key]++
+		counts[i] = m.counts[req.Key]
+	}
+
+	return counts, nil
+}
+
+func TestPolicyLimiter_UsesBatchStore(t *testing.T) {
+	t.Parallel()
+
+	policy := ratelimit.NewPolicyBuilder().
+		AddLimit(ratelimit.ScopeWrite, 2, time.Minute).
+		AddLimit(ratelimit.ScopeWrite, 100, time.Hour).
+		AddLimit(ratelimit.ScopeWrite, 500, 24*time.Hour).
+		Build()
+	scopes := []ratelimit.Scope{ratelimit.ScopeGlobal, ratelimit.ScopeWrite}
+
+	t.Run("records all windows in a single batch", func(t *testing.T) {
+		t.Parallel()
+
+		store := &mockBatchStore{mockStore: *newMockStore()}
+		limiter := ratelimit.NewPolicyLimiter(store, policy)
+
+		allowed, exceeded, err := limiter.Allow(context.Background(), "client1", scopes)
+
+		require.NoError(t, err)
+		assert.True(t, allowed)
+		assert.Nil(t, exceeded)
+		assert.Equal(t, 1, store.batchCalls)
+		assert.Len(t, store.counts, 3)
+	})
+
+	t.Run("reports exceeded limit from batch counts", func(t *testing.T) {
+		t.Parallel()
+
+		store := &mockBatchStore{mockStore: *newMockStore()}
+		limiter := ratelimit.NewPolicyLimiter(store, policy)
+
+		for range 2 {
+			_, _, _ = limiter.Allow(context.Background(), "client1", scopes)
+		}
+
+		allowed, exceeded, err := limiter.Allow(context.Background(), "client1", scopes)
+
+		require.NoError(t, err)
+		assert.False(t, allowed)
+		require.NotNil(t, exceeded)
+		assert.Equal(t, ratelimit.ScopeWrite, exceeded.Scope)
+		assert.Equal(t, time.Minute, exceeded.Config.Window)
+		assert.Equal(t, int64(3), exceeded.Count)
+	})
+
+	t.Run("returns batch error", func(t *testing.T) {
+		t.Parallel()
+
+		store := &mockBatchStore{mockStore: *newMockStore(), batchErr: errors.New("redis down")}
+		limiter := ratelimit.NewPolicyLimiter(store, policy)
+
+		allowed, _, err := limiter.Allow(context.Background(), "client1", scopes)
+
+		require.Error(t, err)
+		assert.False(t, allowed)
+	})
+
+	t.Run("skips batch when no limits apply", func(t *testing.T) {
+		t.Parallel()
+
+		store := &mockBatchStore{mockStore: *newMockStore()}
+		limiter := ratelimit.NewPolicyLimiter(store, policy)
+
+		allowed, _, err := limiter.Allow(context.Background(), "client1", []ratelimit.Scope{ratelimit.ScopeRead})
+
+		require.NoError(t, err)
+		assert.True(t, allowed)
+		assert.Zero(t, store.batchCalls)
+	})
+}
+
+func TestPolicyLimiter_Inspect(t *testing.T) {
+	t.Parallel()
+
+	policy := ratelimit.NewPolicyBuilder().
+		AddLimit(ratelimit.ScopeWrite, 10, time.Minute).
+		AddLimit(ratelimit.ScopeWrite, 100, time.Hour).
+		AddLimit(ratelimit.ScopeRead, 50, time.Minute).
+		Build()
+
+	t.Run("reports counts and keys per bucket", func(t *testing.T) {
+		t.Parallel()
+
+		memStore := store.NewMemory()
+		ctx := context.Background()
+
+		for range 3 {
+			_, _ = memStore.Record(ctx, "client:write:60000", time.Minute)
+		}
+
+		for range 5 {
+			_, _ = memStore.Record(ctx, "client:write:3600000", time.Hour)
+		}
+
+		_, _ = memStore.Record(ctx, "client:read:60000", time.Minute)
+
+		limiter := ratelimit.NewPolicyLimiter(memStore, policy)
+
+		buckets, err := limiter.Inspect(ctx, "client")
+
+		require.NoError(t, err)
+		require.Len(t, buckets, 3)
+
+		assert.Equal(t, ratelimit.ScopeRead, buckets[0].Scope)
+		assert.Equal(t, "client:read:60000", buckets[0].Key)
+		assert.Equal(t, int64(1), buckets[0].Count)
+		assert.Equal(t, int64(50), buckets[0].Config.Max)
+
+		assert.Equal(t, ratelimit.ScopeWrite, buckets[1].Scope)
+		assert.Equal(t, "client:write:60000", buckets[1].Key)
+		assert.Equal(t, int64(3), buckets[1].Count)
+
+		assert.Equal(t, ratelimit.ScopeWrite, buckets[2].Scope)
+		assert.Equal(t, "client:write:3600000", buckets[2].Key)
+		assert.Equal(t, int64(5), buckets[2].Count)
+
+		// Inspecting must not consume quota
+		again, err := limiter.Inspect(ctx, "client")
+
+		require.NoError(t, err)
+		assert.Equal(t, buckets, again)
+	})
+
+	t.Run("propagates store errors", func(t *testing.T) {
+		t.Parallel()
+
+		limiter := ratelimit.NewPolicyLimiter(&mockStore{err: errors.New("boom")}, policy)
+
+		buckets, err := limiter.Inspect(context.Background(), "client")
+
+		require.Error(t, err)
+		assert.Nil(t, buckets)
+	})
+}
+
+func TestPolicyLimiter_SetPolicy(t *testing.T) {
+	t.Parallel()
+
+	store := newMockStore()
+	limiter := ratelimit.NewPolicyLimiter(store, ratelimit.NewPolicyBuilder().
+		AddLimit(ratelimit.ScopeWrite, 1, time.Minute).
+		Build())
+	scopes := []ratelimit.Scope{ratelimit.ScopeWrite}
+
+	allowed, _, err := limiter.Allow(context.Background(), "client1", scopes)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+
+	allowed, _, err = limiter.Allow(context.Background(), "client1", scopes)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+
+	raised := ratelimit.NewPolicyBuilder().AddLimit(ratelimit.ScopeWrite, 5, time.Minute).Build()
+	limiter.SetPolicy(raised)
+
+	assert.Same(t, raised, limiter.Policy())
+
+	// The minute bucket carries over (2 recorded so far), so 3 more fit under the new limit
+	for range 3 {
+		allowed, _, err = limiter.Allow(context.Background(), "client1", scopes)
+		require.NoError(t, err)
+		assert.True(t, allowed)
+	}
+
+	allowed, exceeded, err := limiter.Allow(context.Background(), "client1", scopes)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+	assert.Equal(t, int64(5), exceeded.Config.Max)
+}
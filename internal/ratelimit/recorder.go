@@ -0,0 +1,22 @@
+package ratelimit
+
+// Decision is the outcome of a rate limit check.
+type Decision string
+
+const (
+	// DecisionAllowed indicates the request was within its limits.
+	DecisionAllowed Decision = "allowed"
+	// DecisionDenied indicates the request exceeded a limit.
+	DecisionDenied Decision = "denied"
+)
+
+// Recorder records rate limit decisions for observability.
+type Recorder interface {
+	RecordDecision(scope Scope, decision Decision)
+}
+
+// NopRecorder is a Recorder that discards all decisions.
+type NopRecorder struct{}
+
+// RecordDecision implements Recorder.
+func (NopRecorder) RecordDecision(Scope, Decision) {}
@@ -13,6 +13,8 @@ const (
 	ScopeRead Scope = "read"
 	// ScopeWrite applies to write operations (POST, PUT, PATCH, DELETE).
 	ScopeWrite Scope = "write"
+	// ScopeCustom identifies per-endpoint custom limits defined in EndpointConfig.
+	ScopeCustom Scope = "custom"
 )
 
 // MetadataKey is the key used to store rate limit config in operation metadata.
@@ -44,6 +46,16 @@ type EndpointConfig struct {
 
 	// Disabled skips rate limiting entirely for this endpoint.
 	Disabled bool
+
+	// MonitorOnly logs requests that would exceed a limit but still lets them
+	// through. Useful for trialling new limits before enforcing them.
+	MonitorOnly bool
+
+	// KeyByOperation counts the endpoint's requests by its OperationID instead
+	// of its route template, so operations sharing a path (e.g. GET and DELETE
+	// of /{code}) never share buckets. Scope-based limits are then counted per
+	// operation too, instead of across every endpoint in the scope.
+	KeyByOperation bool
 }
 
 // ScopeResolver determines which scopes apply to a given request.
@@ -19,6 +19,7 @@ var errMultipartNotSupported = errors.New("multipart not supported in mock")
 
 // mockHumaContext implements huma.Context for testing scope resolution.
 type mockHumaContext struct {
+	ctx       context.Context //nolint:containedctx // carries request values such as the tier
 	method    string
 	operation *huma.Operation
 }
@@ -26,7 +27,13 @@ type mockHumaContext struct {
 func (m *mockHumaContext) Operation() *huma.Operation {
 	return m.operation
 }
-func (m *mockHumaContext) Context() context.Context          { return context.Background() }
+func (m *mockHumaContext) Context() context.Context {
+	if m.ctx != nil {
+		return m.ctx
+	}
+
+	return context.Background()
+}
 func (m *mockHumaContext) TLS() *tls.ConnectionState         { return nil }
 func (m *mockHumaContext) Version() huma.ProtoVersion        { return huma.ProtoVersion{} }
 func (m *mockHumaContext) Method() string                    { return m.method }
@@ -5,9 +5,48 @@ import (
 	"time"
 )
 
-// Store defines the interface for rate limit data storage.
+// Store defines the interface for rate limit data storage. How a window is
+// counted depends on the backend: see SlidingWindowStore and FixedWindowStore.
+// PolicyLimiter accepts either.
 type Store interface {
 	// Record records a request and returns the count of requests in the current window.
 	// It automatically prunes expired entries.
 	Record(ctx context.Context, key string, window time.Duration) (count int64, err error)
+
+	// Peek returns the count of requests in the current window without recording one.
+	Peek(ctx context.Context, key string, window time.Duration) (count int64, err error)
+}
+
+// SlidingWindowStore is a Store whose counts cover the window ending at the
+// moment of the call, so a client can never exceed the limit within any window
+// length of time. It keeps one entry per request.
+type SlidingWindowStore interface {
+	Store
+	// SlidingWindow marks the store as counting sliding windows. It does nothing.
+	SlidingWindow()
+}
+
+// FixedWindowStore is a Store that keeps one counter per key and aligned
+// window, resetting it when the next window starts. It is cheaper than a
+// sliding window but lets a client make up to twice the limit across a window
+// boundary.
+type FixedWindowStore interface {
+	Store
+	// FixedWindow marks the store as counting fixed windows. It does nothing.
+	FixedWindow()
+}
+
+// RecordRequest identifies a single key and window to record in a batch.
+type RecordRequest struct {
+	Key    string
+	Window time.Duration
+}
+
+// BatchStore is an optional extension of Store for backends that can record
+// several keys in a single round trip. PolicyLimiter uses it when available.
+type BatchStore interface {
+	Store
+	// RecordBatch records a request against every key and returns the counts
+	// in the same order as reqs.
+	RecordBatch(ctx context.Context, reqs []RecordRequest) ([]int64, error)
 }
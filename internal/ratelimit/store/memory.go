@@ -4,26 +4,48 @@ import (
 	"context"
 	"sync"
 	"time"
+
+	"github.com/serroba/web-demo-go/internal/clock"
+	"github.com/serroba/web-demo-go/internal/ratelimit"
 )
 
-// Memory is an in-memory implementation of ratelimit.Store.
+// Memory is an in-memory implementation of ratelimit.SlidingWindowStore that
+// keeps the timestamp of every request in the window.
 type Memory struct {
 	mu       sync.Mutex
 	requests map[string][]time.Time
+	clock    clock.Clock
+}
+
+// MemoryOption configures optional Memory behavior.
+type MemoryOption func(*Memory)
+
+// WithClock sets the clock used to timestamp requests and slide windows.
+func WithClock(c clock.Clock) MemoryOption {
+	return func(s *Memory) {
+		s.clock = c
+	}
 }
 
 // NewMemory creates a new in-memory rate limit store.
-func NewMemory() *Memory {
-	return &Memory{
+func NewMemory(opts ...MemoryOption) *Memory {
+	s := &Memory{
 		requests: make(map[string][]time.Time),
+		clock:    clock.Real{},
 	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
 }
 
 func (s *Memory) Record(_ context.Context, key string, window time.Duration) (int64, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	now := time.Now()
+	now := s.clock.Now()
 	cutoff := now.Add(-window)
 
 	// Get existing timestamps and prune expired ones
@@ -42,3 +64,27 @@ func (s *Memory) Record(_ context.Context, key string, window time.Duration) (in
 
 	return int64(len(valid)), nil
 }
+
+// Peek returns the count of requests in the current window without recording one.
+func (s *Memory) Peek(_ context.Context, key string, window time.Duration) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cutoff := s.clock.Now().Add(-window)
+
+	var count int64
+
+	for _, ts := range s.requests[key] {
+		if ts.After(cutoff) {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// SlidingWindow implements ratelimit.SlidingWindowStore.
+func (s *Memory) SlidingWindow() {}
+
+// Compile-time check.
+var _ ratelimit.SlidingWindowStore = (*Memory)(nil)
@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/serroba/web-demo-go/internal/clock"
 	"github.com/serroba/web-demo-go/internal/ratelimit/store"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -43,19 +44,77 @@ func TestMemory(t *testing.T) {
 	})
 
 	t.Run("prunes expired entries", func(t *testing.T) {
-		s := store.NewMemory()
+		c := clock.NewFake(time.Now())
+		s := store.NewMemory(store.WithClock(c))
 
 		// Record some requests
-		_, _ = s.Record(context.Background(), "key1", 50*time.Millisecond)
-		_, _ = s.Record(context.Background(), "key1", 50*time.Millisecond)
+		_, _ = s.Record(context.Background(), "key1", time.Minute)
+		_, _ = s.Record(context.Background(), "key1", time.Minute)
 
-		// Wait for them to expire
-		time.Sleep(60 * time.Millisecond)
+		// Move past the window
+		c.Advance(time.Minute + time.Second)
 
 		// New request should only count itself
-		count, err := s.Record(context.Background(), "key1", 50*time.Millisecond)
+		count, err := s.Record(context.Background(), "key1", time.Minute)
 
 		require.NoError(t, err)
 		assert.Equal(t, int64(1), count, "expired entries should be pruned")
 	})
+
+	t.Run("window slides one request at a time", func(t *testing.T) {
+		c := clock.NewFake(time.Now())
+		s := store.NewMemory(store.WithClock(c))
+
+		_, _ = s.Record(context.Background(), "key1", time.Minute)
+
+		c.Advance(30 * time.Second)
+		_, _ = s.Record(context.Background(), "key1", time.Minute)
+
+		// Only the first request has left the window
+		c.Advance(31 * time.Second)
+
+		count, err := s.Peek(context.Background(), "key1", time.Minute)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("entry exactly at the window edge has expired", func(t *testing.T) {
+		c := clock.NewFake(time.Now())
+		s := store.NewMemory(store.WithClock(c))
+
+		_, _ = s.Record(context.Background(), "key1", time.Minute)
+
+		c.Advance(time.Minute)
+
+		count, err := s.Peek(context.Background(), "key1", time.Minute)
+
+		require.NoError(t, err)
+		assert.Zero(t, count)
+	})
+	t.Run("peek counts without recording", func(t *testing.T) {
+		s := store.NewMemory()
+
+		_, _ = s.Record(context.Background(), "key1", time.Minute)
+		_, _ = s.Record(context.Background(), "key1", time.Minute)
+
+		count, err := s.Peek(context.Background(), "key1", time.Minute)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), count)
+
+		count, err = s.Peek(context.Background(), "key1", time.Minute)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), count, "peek should not record a request")
+	})
+
+	t.Run("peek returns zero for unknown key", func(t *testing.T) {
+		s := store.NewMemory()
+
+		count, err := s.Peek(context.Background(), "missing", time.Minute)
+
+		require.NoError(t, err)
+		assert.Zero(t, count)
+	})
 }
@@ -0,0 +1,127 @@
+package store
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/serroba/web-demo-go/internal/clock"
+	"github.com/serroba/web-demo-go/internal/ratelimit"
+)
+
+// defaultPruneInterval is how often Record also deletes expired counters.
+const defaultPruneInterval = time.Minute
+
+// Postgres is a PostgreSQL implementation of ratelimit.FixedWindowStore for
+// deployments without Redis. It keeps one counter row per key and fixed window,
+// so a client can make up to twice the limit across a window boundary, unlike
+// the sliding windows of the Memory and Redis stores.
+type Postgres struct {
+	pool          *pgxpool.Pool
+	clock         clock.Clock
+	pruneInterval time.Duration
+	lastPrune     atomic.Int64
+}
+
+// PostgresOption configures optional Postgres behavior.
+type PostgresOption func(*Postgres)
+
+// WithPostgresClock sets the clock used to pick the current window.
+func WithPostgresClock(c clock.Clock) PostgresOption {
+	return func(s *Postgres) {
+		s.clock = c
+	}
+}
+
+// WithPruneInterval sets how often Record deletes expired counters.
+func WithPruneInterval(d time.Duration) PostgresOption {
+	return func(s *Postgres) {
+		s.pruneInterval = d
+	}
+}
+
+// NewPostgres creates a new PostgreSQL-backed rate limit store.
+func NewPostgres(pool *pgxpool.Pool, opts ...PostgresOption) *Postgres {
+	s := &Postgres{
+		pool:          pool,
+		clock:         clock.Real{},
+		pruneInterval: defaultPruneInterval,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// Record increments the counter for the current window and returns its value.
+func (s *Postgres) Record(ctx context.Context, key string, window time.Duration) (int64, error) {
+	now := s.clock.Now()
+	start := now.Truncate(window)
+
+	query := `
+		INSERT INTO rate_limit_counters (key, window_ms, window_start, count, expires_at)
+		VALUES ($1, $2, $3, 1, $4)
+		ON CONFLICT (key, window_ms, window_start)
+		DO UPDATE SET count = rate_limit_counters.count + 1
+		RETURNING count
+	`
+
+	var count int64
+
+	err := s.pool.QueryRow(ctx, query, key, window.Milliseconds(), start, start.Add(window)).Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+
+	s.maybePrune(ctx, now)
+
+	return count, nil
+}
+
+// Peek returns the counter for the current window without incrementing it.
+func (s *Postgres) Peek(ctx context.Context, key string, window time.Duration) (int64, error) {
+	query := `
+		SELECT COALESCE(SUM(count), 0) FROM rate_limit_counters
+		WHERE key = $1 AND window_ms = $2 AND window_start = $3
+	`
+
+	var count int64
+
+	err := s.pool.QueryRow(ctx, query, key, window.Milliseconds(), s.clock.Now().Truncate(window)).Scan(&count)
+
+	return count, err
+}
+
+// Prune deletes counters whose window has ended and returns how many were removed.
+func (s *Postgres) Prune(ctx context.Context) (int64, error) {
+	tag, err := s.pool.Exec(ctx, "DELETE FROM rate_limit_counters WHERE expires_at <= $1", s.clock.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// maybePrune runs Prune at most once per prune interval, acting as a TTL for
+// counters of keys that are no longer seen. Failures are left for the next run.
+func (s *Postgres) maybePrune(ctx context.Context, now time.Time) {
+	last := s.lastPrune.Load()
+	if now.UnixNano()-last < s.pruneInterval.Nanoseconds() {
+		return
+	}
+
+	if !s.lastPrune.CompareAndSwap(last, now.UnixNano()) {
+		return
+	}
+
+	_, _ = s.Prune(ctx)
+}
+
+// FixedWindow implements ratelimit.FixedWindowStore.
+func (s *Postgres) FixedWindow() {}
+
+// Compile-time check.
+var _ ratelimit.FixedWindowStore = (*Postgres)(nil)
@@ -6,9 +6,10 @@ import (
 	"time"
 
 	"github.com/redis/go-redis/v9"
+	"github.com/serroba/web-demo-go/internal/ratelimit"
 )
 
-// Redis is a Redis implementation of ratelimit.Store using sorted sets.
+// Redis is a Redis implementation of ratelimit.SlidingWindowStore using sorted sets.
 type Redis struct {
 	client *redis.Client
 	prefix string
@@ -25,14 +26,60 @@ func NewRedis(client *redis.Client) *Redis {
 // Record records a request and returns the count of requests in the current window.
 // Uses Redis sorted sets with timestamps as scores for sliding window implementation.
 func (s *Redis) Record(ctx context.Context, key string, window time.Duration) (int64, error) {
+	// Use a pipeline for atomic operations
+	pipe := s.client.Pipeline()
+	countCmd := s.queueRecord(ctx, pipe, key, window, time.Now())
+
+	_, err := pipe.Exec(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return countCmd.Val(), nil
+}
+
+// Peek returns the count of requests in the current window without recording one.
+func (s *Redis) Peek(ctx context.Context, key string, window time.Duration) (int64, error) {
+	cutoff := time.Now().Add(-window).UnixNano()
+
+	return s.client.ZCount(ctx, s.prefix+key, "("+strconv.FormatInt(cutoff, 10), "+inf").Result()
+}
+
+// RecordBatch records several keys in a single pipelined round trip.
+func (s *Redis) RecordBatch(ctx context.Context, reqs []ratelimit.RecordRequest) ([]int64, error) {
 	now := time.Now()
+	pipe := s.client.Pipeline()
+	cmds := make([]*redis.IntCmd, len(reqs))
+
+	for i, req := range reqs {
+		cmds[i] = s.queueRecord(ctx, pipe, req.Key, req.Window, now)
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	counts := make([]int64, len(cmds))
+	for i, cmd := range cmds {
+		counts[i] = cmd.Val()
+	}
+
+	return counts, nil
+}
+
+// queueRecord adds the sliding window commands for one key to the pipeline
+// and returns the command that yields the resulting count.
+func (s *Redis) queueRecord(
+	ctx context.Context,
+	pipe redis.Pipeliner,
+	key string,
+	window time.Duration,
+	now time.Time,
+) *redis.IntCmd {
 	nowUnix := float64(now.UnixNano())
 	cutoff := float64(now.Add(-window).UnixNano())
 	redisKey := s.prefix + key
 
-	// Use a pipeline for atomic operations
-	pipe := s.client.Pipeline()
-
 	// Remove expired entries
 	pipe.ZRemRangeByScore(ctx, redisKey, "-inf", strconv.FormatFloat(cutoff, 'f', -1, 64))
 
@@ -49,10 +96,14 @@ func (s *Redis) Record(ctx context.Context, key string, window time.Duration) (i
 	// Set TTL to auto-expire the key after the window
 	pipe.Expire(ctx, redisKey, window+time.Second)
 
-	_, err := pipe.Exec(ctx)
-	if err != nil {
-		return 0, err
-	}
-
-	return countCmd.Val(), nil
+	return countCmd
 }
+
+// SlidingWindow implements ratelimit.SlidingWindowStore.
+func (s *Redis) SlidingWindow() {}
+
+// Compile-time checks.
+var (
+	_ ratelimit.SlidingWindowStore = (*Redis)(nil)
+	_ ratelimit.BatchStore         = (*Redis)(nil)
+)
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/redis/go-redis/v9"
+	"github.com/serroba/web-demo-go/internal/ratelimit"
 	"github.com/serroba/web-demo-go/internal/ratelimit/store"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -93,4 +94,85 @@ func TestRedis(t *testing.T) {
 		require.NoError(t, err)
 		assert.Equal(t, int64(1), count, "expired entries should be pruned")
 	})
+
+	t.Run("record batch returns counts in one round trip", func(t *testing.T) {
+		s := store.NewRedis(client)
+		keys := []string{
+			"test:ratelimit:batch:minute:" + t.Name(),
+			"test:ratelimit:batch:hour:" + t.Name(),
+			"test:ratelimit:batch:day:" + t.Name(),
+		}
+
+		// Clean up before test
+		client.Del(context.Background(), "ratelimit:"+keys[0], "ratelimit:"+keys[1], "ratelimit:"+keys[2])
+
+		_, _ = s.Record(context.Background(), keys[0], time.Minute)
+
+		hook := &roundTripCounter{}
+		client.AddHook(hook)
+
+		counts, err := s.RecordBatch(context.Background(), []ratelimit.RecordRequest{
+			{Key: keys[0], Window: time.Minute},
+			{Key: keys[1], Window: time.Hour},
+			{Key: keys[2], Window: 24 * time.Hour},
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, []int64{2, 1, 1}, counts)
+		assert.Equal(t, 1, hook.pipelines, "batch should use a single pipeline")
+	})
+}
+
+// roundTripCounter counts pipelines sent to Redis.
+type roundTripCounter struct {
+	pipelines int
+}
+
+func (h *roundTripCounter) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (h *roundTripCounter) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return next
+}
+
+func (h *roundTripCounter) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		h.pipelines++
+
+		return next(ctx, cmds)
+	}
+}
+
+func BenchmarkRedisRecord(b *testing.B) {
+	addr := os.Getenv("REDIS_ADDR")
+	if addr == "" {
+		addr = "localhost:6379"
+	}
+
+	client := redis.NewClient(&redis.Options{Addr: addr})
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		b.Skipf("Redis not available at %s: %v", addr, err)
+	}
+
+	s := store.NewRedis(client)
+	reqs := []ratelimit.RecordRequest{
+		{Key: "bench:minute", Window: time.Minute},
+		{Key: "bench:hour", Window: time.Hour},
+		{Key: "bench:day", Window: 24 * time.Hour},
+	}
+
+	b.Run("sequential", func(b *testing.B) {
+		for b.Loop() {
+			for _, req := range reqs {
+				_, _ = s.Record(context.Background(), req.Key, req.Window)
+			}
+		}
+	})
+
+	b.Run("batch", func(b *testing.B) {
+		for b.Loop() {
+			_, _ = s.RecordBatch(context.Background(), reqs)
+		}
+	})
 }
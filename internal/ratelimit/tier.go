@@ -0,0 +1,122 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// Tier names the plan of an authenticated API key (e.g. "free" or "pro").
+type Tier string
+
+type tierKey struct{}
+
+// WithTier returns a copy of ctx carrying the API-key tier. Authentication
+// middleware calls it once the key has been resolved.
+func WithTier(ctx context.Context, tier Tier) context.Context {
+	return context.WithValue(ctx, tierKey{}, tier)
+}
+
+// TierFromContext returns the API-key tier stored in ctx, if any.
+func TierFromContext(ctx context.Context) (Tier, bool) {
+	tier, ok := ctx.Value(tierKey{}).(Tier)
+
+	return tier, ok && tier != ""
+}
+
+// TierScope returns the named scope holding a tier's limits for scope,
+// e.g. TierScope("pro", ScopeWrite) is "pro:write".
+func TierScope(tier Tier, scope Scope) Scope {
+	return Scope(string(tier) + ":" + string(scope))
+}
+
+// isTierScope reports whether scope is a tier scope of one of the policy scopes.
+func isTierScope(scope Scope) bool {
+	tier, base, ok := strings.Cut(string(scope), ":")
+
+	return ok && tier != "" && isPolicyScope(Scope(base))
+}
+
+// ParseTierLimits parses comma-separated tier limits written as
+// tier:scope=max/window, such as "pro:write=100/1m,pro:write=5000/24h", into
+// limits keyed by tier scope. An empty spec yields no limits.
+func ParseTierLimits(spec string) (map[Scope][]LimitConfig, error) {
+	limits := map[Scope][]LimitConfig{}
+
+	for entry := range strings.SplitSeq(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		name, rule, okName := strings.Cut(entry, "=")
+		tier, scope, okScope := strings.Cut(name, ":")
+		maxStr, windowStr, okRule := strings.Cut(rule, "/")
+
+		if !okName || !okScope || !okRule || tier == "" {
+			return nil, fmt.Errorf("%w: tier limit %q must be tier:scope=max/window", ErrInvalidPolicy, entry)
+		}
+
+		maxReqs, err := strconv.ParseInt(maxStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: tier limit %q: invalid max: %w", ErrInvalidPolicy, entry, err)
+		}
+
+		window, err := time.ParseDuration(windowStr)
+		if err != nil {
+			return nil, fmt.Errorf("%w: tier limit %q: invalid window: %w", ErrInvalidPolicy, entry, err)
+		}
+
+		tierScope := TierScope(Tier(tier), Scope(scope))
+		limits[tierScope] = append(limits[tierScope], LimitConfig{Window: window, Max: maxReqs})
+	}
+
+	return limits, nil
+}
+
+// TieredPolicyResolver resolves scopes with a base resolver and then swaps
+// each one for its tier-named scope when the limiter's current policy defines
+// limits for it. Requests without a tier, or whose tier has no limits for a
+// scope, keep the base scope, so tiers only need to define the limits they
+// change, and a policy replaced without tier limits falls back to the base
+// limits rather than leaving tiers unlimited.
+type TieredPolicyResolver struct {
+	base    ScopeResolver
+	limiter *PolicyLimiter
+}
+
+// NewTieredPolicyResolver creates a resolver that applies the tier limits of
+// limiter's policy on top of the scopes returned by base.
+func NewTieredPolicyResolver(base ScopeResolver, limiter *PolicyLimiter) *TieredPolicyResolver {
+	return &TieredPolicyResolver{
+		base:    base,
+		limiter: limiter,
+	}
+}
+
+// Resolve returns the base scopes, replaced by tier scopes where defined.
+func (r *TieredPolicyResolver) Resolve(ctx huma.Context) []Scope {
+	scopes := r.base.Resolve(ctx)
+
+	tier, ok := TierFromContext(ctx.Context())
+	if !ok {
+		return scopes
+	}
+
+	policy := r.limiter.Policy()
+	resolved := make([]Scope, len(scopes))
+
+	for i, scope := range scopes {
+		resolved[i] = scope
+
+		if tiered := TierScope(tier, scope); len(policy.Limits[tiered]) > 0 {
+			resolved[i] = tiered
+		}
+	}
+
+	return resolved
+}
@@ -0,0 +1,145 @@
+package ratelimit_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/serroba/web-demo-go/internal/ratelimit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func tieredPolicy() *ratelimit.Policy {
+	return ratelimit.NewPolicyBuilder().
+		AddLimit(ratelimit.ScopeGlobal, 1000, time.Hour).
+		AddLimit(ratelimit.ScopeWrite, 2, time.Minute).
+		AddLimit(ratelimit.TierScope("free", ratelimit.ScopeWrite), 2, time.Minute).
+		AddLimit(ratelimit.TierScope("pro", ratelimit.ScopeWrite), 5, time.Minute).
+		Build()
+}
+
+func TestTierFromContext(t *testing.T) {
+	t.Run("returns stored tier", func(t *testing.T) {
+		tier, ok := ratelimit.TierFromContext(ratelimit.WithTier(context.Background(), "pro"))
+
+		assert.True(t, ok)
+		assert.Equal(t, ratelimit.Tier("pro"), tier)
+	})
+
+	t.Run("reports missing tier", func(t *testing.T) {
+		_, ok := ratelimit.TierFromContext(context.Background())
+
+		assert.False(t, ok)
+	})
+}
+
+func TestTieredPolicyResolver_Resolve(t *testing.T) {
+	limiter := ratelimit.NewPolicyLimiter(newMockStore(), tieredPolicy())
+	resolver := ratelimit.NewTieredPolicyResolver(ratelimit.NewMethodScopeResolver(), limiter)
+
+	tests := []struct {
+		name   string
+		tier   ratelimit.Tier
+		method string
+		want   []ratelimit.Scope
+	}{
+		{
+			name:   "no tier keeps base scopes",
+			method: "POST",
+			want:   []ratelimit.Scope{ratelimit.ScopeGlobal, ratelimit.ScopeWrite},
+		},
+		{
+			name:   "tier scope replaces base scope",
+			tier:   "pro",
+			method: "POST",
+			want:   []ratelimit.Scope{ratelimit.ScopeGlobal, "pro:write"},
+		},
+		{
+			name:   "tier without limits for scope keeps base scope",
+			tier:   "pro",
+			method: "GET",
+			want:   []ratelimit.Scope{ratelimit.ScopeGlobal, ratelimit.ScopeRead},
+		},
+		{
+			name:   "unknown tier keeps base scopes",
+			tier:   "enterprise",
+			method: "POST",
+			want:   []ratelimit.Scope{ratelimit.ScopeGlobal, ratelimit.ScopeWrite},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := &mockHumaContext{method: tt.method}
+			if tt.tier != "" {
+				ctx.ctx = ratelimit.WithTier(context.Background(), tt.tier)
+			}
+
+			assert.Equal(t, tt.want, resolver.Resolve(ctx))
+		})
+	}
+}
+
+func TestTieredPolicyResolver_ProGetsHigherLimits(t *testing.T) {
+	limiter := ratelimit.NewPolicyLimiter(newMockStore(), tieredPolicy())
+	resolver := ratelimit.NewTieredPolicyResolver(ratelimit.NewMethodScopeResolver(), limiter)
+
+	allowedFor := func(tier ratelimit.Tier) int {
+		ctx := &mockHumaContext{method: "POST", ctx: ratelimit.WithTier(context.Background(), tier)}
+
+		allowed := 0
+
+		for range 10 {
+			ok, _, err := limiter.Allow(ctx.Context(), "same-client", resolver.Resolve(ctx))
+			require.NoError(t, err)
+
+			if ok {
+				allowed++
+			}
+		}
+
+		return allowed
+	}
+
+	assert.Equal(t, 2, allowedFor("free"))
+	assert.Equal(t, 5, allowedFor("pro"))
+}
+
+func TestTieredPolicyResolver_FollowsPolicyUpdates(t *testing.T) {
+	limiter := ratelimit.NewPolicyLimiter(newMockStore(), tieredPolicy())
+	resolver := ratelimit.NewTieredPolicyResolver(ratelimit.NewMethodScopeResolver(), limiter)
+	ctx := &mockHumaContext{method: "POST", ctx: ratelimit.WithTier(context.Background(), "pro")}
+
+	limiter.SetPolicy(ratelimit.NewPolicyBuilder().AddLimit(ratelimit.ScopeWrite, 2, time.Minute).Build())
+
+	assert.Equal(t, []ratelimit.Scope{ratelimit.ScopeGlobal, ratelimit.ScopeWrite}, resolver.Resolve(ctx),
+		"a policy without tier limits falls back to the base scopes")
+}
+
+func TestParseTierLimits(t *testing.T) {
+	t.Run("parses tier limits", func(t *testing.T) {
+		limits, err := ratelimit.ParseTierLimits("pro:write=100/1m, pro:write=5000/24h,free:read=50/1m")
+
+		require.NoError(t, err)
+		assert.Equal(t, map[ratelimit.Scope][]ratelimit.LimitConfig{
+			"pro:write": {{Window: time.Minute, Max: 100}, {Window: 24 * time.Hour, Max: 5000}},
+			"free:read": {{Window: time.Minute, Max: 50}},
+		}, limits)
+	})
+
+	t.Run("empty spec has no limits", func(t *testing.T) {
+		limits, err := ratelimit.ParseTierLimits("")
+
+		require.NoError(t, err)
+		assert.Empty(t, limits)
+	})
+
+	for _, spec := range []string{"pro=100/1m", "pro:write=100", ":write=1/1m", "pro:write=many/1m", "pro:write=1/soon"} {
+		t.Run("rejects "+spec, func(t *testing.T) {
+			_, err := ratelimit.ParseTierLimits(spec)
+
+			require.ErrorIs(t, err, ratelimit.ErrInvalidPolicy)
+		})
+	}
+}
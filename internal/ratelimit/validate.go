@@ -0,0 +1,106 @@
+package ratelimit
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// ErrTooManyLimits is returned when an endpoint defines more custom limits than allowed.
+var ErrTooManyLimits = errors.New("too many custom rate limits")
+
+// ErrInvalidPolicy is returned when a policy cannot be enforced.
+var ErrInvalidPolicy = errors.New("invalid rate limit policy")
+
+// policyScopes are the scopes a policy may define; custom limits come from
+// endpoint metadata instead.
+var policyScopes = []Scope{ScopeGlobal, ScopeRead, ScopeWrite}
+
+// isPolicyScope reports whether scope is one a policy may define directly.
+func isPolicyScope(scope Scope) bool {
+	return slices.Contains(policyScopes, scope)
+}
+
+// ValidatePolicy checks that policy only limits known scopes and their tier
+// scopes (see TierScope), that every window
+// is at least a millisecond (bucket keys use milliseconds), that no limit is
+// negative, and that no scope repeats a window, which would share one bucket.
+func ValidatePolicy(policy *Policy) error {
+	if policy == nil {
+		return fmt.Errorf("%w: no policy", ErrInvalidPolicy)
+	}
+
+	var errs []error
+
+	for _, scope := range slices.Sorted(maps.Keys(policy.Limits)) {
+		if !isPolicyScope(scope) && !isTierScope(scope) {
+			errs = append(errs, fmt.Errorf("%w: unknown scope %q", ErrInvalidPolicy, scope))
+
+			continue
+		}
+
+		windows := map[time.Duration]bool{}
+
+		for _, limit := range policy.Limits[scope] {
+			switch {
+			case limit.Window < time.Millisecond:
+				errs = append(errs, fmt.Errorf("%w: %s window %s is shorter than 1ms", ErrInvalidPolicy, scope, limit.Window))
+			case limit.Max < 0:
+				errs = append(errs, fmt.Errorf("%w: %s max %d is negative", ErrInvalidPolicy, scope, limit.Max))
+			case windows[limit.Window]:
+				errs = append(errs, fmt.Errorf("%w: %s window %s is defined twice", ErrInvalidPolicy, scope, limit.Window))
+			}
+
+			windows[limit.Window] = true
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// ValidateEndpointConfig checks cfg against the maximum number of custom
+// limits an endpoint may define. Each limit costs a store call per request,
+// so the cap bounds the work a single route can cause. maxLimits <= 0
+// disables the check.
+func ValidateEndpointConfig(cfg EndpointConfig, maxLimits int) error {
+	if maxLimits > 0 && len(cfg.Limits) > maxLimits {
+		return fmt.Errorf("%w: %d configured, at most %d allowed", ErrTooManyLimits, len(cfg.Limits), maxLimits)
+	}
+
+	return nil
+}
+
+// ValidateOperations runs ValidateEndpointConfig for every operation
+// registered on api and returns all failures, ordered by path.
+func ValidateOperations(api huma.API, maxLimits int) error {
+	paths := api.OpenAPI().Paths
+
+	var errs []error
+
+	for _, path := range slices.Sorted(maps.Keys(paths)) {
+		item := paths[path]
+
+		for _, op := range []*huma.Operation{
+			item.Get, item.Put, item.Post, item.Delete, item.Options, item.Head, item.Patch, item.Trace,
+		} {
+			if op == nil {
+				continue
+			}
+
+			cfg, ok := op.Metadata[MetadataKey].(EndpointConfig)
+			if !ok {
+				continue
+			}
+
+			if err := ValidateEndpointConfig(cfg, maxLimits); err != nil {
+				errs = append(errs, fmt.Errorf("%s %s: %w", op.Method, path, err))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
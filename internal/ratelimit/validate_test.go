@@ -0,0 +1,164 @@
+package ratelimit_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/adapters/humachi"
+	"github.com/go-chi/chi/v5"
+	"github.com/serroba/web-demo-go/internal/ratelimit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func limits(n int) []ratelimit.LimitConfig {
+	configs := make([]ratelimit.LimitConfig, n)
+	for i := range configs {
+		configs[i] = ratelimit.LimitConfig{Window: time.Duration(i+1) * time.Minute, Max: 10}
+	}
+
+	return configs
+}
+
+func TestValidateEndpointConfig(t *testing.T) {
+	tests := []struct {
+		name      string
+		limits    int
+		maxLimits int
+		wantErr   bool
+	}{
+		{name: "within cap", limits: 3, maxLimits: 3},
+		{name: "over cap", limits: 4, maxLimits: 3, wantErr: true},
+		{name: "zero cap disables check", limits: 50, maxLimits: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ratelimit.ValidateEndpointConfig(ratelimit.EndpointConfig{Limits: limits(tt.limits)}, tt.maxLimits)
+
+			if tt.wantErr {
+				require.ErrorIs(t, err, ratelimit.ErrTooManyLimits)
+
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestValidatePolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  *ratelimit.Policy
+		wantErr string
+	}{
+		{
+			name: "valid policy",
+			policy: ratelimit.NewPolicyBuilder().
+				AddLimit(ratelimit.ScopeGlobal, 1000, 24*time.Hour).
+				AddLimit(ratelimit.ScopeWrite, 10, time.Minute).
+				AddLimit(ratelimit.ScopeWrite, 100, time.Hour).
+				Build(),
+		},
+		{
+			name:   "zero max blocks the scope",
+			policy: ratelimit.NewPolicyBuilder().AddLimit(ratelimit.ScopeWrite, 0, time.Minute).Build(),
+		},
+		{name: "nil policy", wantErr: "no policy"},
+		{
+			name:    "unknown scope",
+			policy:  ratelimit.NewPolicyBuilder().AddLimit("admin", 10, time.Minute).Build(),
+			wantErr: `unknown scope "admin"`,
+		},
+		{
+			name: "tier scopes are allowed",
+			policy: ratelimit.NewPolicyBuilder().
+				AddLimit(ratelimit.TierScope("pro", ratelimit.ScopeWrite), 100, time.Minute).
+				Build(),
+		},
+		{
+			name:    "tier of an unknown scope",
+			policy:  ratelimit.NewPolicyBuilder().AddLimit("pro:admin", 10, time.Minute).Build(),
+			wantErr: `unknown scope "pro:admin"`,
+		},
+		{
+			name:    "custom scope belongs to endpoints",
+			policy:  ratelimit.NewPolicyBuilder().AddLimit(ratelimit.ScopeCustom, 10, time.Minute).Build(),
+			wantErr: `unknown scope "custom"`,
+		},
+		{
+			name:    "sub-millisecond window",
+			policy:  ratelimit.NewPolicyBuilder().AddLimit(ratelimit.ScopeRead, 10, time.Microsecond).Build(),
+			wantErr: "read window 1µs is shorter than 1ms",
+		},
+		{
+			name:    "negative max",
+			policy:  ratelimit.NewPolicyBuilder().AddLimit(ratelimit.ScopeRead, -1, time.Minute).Build(),
+			wantErr: "read max -1 is negative",
+		},
+		{
+			name: "repeated window",
+			policy: ratelimit.NewPolicyBuilder().
+				AddLimit(ratelimit.ScopeWrite, 10, time.Minute).
+				AddLimit(ratelimit.ScopeWrite, 20, time.Minute).
+				Build(),
+			wantErr: "write window 1m0s is defined twice",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ratelimit.ValidatePolicy(tt.policy)
+
+			if tt.wantErr != "" {
+				require.ErrorIs(t, err, ratelimit.ErrInvalidPolicy)
+				assert.Contains(t, err.Error(), tt.wantErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestValidateOperations(t *testing.T) {
+	register := func(api huma.API, method, path string, metadata map[string]any) {
+		huma.Register(api, huma.Operation{
+			OperationID: method + path,
+			Method:      method,
+			Path:        path,
+			Metadata:    metadata,
+		}, func(_ context.Context, _ *struct{}) (*struct{}, error) { return &struct{}{}, nil })
+	}
+
+	t.Run("passes when every endpoint is within the cap", func(t *testing.T) {
+		api := humachi.New(chi.NewMux(), huma.DefaultConfig("Test", "1.0.0"))
+		register(api, http.MethodGet, "/ok", map[string]any{
+			ratelimit.MetadataKey: ratelimit.EndpointConfig{Limits: limits(2)},
+		})
+		register(api, http.MethodGet, "/plain", nil)
+
+		assert.NoError(t, ratelimit.ValidateOperations(api, 2))
+	})
+
+	t.Run("reports over-cap endpoints", func(t *testing.T) {
+		api := humachi.New(chi.NewMux(), huma.DefaultConfig("Test", "1.0.0"))
+		register(api, http.MethodGet, "/ok", map[string]any{
+			ratelimit.MetadataKey: ratelimit.EndpointConfig{Limits: limits(2)},
+		})
+		register(api, http.MethodPost, "/busy", map[string]any{
+			ratelimit.MetadataKey: ratelimit.EndpointConfig{Limits: limits(12)},
+		})
+
+		err := ratelimit.ValidateOperations(api, 2)
+
+		require.ErrorIs(t, err, ratelimit.ErrTooManyLimits)
+		assert.Contains(t, err.Error(), "POST /busy")
+		assert.NotContains(t, err.Error(), "/ok")
+	})
+}
@@ -0,0 +1,237 @@
+package shortener
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/serroba/web-demo-go/internal/clock"
+)
+
+var (
+	// ErrInvalidAlias is returned when a requested alias fails validation.
+	ErrInvalidAlias = errors.New("invalid alias")
+	// ErrAliasTaken is returned when a requested alias is already in use.
+	ErrAliasTaken = errors.New("alias already in use")
+	// ErrInvalidCode is returned when a code supplied from outside fails validation.
+	ErrInvalidCode = errors.New("invalid code")
+	// ErrInvalidAliasCharset is returned when a configured alias charset is unusable.
+	ErrInvalidAliasCharset = errors.New("invalid alias charset")
+)
+
+// DefaultMaxAliasLength matches the width of the short_urls.code column.
+const DefaultMaxAliasLength = 16
+
+// DefaultAliasCharset lists the characters aliases may use unless a policy
+// narrows it. Any configured charset must be a subset of it.
+const DefaultAliasCharset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_"
+
+// DefaultReservedAliasPrefixes are the leading characters reserved for system use.
+// Generated codes may contain them, so aliases starting with them are rejected.
+var DefaultReservedAliasPrefixes = []string{"_", "-"}
+
+// AliasPolicy describes which vanity aliases are acceptable.
+type AliasPolicy struct {
+	MaxLength        int
+	ReservedPrefixes []string
+	// Charset lists the characters aliases may use, e.g. DefaultAliasCharset
+	// without look-alikes such as 0, O, 1, l and I. Empty allows DefaultAliasCharset.
+	Charset string
+}
+
+// DefaultAliasPolicy returns the policy used when no configuration is provided.
+func DefaultAliasPolicy() AliasPolicy {
+	return AliasPolicy{
+		MaxLength:        DefaultMaxAliasLength,
+		ReservedPrefixes: DefaultReservedAliasPrefixes,
+	}
+}
+
+// Validate reports why an alias is not acceptable, wrapping ErrInvalidAlias.
+func (p AliasPolicy) Validate(alias string) error {
+	if alias == "" {
+		return fmt.Errorf("%w: alias must not be empty", ErrInvalidAlias)
+	}
+
+	// Check characters first so non-ASCII aliases are not reported by byte length
+	for _, r := range alias {
+		if r > unicode.MaxASCII {
+			return fmt.Errorf("%w: alias must be plain ASCII, %q can be mistaken for another character",
+				ErrInvalidAlias, r)
+		}
+
+		if !p.allows(r) {
+			if p.Charset == "" {
+				return fmt.Errorf("%w: alias may only contain letters, digits, '-' and '_'", ErrInvalidAlias)
+			}
+
+			return fmt.Errorf("%w: alias may only contain characters from %q", ErrInvalidAlias, p.Charset)
+		}
+	}
+
+	if p.MaxLength > 0 && len(alias) > p.MaxLength {
+		return fmt.Errorf("%w: alias must be at most %d characters", ErrInvalidAlias, p.MaxLength)
+	}
+
+	for _, prefix := range p.ReservedPrefixes {
+		if prefix != "" && strings.HasPrefix(alias, prefix) {
+			return fmt.Errorf("%w: alias must not start with reserved prefix %q", ErrInvalidAlias, prefix)
+		}
+	}
+
+	return nil
+}
+
+// allows reports whether r is in the policy's charset.
+func (p AliasPolicy) allows(r rune) bool {
+	if p.Charset == "" {
+		return isAliasRune(r)
+	}
+
+	return strings.ContainsRune(p.Charset, r)
+}
+
+// ValidateAliasCharset reports whether charset can be used as AliasPolicy.Charset:
+// it must not be empty and may only contain characters from DefaultAliasCharset,
+// so aliases stay plain ASCII and safe in a URL path.
+func ValidateAliasCharset(charset string) error {
+	if charset == "" {
+		return fmt.Errorf("%w: charset must not be empty", ErrInvalidAliasCharset)
+	}
+
+	for _, r := range charset {
+		if !isAliasRune(r) {
+			return fmt.Errorf("%w: %q is not a letter, digit, '-' or '_'", ErrInvalidAliasCharset, r)
+		}
+	}
+
+	return nil
+}
+
+// ValidateCode reports whether code fits the code column and only uses characters
+// that generated codes and aliases may contain. It wraps ErrInvalidCode.
+func ValidateCode(code string) error {
+	if code == "" {
+		return fmt.Errorf("%w: code must not be empty", ErrInvalidCode)
+	}
+
+	if len(code) > DefaultMaxAliasLength {
+		return fmt.Errorf("%w: code must be at most %d characters", ErrInvalidCode, DefaultMaxAliasLength)
+	}
+
+	for _, r := range code {
+		if !isAliasRune(r) {
+			return fmt.Errorf("%w: code may only contain letters, digits, '-' and '_'", ErrInvalidCode)
+		}
+	}
+
+	return nil
+}
+
+func isAliasRune(r rune) bool {
+	return (r >= 'a' && r <= 'z') ||
+		(r >= 'A' && r <= 'Z') ||
+		(r >= '0' && r <= '9') ||
+		r == '-' || r == '_'
+}
+
+// AliasStrategy stores a URL under a caller-chosen alias instead of a generated code.
+type AliasStrategy struct {
+	store  Repository
+	policy AliasPolicy
+	clock  clock.Clock
+}
+
+// AliasStrategyOption configures optional AliasStrategy behavior.
+type AliasStrategyOption func(*AliasStrategy)
+
+// WithAliasClock sets the clock used to stamp CreatedAt.
+func WithAliasClock(c clock.Clock) AliasStrategyOption {
+	return func(s *AliasStrategy) {
+		s.clock = c
+	}
+}
+
+// NewAliasStrategy creates a new alias-based shortening strategy.
+func NewAliasStrategy(store Repository, policy AliasPolicy, opts ...AliasStrategyOption) *AliasStrategy {
+	s := &AliasStrategy{
+		store:  store,
+		policy: policy,
+		clock:  clock.Real{},
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// Shorten validates the alias and saves the URL under it.
+func (s *AliasStrategy) Shorten(ctx context.Context, alias, url string, opts ...ShortenOption) (*ShortURL, error) {
+	if err := s.policy.Validate(alias); err != nil {
+		return nil, err
+	}
+
+	_, err := s.store.GetByCode(ctx, Code(alias))
+	if err == nil {
+		return nil, ErrAliasTaken
+	}
+
+	if !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+
+	shortURL := &ShortURL{
+		Code:        Code(alias),
+		OriginalURL: url,
+		URLHash:     "",
+		CreatedAt:   s.clock.Now(),
+		CreatedBy:   CreatorFromContext(ctx),
+		Strategy:    StrategyAlias,
+	}
+	applyShortenOptions(shortURL, opts)
+
+	if err = s.store.Save(ctx, shortURL); err != nil {
+		// Another request claimed the alias between the lookup and the insert.
+		if errors.Is(err, ErrCodeCollision) {
+			return nil, ErrAliasTaken
+		}
+
+		return nil, err
+	}
+
+	return shortURL, nil
+}
+
+// ShortenIfAbsent saves the URL under the alias only if the alias does not exist
+// yet, reporting whether it was created. An existing alias that already points
+// to url is returned unchanged, so retried creates are idempotent; one pointing
+// anywhere else fails with ErrAliasTaken.
+func (s *AliasStrategy) ShortenIfAbsent(
+	ctx context.Context,
+	alias, url string,
+	opts ...ShortenOption,
+) (*ShortURL, bool, error) {
+	shortURL, err := s.Shorten(ctx, alias, url, opts...)
+	if !errors.Is(err, ErrAliasTaken) {
+		return shortURL, err == nil, err
+	}
+
+	existing, err := s.store.GetByCode(ctx, Code(alias))
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, false, ErrAliasTaken
+		}
+
+		return nil, false, err
+	}
+
+	if existing.OriginalURL != url {
+		return nil, false, ErrAliasTaken
+	}
+
+	return existing, false, nil
+}
@@ -0,0 +1,230 @@
+package shortener_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/serroba/web-demo-go/internal/shortener"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAliasPolicy_Validate(t *testing.T) {
+	policy := shortener.AliasPolicy{MaxLength: 10, ReservedPrefixes: []string{"_", "-"}}
+
+	t.Run("accepts valid alias", func(t *testing.T) {
+		require.NoError(t, policy.Validate("my-link_1"))
+	})
+
+	t.Run("rejects empty alias", func(t *testing.T) {
+		err := policy.Validate("")
+
+		require.ErrorIs(t, err, shortener.ErrInvalidAlias)
+	})
+
+	t.Run("rejects over-length alias", func(t *testing.T) {
+		err := policy.Validate(strings.Repeat("a", 11))
+
+		require.ErrorIs(t, err, shortener.ErrInvalidAlias)
+		assert.Contains(t, err.Error(), "at most 10 characters")
+	})
+
+	t.Run("rejects forbidden characters", func(t *testing.T) {
+		err := policy.Validate("my/link")
+
+		require.ErrorIs(t, err, shortener.ErrInvalidAlias)
+		assert.Contains(t, err.Error(), "may only contain")
+	})
+
+	t.Run("rejects reserved prefix", func(t *testing.T) {
+		err := policy.Validate("_admin")
+
+		require.ErrorIs(t, err, shortener.ErrInvalidAlias)
+		assert.Contains(t, err.Error(), `reserved prefix "_"`)
+	})
+
+	t.Run("zero max length disables length check", func(t *testing.T) {
+		require.NoError(t, shortener.AliasPolicy{}.Validate(strings.Repeat("a", 100)))
+	})
+
+	t.Run("rejects a cyrillic look-alike alias", func(t *testing.T) {
+		// "раураl" spells "paypal" with Cyrillic р, а and у
+		err := policy.Validate("раураl")
+
+		require.ErrorIs(t, err, shortener.ErrInvalidAlias)
+		assert.Contains(t, err.Error(), "plain ASCII")
+		assert.Contains(t, err.Error(), `'р'`)
+	})
+
+	t.Run("accepts a plain ascii alias", func(t *testing.T) {
+		require.NoError(t, policy.Validate("paypal"))
+	})
+
+	t.Run("rejects characters outside a configured charset", func(t *testing.T) {
+		strict := shortener.AliasPolicy{Charset: "abcdefghjkmnpqrstuvwxyz23456789-"}
+
+		require.NoError(t, strict.Validate("my-key"))
+
+		err := strict.Validate("my-k3y1")
+		require.ErrorIs(t, err, shortener.ErrInvalidAlias)
+		assert.Contains(t, err.Error(), "may only contain characters from")
+	})
+}
+
+func TestValidateAliasCharset(t *testing.T) {
+	t.Run("accepts a subset of the default charset", func(t *testing.T) {
+		require.NoError(t, shortener.ValidateAliasCharset("abcdef-"))
+		require.NoError(t, shortener.ValidateAliasCharset(shortener.DefaultAliasCharset))
+	})
+
+	t.Run("rejects unsafe or non-ascii characters", func(t *testing.T) {
+		for _, charset := range []string{"", "abc/", "abc.", "abcа"} {
+			require.ErrorIs(t, shortener.ValidateAliasCharset(charset), shortener.ErrInvalidAliasCharset, charset)
+		}
+	})
+}
+
+func TestAliasStrategy_Shorten(t *testing.T) {
+	t.Run("saves url under alias", func(t *testing.T) {
+		var savedURL *shortener.ShortURL
+
+		repo := &mockRepository{
+			saveFunc: func(_ context.Context, s *shortener.ShortURL) error {
+				savedURL = s
+
+				return nil
+			},
+		}
+
+		strategy := shortener.NewAliasStrategy(repo, shortener.DefaultAliasPolicy())
+		result, err := strategy.Shorten(context.Background(), "docs", "https://example.com")
+
+		require.NoError(t, err)
+		assert.Equal(t, shortener.Code("docs"), result.Code)
+		assert.Equal(t, "https://example.com", result.OriginalURL)
+		assert.Same(t, result, savedURL)
+	})
+
+	t.Run("returns invalid alias error without touching store", func(t *testing.T) {
+		repo := &mockRepository{
+			saveFunc: func(_ context.Context, _ *shortener.ShortURL) error {
+				t.Fatal("save should not be called")
+
+				return nil
+			},
+		}
+
+		strategy := shortener.NewAliasStrategy(repo, shortener.DefaultAliasPolicy())
+		_, err := strategy.Shorten(context.Background(), "bad alias", "https://example.com")
+
+		require.ErrorIs(t, err, shortener.ErrInvalidAlias)
+	})
+
+	t.Run("returns alias taken when code exists", func(t *testing.T) {
+		repo := &mockRepository{
+			getByCodeFunc: func(_ context.Context, code shortener.Code) (*shortener.ShortURL, error) {
+				return &shortener.ShortURL{Code: code}, nil
+			},
+		}
+
+		strategy := shortener.NewAliasStrategy(repo, shortener.DefaultAliasPolicy())
+		_, err := strategy.Shorten(context.Background(), "docs", "https://example.com")
+
+		require.ErrorIs(t, err, shortener.ErrAliasTaken)
+	})
+
+	t.Run("returns alias taken when save collides", func(t *testing.T) {
+		repo := &mockRepository{
+			saveFunc: func(_ context.Context, _ *shortener.ShortURL) error {
+				return shortener.ErrCodeCollision
+			},
+		}
+
+		strategy := shortener.NewAliasStrategy(repo, shortener.DefaultAliasPolicy())
+		_, err := strategy.Shorten(context.Background(), "docs", "https://example.com")
+
+		require.ErrorIs(t, err, shortener.ErrAliasTaken)
+	})
+
+	t.Run("returns lookup error", func(t *testing.T) {
+		repoErr := errors.New("repository error")
+		repo := &mockRepository{
+			getByCodeFunc: func(_ context.Context, _ shortener.Code) (*shortener.ShortURL, error) {
+				return nil, repoErr
+			},
+		}
+
+		strategy := shortener.NewAliasStrategy(repo, shortener.DefaultAliasPolicy())
+		_, err := strategy.Shorten(context.Background(), "docs", "https://example.com")
+
+		require.ErrorIs(t, err, repoErr)
+	})
+
+	t.Run("returns save error", func(t *testing.T) {
+		saveErr := errors.New("save failed")
+		repo := &mockRepository{
+			saveFunc: func(_ context.Context, _ *shortener.ShortURL) error {
+				return saveErr
+			},
+		}
+
+		strategy := shortener.NewAliasStrategy(repo, shortener.DefaultAliasPolicy())
+		_, err := strategy.Shorten(context.Background(), "docs", "https://example.com")
+
+		require.ErrorIs(t, err, saveErr)
+	})
+}
+
+func TestAliasStrategy_ShortenIfAbsent(t *testing.T) {
+	existing := func(url string) *mockRepository {
+		return &mockRepository{
+			getByCodeFunc: func(_ context.Context, code shortener.Code) (*shortener.ShortURL, error) {
+				return &shortener.ShortURL{Code: code, OriginalURL: url}, nil
+			},
+		}
+	}
+
+	t.Run("creates a new alias", func(t *testing.T) {
+		strategy := shortener.NewAliasStrategy(&mockRepository{}, shortener.DefaultAliasPolicy())
+		result, created, err := strategy.ShortenIfAbsent(context.Background(), "docs", "https://example.com")
+
+		require.NoError(t, err)
+		assert.True(t, created)
+		assert.Equal(t, shortener.Code("docs"), result.Code)
+	})
+
+	t.Run("returns the existing alias for the same url", func(t *testing.T) {
+		strategy := shortener.NewAliasStrategy(existing("https://example.com"), shortener.DefaultAliasPolicy())
+		result, created, err := strategy.ShortenIfAbsent(context.Background(), "docs", "https://example.com")
+
+		require.NoError(t, err)
+		assert.False(t, created)
+		assert.Equal(t, "https://example.com", result.OriginalURL)
+	})
+
+	t.Run("returns alias taken for a different url", func(t *testing.T) {
+		strategy := shortener.NewAliasStrategy(existing("https://other.example.com"), shortener.DefaultAliasPolicy())
+		_, _, err := strategy.ShortenIfAbsent(context.Background(), "docs", "https://example.com")
+
+		require.ErrorIs(t, err, shortener.ErrAliasTaken)
+	})
+
+	t.Run("returns invalid alias error", func(t *testing.T) {
+		strategy := shortener.NewAliasStrategy(&mockRepository{}, shortener.DefaultAliasPolicy())
+		_, _, err := strategy.ShortenIfAbsent(context.Background(), "bad alias", "https://example.com")
+
+		require.ErrorIs(t, err, shortener.ErrInvalidAlias)
+	})
+}
+
+func TestValidateCode(t *testing.T) {
+	for _, code := range []string{"abc123", "_y05goOy", "a-b_c", "abcdefghijklmnop"} {
+		assert.NoError(t, shortener.ValidateCode(code), code)
+	}
+
+	for _, code := range []string{"", "has space", "abcdefghijklmnopq", "emoji😀"} {
+		assert.ErrorIs(t, shortener.ValidateCode(code), shortener.ErrInvalidCode, code)
+	}
+}
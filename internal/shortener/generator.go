@@ -0,0 +1,134 @@
+package shortener
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+	"sync/atomic"
+
+	"github.com/google/uuid"
+)
+
+// GeneratorType selects one of the built-in code generators.
+type GeneratorType string
+
+const (
+	// GeneratorRandom draws random nanoid codes (default).
+	GeneratorRandom GeneratorType = "random"
+	// GeneratorSequential encodes an incrementing counter, so codes sort in creation order.
+	GeneratorSequential GeneratorType = "sequential"
+	// GeneratorUUID encodes a random UUID and keeps its trailing characters.
+	GeneratorUUID GeneratorType = "uuid"
+)
+
+// SequentialAlphabet is the base62 alphabet of sequential codes. It is in ASCII
+// order so that fixed-width codes sort the same way as their counter values.
+const SequentialAlphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// ErrUnknownGenerator is returned for a generator type that is not built in.
+var ErrUnknownGenerator = errors.New("unknown code generator")
+
+// GeneratorFactory builds a code generator for a code length. When lowercase is
+// set, the generator must only emit LowercaseAlphabet.
+type GeneratorFactory func(length int, lowercase bool) (CodeGenerator, error)
+
+// NewGeneratorFactory returns the factory for a built-in generator type. Sequential
+// generators built by one factory share a counter starting at seed, so strategies
+// with the same code length never hand out the same code.
+func NewGeneratorFactory(kind GeneratorType, seed uint64) (GeneratorFactory, error) {
+	switch kind {
+	case GeneratorRandom, "":
+		return NewCodeGenerator, nil
+	case GeneratorSequential:
+		counter := &atomic.Uint64{}
+		counter.Store(seed)
+
+		return func(length int, lowercase bool) (CodeGenerator, error) {
+			return newSequentialGenerator(length, lowercase, counter)
+		}, nil
+	case GeneratorUUID:
+		return NewUUIDGenerator, nil
+	default:
+		return nil, fmt.Errorf("%w %q: must be 'random', 'sequential' or 'uuid'", ErrUnknownGenerator, kind)
+	}
+}
+
+// NewSequentialGenerator returns a generator of fixed-width base62 (or base36
+// when lowercase) counter values starting at start. The counter wraps around
+// once it exceeds the code space, and it restarts at start with the process,
+// so start should be past any code already handed out.
+func NewSequentialGenerator(length int, lowercase bool, start uint64) (CodeGenerator, error) {
+	counter := &atomic.Uint64{}
+	counter.Store(start)
+
+	return newSequentialGenerator(length, lowercase, counter)
+}
+
+func newSequentialGenerator(length int, lowercase bool, counter *atomic.Uint64) (CodeGenerator, error) {
+	if err := validateCodeLength(length); err != nil {
+		return nil, err
+	}
+
+	alphabet := SequentialAlphabet
+	if lowercase {
+		alphabet = LowercaseAlphabet
+	}
+
+	space := codeSpace(len(alphabet), length)
+
+	return func() string {
+		n := counter.Add(1) - 1
+		if space > 0 {
+			n %= space
+		}
+
+		code := make([]byte, length)
+		for i := length - 1; i >= 0; i-- {
+			code[i] = alphabet[n%uint64(len(alphabet))]
+			n /= uint64(len(alphabet))
+		}
+
+		return string(code)
+	}, nil
+}
+
+// codeSpace returns base^length, or 0 when it does not fit in a uint64.
+func codeSpace(base, length int) uint64 {
+	space := uint64(1)
+
+	for range length {
+		if space > math.MaxUint64/uint64(base) {
+			return 0
+		}
+
+		space *= uint64(base)
+	}
+
+	return space
+}
+
+// NewUUIDGenerator returns a generator that encodes a random UUID in base62 (or
+// base36 when lowercase) and keeps its last length characters.
+func NewUUIDGenerator(length int, lowercase bool) (CodeGenerator, error) {
+	if err := validateCodeLength(length); err != nil {
+		return nil, err
+	}
+
+	base := 62
+	if lowercase {
+		base = 36
+	}
+
+	return func() string {
+		id := uuid.New()
+		encoded := new(big.Int).SetBytes(id[:]).Text(base)
+
+		if len(encoded) < length {
+			encoded = strings.Repeat("0", length-len(encoded)) + encoded
+		}
+
+		return encoded[len(encoded)-length:]
+	}, nil
+}
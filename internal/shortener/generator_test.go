@@ -0,0 +1,126 @@
+package shortener_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/serroba/web-demo-go/internal/shortener"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSequentialGenerator(t *testing.T) {
+	t.Run("produces monotonic fixed-width codes", func(t *testing.T) {
+		gen, err := shortener.NewSequentialGenerator(6, false, 60)
+		require.NoError(t, err)
+
+		codes := []string{gen(), gen(), gen(), gen()}
+
+		assert.Equal(t, []string{"00000y", "00000z", "000010", "000011"}, codes)
+
+		for i := 1; i < len(codes); i++ {
+			assert.Less(t, codes[i-1], codes[i])
+		}
+	})
+
+	t.Run("lowercase uses base36", func(t *testing.T) {
+		gen, err := shortener.NewSequentialGenerator(4, true, 35)
+		require.NoError(t, err)
+
+		assert.Equal(t, "000z", gen())
+		assert.Equal(t, "0010", gen())
+	})
+
+	t.Run("wraps around at the end of the code space", func(t *testing.T) {
+		gen, err := shortener.NewSequentialGenerator(4, true, 36*36*36*36-1)
+		require.NoError(t, err)
+
+		assert.Equal(t, "zzzz", gen())
+		assert.Equal(t, "0000", gen())
+	})
+
+	t.Run("rejects out of range lengths", func(t *testing.T) {
+		_, err := shortener.NewSequentialGenerator(shortener.MaxCodeLength+1, false, 0)
+
+		require.ErrorIs(t, err, shortener.ErrInvalidCodeLength)
+	})
+}
+
+func TestNewUUIDGenerator(t *testing.T) {
+	t.Run("generates distinct codes of the requested length", func(t *testing.T) {
+		gen, err := shortener.NewUUIDGenerator(10, false)
+		require.NoError(t, err)
+
+		first, second := gen(), gen()
+
+		assert.Len(t, first, 10)
+		assert.NotEqual(t, first, second)
+	})
+
+	t.Run("lowercase generator only emits lowercase alphabet", func(t *testing.T) {
+		gen, err := shortener.NewUUIDGenerator(16, true)
+		require.NoError(t, err)
+
+		for _, r := range gen() {
+			assert.True(t, strings.ContainsRune(shortener.LowercaseAlphabet, r))
+		}
+	})
+}
+
+func TestNewGeneratorFactory(t *testing.T) {
+	t.Run("builds every built-in type", func(t *testing.T) {
+		for _, kind := range []shortener.GeneratorType{
+			shortener.GeneratorRandom, shortener.GeneratorSequential, shortener.GeneratorUUID,
+		} {
+			factory, err := shortener.NewGeneratorFactory(kind, 0)
+			require.NoError(t, err, kind)
+
+			gen, err := factory(8, false)
+			require.NoError(t, err, kind)
+			assert.Len(t, gen(), 8, kind)
+		}
+	})
+
+	t.Run("sequential generators share one counter", func(t *testing.T) {
+		factory, err := shortener.NewGeneratorFactory(shortener.GeneratorSequential, 0)
+		require.NoError(t, err)
+
+		token, err := factory(4, false)
+		require.NoError(t, err)
+		hash, err := factory(4, false)
+		require.NoError(t, err)
+
+		assert.Equal(t, "0000", token())
+		assert.Equal(t, "0001", hash())
+		assert.Equal(t, "0002", token())
+	})
+
+	t.Run("rejects unknown types", func(t *testing.T) {
+		_, err := shortener.NewGeneratorFactory("snowflake", 0)
+
+		require.ErrorIs(t, err, shortener.ErrUnknownGenerator)
+	})
+}
+
+func TestStrategiesUseInjectedGenerator(t *testing.T) {
+	// A deterministic generator stands in for a custom one provided by users.
+	codes := []string{"first", "second"}
+	next := 0
+	gen := func() string {
+		code := codes[next]
+		next++
+
+		return code
+	}
+
+	repo := &mockRepository{}
+
+	token, err := shortener.NewTokenStrategy(repo, gen).Shorten(context.Background(), "https://example.com/a")
+	require.NoError(t, err)
+	hash, err := shortener.NewHashStrategy(repo, gen).Shorten(context.Background(), "https://example.com/b")
+	require.NoError(t, err)
+
+	assert.Equal(t, shortener.Code("first"), token.Code)
+	assert.Equal(t, shortener.Code("second"), hash.Code)
+}
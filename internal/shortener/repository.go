@@ -5,12 +5,46 @@ import (
 	"errors"
 )
 
-// ErrNotFound is returned when a short URL is not found.
-var ErrNotFound = errors.New("short url not found")
+var (
+	// ErrNotFound is returned when a short URL is not found.
+	ErrNotFound = errors.New("short url not found")
+	// ErrCodeCollision is returned by Save when the code already belongs to another short URL.
+	ErrCodeCollision = errors.New("code already in use")
+)
 
 // Repository defines the interface for short URL storage operations.
 type Repository interface {
+	// Save stores a new short URL. It returns ErrCodeCollision instead of
+	// overwriting when the code is taken.
 	Save(ctx context.Context, shortURL *ShortURL) error
+	// SaveBatch saves several short URLs in as few round trips as the backend
+	// allows and returns the codes it inserted. Short URLs whose code is
+	// already taken are left untouched and omitted from the result.
+	SaveBatch(ctx context.Context, shortURLs []*ShortURL) ([]Code, error)
 	GetByCode(ctx context.Context, code Code) (*ShortURL, error)
 	GetByHash(ctx context.Context, hash URLHash) (*ShortURL, error)
+	// GetWithStats returns the short URL stored under code with its access
+	// count in a single round trip. Backends that do not count accesses
+	// report 0. It returns ErrNotFound when the code does not exist.
+	GetWithStats(ctx context.Context, code Code) (*ShortURLStats, error)
+	// Exists reports for each of codes whether it is taken, in as few round
+	// trips as the backend allows. The map has an entry for every code.
+	Exists(ctx context.Context, codes []Code) (map[Code]bool, error)
+	Count(ctx context.Context) (int64, error)
+	// MostRecent returns up to limit short URLs, newest first by creation time.
+	MostRecent(ctx context.Context, limit int) ([]*ShortURL, error)
+	// ListByCreator returns up to limit short URLs created by creator, newest
+	// first. An empty creator matches nothing, so unauthenticated creates are
+	// never listed.
+	ListByCreator(ctx context.Context, creator string, limit int) ([]*ShortURL, error)
+	// UpdateTarget points code at newURL, stored exactly as given so redirects
+	// go where the caller asked. normalizedURL is newURL after NormalizeURL:
+	// short URLs that carry a URL hash get its hash, so hash lookups find them
+	// under their new target, and those storing a normalized URL get it. It
+	// returns ErrNotFound when the code does not exist.
+	UpdateTarget(ctx context.Context, code Code, newURL, normalizedURL string) error
+	// Flag marks the target of code as known bad, or clears the mark, so
+	// redirects use the fallback URL while it is set. It returns ErrNotFound
+	// when the code does not exist.
+	Flag(ctx context.Context, code Code, flagged bool) error
 }
@@ -1,6 +1,9 @@
 package shortener
 
-import "time"
+import (
+	"context"
+	"time"
+)
 
 // Code represents a short URL code.
 type Code string
@@ -14,4 +17,91 @@ type ShortURL struct {
 	OriginalURL string
 	URLHash     URLHash // empty for token strategy, populated for hash strategy
 	CreatedAt   time.Time
+	FallbackURL string // optional target used while the original URL is flagged
+	Flagged     bool   // set when the original URL is known to be bad
+	// NormalizedURL is OriginalURL after NormalizeURL, stored when enabled so
+	// equivalent URLs can be grouped regardless of strategy. Empty otherwise.
+	NormalizedURL string
+	// CreatedBy identifies the API key or user that created the short URL.
+	// Empty for unauthenticated creates.
+	CreatedBy string
+	// Strategy names the strategy that created the short URL (StrategyToken,
+	// StrategyHash or StrategyAlias). Empty for imported and older records.
+	Strategy string
+	// ContentTypeHint is the media type the target is expected to serve, such
+	// as application/pdf for a download, so clients can preview it. Optional.
+	ContentTypeHint string
+}
+
+// Names stored in ShortURL.Strategy.
+const (
+	StrategyToken = "token"
+	StrategyHash  = "hash"
+	StrategyAlias = "alias"
+)
+
+// ShortURLStats is a short URL together with its access count.
+type ShortURLStats struct {
+	ShortURL *ShortURL
+	// AccessCount is the persisted number of redirects, which trails live
+	// traffic by up to the analytics consumer's flush interval.
+	AccessCount int64
+}
+
+// ShortenOption sets an optional detail of a short URL a strategy creates.
+// Short URLs a strategy returns because they already exist are left unchanged.
+type ShortenOption func(*ShortURL)
+
+// WithFallbackURL stores url as the fallback used while the target is flagged.
+func WithFallbackURL(url string) ShortenOption {
+	return func(s *ShortURL) {
+		s.FallbackURL = url
+	}
+}
+
+// WithContentTypeHint stores hint as the media type the target is expected to
+// serve.
+func WithContentTypeHint(hint string) ShortenOption {
+	return func(s *ShortURL) {
+		s.ContentTypeHint = hint
+	}
+}
+
+// applyShortenOptions applies opts to a short URL being created.
+func applyShortenOptions(shortURL *ShortURL, opts []ShortenOption) {
+	for _, opt := range opts {
+		opt(shortURL)
+	}
+}
+
+type creatorKey struct{}
+
+// WithCreator returns a copy of ctx carrying the identity of the API key or user
+// making the request. Authentication middleware calls it once the caller has
+// been resolved; strategies store it as CreatedBy.
+func WithCreator(ctx context.Context, creator string) context.Context {
+	return context.WithValue(ctx, creatorKey{}, creator)
+}
+
+// CreatorFromContext returns the creator stored in ctx, or "".
+func CreatorFromContext(ctx context.Context) string {
+	creator, _ := ctx.Value(creatorKey{}).(string)
+
+	return creator
+}
+
+type warmUpKey struct{}
+
+// WithWarmUp returns a copy of ctx marking the lookups made with it as cache
+// warm-ups rather than client requests, so repository decorators measuring
+// client traffic can tell them apart.
+func WithWarmUp(ctx context.Context) context.Context {
+	return context.WithValue(ctx, warmUpKey{}, true)
+}
+
+// IsWarmUp reports whether ctx was marked by WithWarmUp.
+func IsWarmUp(ctx context.Context) bool {
+	warmUp, _ := ctx.Value(warmUpKey{}).(bool)
+
+	return warmUp
 }
@@ -3,40 +3,151 @@ package shortener
 import (
 	"context"
 	"errors"
-	"time"
+	"fmt"
+
+	"github.com/jaevor/go-nanoid"
+	"github.com/serroba/web-demo-go/internal/clock"
 )
 
 // Strategy defines the interface for URL shortening strategies.
 type Strategy interface {
-	Shorten(ctx context.Context, url string) (*ShortURL, error)
+	Shorten(ctx context.Context, url string, opts ...ShortenOption) (*ShortURL, error)
 }
 
 // CodeGenerator generates unique short codes.
 type CodeGenerator func() string
 
+// LowercaseAlphabet is the code alphabet used when codes are case-insensitive.
+const LowercaseAlphabet = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+const (
+	// MinCodeLength is the shortest generated code allowed.
+	MinCodeLength = 4
+	// MaxCodeLength matches the width of the short_urls.code column.
+	MaxCodeLength = 16
+)
+
+// ErrInvalidCodeLength is returned when a generated code length is out of range.
+var ErrInvalidCodeLength = errors.New("invalid code length")
+
+// ErrInvalidStrategy is returned when a strategy name is not one of the
+// registered strategies.
+var ErrInvalidStrategy = errors.New("invalid strategy")
+
+// maxCodeAttempts bounds how many generated codes are tried when Save reports a collision.
+const maxCodeAttempts = 3
+
+// saveWithFreshCode saves shortURL under a generated code, drawing a new code
+// whenever the store reports ErrCodeCollision.
+func saveWithFreshCode(ctx context.Context, store Repository, generate CodeGenerator, shortURL *ShortURL) error {
+	var err error
+
+	for range maxCodeAttempts {
+		shortURL.Code = Code(generate())
+
+		if err = store.Save(ctx, shortURL); !errors.Is(err, ErrCodeCollision) {
+			return err
+		}
+	}
+
+	return fmt.Errorf("no free code after %d attempts: %w", maxCodeAttempts, err)
+}
+
+// NewCodeGenerator returns a random code generator for the given length.
+// When lowercase is set, codes only use LowercaseAlphabet.
+func NewCodeGenerator(length int, lowercase bool) (CodeGenerator, error) {
+	if err := validateCodeLength(length); err != nil {
+		return nil, err
+	}
+
+	if lowercase {
+		return nanoid.CustomASCII(LowercaseAlphabet, length)
+	}
+
+	return nanoid.Standard(length)
+}
+
+func validateCodeLength(length int) error {
+	if length < MinCodeLength || length > MaxCodeLength {
+		return fmt.Errorf("%w: %d must be between %d and %d",
+			ErrInvalidCodeLength, length, MinCodeLength, MaxCodeLength)
+	}
+
+	return nil
+}
+
 // TokenStrategy always generates a new code for each URL.
 type TokenStrategy struct {
-	store        Repository
-	generateCode CodeGenerator
+	store           Repository
+	generateCode    CodeGenerator
+	clock           clock.Clock
+	storeNormalized bool
+	normalizeOpts   []NormalizeOption
+}
+
+// TokenStrategyOption configures optional TokenStrategy behavior.
+type TokenStrategyOption func(*TokenStrategy)
+
+// WithTokenClock sets the clock used to stamp CreatedAt.
+func WithTokenClock(c clock.Clock) TokenStrategyOption {
+	return func(s *TokenStrategy) {
+		s.clock = c
+	}
+}
+
+// WithTokenNormalizedURL stores the normalized form of each URL in
+// ShortURL.NormalizedURL.
+func WithTokenNormalizedURL() TokenStrategyOption {
+	return func(s *TokenStrategy) {
+		s.storeNormalized = true
+	}
+}
+
+// WithTokenNormalizeOptions normalizes every URL with opts, rejecting URLs that
+// exceed their limits even when the normalized form is not stored.
+func WithTokenNormalizeOptions(opts ...NormalizeOption) TokenStrategyOption {
+	return func(s *TokenStrategy) {
+		s.normalizeOpts = opts
+	}
 }
 
 // NewTokenStrategy creates a new token-based shortening strategy.
-func NewTokenStrategy(store Repository, generator CodeGenerator) *TokenStrategy {
-	return &TokenStrategy{
+func NewTokenStrategy(store Repository, generator CodeGenerator, opts ...TokenStrategyOption) *TokenStrategy {
+	s := &TokenStrategy{
 		store:        store,
 		generateCode: generator,
+		clock:        clock.Real{},
+	}
+
+	for _, opt := range opts {
+		opt(s)
 	}
+
+	return s
 }
 
-func (s *TokenStrategy) Shorten(ctx context.Context, url string) (*ShortURL, error) {
+func (s *TokenStrategy) Shorten(ctx context.Context, url string, opts ...ShortenOption) (*ShortURL, error) {
 	shortURL := &ShortURL{
-		Code:        Code(s.generateCode()),
 		OriginalURL: url,
 		URLHash:     "",
-		CreatedAt:   time.Now(),
+		CreatedAt:   s.clock.Now(),
+		CreatedBy:   CreatorFromContext(ctx),
+		Strategy:    StrategyToken,
 	}
+	applyShortenOptions(shortURL, opts)
 
-	if err := s.store.Save(ctx, shortURL); err != nil {
+	if s.storeNormalized || len(s.normalizeOpts) > 0 {
+		normalizedURL, err := NormalizeURL(url, s.normalizeOpts...)
+		if err != nil {
+			return nil, err
+		}
+
+		if s.storeNormalized {
+			shortURL.NormalizedURL = normalizedURL
+		}
+	}
+
+	if err := saveWithFreshCode(ctx, s.store, s.generateCode, shortURL); err != nil {
 		return nil, err
 	}
 
@@ -45,20 +156,71 @@ func (s *TokenStrategy) Shorten(ctx context.Context, url string) (*ShortURL, err
 
 // HashStrategy deduplicates URLs by returning the same code for identical URLs.
 type HashStrategy struct {
-	store        Repository
-	generateCode CodeGenerator
+	store           Repository
+	generateCode    CodeGenerator
+	rules           URLRules
+	clock           clock.Clock
+	storeNormalized bool
+	normalizeOpts   []NormalizeOption
+}
+
+// HashStrategyOption configures optional HashStrategy behavior.
+type HashStrategyOption func(*HashStrategy)
+
+// WithURLRules rejects URLs that fail the given rules before they are hashed.
+func WithURLRules(rules URLRules) HashStrategyOption {
+	return func(s *HashStrategy) {
+		s.rules = rules
+	}
+}
+
+// WithHashClock sets the clock used to stamp CreatedAt.
+func WithHashClock(c clock.Clock) HashStrategyOption {
+	return func(s *HashStrategy) {
+		s.clock = c
+	}
+}
+
+// WithHashNormalizedURL stores the normalized form of each URL, the one it is
+// hashed from, in ShortURL.NormalizedURL.
+func WithHashNormalizedURL() HashStrategyOption {
+	return func(s *HashStrategy) {
+		s.storeNormalized = true
+	}
+}
+
+// WithHashNormalizeOptions passes opts to NormalizeURL before each URL is hashed.
+func WithHashNormalizeOptions(opts ...NormalizeOption) HashStrategyOption {
+	return func(s *HashStrategy) {
+		s.normalizeOpts = opts
+	}
 }
 
 // NewHashStrategy creates a new hash-based shortening strategy.
-func NewHashStrategy(store Repository, generator CodeGenerator) *HashStrategy {
-	return &HashStrategy{
+func NewHashStrategy(store Repository, generator CodeGenerator, opts ...HashStrategyOption) *HashStrategy {
+	s := &HashStrategy{
 		store:        store,
 		generateCode: generator,
+		clock:        clock.Real{},
 	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
 }
 
-func (s *HashStrategy) Shorten(ctx context.Context, rawURL string) (*ShortURL, error) {
-	normalizedURL, err := NormalizeURL(rawURL)
+// Shorten returns the short URL already stored for rawURL's normalized form, or
+// saves a new one. Normalization only builds the dedup key: URLs differing in
+// scheme or host case share a code, while OriginalURL keeps the URL exactly as
+// first submitted so redirects go where that creator asked, path case included.
+func (s *HashStrategy) Shorten(ctx context.Context, rawURL string, opts ...ShortenOption) (*ShortURL, error) {
+	if err := s.rules.Validate(rawURL); err != nil {
+		return nil, err
+	}
+
+	normalizedURL, err := NormalizeURL(rawURL, s.normalizeOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -75,13 +237,19 @@ func (s *HashStrategy) Shorten(ctx context.Context, rawURL string) (*ShortURL, e
 	}
 
 	shortURL := &ShortURL{
-		Code:        Code(s.generateCode()),
 		OriginalURL: rawURL,
 		URLHash:     urlHash,
-		CreatedAt:   time.Now(),
+		CreatedAt:   s.clock.Now(),
+		CreatedBy:   CreatorFromContext(ctx),
+		Strategy:    StrategyHash,
+	}
+	applyShortenOptions(shortURL, opts)
+
+	if s.storeNormalized {
+		shortURL.NormalizedURL = normalizedURL
 	}
 
-	if err = s.store.Save(ctx, shortURL); err != nil {
+	if err = saveWithFreshCode(ctx, s.store, s.generateCode, shortURL); err != nil {
 		return nil, err
 	}
 
@@ -4,7 +4,9 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
+	"github.com/serroba/web-demo-go/internal/clock"
 	"github.com/serroba/web-demo-go/internal/shortener"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -42,6 +44,38 @@ func (m *mockRepository) GetByHash(ctx context.Context, hash shortener.URLHash)
 	return nil, shortener.ErrNotFound
 }
 
+func (m *mockRepository) GetWithStats(_ context.Context, _ shortener.Code) (*shortener.ShortURLStats, error) {
+	return nil, shortener.ErrNotFound
+}
+
+func (m *mockRepository) SaveBatch(_ context.Context, _ []*shortener.ShortURL) ([]shortener.Code, error) {
+	return nil, nil
+}
+
+func (m *mockRepository) Exists(_ context.Context, _ []shortener.Code) (map[shortener.Code]bool, error) {
+	return nil, nil
+}
+
+func (m *mockRepository) Count(_ context.Context) (int64, error) {
+	return 0, nil
+}
+
+func (m *mockRepository) MostRecent(_ context.Context, _ int) ([]*shortener.ShortURL, error) {
+	return nil, nil
+}
+
+func (m *mockRepository) ListByCreator(_ context.Context, _ string, _ int) ([]*shortener.ShortURL, error) {
+	return nil, nil
+}
+
+func (m *mockRepository) UpdateTarget(_ context.Context, _ shortener.Code, _, _ string) error {
+	return nil
+}
+
+func (m *mockRepository) Flag(_ context.Context, _ shortener.Code, _ bool) error {
+	return nil
+}
+
 func TestTokenStrategy_Shorten(t *testing.T) {
 	t.Run("generates new code and saves", func(t *testing.T) {
 		var savedURL *shortener.ShortURL
@@ -80,6 +114,54 @@ func TestTokenStrategy_Shorten(t *testing.T) {
 		assert.Nil(t, result)
 		assert.ErrorIs(t, err, saveErr)
 	})
+
+	t.Run("retries with a fresh code on collision", func(t *testing.T) {
+		taken := map[shortener.Code]bool{"taken1": true}
+		repo := &mockRepository{
+			saveFunc: func(_ context.Context, s *shortener.ShortURL) error {
+				if taken[s.Code] {
+					return shortener.ErrCodeCollision
+				}
+
+				taken[s.Code] = true
+
+				return nil
+			},
+		}
+
+		codes := []string{"taken1", "fresh1"}
+		generator := func() string {
+			code := codes[0]
+			codes = codes[1:]
+
+			return code
+		}
+
+		strategy := shortener.NewTokenStrategy(repo, generator)
+		result, err := strategy.Shorten(context.Background(), "https://example.com")
+
+		require.NoError(t, err)
+		assert.Equal(t, shortener.Code("fresh1"), result.Code)
+		assert.Empty(t, codes, "generator should be called once per attempt")
+	})
+
+	t.Run("gives up when every code collides", func(t *testing.T) {
+		attempts := 0
+		repo := &mockRepository{
+			saveFunc: func(_ context.Context, _ *shortener.ShortURL) error {
+				attempts++
+
+				return shortener.ErrCodeCollision
+			},
+		}
+
+		strategy := shortener.NewTokenStrategy(repo, func() string { return "taken1" })
+		result, err := strategy.Shorten(context.Background(), "https://example.com")
+
+		assert.Nil(t, result)
+		require.ErrorIs(t, err, shortener.ErrCodeCollision)
+		assert.Equal(t, 3, attempts)
+	})
 }
 
 func TestHashStrategy_Shorten(t *testing.T) {
@@ -174,3 +256,334 @@ func TestHashStrategy_Shorten(t *testing.T) {
 		assert.Error(t, err)
 	})
 }
+
+func TestHashStrategy_HostCase(t *testing.T) {
+	saved := map[shortener.URLHash]*shortener.ShortURL{}
+	repo := &mockRepository{
+		getByHashFunc: func(_ context.Context, hash shortener.URLHash) (*shortener.ShortURL, error) {
+			if s, ok := saved[hash]; ok {
+				return s, nil
+			}
+
+			return nil, shortener.ErrNotFound
+		},
+		saveFunc: func(_ context.Context, s *shortener.ShortURL) error {
+			saved[s.URLHash] = s
+
+			return nil
+		},
+	}
+
+	strategy := shortener.NewHashStrategy(repo, func() string { return testNewCode })
+
+	first, err := strategy.Shorten(context.Background(), "HTTPS://Example.COM/Docs")
+	require.NoError(t, err)
+
+	second, err := strategy.Shorten(context.Background(), "https://example.com/Docs")
+	require.NoError(t, err)
+
+	assert.Same(t, first, second)
+	assert.Equal(t, "HTTPS://Example.COM/Docs", second.OriginalURL)
+}
+
+func TestNewCodeGenerator(t *testing.T) {
+	t.Run("generates codes of the requested length", func(t *testing.T) {
+		gen, err := shortener.NewCodeGenerator(6, false)
+
+		require.NoError(t, err)
+		assert.Len(t, gen(), 6)
+	})
+
+	t.Run("lowercase generator only emits lowercase alphabet", func(t *testing.T) {
+		gen, err := shortener.NewCodeGenerator(16, true)
+
+		require.NoError(t, err)
+
+		for _, r := range gen() {
+			assert.Contains(t, shortener.LowercaseAlphabet, string(r))
+		}
+	})
+
+	t.Run("rejects out of range lengths", func(t *testing.T) {
+		for _, length := range []int{0, shortener.MinCodeLength - 1, shortener.MaxCodeLength + 1} {
+			_, err := shortener.NewCodeGenerator(length, false)
+
+			require.ErrorIs(t, err, shortener.ErrInvalidCodeLength)
+		}
+	})
+
+	t.Run("strategies use their own code lengths", func(t *testing.T) {
+		repo := &mockRepository{}
+		tokenGen, err := shortener.NewCodeGenerator(12, false)
+		require.NoError(t, err)
+		hashGen, err := shortener.NewCodeGenerator(5, false)
+		require.NoError(t, err)
+
+		token, err := shortener.NewTokenStrategy(repo, tokenGen).Shorten(context.Background(), "https://example.com")
+		require.NoError(t, err)
+		hash, err := shortener.NewHashStrategy(repo, hashGen).Shorten(context.Background(), "https://example.com")
+		require.NoError(t, err)
+
+		assert.Len(t, string(token.Code), 12)
+		assert.Len(t, string(hash.Code), 5)
+	})
+}
+
+func TestHashStrategy_URLRules(t *testing.T) {
+	rules := shortener.URLRules{RequireDottedHost: true}
+
+	t.Run("rejects bare hostname without touching store", func(t *testing.T) {
+		repo := &mockRepository{
+			getByHashFunc: func(_ context.Context, _ shortener.URLHash) (*shortener.ShortURL, error) {
+				t.Fatal("store should not be queried")
+
+				return nil, nil
+			},
+		}
+
+		strategy := shortener.NewHashStrategy(repo, func() string { return testNewCode }, shortener.WithURLRules(rules))
+		_, err := strategy.Shorten(context.Background(), "http://a")
+
+		require.ErrorIs(t, err, shortener.ErrInvalidURL)
+	})
+
+	t.Run("accepts valid url", func(t *testing.T) {
+		strategy := shortener.NewHashStrategy(&mockRepository{}, func() string { return testNewCode }, shortener.WithURLRules(rules))
+		result, err := strategy.Shorten(context.Background(), "https://example.com")
+
+		require.NoError(t, err)
+		assert.Equal(t, shortener.Code(testNewCode), result.Code)
+	})
+}
+
+func TestStrategies_NormalizeOptions(t *testing.T) {
+	deep := "https://example.com/a/b/c/d/e"
+	limit := shortener.WithMaxPathSegments(4)
+	generator := func() string { return testNewCode }
+
+	t.Run("token strategy rejects an over-deep path", func(t *testing.T) {
+		strategy := shortener.NewTokenStrategy(&mockRepository{}, generator, shortener.WithTokenNormalizeOptions(limit))
+		_, err := strategy.Shorten(context.Background(), deep)
+
+		require.ErrorIs(t, err, shortener.ErrPathTooDeep)
+	})
+
+	t.Run("hash strategy rejects an over-deep path", func(t *testing.T) {
+		strategy := shortener.NewHashStrategy(&mockRepository{}, generator, shortener.WithHashNormalizeOptions(limit))
+		_, err := strategy.Shorten(context.Background(), deep)
+
+		require.ErrorIs(t, err, shortener.ErrPathTooDeep)
+	})
+
+	t.Run("accepts a path within the limit", func(t *testing.T) {
+		strategy := shortener.NewHashStrategy(&mockRepository{}, generator, shortener.WithHashNormalizeOptions(limit))
+		result, err := strategy.Shorten(context.Background(), "https://example.com/a/b")
+
+		require.NoError(t, err)
+		assert.Equal(t, shortener.Code(testNewCode), result.Code)
+	})
+}
+
+func TestStrategies_Clock(t *testing.T) {
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	c := clock.NewFake(now)
+	generator := func() string { return "abc123" }
+
+	t.Run("token strategy stamps clock time", func(t *testing.T) {
+		strategy := shortener.NewTokenStrategy(&mockRepository{}, generator, shortener.WithTokenClock(c))
+
+		result, err := strategy.Shorten(context.Background(), "https://example.com")
+
+		require.NoError(t, err)
+		assert.Equal(t, now, result.CreatedAt)
+	})
+
+	t.Run("hash strategy stamps clock time", func(t *testing.T) {
+		strategy := shortener.NewHashStrategy(&mockRepository{}, generator, shortener.WithHashClock(c))
+
+		result, err := strategy.Shorten(context.Background(), "https://example.com")
+
+		require.NoError(t, err)
+		assert.Equal(t, now, result.CreatedAt)
+	})
+
+	t.Run("alias strategy stamps clock time", func(t *testing.T) {
+		strategy := shortener.NewAliasStrategy(&mockRepository{}, shortener.DefaultAliasPolicy(),
+			shortener.WithAliasClock(c))
+
+		result, err := strategy.Shorten(context.Background(), "docs", "https://example.com")
+
+		require.NoError(t, err)
+		assert.Equal(t, now, result.CreatedAt)
+	})
+}
+
+func TestStrategies_FallbackURL(t *testing.T) {
+	ctx := context.Background()
+	fallback := shortener.WithFallbackURL("https://backup.example.com")
+	generator := func() string { return "abc123" }
+
+	t.Run("token strategy stores the fallback", func(t *testing.T) {
+		result, err := shortener.NewTokenStrategy(&mockRepository{}, generator).
+			Shorten(ctx, "https://example.com", fallback)
+
+		require.NoError(t, err)
+		assert.Equal(t, "https://backup.example.com", result.FallbackURL)
+	})
+
+	t.Run("hash strategy stores the fallback", func(t *testing.T) {
+		result, err := shortener.NewHashStrategy(&mockRepository{}, generator).
+			Shorten(ctx, "https://example.com", fallback)
+
+		require.NoError(t, err)
+		assert.Equal(t, "https://backup.example.com", result.FallbackURL)
+	})
+
+	t.Run("alias strategy stores the fallback", func(t *testing.T) {
+		result, err := shortener.NewAliasStrategy(&mockRepository{}, shortener.DefaultAliasPolicy()).
+			Shorten(ctx, "docs", "https://example.com", fallback)
+
+		require.NoError(t, err)
+		assert.Equal(t, "https://backup.example.com", result.FallbackURL)
+	})
+
+	t.Run("no fallback without the option", func(t *testing.T) {
+		result, err := shortener.NewTokenStrategy(&mockRepository{}, generator).Shorten(ctx, "https://example.com")
+
+		require.NoError(t, err)
+		assert.Empty(t, result.FallbackURL)
+	})
+}
+
+func TestStrategies_ContentTypeHint(t *testing.T) {
+	ctx := context.Background()
+	hint := shortener.WithContentTypeHint("application/pdf")
+	generator := func() string { return "abc123" }
+
+	t.Run("token strategy stores the hint", func(t *testing.T) {
+		result, err := shortener.NewTokenStrategy(&mockRepository{}, generator).
+			Shorten(ctx, "https://example.com/report.pdf", hint)
+
+		require.NoError(t, err)
+		assert.Equal(t, "application/pdf", result.ContentTypeHint)
+	})
+
+	t.Run("hash strategy stores the hint", func(t *testing.T) {
+		result, err := shortener.NewHashStrategy(&mockRepository{}, generator).
+			Shorten(ctx, "https://example.com/report.pdf", hint)
+
+		require.NoError(t, err)
+		assert.Equal(t, "application/pdf", result.ContentTypeHint)
+	})
+
+	t.Run("alias strategy stores the hint", func(t *testing.T) {
+		result, err := shortener.NewAliasStrategy(&mockRepository{}, shortener.DefaultAliasPolicy()).
+			Shorten(ctx, "report", "https://example.com/report.pdf", hint)
+
+		require.NoError(t, err)
+		assert.Equal(t, "application/pdf", result.ContentTypeHint)
+	})
+}
+
+func TestStrategies_NormalizedURL(t *testing.T) {
+	generator := func() string { return "abc123" }
+	rawURL := "HTTPS://Example.COM:443/Path/#"
+
+	t.Run("token strategy stores the normalized url when enabled", func(t *testing.T) {
+		result, err := shortener.NewTokenStrategy(&mockRepository{}, generator, shortener.WithTokenNormalizedURL()).
+			Shorten(context.Background(), rawURL)
+
+		require.NoError(t, err)
+		assert.Equal(t, rawURL, result.OriginalURL)
+		assert.Equal(t, "https://example.com/Path", result.NormalizedURL)
+	})
+
+	t.Run("hash strategy stores the url it hashed when enabled", func(t *testing.T) {
+		var saved *shortener.ShortURL
+
+		repo := &mockRepository{saveFunc: func(_ context.Context, shortURL *shortener.ShortURL) error {
+			saved = shortURL
+
+			return nil
+		}}
+
+		result, err := shortener.NewHashStrategy(repo, generator, shortener.WithHashNormalizedURL()).
+			Shorten(context.Background(), rawURL)
+
+		require.NoError(t, err)
+		assert.Equal(t, rawURL, saved.OriginalURL)
+		assert.Equal(t, "https://example.com/Path", saved.NormalizedURL)
+		assert.Equal(t, shortener.URLHash(shortener.HashURL(result.NormalizedURL)), result.URLHash)
+	})
+
+	t.Run("already normalized urls are stored unchanged", func(t *testing.T) {
+		result, err := shortener.NewTokenStrategy(&mockRepository{}, generator, shortener.WithTokenNormalizedURL()).
+			Shorten(context.Background(), "https://example.com/path")
+
+		require.NoError(t, err)
+		assert.Equal(t, result.OriginalURL, result.NormalizedURL)
+	})
+
+	t.Run("not stored by default", func(t *testing.T) {
+		token, err := shortener.NewTokenStrategy(&mockRepository{}, generator).Shorten(context.Background(), rawURL)
+		require.NoError(t, err)
+		assert.Empty(t, token.NormalizedURL)
+
+		hash, err := shortener.NewHashStrategy(&mockRepository{}, generator).Shorten(context.Background(), rawURL)
+		require.NoError(t, err)
+		assert.Empty(t, hash.NormalizedURL)
+	})
+}
+
+func TestStrategies_CreatedBy(t *testing.T) {
+	generator := func() string { return "abc123" }
+	ctx := shortener.WithCreator(context.Background(), "key:team-a")
+
+	t.Run("token strategy stores the creator", func(t *testing.T) {
+		result, err := shortener.NewTokenStrategy(&mockRepository{}, generator).Shorten(ctx, "https://example.com")
+
+		require.NoError(t, err)
+		assert.Equal(t, "key:team-a", result.CreatedBy)
+	})
+
+	t.Run("hash strategy stores the creator", func(t *testing.T) {
+		result, err := shortener.NewHashStrategy(&mockRepository{}, generator).Shorten(ctx, "https://example.com")
+
+		require.NoError(t, err)
+		assert.Equal(t, "key:team-a", result.CreatedBy)
+	})
+
+	t.Run("alias strategy stores the creator", func(t *testing.T) {
+		result, err := shortener.NewAliasStrategy(&mockRepository{}, shortener.DefaultAliasPolicy()).
+			Shorten(ctx, "my-link", "https://example.com")
+
+		require.NoError(t, err)
+		assert.Equal(t, "key:team-a", result.CreatedBy)
+	})
+
+	t.Run("unauthenticated creates store no creator", func(t *testing.T) {
+		result, err := shortener.NewTokenStrategy(&mockRepository{}, generator).
+			Shorten(context.Background(), "https://example.com")
+
+		require.NoError(t, err)
+		assert.Empty(t, result.CreatedBy)
+	})
+}
+
+func TestStrategies_Strategy(t *testing.T) {
+	generator := func() string { return "abc123" }
+	ctx := context.Background()
+
+	token, err := shortener.NewTokenStrategy(&mockRepository{}, generator).Shorten(ctx, "https://example.com")
+	require.NoError(t, err)
+	assert.Equal(t, shortener.StrategyToken, token.Strategy)
+
+	hash, err := shortener.NewHashStrategy(&mockRepository{}, generator).Shorten(ctx, "https://example.com")
+	require.NoError(t, err)
+	assert.Equal(t, shortener.StrategyHash, hash.Strategy)
+
+	alias, err := shortener.NewAliasStrategy(&mockRepository{}, shortener.DefaultAliasPolicy()).
+		Shorten(ctx, "my-link", "https://example.com")
+	require.NoError(t, err)
+	assert.Equal(t, shortener.StrategyAlias, alias.Strategy)
+}
@@ -0,0 +1,14 @@
+package shortener
+
+import "context"
+
+// RecentSubmissions remembers, for a short window, which code a client was
+// given for a URL, so an accidental resubmission (a double click, a retried
+// form) can be answered with the same code instead of a new one.
+type RecentSubmissions interface {
+	// Recent returns the code client was given for url within the window, or ""
+	// when there is none.
+	Recent(ctx context.Context, client, url string) (Code, error)
+	// Remember records that client was given code for url.
+	Remember(ctx context.Context, client, url string, code Code) error
+}
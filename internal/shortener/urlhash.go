@@ -3,18 +3,82 @@ package shortener
 import (
 	"crypto/sha256"
 	"encoding/hex"
+	"fmt"
 	"net/url"
 	"strings"
 )
 
+// ErrPathTooDeep is returned by NormalizeURL when a URL path exceeds the
+// configured segment count or length. It wraps ErrInvalidURL.
+var ErrPathTooDeep = fmt.Errorf("%w: path too deep", ErrInvalidURL)
+
+// NormalizeOption bounds the URLs NormalizeURL accepts.
+type NormalizeOption func(*normalizeLimits)
+
+type normalizeLimits struct {
+	maxPathSegments int
+	maxPathLength   int
+}
+
+// WithMaxPathSegments rejects paths with more than n non-empty segments (0 disables).
+func WithMaxPathSegments(n int) NormalizeOption {
+	return func(l *normalizeLimits) {
+		l.maxPathSegments = n
+	}
+}
+
+// WithMaxPathLength rejects paths longer than n characters once escaped (0 disables).
+func WithMaxPathLength(n int) NormalizeOption {
+	return func(l *normalizeLimits) {
+		l.maxPathLength = n
+	}
+}
+
+// check reports ErrPathTooDeep when u's path exceeds the limits.
+func (l normalizeLimits) check(u *url.URL) error {
+	path := u.EscapedPath()
+	if l.maxPathLength > 0 && len(path) > l.maxPathLength {
+		return fmt.Errorf("%w: path must be at most %d characters", ErrPathTooDeep, l.maxPathLength)
+	}
+
+	if l.maxPathSegments > 0 {
+		segments := 0
+
+		for segment := range strings.SplitSeq(path, "/") {
+			if segment != "" {
+				segments++
+			}
+		}
+
+		if segments > l.maxPathSegments {
+			return fmt.Errorf("%w: path must have at most %d segments", ErrPathTooDeep, l.maxPathSegments)
+		}
+	}
+
+	return nil
+}
+
 // NormalizeURL normalizes a URL for consistent hashing.
-// - Lowercases the scheme and host.
+// - Lowercases the scheme and host; paths are case-sensitive and kept as is.
 // - Removes default ports (80 for http, 443 for https).
 // - Removes trailing slashes from path (unless path is just "/").
 // - Removes empty fragment.
-func NormalizeURL(rawURL string) (string, error) {
+//
+// URLs that cannot be parsed are rejected with ErrInvalidURL, and paths beyond
+// the limits set by opts with ErrPathTooDeep, before any normalization work is
+// done.
+func NormalizeURL(rawURL string, opts ...NormalizeOption) (string, error) {
 	u, err := url.Parse(rawURL)
 	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidURL, err)
+	}
+
+	var limits normalizeLimits
+	for _, opt := range opts {
+		opt(&limits)
+	}
+
+	if err = limits.check(u); err != nil {
 		return "", err
 	}
 
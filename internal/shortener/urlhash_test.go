@@ -1,6 +1,8 @@
 package shortener_test
 
 import (
+	"errors"
+	"strings"
 	"testing"
 
 	"github.com/serroba/web-demo-go/internal/shortener"
@@ -79,9 +81,53 @@ func TestNormalizeURL(t *testing.T) {
 }
 
 func TestNormalizeURL_InvalidURL(t *testing.T) {
-	_, err := shortener.NormalizeURL("://invalid")
-	if err == nil {
-		t.Error("expected error for invalid URL, got nil")
+	for _, rawURL := range []string{"://invalid", "http://[::1", "https://example.com/%zz"} {
+		t.Run(rawURL, func(t *testing.T) {
+			_, err := shortener.NormalizeURL(rawURL)
+			if !errors.Is(err, shortener.ErrInvalidURL) {
+				t.Errorf("got %v, want ErrInvalidURL", err)
+			}
+		})
+	}
+}
+
+func TestNormalizeURL_PathLimits(t *testing.T) {
+	deep := "https://example.com/" + strings.Repeat("a/", 50) + "end"
+
+	tests := []struct {
+		name    string
+		url     string
+		opts    []shortener.NormalizeOption
+		wantErr bool
+	}{
+		{"deep path without limits", deep, nil, false},
+		{"too many segments", deep, []shortener.NormalizeOption{shortener.WithMaxPathSegments(10)}, true},
+		{"too long", deep, []shortener.NormalizeOption{shortener.WithMaxPathLength(64)}, true},
+		{"normal path", "https://example.com/a/b/c/", []shortener.NormalizeOption{
+			shortener.WithMaxPathSegments(3),
+			shortener.WithMaxPathLength(64),
+		}, false},
+		{"empty segments are not counted", "https://example.com//a//b", []shortener.NormalizeOption{
+			shortener.WithMaxPathSegments(2),
+		}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := shortener.NormalizeURL(tt.url, tt.opts...)
+
+			if tt.wantErr {
+				if !errors.Is(err, shortener.ErrPathTooDeep) || !errors.Is(err, shortener.ErrInvalidURL) {
+					t.Errorf("got %v, want ErrPathTooDeep wrapping ErrInvalidURL", err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
 	}
 }
 
@@ -0,0 +1,41 @@
+package shortener
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ErrInvalidURL is returned when a URL fails the configured URL rules.
+var ErrInvalidURL = errors.New("invalid url")
+
+// URLRules describes optional sanity checks applied before shortening a URL.
+// The zero value accepts every URL.
+type URLRules struct {
+	// MinLength rejects URLs shorter than this many characters (0 disables).
+	MinLength int
+	// RequireDottedHost rejects hosts without a dot, such as "http://a" or "http://localhost".
+	RequireDottedHost bool
+}
+
+// Validate reports why rawURL does not satisfy the rules, wrapping ErrInvalidURL.
+func (r URLRules) Validate(rawURL string) error {
+	if r.MinLength > 0 && len(rawURL) < r.MinLength {
+		return fmt.Errorf("%w: url must be at least %d characters", ErrInvalidURL, r.MinLength)
+	}
+
+	if r.RequireDottedHost {
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidURL, err)
+		}
+
+		host := strings.Trim(u.Hostname(), ".")
+		if !strings.Contains(host, ".") {
+			return fmt.Errorf("%w: host %q must include a domain with a dot", ErrInvalidURL, u.Hostname())
+		}
+	}
+
+	return nil
+}
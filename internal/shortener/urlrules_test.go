@@ -0,0 +1,43 @@
+package shortener_test
+
+import (
+	"testing"
+
+	"github.com/serroba/web-demo-go/internal/shortener"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestURLRules_Validate(t *testing.T) {
+	rules := shortener.URLRules{MinLength: 12, RequireDottedHost: true}
+
+	t.Run("zero value accepts any url", func(t *testing.T) {
+		require.NoError(t, shortener.URLRules{}.Validate("http://a"))
+	})
+
+	t.Run("accepts valid url", func(t *testing.T) {
+		require.NoError(t, rules.Validate("https://example.com/path"))
+	})
+
+	t.Run("rejects bare hostname", func(t *testing.T) {
+		for _, rawURL := range []string{"http://localhost/page", "http://intranet:8080/x"} {
+			err := rules.Validate(rawURL)
+
+			require.ErrorIs(t, err, shortener.ErrInvalidURL, rawURL)
+			assert.Contains(t, err.Error(), "must include a domain")
+		}
+	})
+
+	t.Run("rejects url shorter than minimum", func(t *testing.T) {
+		err := rules.Validate("http://a.io")
+
+		require.ErrorIs(t, err, shortener.ErrInvalidURL)
+		assert.Contains(t, err.Error(), "at least 12 characters")
+	})
+
+	t.Run("rejects unparseable url", func(t *testing.T) {
+		err := shortener.URLRules{RequireDottedHost: true}.Validate("http://[::1")
+
+		require.ErrorIs(t, err, shortener.ErrInvalidURL)
+	})
+}
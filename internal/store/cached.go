@@ -2,6 +2,8 @@ package store
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/serroba/web-demo-go/internal/cache"
 	"github.com/serroba/web-demo-go/internal/shortener"
@@ -9,16 +11,35 @@ import (
 
 // CachedRepository wraps a Repository with an LRU cache for GetByCode lookups.
 type CachedRepository struct {
-	store shortener.Repository
-	cache *cache.LRU
+	store       shortener.Repository
+	cache       *cache.LRU
+	negativeTTL time.Duration
+}
+
+// CachedRepositoryOption configures a CachedRepository.
+type CachedRepositoryOption func(*CachedRepository)
+
+// WithLRUNegativeTTL caches codes the store does not know for ttl, so scanners
+// requesting the same missing code repeatedly do not reach the store. Saving
+// the code replaces the entry. Zero disables negative caching.
+func WithLRUNegativeTTL(ttl time.Duration) CachedRepositoryOption {
+	return func(c *CachedRepository) {
+		c.negativeTTL = ttl
+	}
 }
 
 // NewCachedRepository creates a new cached repository decorator.
-func NewCachedRepository(store shortener.Repository, c *cache.LRU) *CachedRepository {
-	return &CachedRepository{
+func NewCachedRepository(store shortener.Repository, c *cache.LRU, opts ...CachedRepositoryOption) *CachedRepository {
+	r := &CachedRepository{
 		store: store,
 		cache: c,
 	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
 }
 
 // Save stores a short URL and updates the cache.
@@ -33,16 +54,39 @@ func (c *CachedRepository) Save(ctx context.Context, shortURL *shortener.ShortUR
 	return nil
 }
 
+// SaveBatch saves the short URLs to the underlying store, then caches the
+// ones it inserted.
+func (c *CachedRepository) SaveBatch(ctx context.Context, shortURLs []*shortener.ShortURL) ([]shortener.Code, error) {
+	inserted, err := c.store.SaveBatch(ctx, shortURLs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, shortURL := range insertedURLs(shortURLs, inserted) {
+		c.cache.Set(string(shortURL.Code), shortURL)
+	}
+
+	return inserted, nil
+}
+
 // GetByCode retrieves a short URL by its code, using cache-aside pattern.
 func (c *CachedRepository) GetByCode(ctx context.Context, code shortener.Code) (*shortener.ShortURL, error) {
-	// Check cache first
+	// Check cache first; a nil entry is a cached miss
 	if url, ok := c.cache.Get(string(code)); ok {
+		if url == nil {
+			return nil, shortener.ErrNotFound
+		}
+
 		return url, nil
 	}
 
 	// Cache miss - fetch from store
 	url, err := c.store.GetByCode(ctx, code)
 	if err != nil {
+		if c.negativeTTL > 0 && errors.Is(err, shortener.ErrNotFound) {
+			c.cache.SetWithTTL(string(code), nil, c.negativeTTL)
+		}
+
 		return nil, err
 	}
 
@@ -56,3 +100,74 @@ func (c *CachedRepository) GetByCode(ctx context.Context, code shortener.Code) (
 func (c *CachedRepository) GetByHash(ctx context.Context, hash shortener.URLHash) (*shortener.ShortURL, error) {
 	return c.store.GetByHash(ctx, hash)
 }
+
+// GetWithStats returns a short URL with its access count (pass-through, not
+// cached, since the count changes with every flush).
+func (c *CachedRepository) GetWithStats(ctx context.Context, code shortener.Code) (*shortener.ShortURLStats, error) {
+	return c.store.GetWithStats(ctx, code)
+}
+
+// Exists reports which of codes are taken (pass-through, not cached).
+func (c *CachedRepository) Exists(ctx context.Context, codes []shortener.Code) (map[shortener.Code]bool, error) {
+	return c.store.Exists(ctx, codes)
+}
+
+// Count returns the number of stored URLs (pass-through, not cached).
+func (c *CachedRepository) Count(ctx context.Context) (int64, error) {
+	return c.store.Count(ctx)
+}
+
+// MostRecent returns the newest short URLs (pass-through, not cached).
+func (c *CachedRepository) MostRecent(ctx context.Context, limit int) ([]*shortener.ShortURL, error) {
+	return c.store.MostRecent(ctx, limit)
+}
+
+// ListByCreator returns a creator's short URLs (pass-through, not cached).
+func (c *CachedRepository) ListByCreator(
+	ctx context.Context,
+	creator string,
+	limit int,
+) ([]*shortener.ShortURL, error) {
+	return c.store.ListByCreator(ctx, creator, limit)
+}
+
+// UpdateTarget repoints a short URL in the underlying store and evicts the cached entry.
+func (c *CachedRepository) UpdateTarget(ctx context.Context, code shortener.Code, newURL, normalizedURL string) error {
+	if err := c.store.UpdateTarget(ctx, code, newURL, normalizedURL); err != nil {
+		return err
+	}
+
+	c.cache.Delete(string(code))
+
+	return nil
+}
+
+// Flag flags a short URL in the underlying store and evicts the cached entry,
+// so redirects see the change before the entry would expire.
+func (c *CachedRepository) Flag(ctx context.Context, code shortener.Code, flagged bool) error {
+	if err := c.store.Flag(ctx, code, flagged); err != nil {
+		return err
+	}
+
+	c.cache.Delete(string(code))
+
+	return nil
+}
+
+// insertedURLs returns the short URLs in shortURLs whose codes are in inserted.
+func insertedURLs(shortURLs []*shortener.ShortURL, inserted []shortener.Code) []*shortener.ShortURL {
+	saved := make(map[shortener.Code]bool, len(inserted))
+	for _, code := range inserted {
+		saved[code] = true
+	}
+
+	urls := make([]*shortener.ShortURL, 0, len(inserted))
+
+	for _, shortURL := range shortURLs {
+		if saved[shortURL.Code] {
+			urls = append(urls, shortURL)
+		}
+	}
+
+	return urls
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/serroba/web-demo-go/internal/cache"
+	"github.com/serroba/web-demo-go/internal/clock"
 	"github.com/serroba/web-demo-go/internal/shortener"
 	"github.com/serroba/web-demo-go/internal/store"
 	"github.com/stretchr/testify/assert"
@@ -13,10 +15,15 @@ import (
 )
 
 type mockStore struct {
-	saveFunc      func(ctx context.Context, shortURL *shortener.ShortURL) error
-	getByCodeFunc func(ctx context.Context, code shortener.Code) (*shortener.ShortURL, error)
-	getByHashFunc func(ctx context.Context, hash shortener.URLHash) (*shortener.ShortURL, error)
-	callCount     int
+	saveFunc       func(ctx context.Context, shortURL *shortener.ShortURL) error
+	getByCodeFunc  func(ctx context.Context, code shortener.Code) (*shortener.ShortURL, error)
+	getByHashFunc  func(ctx context.Context, hash shortener.URLHash) (*shortener.ShortURL, error)
+	countFunc      func(ctx context.Context) (int64, error)
+	saveBatchFunc  func(ctx context.Context, shortURLs []*shortener.ShortURL) ([]shortener.Code, error)
+	mostRecentFunc func(ctx context.Context, limit int) ([]*shortener.ShortURL, error)
+	updateFunc     func(ctx context.Context, code shortener.Code, newURL, normalizedURL string) error
+	flagFunc       func(ctx context.Context, code shortener.Code, flagged bool) error
+	callCount      int
 }
 
 func (m *mockStore) Save(ctx context.Context, shortURL *shortener.ShortURL) error {
@@ -49,6 +56,84 @@ func (m *mockStore) GetByHash(ctx context.Context, hash shortener.URLHash) (*sho
 	return nil, shortener.ErrNotFound
 }
 
+func (m *mockStore) SaveBatch(ctx context.Context, shortURLs []*shortener.ShortURL) ([]shortener.Code, error) {
+	m.callCount++
+
+	if m.saveBatchFunc != nil {
+		return m.saveBatchFunc(ctx, shortURLs)
+	}
+
+	return codesOf(shortURLs), nil
+}
+
+// codesOf returns the codes of shortURLs in order.
+func codesOf(shortURLs []*shortener.ShortURL) []shortener.Code {
+	codes := make([]shortener.Code, 0, len(shortURLs))
+	for _, shortURL := range shortURLs {
+		codes = append(codes, shortURL.Code)
+	}
+
+	return codes
+}
+
+func (m *mockStore) Count(ctx context.Context) (int64, error) {
+	m.callCount++
+
+	if m.countFunc != nil {
+		return m.countFunc(ctx)
+	}
+
+	return 0, nil
+}
+
+func (m *mockStore) GetWithStats(_ context.Context, _ shortener.Code) (*shortener.ShortURLStats, error) {
+	m.callCount++
+
+	return nil, shortener.ErrNotFound
+}
+
+func (m *mockStore) Exists(_ context.Context, _ []shortener.Code) (map[shortener.Code]bool, error) {
+	m.callCount++
+
+	return nil, nil
+}
+
+func (m *mockStore) MostRecent(ctx context.Context, limit int) ([]*shortener.ShortURL, error) {
+	m.callCount++
+
+	if m.mostRecentFunc != nil {
+		return m.mostRecentFunc(ctx, limit)
+	}
+
+	return nil, nil
+}
+
+func (m *mockStore) ListByCreator(_ context.Context, _ string, _ int) ([]*shortener.ShortURL, error) {
+	m.callCount++
+
+	return nil, nil
+}
+
+func (m *mockStore) UpdateTarget(ctx context.Context, code shortener.Code, newURL, normalizedURL string) error {
+	m.callCount++
+
+	if m.updateFunc != nil {
+		return m.updateFunc(ctx, code, newURL, normalizedURL)
+	}
+
+	return nil
+}
+
+func (m *mockStore) Flag(ctx context.Context, code shortener.Code, flagged bool) error {
+	m.callCount++
+
+	if m.flagFunc != nil {
+		return m.flagFunc(ctx, code, flagged)
+	}
+
+	return nil
+}
+
 func TestCachedRepository_GetByCode(t *testing.T) {
 	t.Run("cache miss fetches from store and caches", func(t *testing.T) {
 		url := &shortener.ShortURL{
@@ -118,6 +203,67 @@ func TestCachedRepository_GetByCode(t *testing.T) {
 	})
 }
 
+func TestCachedRepository_NegativeCache(t *testing.T) {
+	t.Run("serves a repeated miss from the cache", func(t *testing.T) {
+		calls := 0
+		mock := &mockStore{
+			getByCodeFunc: func(_ context.Context, _ shortener.Code) (*shortener.ShortURL, error) {
+				calls++
+
+				return nil, shortener.ErrNotFound
+			},
+		}
+		cached := store.NewCachedRepository(mock, cache.New(10), store.WithLRUNegativeTTL(time.Minute))
+
+		_, err := cached.GetByCode(context.Background(), "missing")
+		require.ErrorIs(t, err, shortener.ErrNotFound)
+
+		_, err = cached.GetByCode(context.Background(), "missing")
+		require.ErrorIs(t, err, shortener.ErrNotFound)
+
+		assert.Equal(t, 1, calls, "second lookup should be served from the negative cache")
+	})
+
+	t.Run("queries the store again once the entry expires", func(t *testing.T) {
+		calls := 0
+		mock := &mockStore{
+			getByCodeFunc: func(_ context.Context, _ shortener.Code) (*shortener.ShortURL, error) {
+				calls++
+
+				return nil, shortener.ErrNotFound
+			},
+		}
+		clk := clock.NewFake(time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC))
+		cached := store.NewCachedRepository(mock, cache.New(10, cache.WithClock(clk)),
+			store.WithLRUNegativeTTL(time.Minute))
+
+		_, _ = cached.GetByCode(context.Background(), "missing")
+
+		clk.Advance(time.Minute)
+
+		_, _ = cached.GetByCode(context.Background(), "missing")
+
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("creating the code clears the negative entry", func(t *testing.T) {
+		cached := store.NewCachedRepository(store.NewMemoryStore(), cache.New(10),
+			store.WithLRUNegativeTTL(time.Minute))
+
+		_, err := cached.GetByCode(context.Background(), "abc123")
+		require.ErrorIs(t, err, shortener.ErrNotFound)
+
+		require.NoError(t, cached.Save(context.Background(), &shortener.ShortURL{
+			Code:        "abc123",
+			OriginalURL: "https://example.com",
+		}))
+
+		got, err := cached.GetByCode(context.Background(), "abc123")
+		require.NoError(t, err)
+		assert.Equal(t, "https://example.com", got.OriginalURL)
+	})
+}
+
 func TestCachedRepository_Save(t *testing.T) {
 	t.Run("save updates cache", func(t *testing.T) {
 		url := &shortener.ShortURL{
@@ -163,6 +309,60 @@ func TestCachedRepository_Save(t *testing.T) {
 	})
 }
 
+func TestCachedRepository_SaveBatch(t *testing.T) {
+	urls := []*shortener.ShortURL{
+		{Code: "abc123", OriginalURL: "https://example.com/a"},
+		{Code: "def456", OriginalURL: "https://example.com/b"},
+	}
+
+	t.Run("save batch updates cache", func(t *testing.T) {
+		mock := &mockStore{}
+		lru := cache.New(10)
+		cached := store.NewCachedRepository(mock, lru)
+
+		inserted, err := cached.SaveBatch(context.Background(), urls)
+
+		require.NoError(t, err)
+		assert.Equal(t, []shortener.Code{"abc123", "def456"}, inserted)
+		assert.Equal(t, 2, lru.Len())
+	})
+
+	t.Run("save batch caches only inserted codes", func(t *testing.T) {
+		mock := &mockStore{
+			saveBatchFunc: func(_ context.Context, _ []*shortener.ShortURL) ([]shortener.Code, error) {
+				return []shortener.Code{"def456"}, nil
+			},
+		}
+		lru := cache.New(10)
+		cached := store.NewCachedRepository(mock, lru)
+
+		inserted, err := cached.SaveBatch(context.Background(), urls)
+
+		require.NoError(t, err)
+		assert.Equal(t, []shortener.Code{"def456"}, inserted)
+		assert.Equal(t, 1, lru.Len())
+
+		_, ok := lru.Get("abc123")
+		assert.False(t, ok, "a code taken by someone else must not be cached")
+	})
+
+	t.Run("save batch error does not update cache", func(t *testing.T) {
+		saveErr := errors.New("save failed")
+		mock := &mockStore{
+			saveBatchFunc: func(_ context.Context, _ []*shortener.ShortURL) ([]shortener.Code, error) {
+				return nil, saveErr
+			},
+		}
+		lru := cache.New(10)
+		cached := store.NewCachedRepository(mock, lru)
+
+		_, err := cached.SaveBatch(context.Background(), urls)
+
+		require.ErrorIs(t, err, saveErr)
+		assert.Equal(t, 0, lru.Len())
+	})
+}
+
 func TestCachedRepository_GetByHash(t *testing.T) {
 	t.Run("passes through to store without caching", func(t *testing.T) {
 		url := &shortener.ShortURL{
@@ -193,3 +393,100 @@ func TestCachedRepository_GetByHash(t *testing.T) {
 		assert.Equal(t, 2, mock.callCount, "store should be called each time (no caching)")
 	})
 }
+
+func TestCachedRepository_UpdateTarget(t *testing.T) {
+	t.Run("evicts the cached entry so reads see the new url", func(t *testing.T) {
+		s := store.NewMemoryStore()
+		require.NoError(t, s.Save(context.Background(), &shortener.ShortURL{
+			Code:        "abc123",
+			OriginalURL: "https://example.com",
+		}))
+
+		lru := cache.New(10)
+		cached := store.NewCachedRepository(s, lru)
+
+		// Warm the cache
+		result, err := cached.GetByCode(context.Background(), "abc123")
+		require.NoError(t, err)
+		assert.Equal(t, "https://example.com", result.OriginalURL)
+
+		require.NoError(t, cached.UpdateTarget(context.Background(), "abc123", "https://example.org", "https://example.org"))
+
+		result, err = cached.GetByCode(context.Background(), "abc123")
+		require.NoError(t, err)
+		assert.Equal(t, "https://example.org", result.OriginalURL)
+	})
+
+	t.Run("keeps the cached entry when the store fails", func(t *testing.T) {
+		storeErr := errors.New("store error")
+		mock := &mockStore{
+			updateFunc: func(_ context.Context, _ shortener.Code, _, _ string) error {
+				return storeErr
+			},
+		}
+		lru := cache.New(10)
+		lru.Set("abc123", &shortener.ShortURL{Code: "abc123", OriginalURL: "https://example.com"})
+		cached := store.NewCachedRepository(mock, lru)
+
+		err := cached.UpdateTarget(context.Background(), "abc123", "https://example.org", "https://example.org")
+
+		require.ErrorIs(t, err, storeErr)
+		assert.Equal(t, 1, lru.Len())
+	})
+}
+
+func TestCachedRepository_Flag(t *testing.T) {
+	t.Run("evicts the cached entry so reads see the flag", func(t *testing.T) {
+		s := store.NewMemoryStore()
+		require.NoError(t, s.Save(context.Background(), &shortener.ShortURL{
+			Code:        "abc123",
+			OriginalURL: "https://example.com",
+		}))
+
+		cached := store.NewCachedRepository(s, cache.New(10))
+
+		// Warm the cache
+		result, err := cached.GetByCode(context.Background(), "abc123")
+		require.NoError(t, err)
+		assert.False(t, result.Flagged)
+
+		require.NoError(t, cached.Flag(context.Background(), "abc123", true))
+
+		result, err = cached.GetByCode(context.Background(), "abc123")
+		require.NoError(t, err)
+		assert.True(t, result.Flagged)
+	})
+
+	t.Run("keeps the cached entry when the store fails", func(t *testing.T) {
+		mock := &mockStore{
+			flagFunc: func(_ context.Context, _ shortener.Code, _ bool) error {
+				return shortener.ErrNotFound
+			},
+		}
+		lru := cache.New(10)
+		lru.Set("abc123", &shortener.ShortURL{Code: "abc123", OriginalURL: "https://example.com"})
+		cached := store.NewCachedRepository(mock, lru)
+
+		err := cached.Flag(context.Background(), "abc123", true)
+
+		require.ErrorIs(t, err, shortener.ErrNotFound)
+		assert.Equal(t, 1, lru.Len())
+	})
+}
+
+func TestCachedRepository_Count(t *testing.T) {
+	t.Run("passes through to store", func(t *testing.T) {
+		mock := &mockStore{
+			countFunc: func(_ context.Context) (int64, error) {
+				return 42, nil
+			},
+		}
+		repo := store.NewCachedRepository(mock, cache.New(10))
+
+		count, err := repo.Count(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(42), count)
+		assert.Equal(t, 1, mock.callCount)
+	})
+}
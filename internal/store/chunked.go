@@ -0,0 +1,133 @@
+package store
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/serroba/web-demo-go/internal/shortener"
+	"golang.org/x/sync/errgroup"
+)
+
+// ChunkedRepository wraps a Repository and splits SaveBatch calls into chunks,
+// saving at most a fixed number of chunks at a time, so a large import cannot
+// take every connection in the database pool.
+type ChunkedRepository struct {
+	store       shortener.Repository
+	chunkSize   int
+	concurrency int
+}
+
+// NewChunkedRepository creates a repository decorator that saves batches in
+// chunks of chunkSize short URLs, running up to concurrency chunks at once.
+// A chunkSize of zero saves each batch in one call; concurrency below one is
+// treated as one.
+func NewChunkedRepository(store shortener.Repository, chunkSize, concurrency int) *ChunkedRepository {
+	return &ChunkedRepository{
+		store:       store,
+		chunkSize:   chunkSize,
+		concurrency: max(concurrency, 1),
+	}
+}
+
+// Save stores a short URL.
+func (r *ChunkedRepository) Save(ctx context.Context, shortURL *shortener.ShortURL) error {
+	return r.store.Save(ctx, shortURL)
+}
+
+// SaveBatch saves the short URLs chunk by chunk and returns the codes
+// inserted across all chunks. Chunks are saved independently, so when one
+// fails the chunks saved before it are kept; no new chunks are started after a
+// failure and the first error is returned.
+func (r *ChunkedRepository) SaveBatch(ctx context.Context, shortURLs []*shortener.ShortURL) ([]shortener.Code, error) {
+	if r.chunkSize <= 0 || len(shortURLs) <= r.chunkSize {
+		return r.store.SaveBatch(ctx, shortURLs)
+	}
+
+	group, ctx := errgroup.WithContext(ctx)
+	group.SetLimit(r.concurrency)
+
+	var (
+		mu       sync.Mutex
+		inserted = make([]shortener.Code, 0, len(shortURLs))
+	)
+
+	for chunk := range slices.Chunk(shortURLs, r.chunkSize) {
+		if ctx.Err() != nil {
+			break
+		}
+
+		group.Go(func() error {
+			// Go may have waited for a slot while another chunk failed.
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			codes, err := r.store.SaveBatch(ctx, chunk)
+			if err != nil {
+				return err
+			}
+
+			mu.Lock()
+			inserted = append(inserted, codes...)
+			mu.Unlock()
+
+			return nil
+		})
+	}
+
+	if err := group.Wait(); err != nil {
+		return nil, err
+	}
+
+	return inserted, nil
+}
+
+// GetByCode retrieves a short URL by its code.
+func (r *ChunkedRepository) GetByCode(ctx context.Context, code shortener.Code) (*shortener.ShortURL, error) {
+	return r.store.GetByCode(ctx, code)
+}
+
+// GetByHash retrieves a short URL by its URL hash.
+func (r *ChunkedRepository) GetByHash(ctx context.Context, hash shortener.URLHash) (*shortener.ShortURL, error) {
+	return r.store.GetByHash(ctx, hash)
+}
+
+// GetWithStats retrieves a short URL with its access count.
+func (r *ChunkedRepository) GetWithStats(ctx context.Context, code shortener.Code) (*shortener.ShortURLStats, error) {
+	return r.store.GetWithStats(ctx, code)
+}
+
+// Exists reports which of codes are taken.
+func (r *ChunkedRepository) Exists(ctx context.Context, codes []shortener.Code) (map[shortener.Code]bool, error) {
+	return r.store.Exists(ctx, codes)
+}
+
+// Count returns the number of stored short URLs.
+func (r *ChunkedRepository) Count(ctx context.Context) (int64, error) {
+	return r.store.Count(ctx)
+}
+
+// MostRecent returns up to limit short URLs, newest first.
+func (r *ChunkedRepository) MostRecent(ctx context.Context, limit int) ([]*shortener.ShortURL, error) {
+	return r.store.MostRecent(ctx, limit)
+}
+
+// ListByCreator returns up to limit short URLs created by creator, newest first.
+func (r *ChunkedRepository) ListByCreator(
+	ctx context.Context, creator string, limit int,
+) ([]*shortener.ShortURL, error) {
+	return r.store.ListByCreator(ctx, creator, limit)
+}
+
+// UpdateTarget points code at newURL.
+func (r *ChunkedRepository) UpdateTarget(ctx context.Context, code shortener.Code, newURL, normalizedURL string) error {
+	return r.store.UpdateTarget(ctx, code, newURL, normalizedURL)
+}
+
+// Flag sets or clears the flagged mark of code.
+func (r *ChunkedRepository) Flag(ctx context.Context, code shortener.Code, flagged bool) error {
+	return r.store.Flag(ctx, code, flagged)
+}
+
+var _ shortener.Repository = (*ChunkedRepository)(nil)
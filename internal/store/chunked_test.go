@@ -0,0 +1,127 @@
+package store_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/serroba/web-demo-go/internal/shortener"
+	"github.com/serroba/web-demo-go/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingBatches records the size of every SaveBatch call and the most calls
+// that were in flight at once.
+type countingBatches struct {
+	*mockStore
+
+	mu       sync.Mutex
+	sizes    []int
+	inFlight int
+	peak     int
+	fail     bool
+}
+
+func (c *countingBatches) SaveBatch(_ context.Context, shortURLs []*shortener.ShortURL) ([]shortener.Code, error) {
+	c.mu.Lock()
+	c.sizes = append(c.sizes, len(shortURLs))
+	c.inFlight++
+	c.peak = max(c.peak, c.inFlight)
+	c.mu.Unlock()
+
+	// Give other chunks the chance to overlap with this one.
+	time.Sleep(5 * time.Millisecond)
+
+	c.mu.Lock()
+	c.inFlight--
+	c.mu.Unlock()
+
+	if c.fail {
+		return nil, errors.New("db down")
+	}
+
+	return codesOf(shortURLs), nil
+}
+
+func newImportBatch(n int) []*shortener.ShortURL {
+	urls := make([]*shortener.ShortURL, n)
+	for i := range urls {
+		urls[i] = &shortener.ShortURL{
+			Code:        shortener.Code(fmt.Sprintf("imp%05d", i)),
+			OriginalURL: fmt.Sprintf("https://example.com/%d", i),
+		}
+	}
+
+	return urls
+}
+
+func TestChunkedRepository_SaveBatch(t *testing.T) {
+	t.Run("splits large batches and caps concurrent chunks", func(t *testing.T) {
+		backing := &countingBatches{mockStore: &mockStore{}}
+		repo := store.NewChunkedRepository(backing, 100, 3)
+
+		batch := newImportBatch(1050)
+
+		inserted, err := repo.SaveBatch(context.Background(), batch)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, codesOf(batch), inserted, "inserted codes from every chunk are returned")
+
+		require.Len(t, backing.sizes, 11)
+
+		total := 0
+		for _, size := range backing.sizes {
+			assert.LessOrEqual(t, size, 100)
+
+			total += size
+		}
+
+		assert.Equal(t, 1050, total)
+		assert.LessOrEqual(t, backing.peak, 3)
+		assert.Greater(t, backing.peak, 1, "chunks should run concurrently up to the limit")
+	})
+
+	t.Run("small batches are saved in one call", func(t *testing.T) {
+		backing := &countingBatches{mockStore: &mockStore{}}
+		repo := store.NewChunkedRepository(backing, 100, 3)
+
+		_, err := repo.SaveBatch(context.Background(), newImportBatch(100))
+		require.NoError(t, err)
+
+		assert.Equal(t, []int{100}, backing.sizes)
+	})
+
+	t.Run("zero chunk size disables chunking", func(t *testing.T) {
+		backing := &countingBatches{mockStore: &mockStore{}}
+		repo := store.NewChunkedRepository(backing, 0, 3)
+
+		_, err := repo.SaveBatch(context.Background(), newImportBatch(500))
+		require.NoError(t, err)
+
+		assert.Equal(t, []int{500}, backing.sizes)
+	})
+
+	t.Run("concurrency of one saves chunks sequentially", func(t *testing.T) {
+		backing := &countingBatches{mockStore: &mockStore{}}
+		repo := store.NewChunkedRepository(backing, 10, 0)
+
+		_, err := repo.SaveBatch(context.Background(), newImportBatch(50))
+		require.NoError(t, err)
+
+		assert.Len(t, backing.sizes, 5)
+		assert.Equal(t, 1, backing.peak)
+	})
+
+	t.Run("returns the chunk error", func(t *testing.T) {
+		backing := &countingBatches{mockStore: &mockStore{}, fail: true}
+		repo := store.NewChunkedRepository(backing, 10, 1)
+
+		_, err := repo.SaveBatch(context.Background(), newImportBatch(50))
+
+		require.Error(t, err)
+		assert.Len(t, backing.sizes, 1, "no chunks should start after a failure")
+	})
+}
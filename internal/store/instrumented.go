@@ -0,0 +1,186 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/serroba/web-demo-go/internal/shortener"
+)
+
+// Outcome classifies the result of a repository operation.
+type Outcome string
+
+const (
+	// OutcomeOK is a successful write or count.
+	OutcomeOK Outcome = "ok"
+	// OutcomeHit is a lookup that found the short URL.
+	OutcomeHit Outcome = "hit"
+	// OutcomeMiss is a lookup that returned shortener.ErrNotFound.
+	OutcomeMiss Outcome = "miss"
+	// OutcomeError is any other failure.
+	OutcomeError Outcome = "error"
+)
+
+// Recorder observes the latency of repository operations.
+type Recorder interface {
+	ObserveOperation(operation string, outcome Outcome, duration time.Duration)
+}
+
+// InstrumentedRepository wraps a Repository and reports the duration and
+// outcome of every call to a Recorder.
+type InstrumentedRepository struct {
+	store    shortener.Repository
+	recorder Recorder
+	now      func() time.Time
+}
+
+// NewInstrumentedRepository creates a new timing repository decorator.
+func NewInstrumentedRepository(store shortener.Repository, recorder Recorder) *InstrumentedRepository {
+	return &InstrumentedRepository{
+		store:    store,
+		recorder: recorder,
+		now:      time.Now,
+	}
+}
+
+// Save stores a short URL and records the call.
+func (r *InstrumentedRepository) Save(ctx context.Context, shortURL *shortener.ShortURL) error {
+	start := r.now()
+	err := r.store.Save(ctx, shortURL)
+	r.observe("save", start, err)
+
+	return err
+}
+
+// SaveBatch stores several short URLs and records the call.
+func (r *InstrumentedRepository) SaveBatch(
+	ctx context.Context, shortURLs []*shortener.ShortURL,
+) ([]shortener.Code, error) {
+	start := r.now()
+	inserted, err := r.store.SaveBatch(ctx, shortURLs)
+	r.observe("save_batch", start, err)
+
+	return inserted, err
+}
+
+// GetByCode looks up a short URL by code and records the call. Cache warm-up
+// lookups are recorded as warm_by_code, so get_by_code only counts client
+// traffic and its not-found ratio is not skewed by codes that were deleted.
+func (r *InstrumentedRepository) GetByCode(ctx context.Context, code shortener.Code) (*shortener.ShortURL, error) {
+	operation := "get_by_code"
+	if shortener.IsWarmUp(ctx) {
+		operation = "warm_by_code"
+	}
+
+	start := r.now()
+	shortURL, err := r.store.GetByCode(ctx, code)
+	r.observeLookup(operation, start, err)
+
+	return shortURL, err
+}
+
+// GetByHash looks up a short URL by hash and records the call.
+func (r *InstrumentedRepository) GetByHash(ctx context.Context, hash shortener.URLHash) (*shortener.ShortURL, error) {
+	start := r.now()
+	shortURL, err := r.store.GetByHash(ctx, hash)
+	r.observeLookup("get_by_hash", start, err)
+
+	return shortURL, err
+}
+
+// GetWithStats looks up a short URL with its access count and records the call.
+func (r *InstrumentedRepository) GetWithStats(
+	ctx context.Context,
+	code shortener.Code,
+) (*shortener.ShortURLStats, error) {
+	start := r.now()
+	stats, err := r.store.GetWithStats(ctx, code)
+	r.observeLookup("get_with_stats", start, err)
+
+	return stats, err
+}
+
+// Exists reports which of codes are taken and records the call.
+func (r *InstrumentedRepository) Exists(ctx context.Context, codes []shortener.Code) (map[shortener.Code]bool, error) {
+	start := r.now()
+	exists, err := r.store.Exists(ctx, codes)
+	r.observe("exists", start, err)
+
+	return exists, err
+}
+
+// Count returns the number of stored short URLs and records the call.
+func (r *InstrumentedRepository) Count(ctx context.Context) (int64, error) {
+	start := r.now()
+	count, err := r.store.Count(ctx)
+	r.observe("count", start, err)
+
+	return count, err
+}
+
+// MostRecent returns the newest short URLs and records the call.
+func (r *InstrumentedRepository) MostRecent(ctx context.Context, limit int) ([]*shortener.ShortURL, error) {
+	start := r.now()
+	shortURLs, err := r.store.MostRecent(ctx, limit)
+	r.observe("most_recent", start, err)
+
+	return shortURLs, err
+}
+
+// ListByCreator returns a creator's short URLs and records the call.
+func (r *InstrumentedRepository) ListByCreator(
+	ctx context.Context,
+	creator string,
+	limit int,
+) ([]*shortener.ShortURL, error) {
+	start := r.now()
+	shortURLs, err := r.store.ListByCreator(ctx, creator, limit)
+	r.observe("list_by_creator", start, err)
+
+	return shortURLs, err
+}
+
+// UpdateTarget repoints a short URL and records the call.
+func (r *InstrumentedRepository) UpdateTarget(
+	ctx context.Context,
+	code shortener.Code,
+	newURL, normalizedURL string,
+) error {
+	start := r.now()
+	err := r.store.UpdateTarget(ctx, code, newURL, normalizedURL)
+	r.observeLookup("update_target", start, err)
+
+	return err
+}
+
+// Flag flags a short URL and records the call.
+func (r *InstrumentedRepository) Flag(ctx context.Context, code shortener.Code, flagged bool) error {
+	start := r.now()
+	err := r.store.Flag(ctx, code, flagged)
+	r.observeLookup("flag", start, err)
+
+	return err
+}
+
+func (r *InstrumentedRepository) observe(operation string, start time.Time, err error) {
+	outcome := OutcomeOK
+	if err != nil {
+		outcome = OutcomeError
+	}
+
+	r.recorder.ObserveOperation(operation, outcome, r.now().Sub(start))
+}
+
+func (r *InstrumentedRepository) observeLookup(operation string, start time.Time, err error) {
+	outcome := OutcomeHit
+
+	switch {
+	case errors.Is(err, shortener.ErrNotFound):
+		outcome = OutcomeMiss
+	case err != nil:
+		outcome = OutcomeError
+	}
+
+	r.recorder.ObserveOperation(operation, outcome, r.now().Sub(start))
+}
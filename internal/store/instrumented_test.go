@@ -0,0 +1,99 @@
+package store_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/serroba/web-demo-go/internal/shortener"
+	"github.com/serroba/web-demo-go/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type observation struct {
+	operation string
+	outcome   store.Outcome
+}
+
+type fakeRecorder struct {
+	observations []observation
+}
+
+func (f *fakeRecorder) ObserveOperation(operation string, outcome store.Outcome, _ time.Duration) {
+	f.observations = append(f.observations, observation{operation: operation, outcome: outcome})
+}
+
+func TestInstrumentedRepository(t *testing.T) {
+	ctx := context.Background()
+	errDB := errors.New("db down")
+
+	t.Run("records one observation per call", func(t *testing.T) {
+		recorder := &fakeRecorder{}
+		repo := store.NewInstrumentedRepository(store.NewMemoryStore(), recorder)
+
+		shortURL := &shortener.ShortURL{Code: "abc123", OriginalURL: "https://example.com", URLHash: "h1"}
+
+		require.NoError(t, repo.Save(ctx, shortURL))
+		_, err := repo.SaveBatch(ctx, []*shortener.ShortURL{{Code: "def456", OriginalURL: "https://example.org"}})
+		require.NoError(t, err)
+
+		_, err = repo.GetByCode(ctx, "abc123")
+		require.NoError(t, err)
+
+		_, err = repo.GetByCode(ctx, "missing")
+		require.ErrorIs(t, err, shortener.ErrNotFound)
+
+		_, err = repo.GetByHash(ctx, "h1")
+		require.NoError(t, err)
+
+		count, err := repo.Count(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), count)
+
+		_, err = repo.MostRecent(ctx, 10)
+		require.NoError(t, err)
+
+		assert.Equal(t, []observation{
+			{"save", store.OutcomeOK},
+			{"save_batch", store.OutcomeOK},
+			{"get_by_code", store.OutcomeHit},
+			{"get_by_code", store.OutcomeMiss},
+			{"get_by_hash", store.OutcomeHit},
+			{"count", store.OutcomeOK},
+			{"most_recent", store.OutcomeOK},
+		}, recorder.observations)
+	})
+
+	t.Run("records errors and passes them through", func(t *testing.T) {
+		recorder := &fakeRecorder{}
+		mock := &mockStore{
+			saveFunc: func(context.Context, *shortener.ShortURL) error { return errDB },
+			getByHashFunc: func(context.Context, shortener.URLHash) (*shortener.ShortURL, error) {
+				return nil, errDB
+			},
+		}
+		repo := store.NewInstrumentedRepository(mock, recorder)
+
+		require.ErrorIs(t, repo.Save(ctx, &shortener.ShortURL{Code: "abc123"}), errDB)
+
+		_, err := repo.GetByHash(ctx, "h1")
+		require.ErrorIs(t, err, errDB)
+
+		assert.Equal(t, []observation{
+			{"save", store.OutcomeError},
+			{"get_by_hash", store.OutcomeError},
+		}, recorder.observations)
+	})
+
+	t.Run("records warm-up lookups separately", func(t *testing.T) {
+		recorder := &fakeRecorder{}
+		repo := store.NewInstrumentedRepository(store.NewMemoryStore(), recorder)
+
+		_, err := repo.GetByCode(shortener.WithWarmUp(ctx), "missing")
+		require.ErrorIs(t, err, shortener.ErrNotFound)
+
+		assert.Equal(t, []observation{{"warm_by_code", store.OutcomeMiss}}, recorder.observations)
+	})
+}
@@ -1,7 +1,10 @@
 package store
 
 import (
+	"cmp"
 	"context"
+	"maps"
+	"slices"
 	"sync"
 
 	"github.com/serroba/web-demo-go/internal/shortener"
@@ -26,6 +29,10 @@ func (m *MemoryStore) Save(_ context.Context, shortURL *shortener.ShortURL) erro
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	if _, ok := m.urls[shortURL.Code]; ok {
+		return shortener.ErrCodeCollision
+	}
+
 	m.urls[shortURL.Code] = shortURL
 
 	// Index by hash if present (for hash strategy)
@@ -36,6 +43,28 @@ func (m *MemoryStore) Save(_ context.Context, shortURL *shortener.ShortURL) erro
 	return nil
 }
 
+func (m *MemoryStore) SaveBatch(_ context.Context, shortURLs []*shortener.ShortURL) ([]shortener.Code, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	inserted := make([]shortener.Code, 0, len(shortURLs))
+
+	for _, shortURL := range shortURLs {
+		if _, ok := m.urls[shortURL.Code]; ok {
+			continue
+		}
+
+		m.urls[shortURL.Code] = shortURL
+		inserted = append(inserted, shortURL.Code)
+
+		if shortURL.URLHash != "" {
+			m.hashes[shortURL.URLHash] = shortURL.Code
+		}
+	}
+
+	return inserted, nil
+}
+
 func (m *MemoryStore) GetByCode(_ context.Context, code shortener.Code) (*shortener.ShortURL, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
@@ -64,3 +93,120 @@ func (m *MemoryStore) GetByHash(_ context.Context, hash shortener.URLHash) (*sho
 
 	return shortURL, nil
 }
+
+// GetWithStats returns the short URL stored under code. Accesses are not
+// counted in memory, so the count is always 0.
+func (m *MemoryStore) GetWithStats(ctx context.Context, code shortener.Code) (*shortener.ShortURLStats, error) {
+	shortURL, err := m.GetByCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+
+	return &shortener.ShortURLStats{ShortURL: shortURL}, nil
+}
+
+// Exists reports which of codes are stored.
+func (m *MemoryStore) Exists(_ context.Context, codes []shortener.Code) (map[shortener.Code]bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	exists := make(map[shortener.Code]bool, len(codes))
+
+	for _, code := range codes {
+		_, exists[code] = m.urls[code]
+	}
+
+	return exists, nil
+}
+
+// MostRecent returns up to limit short URLs ordered by creation time, newest first.
+func (m *MemoryStore) MostRecent(_ context.Context, limit int) ([]*shortener.ShortURL, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return mostRecent(slices.Collect(maps.Values(m.urls)), limit), nil
+}
+
+// ListByCreator returns up to limit short URLs created by creator, newest first.
+func (m *MemoryStore) ListByCreator(_ context.Context, creator string, limit int) ([]*shortener.ShortURL, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var shortURLs []*shortener.ShortURL
+
+	for _, shortURL := range m.urls {
+		if creator != "" && shortURL.CreatedBy == creator {
+			shortURLs = append(shortURLs, shortURL)
+		}
+	}
+
+	return mostRecent(shortURLs, limit), nil
+}
+
+// UpdateTarget replaces the entity for code with one pointing at newURL.
+func (m *MemoryStore) UpdateTarget(_ context.Context, code shortener.Code, newURL, normalizedURL string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	current, ok := m.urls[code]
+	if !ok {
+		return shortener.ErrNotFound
+	}
+
+	updated := *current
+	updated.OriginalURL = newURL
+
+	if current.URLHash != "" {
+		urlHash := shortener.URLHash(shortener.HashURL(normalizedURL))
+
+		delete(m.hashes, current.URLHash)
+		updated.URLHash = urlHash
+		m.hashes[urlHash] = code
+	}
+
+	if current.NormalizedURL != "" {
+		updated.NormalizedURL = normalizedURL
+	}
+
+	// Replace rather than mutate: callers and caches may hold the old entity
+	m.urls[code] = &updated
+
+	return nil
+}
+
+// Flag replaces the entity for code with one carrying the flag.
+func (m *MemoryStore) Flag(_ context.Context, code shortener.Code, flagged bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	current, ok := m.urls[code]
+	if !ok {
+		return shortener.ErrNotFound
+	}
+
+	updated := *current
+	updated.Flagged = flagged
+	m.urls[code] = &updated
+
+	return nil
+}
+
+func (m *MemoryStore) Count(_ context.Context) (int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return int64(len(m.urls)), nil
+}
+
+// mostRecent sorts shortURLs newest first, breaking ties by code, and keeps at most limit.
+func mostRecent(shortURLs []*shortener.ShortURL, limit int) []*shortener.ShortURL {
+	slices.SortFunc(shortURLs, func(a, b *shortener.ShortURL) int {
+		if c := b.CreatedAt.Compare(a.CreatedAt); c != 0 {
+			return c
+		}
+
+		return cmp.Compare(b.Code, a.Code)
+	})
+
+	return shortURLs[:min(limit, len(shortURLs))]
+}
@@ -3,6 +3,7 @@ package store_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/serroba/web-demo-go/internal/shortener"
 	"github.com/serroba/web-demo-go/internal/store"
@@ -46,7 +47,7 @@ func TestMemoryStore_Save(t *testing.T) {
 		assert.Equal(t, shortener.Code("abc123"), shortURL.Code)
 	})
 
-	t.Run("overwrites existing url", func(t *testing.T) {
+	t.Run("rejects an existing code", func(t *testing.T) {
 		s := store.NewMemoryStore()
 		_ = s.Save(context.Background(), &shortener.ShortURL{
 			Code:        "abc123",
@@ -58,11 +59,11 @@ func TestMemoryStore_Save(t *testing.T) {
 			OriginalURL: "https://other.com",
 		})
 
-		require.NoError(t, err)
+		require.ErrorIs(t, err, shortener.ErrCodeCollision)
 
 		shortURL, _ := s.GetByCode(context.Background(), "abc123")
 
-		assert.Equal(t, "https://other.com", shortURL.OriginalURL)
+		assert.Equal(t, "https://example.com", shortURL.OriginalURL)
 	})
 }
 
@@ -115,3 +116,207 @@ func TestMemoryStore_GetByHash(t *testing.T) {
 		assert.ErrorIs(t, err, shortener.ErrNotFound)
 	})
 }
+
+func TestMemoryStore_UpdateTarget(t *testing.T) {
+	t.Run("points the code at the new url", func(t *testing.T) {
+		s := store.NewMemoryStore()
+		require.NoError(t, s.Save(context.Background(), &shortener.ShortURL{
+			Code:        "abc123",
+			OriginalURL: "https://example.com",
+		}))
+
+		err := s.UpdateTarget(context.Background(), "abc123", "https://example.org", "https://example.org")
+
+		require.NoError(t, err)
+
+		shortURL, err := s.GetByCode(context.Background(), "abc123")
+
+		require.NoError(t, err)
+		assert.Equal(t, "https://example.org", shortURL.OriginalURL)
+		assert.Empty(t, shortURL.URLHash)
+	})
+
+	t.Run("moves the hash index to the new url", func(t *testing.T) {
+		s := store.NewMemoryStore()
+		oldHash := shortener.URLHash(shortener.HashURL("https://example.com"))
+		require.NoError(t, s.Save(context.Background(), &shortener.ShortURL{
+			Code:        "abc123",
+			OriginalURL: "https://example.com",
+			URLHash:     oldHash,
+		}))
+
+		require.NoError(t, s.UpdateTarget(context.Background(), "abc123", "https://example.org", "https://example.org"))
+
+		newHash := shortener.URLHash(shortener.HashURL("https://example.org"))
+
+		shortURL, err := s.GetByHash(context.Background(), newHash)
+		require.NoError(t, err)
+		assert.Equal(t, shortener.Code("abc123"), shortURL.Code)
+		assert.Equal(t, newHash, shortURL.URLHash)
+
+		_, err = s.GetByHash(context.Background(), oldHash)
+		require.ErrorIs(t, err, shortener.ErrNotFound)
+	})
+
+	t.Run("replaces a stored normalized url", func(t *testing.T) {
+		s := store.NewMemoryStore()
+		require.NoError(t, s.Save(context.Background(), &shortener.ShortURL{
+			Code:          "abc123",
+			OriginalURL:   "https://example.com",
+			NormalizedURL: "https://example.com",
+		}))
+
+		require.NoError(t, s.UpdateTarget(context.Background(), "abc123",
+			"HTTPS://Example.ORG/new/", "https://example.org/new"))
+
+		shortURL, err := s.GetByCode(context.Background(), "abc123")
+		require.NoError(t, err)
+		assert.Equal(t, "HTTPS://Example.ORG/new/", shortURL.OriginalURL)
+		assert.Equal(t, "https://example.org/new", shortURL.NormalizedURL)
+	})
+
+	t.Run("returns ErrNotFound when code does not exist", func(t *testing.T) {
+		s := store.NewMemoryStore()
+
+		err := s.UpdateTarget(context.Background(), "missing", "https://example.org", "https://example.org")
+
+		require.ErrorIs(t, err, shortener.ErrNotFound)
+	})
+}
+
+func TestMemoryStore_Flag(t *testing.T) {
+	t.Run("sets and clears the flag", func(t *testing.T) {
+		s := store.NewMemoryStore()
+		require.NoError(t, s.Save(context.Background(), &shortener.ShortURL{
+			Code:        "abc123",
+			OriginalURL: "https://example.com",
+		}))
+
+		require.NoError(t, s.Flag(context.Background(), "abc123", true))
+
+		shortURL, err := s.GetByCode(context.Background(), "abc123")
+		require.NoError(t, err)
+		assert.True(t, shortURL.Flagged)
+
+		require.NoError(t, s.Flag(context.Background(), "abc123", false))
+
+		shortURL, err = s.GetByCode(context.Background(), "abc123")
+		require.NoError(t, err)
+		assert.False(t, shortURL.Flagged)
+	})
+
+	t.Run("returns ErrNotFound when code does not exist", func(t *testing.T) {
+		s := store.NewMemoryStore()
+
+		err := s.Flag(context.Background(), "missing", true)
+
+		require.ErrorIs(t, err, shortener.ErrNotFound)
+	})
+}
+
+func TestMemoryStore_Count(t *testing.T) {
+	s := store.NewMemoryStore()
+
+	count, err := s.Count(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), count)
+
+	_ = s.Save(context.Background(), &shortener.ShortURL{Code: "abc123", OriginalURL: "https://example.com"})
+	_ = s.Save(context.Background(), &shortener.ShortURL{Code: "def456", OriginalURL: "https://example.com"})
+
+	count, err = s.Count(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+}
+
+func TestMemoryStore_MostRecent(t *testing.T) {
+	s := store.NewMemoryStore()
+	base := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+
+	_ = s.Save(context.Background(), &shortener.ShortURL{Code: "old", CreatedAt: base})
+	_ = s.Save(context.Background(), &shortener.ShortURL{Code: "new", CreatedAt: base.Add(2 * time.Minute)})
+	_ = s.Save(context.Background(), &shortener.ShortURL{Code: "mid", CreatedAt: base.Add(time.Minute)})
+
+	recent, err := s.MostRecent(context.Background(), 2)
+	require.NoError(t, err)
+	require.Len(t, recent, 2)
+	assert.Equal(t, shortener.Code("new"), recent[0].Code)
+	assert.Equal(t, shortener.Code("mid"), recent[1].Code)
+
+	all, err := s.MostRecent(context.Background(), 10)
+	require.NoError(t, err)
+	assert.Len(t, all, 3)
+}
+
+func TestMemoryStore_GetWithStats(t *testing.T) {
+	s := store.NewMemoryStore()
+	_ = s.Save(context.Background(), &shortener.ShortURL{Code: "abc123", OriginalURL: "https://example.com"})
+
+	stats, err := s.GetWithStats(context.Background(), "abc123")
+
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com", stats.ShortURL.OriginalURL)
+	assert.Zero(t, stats.AccessCount, "the memory store does not count accesses")
+
+	_, err = s.GetWithStats(context.Background(), "notfound")
+	assert.ErrorIs(t, err, shortener.ErrNotFound)
+}
+
+func TestMemoryStore_Exists(t *testing.T) {
+	s := store.NewMemoryStore()
+	_ = s.Save(context.Background(), &shortener.ShortURL{Code: "taken", OriginalURL: "https://example.com"})
+
+	exists, err := s.Exists(context.Background(), []shortener.Code{"taken", "free"})
+
+	require.NoError(t, err)
+	assert.Equal(t, map[shortener.Code]bool{"taken": true, "free": false}, exists)
+}
+
+func TestMemoryStore_ListByCreator(t *testing.T) {
+	s := store.NewMemoryStore()
+	base := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+
+	_ = s.Save(context.Background(), &shortener.ShortURL{Code: "a1", CreatedBy: "key:a", CreatedAt: base})
+	_ = s.Save(context.Background(), &shortener.ShortURL{Code: "a2", CreatedBy: "key:a", CreatedAt: base.Add(time.Minute)})
+	_ = s.Save(context.Background(), &shortener.ShortURL{Code: "b1", CreatedBy: "key:b", CreatedAt: base})
+	_ = s.Save(context.Background(), &shortener.ShortURL{Code: "anon", CreatedAt: base})
+
+	mine, err := s.ListByCreator(context.Background(), "key:a", 10)
+	require.NoError(t, err)
+	require.Len(t, mine, 2)
+	assert.Equal(t, shortener.Code("a2"), mine[0].Code)
+	assert.Equal(t, shortener.Code("a1"), mine[1].Code)
+
+	limited, err := s.ListByCreator(context.Background(), "key:a", 1)
+	require.NoError(t, err)
+	assert.Len(t, limited, 1)
+
+	anonymous, err := s.ListByCreator(context.Background(), "", 10)
+	require.NoError(t, err)
+	assert.Empty(t, anonymous)
+}
+
+func TestMemoryStore_SaveBatch(t *testing.T) {
+	s := store.NewMemoryStore()
+	require.NoError(t, s.Save(context.Background(), &shortener.ShortURL{Code: "taken", OriginalURL: "https://example.com/old"}))
+
+	inserted, err := s.SaveBatch(context.Background(), []*shortener.ShortURL{
+		{Code: "abc123", OriginalURL: "https://example.com/a"},
+		{Code: "taken", OriginalURL: "https://example.com/new"},
+		{Code: "def456", OriginalURL: "https://example.com/b", URLHash: "hash-b"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []shortener.Code{"abc123", "def456"}, inserted)
+
+	got, err := s.GetByCode(context.Background(), "taken")
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/old", got.OriginalURL, "a taken code must not be overwritten")
+
+	got, err = s.GetByCode(context.Background(), "abc123")
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/a", got.OriginalURL)
+
+	got, err = s.GetByHash(context.Background(), "hash-b")
+	require.NoError(t, err)
+	assert.Equal(t, shortener.Code("def456"), got.Code)
+}
@@ -21,75 +21,289 @@ func NewPostgresStore(pool *pgxpool.Pool) *PostgresStore {
 
 func (p *PostgresStore) Save(ctx context.Context, shortURL *shortener.ShortURL) error {
 	query := `
-		INSERT INTO short_urls (code, original_url, url_hash, created_at)
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO short_urls (
+			code, original_url, url_hash, created_at, fallback_url, flagged, normalized_url, created_by, strategy,
+			content_type_hint
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 		ON CONFLICT (code) DO NOTHING
 	`
 
-	_, err := p.pool.Exec(ctx, query,
+	tag, err := p.pool.Exec(ctx, query,
 		string(shortURL.Code),
 		shortURL.OriginalURL,
 		nullableString(shortURL.URLHash),
 		shortURL.CreatedAt,
+		nullableString(shortURL.FallbackURL),
+		shortURL.Flagged,
+		nullableString(shortURL.NormalizedURL),
+		nullableString(shortURL.CreatedBy),
+		nullableString(shortURL.Strategy),
+		nullableString(shortURL.ContentTypeHint),
 	)
+	if err != nil {
+		return err
+	}
+
+	// ON CONFLICT DO NOTHING leaves the existing row; report it instead of
+	// handing out a code that points at someone else's URL.
+	if tag.RowsAffected() == 0 {
+		return shortener.ErrCodeCollision
+	}
+
+	return nil
+}
+
+func (p *PostgresStore) SaveBatch(ctx context.Context, shortURLs []*shortener.ShortURL) ([]shortener.Code, error) {
+	query := `
+		INSERT INTO short_urls (
+			code, original_url, url_hash, created_at, fallback_url, flagged, normalized_url, created_by, strategy,
+			content_type_hint
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (code) DO NOTHING
+		RETURNING code
+	`
+
+	batch := &pgx.Batch{}
+	for _, shortURL := range shortURLs {
+		batch.Queue(query,
+			string(shortURL.Code),
+			shortURL.OriginalURL,
+			nullableString(shortURL.URLHash),
+			shortURL.CreatedAt,
+			nullableString(shortURL.FallbackURL),
+			shortURL.Flagged,
+			nullableString(shortURL.NormalizedURL),
+			nullableString(shortURL.CreatedBy),
+			nullableString(shortURL.Strategy),
+			nullableString(shortURL.ContentTypeHint),
+		)
+	}
+
+	results := p.pool.SendBatch(ctx, batch)
+	defer results.Close()
+
+	inserted := make([]shortener.Code, 0, len(shortURLs))
+
+	for range shortURLs {
+		var code string
+
+		// ON CONFLICT DO NOTHING returns no row for a taken code.
+		err := results.QueryRow().Scan(&code)
+		if errors.Is(err, pgx.ErrNoRows) {
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		inserted = append(inserted, shortener.Code(code))
+	}
 
-	return err
+	return inserted, results.Close()
 }
 
 func (p *PostgresStore) GetByCode(ctx context.Context, code shortener.Code) (*shortener.ShortURL, error) {
 	query := `
-		SELECT code, original_url, url_hash, created_at
+		SELECT ` + shortURLColumns + `
 		FROM short_urls
 		WHERE code = $1
 	`
 
-	var url shortener.ShortURL
+	url, err := scanShortURL(p.pool.QueryRow(ctx, query, string(code)))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, shortener.ErrNotFound
+	}
 
-	var urlHash *string
+	return url, err
+}
+
+func (p *PostgresStore) GetByHash(ctx context.Context, hash shortener.URLHash) (*shortener.ShortURL, error) {
+	query := `
+		SELECT ` + shortURLColumns + `
+		FROM short_urls
+		WHERE url_hash = $1
+	`
+
+	url, err := scanShortURL(p.pool.QueryRow(ctx, query, string(hash)))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, shortener.ErrNotFound
+	}
+
+	return url, err
+}
+
+// GetWithStats reads a short URL and its persisted access_count in one query.
+func (p *PostgresStore) GetWithStats(ctx context.Context, code shortener.Code) (*shortener.ShortURLStats, error) {
+	query := `
+		SELECT ` + shortURLColumns + `, access_count
+		FROM short_urls
+		WHERE code = $1
+	`
+
+	var stats shortener.ShortURLStats
+
+	url, err := scanShortURL(p.pool.QueryRow(ctx, query, string(code)), &stats.AccessCount)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, shortener.ErrNotFound
+	}
 
-	err := p.pool.QueryRow(ctx, query, string(code)).Scan(
-		&url.Code,
-		&url.OriginalURL,
-		&urlHash,
-		&url.CreatedAt,
-	)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, shortener.ErrNotFound
+		return nil, err
+	}
+
+	stats.ShortURL = url
+
+	return &stats, nil
+}
+
+// Exists reports which of codes are stored with a single query.
+func (p *PostgresStore) Exists(ctx context.Context, codes []shortener.Code) (map[shortener.Code]bool, error) {
+	exists := make(map[shortener.Code]bool, len(codes))
+	params := make([]string, len(codes))
+
+	for i, code := range codes {
+		exists[code] = false
+		params[i] = string(code)
+	}
+
+	rows, err := p.pool.Query(ctx, `SELECT code FROM short_urls WHERE code = ANY($1)`, params)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var code string
+		if err = rows.Scan(&code); err != nil {
+			return nil, err
 		}
 
+		exists[shortener.Code(code)] = true
+	}
+
+	if err = rows.Err(); err != nil {
 		return nil, err
 	}
 
-	if urlHash != nil {
-		url.URLHash = shortener.URLHash(*urlHash)
+	return exists, nil
+}
+
+func (p *PostgresStore) Count(ctx context.Context) (int64, error) {
+	var count int64
+
+	if err := p.pool.QueryRow(ctx, `SELECT count(*) FROM short_urls`).Scan(&count); err != nil {
+		return 0, err
 	}
 
-	return &url, nil
+	return count, nil
 }
 
-func (p *PostgresStore) GetByHash(ctx context.Context, hash shortener.URLHash) (*shortener.ShortURL, error) {
+// MostRecent returns up to limit short URLs ordered by creation time, newest first.
+func (p *PostgresStore) MostRecent(ctx context.Context, limit int) ([]*shortener.ShortURL, error) {
 	query := `
-		SELECT code, original_url, url_hash, created_at
+		SELECT ` + shortURLColumns + `
 		FROM short_urls
-		WHERE url_hash = $1
+		ORDER BY created_at DESC, code DESC
+		LIMIT $1
+	`
+
+	rows, err := p.pool.Query(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanShortURLs(rows, limit)
+}
+
+// ListByCreator returns up to limit short URLs created by creator, newest first.
+func (p *PostgresStore) ListByCreator(ctx context.Context, creator string, limit int) ([]*shortener.ShortURL, error) {
+	if creator == "" {
+		return []*shortener.ShortURL{}, nil
+	}
+
+	query := `
+		SELECT ` + shortURLColumns + `
+		FROM short_urls
+		WHERE created_by = $1
+		ORDER BY created_at DESC, code DESC
+		LIMIT $2
 	`
 
+	rows, err := p.pool.Query(ctx, query, creator, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanShortURLs(rows, limit)
+}
+
+// UpdateTarget points code at newURL, rehashing rows that carry a URL hash and
+// replacing the normalized URL of rows that carry one.
+func (p *PostgresStore) UpdateTarget(ctx context.Context, code shortener.Code, newURL, normalizedURL string) error {
+	query := `
+		UPDATE short_urls
+		SET original_url = $2,
+		    url_hash = CASE WHEN url_hash IS NULL THEN NULL ELSE $3 END,
+		    normalized_url = CASE WHEN normalized_url IS NULL THEN NULL ELSE $4 END
+		WHERE code = $1
+	`
+
+	tag, err := p.pool.Exec(ctx, query, string(code), newURL, shortener.HashURL(normalizedURL), normalizedURL)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return shortener.ErrNotFound
+	}
+
+	return nil
+}
+
+// Flag sets or clears the flagged mark of code.
+func (p *PostgresStore) Flag(ctx context.Context, code shortener.Code, flagged bool) error {
+	tag, err := p.pool.Exec(ctx, `UPDATE short_urls SET flagged = $2 WHERE code = $1`, string(code), flagged)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return shortener.ErrNotFound
+	}
+
+	return nil
+}
+
+// shortURLColumns lists the short_urls columns in the order scanShortURL reads them.
+const shortURLColumns = "code, original_url, url_hash, created_at, fallback_url, flagged, normalized_url, created_by, " +
+	"strategy, content_type_hint"
+
+// scanShortURL reads one row selected with shortURLColumns, followed by any
+// extra columns into extra.
+func scanShortURL(row pgx.Row, extra ...any) (*shortener.ShortURL, error) {
 	var url shortener.ShortURL
 
-	var urlHash *string
+	var urlHash, fallbackURL, normalizedURL, createdBy, strategy, contentTypeHint *string
 
-	err := p.pool.QueryRow(ctx, query, string(hash)).Scan(
+	dest := []any{
 		&url.Code,
 		&url.OriginalURL,
 		&urlHash,
 		&url.CreatedAt,
-	)
-	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, shortener.ErrNotFound
-		}
+		&fallbackURL,
+		&url.Flagged,
+		&normalizedURL,
+		&createdBy,
+		&strategy,
+		&contentTypeHint,
+	}
 
+	if err := row.Scan(append(dest, extra...)...); err != nil {
 		return nil, err
 	}
 
@@ -97,10 +311,50 @@ func (p *PostgresStore) GetByHash(ctx context.Context, hash shortener.URLHash) (
 		url.URLHash = shortener.URLHash(*urlHash)
 	}
 
+	if fallbackURL != nil {
+		url.FallbackURL = *fallbackURL
+	}
+
+	if normalizedURL != nil {
+		url.NormalizedURL = *normalizedURL
+	}
+
+	if createdBy != nil {
+		url.CreatedBy = *createdBy
+	}
+
+	if strategy != nil {
+		url.Strategy = *strategy
+	}
+
+	if contentTypeHint != nil {
+		url.ContentTypeHint = *contentTypeHint
+	}
+
 	return &url, nil
 }
 
-func nullableString(s shortener.URLHash) *string {
+// scanShortURLs reads every row selected with shortURLColumns.
+func scanShortURLs(rows pgx.Rows, limit int) ([]*shortener.ShortURL, error) {
+	shortURLs := make([]*shortener.ShortURL, 0, limit)
+
+	for rows.Next() {
+		url, err := scanShortURL(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		shortURLs = append(shortURLs, url)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return shortURLs, nil
+}
+
+func nullableString[T ~string](s T) *string {
 	if s == "" {
 		return nil
 	}
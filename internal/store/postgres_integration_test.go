@@ -77,7 +77,7 @@ func TestPostgresStoreIntegration(t *testing.T) {
 		_, _ = pool.Exec(ctx, "DELETE FROM short_urls WHERE code = $1", string(shortURL.Code))
 	})
 
-	t.Run("save with ON CONFLICT does not error", func(t *testing.T) {
+	t.Run("save with a taken code reports a collision", func(t *testing.T) {
 		code := shortener.Code("pgconflict1")
 		first := &shortener.ShortURL{
 			Code:        code,
@@ -93,9 +93,9 @@ func TestPostgresStoreIntegration(t *testing.T) {
 		err := s.Save(ctx, first)
 		require.NoError(t, err)
 
-		// Second save should not error (ON CONFLICT DO NOTHING)
+		// ON CONFLICT DO NOTHING inserts nothing, which Save reports
 		err = s.Save(ctx, second)
-		require.NoError(t, err)
+		require.ErrorIs(t, err, shortener.ErrCodeCollision)
 
 		// First value should be preserved
 		got, _ := s.GetByCode(ctx, code)
@@ -118,4 +118,325 @@ func TestPostgresStoreIntegration(t *testing.T) {
 		assert.Nil(t, got)
 		assert.ErrorIs(t, err, shortener.ErrNotFound)
 	})
+
+	t.Run("count reflects inserted rows", func(t *testing.T) {
+		before, err := s.Count(ctx)
+		require.NoError(t, err)
+
+		codes := []string{"pgcount1", "pgcount2", "pgcount3"}
+		for _, code := range codes {
+			require.NoError(t, s.Save(ctx, &shortener.ShortURL{
+				Code:        shortener.Code(code),
+				OriginalURL: "https://example.com/count",
+				CreatedAt:   time.Now().UTC(),
+			}))
+		}
+
+		after, err := s.Count(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, before+int64(len(codes)), after)
+
+		// Cleanup
+		_, _ = pool.Exec(ctx, "DELETE FROM short_urls WHERE code = ANY($1)", codes)
+	})
+	t.Run("save batch inserts all rows", func(t *testing.T) {
+		codes := []string{"pgbatch1", "pgbatch2"}
+		urls := make([]*shortener.ShortURL, 0, len(codes))
+
+		for _, code := range codes {
+			urls = append(urls, &shortener.ShortURL{
+				Code:        shortener.Code(code),
+				OriginalURL: "https://example.com/" + code,
+				CreatedAt:   time.Now().UTC(),
+			})
+		}
+
+		inserted, err := s.SaveBatch(ctx, urls)
+		require.NoError(t, err)
+		assert.Equal(t, []shortener.Code{"pgbatch1", "pgbatch2"}, inserted)
+
+		for _, code := range codes {
+			got, err := s.GetByCode(ctx, shortener.Code(code))
+			require.NoError(t, err)
+			assert.Equal(t, "https://example.com/"+code, got.OriginalURL)
+		}
+
+		// Cleanup
+		_, _ = pool.Exec(ctx, "DELETE FROM short_urls WHERE code = ANY($1)", codes)
+	})
+
+	t.Run("save batch skips taken codes", func(t *testing.T) {
+		codes := []string{"pgtaken1", "pgfresh1"}
+		require.NoError(t, s.Save(ctx, &shortener.ShortURL{
+			Code:        "pgtaken1",
+			OriginalURL: "https://example.com/old",
+			CreatedAt:   time.Now().UTC(),
+		}))
+
+		inserted, err := s.SaveBatch(ctx, []*shortener.ShortURL{
+			{Code: "pgtaken1", OriginalURL: "https://example.com/new", CreatedAt: time.Now().UTC()},
+			{Code: "pgfresh1", OriginalURL: "https://example.com/fresh", CreatedAt: time.Now().UTC()},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []shortener.Code{"pgfresh1"}, inserted)
+
+		got, err := s.GetByCode(ctx, "pgtaken1")
+		require.NoError(t, err)
+		assert.Equal(t, "https://example.com/old", got.OriginalURL)
+
+		// Cleanup
+		_, _ = pool.Exec(ctx, "DELETE FROM short_urls WHERE code = ANY($1)", codes)
+	})
+
+	t.Run("update target changes the url and rehashes", func(t *testing.T) {
+		oldHash := shortener.URLHash(shortener.HashURL("https://example.com/before"))
+
+		shortURL := &shortener.ShortURL{
+			Code:        shortener.Code("pgupdate1"),
+			OriginalURL: "https://example.com/before",
+			URLHash:     oldHash,
+			CreatedAt:   time.Now().UTC().Truncate(time.Microsecond),
+		}
+
+		require.NoError(t, s.Save(ctx, shortURL))
+		require.NoError(t, s.UpdateTarget(ctx, shortURL.Code, "https://Example.com/after", "https://example.com/after"))
+
+		newHash := shortener.URLHash(shortener.HashURL("https://example.com/after"))
+
+		got, err := s.GetByHash(ctx, newHash)
+		require.NoError(t, err)
+		assert.Equal(t, shortURL.Code, got.Code)
+		assert.Equal(t, "https://Example.com/after", got.OriginalURL, "the target is stored as given")
+
+		_, err = s.GetByHash(ctx, oldHash)
+		require.ErrorIs(t, err, shortener.ErrNotFound)
+
+		// Cleanup
+		_, _ = pool.Exec(ctx, "DELETE FROM short_urls WHERE code = $1", string(shortURL.Code))
+	})
+
+	t.Run("update target of a missing code returns ErrNotFound", func(t *testing.T) {
+		err := s.UpdateTarget(ctx, "pgnonexistent", "https://example.com", "https://example.com")
+
+		assert.ErrorIs(t, err, shortener.ErrNotFound)
+	})
+
+	t.Run("round trips fallback url and flag", func(t *testing.T) {
+		shortURL := &shortener.ShortURL{
+			Code:        shortener.Code("pgfallback1"),
+			OriginalURL: "https://example.com/primary",
+			CreatedAt:   time.Now().UTC().Truncate(time.Microsecond),
+			FallbackURL: "https://example.com/fallback",
+			Flagged:     true,
+		}
+
+		require.NoError(t, s.Save(ctx, shortURL))
+
+		got, err := s.GetByCode(ctx, shortURL.Code)
+		require.NoError(t, err)
+		assert.Equal(t, shortURL.FallbackURL, got.FallbackURL)
+		assert.True(t, got.Flagged)
+
+		// Cleanup
+		_, _ = pool.Exec(ctx, "DELETE FROM short_urls WHERE code = $1", string(shortURL.Code))
+	})
+
+	t.Run("flag sets and clears the mark", func(t *testing.T) {
+		shortURL := &shortener.ShortURL{
+			Code:        shortener.Code("pgflag1"),
+			OriginalURL: "https://example.com",
+			CreatedAt:   time.Now().UTC().Truncate(time.Microsecond),
+		}
+
+		require.NoError(t, s.Save(ctx, shortURL))
+		require.NoError(t, s.Flag(ctx, shortURL.Code, true))
+
+		got, err := s.GetByCode(ctx, shortURL.Code)
+		require.NoError(t, err)
+		assert.True(t, got.Flagged)
+
+		require.NoError(t, s.Flag(ctx, shortURL.Code, false))
+
+		got, err = s.GetByCode(ctx, shortURL.Code)
+		require.NoError(t, err)
+		assert.False(t, got.Flagged)
+
+		// Cleanup
+		_, _ = pool.Exec(ctx, "DELETE FROM short_urls WHERE code = $1", string(shortURL.Code))
+	})
+
+	t.Run("flag of a missing code returns ErrNotFound", func(t *testing.T) {
+		err := s.Flag(ctx, "pgnonexistent", true)
+
+		assert.ErrorIs(t, err, shortener.ErrNotFound)
+	})
+
+	t.Run("round trips and replaces the normalized url", func(t *testing.T) {
+		shortURL := &shortener.ShortURL{
+			Code:          shortener.Code("pgnormal1"),
+			OriginalURL:   "HTTPS://Example.COM/Path/",
+			CreatedAt:     time.Now().UTC().Truncate(time.Microsecond),
+			NormalizedURL: "https://example.com/Path",
+		}
+
+		require.NoError(t, s.Save(ctx, shortURL))
+
+		got, err := s.GetByCode(ctx, shortURL.Code)
+		require.NoError(t, err)
+		assert.Equal(t, "HTTPS://Example.COM/Path/", got.OriginalURL)
+		assert.Equal(t, "https://example.com/Path", got.NormalizedURL)
+
+		require.NoError(t, s.UpdateTarget(ctx, shortURL.Code, "https://Example.ORG:443/new", "https://example.org/new"))
+
+		got, err = s.GetByCode(ctx, shortURL.Code)
+		require.NoError(t, err)
+		assert.Equal(t, "https://example.org/new", got.NormalizedURL)
+
+		// Cleanup
+		_, _ = pool.Exec(ctx, "DELETE FROM short_urls WHERE code = $1", string(shortURL.Code))
+	})
+
+	t.Run("rows without a normalized url stay without one", func(t *testing.T) {
+		shortURL := &shortener.ShortURL{
+			Code:        shortener.Code("pgnormal2"),
+			OriginalURL: "https://example.com",
+			CreatedAt:   time.Now().UTC().Truncate(time.Microsecond),
+		}
+
+		require.NoError(t, s.Save(ctx, shortURL))
+		require.NoError(t, s.UpdateTarget(ctx, shortURL.Code, "https://example.org", "https://example.org"))
+
+		got, err := s.GetByCode(ctx, shortURL.Code)
+		require.NoError(t, err)
+		assert.Empty(t, got.NormalizedURL)
+
+		// Cleanup
+		_, _ = pool.Exec(ctx, "DELETE FROM short_urls WHERE code = $1", string(shortURL.Code))
+	})
+
+	t.Run("most recent orders by creation time and enforces the limit", func(t *testing.T) {
+		// Far-future timestamps keep these rows ahead of anything else in the table.
+		base := time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC)
+		codes := []string{"pgrecent1", "pgrecent2", "pgrecent3"}
+
+		for i, code := range codes {
+			require.NoError(t, s.Save(ctx, &shortener.ShortURL{
+				Code:        shortener.Code(code),
+				OriginalURL: "https://example.com/" + code,
+				CreatedAt:   base.Add(time.Duration(i) * time.Minute),
+			}))
+		}
+
+		recent, err := s.MostRecent(ctx, 2)
+		require.NoError(t, err)
+		require.Len(t, recent, 2)
+		assert.Equal(t, shortener.Code("pgrecent3"), recent[0].Code)
+		assert.Equal(t, shortener.Code("pgrecent2"), recent[1].Code)
+		assert.True(t, recent[0].CreatedAt.After(recent[1].CreatedAt))
+
+		// Cleanup
+		_, _ = pool.Exec(ctx, "DELETE FROM short_urls WHERE code = ANY($1)", codes)
+	})
+
+	t.Run("creates as a key and lists that key's urls", func(t *testing.T) {
+		generate, err := shortener.NewCodeGenerator(8, false)
+		require.NoError(t, err)
+
+		strategy := shortener.NewTokenStrategy(s, generate)
+		keyCtx := shortener.WithCreator(ctx, "key:pgcreator")
+
+		first, err := strategy.Shorten(keyCtx, "https://example.com/first")
+		require.NoError(t, err)
+
+		second, err := strategy.Shorten(keyCtx, "https://example.com/second")
+		require.NoError(t, err)
+
+		anonymous, err := strategy.Shorten(ctx, "https://example.com/anonymous")
+		require.NoError(t, err)
+
+		got, err := s.GetByCode(ctx, first.Code)
+		require.NoError(t, err)
+		assert.Equal(t, "key:pgcreator", got.CreatedBy)
+		assert.Equal(t, shortener.StrategyToken, got.Strategy)
+
+		got, err = s.GetByCode(ctx, anonymous.Code)
+		require.NoError(t, err)
+		assert.Empty(t, got.CreatedBy)
+
+		mine, err := s.ListByCreator(ctx, "key:pgcreator", 10)
+		require.NoError(t, err)
+
+		codes := make([]shortener.Code, 0, len(mine))
+		for _, shortURL := range mine {
+			codes = append(codes, shortURL.Code)
+		}
+
+		assert.ElementsMatch(t, []shortener.Code{first.Code, second.Code}, codes)
+
+		limited, err := s.ListByCreator(ctx, "key:pgcreator", 1)
+		require.NoError(t, err)
+		assert.Len(t, limited, 1)
+
+		none, err := s.ListByCreator(ctx, "", 10)
+		require.NoError(t, err)
+		assert.Empty(t, none)
+
+		// Cleanup
+		_, _ = pool.Exec(ctx, "DELETE FROM short_urls WHERE code = ANY($1)",
+			[]string{string(first.Code), string(second.Code), string(anonymous.Code)})
+	})
+
+	t.Run("exists reports taken and free codes", func(t *testing.T) {
+		shortURL := &shortener.ShortURL{
+			Code:        shortener.Code("pgexists1"),
+			OriginalURL: "https://example.com",
+			CreatedAt:   time.Now().UTC().Truncate(time.Microsecond),
+		}
+
+		require.NoError(t, s.Save(ctx, shortURL))
+
+		exists, err := s.Exists(ctx, []shortener.Code{"pgexists1", "pgexists2"})
+		require.NoError(t, err)
+		assert.Equal(t, map[shortener.Code]bool{"pgexists1": true, "pgexists2": false}, exists)
+
+		// Cleanup
+		_, _ = pool.Exec(ctx, "DELETE FROM short_urls WHERE code = $1", string(shortURL.Code))
+	})
+
+	t.Run("get with stats matches separate queries", func(t *testing.T) {
+		shortURL := &shortener.ShortURL{
+			Code:            shortener.Code("pgstats1"),
+			OriginalURL:     "https://example.com/stats",
+			URLHash:         shortener.URLHash("pgstatshash1"),
+			CreatedAt:       time.Now().UTC().Truncate(time.Microsecond),
+			CreatedBy:       "key:stats",
+			ContentTypeHint: "application/pdf",
+		}
+
+		require.NoError(t, s.Save(ctx, shortURL))
+		_, err := pool.Exec(ctx, "UPDATE short_urls SET access_count = 7 WHERE code = $1", string(shortURL.Code))
+		require.NoError(t, err)
+
+		got, err := s.GetWithStats(ctx, shortURL.Code)
+		require.NoError(t, err)
+
+		byCode, err := s.GetByCode(ctx, shortURL.Code)
+		require.NoError(t, err)
+
+		var accessCount int64
+
+		require.NoError(t, pool.QueryRow(ctx,
+			"SELECT access_count FROM short_urls WHERE code = $1", string(shortURL.Code)).Scan(&accessCount))
+
+		assert.Equal(t, byCode, got.ShortURL)
+		assert.Equal(t, accessCount, got.AccessCount)
+		assert.Equal(t, int64(7), got.AccessCount)
+		assert.Equal(t, "application/pdf", got.ShortURL.ContentTypeHint)
+
+		_, err = s.GetWithStats(ctx, "pgstatsmissing")
+		require.ErrorIs(t, err, shortener.ErrNotFound)
+
+		// Cleanup
+		_, _ = pool.Exec(ctx, "DELETE FROM short_urls WHERE code = $1", string(shortURL.Code))
+	})
 }
@@ -3,7 +3,9 @@ package store
 import (
 	"context"
 	"errors"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -31,10 +33,16 @@ func (r *RedisStore) Save(ctx context.Context, shortURL *shortener.ShortURL) err
 
 	// Store entity as Redis hash
 	pipe.HSet(ctx, r.prefix+string(shortURL.Code), map[string]interface{}{
-		"code":         string(shortURL.Code),
-		"original_url": shortURL.OriginalURL,
-		"url_hash":     string(shortURL.URLHash),
-		"created_at":   shortURL.CreatedAt.UnixNano(),
+		"code":              string(shortURL.Code),
+		"original_url":      shortURL.OriginalURL,
+		"url_hash":          string(shortURL.URLHash),
+		"created_at":        shortURL.CreatedAt.UnixNano(),
+		"fallback_url":      shortURL.FallbackURL,
+		"flagged":           strconv.FormatBool(shortURL.Flagged),
+		"normalized_url":    shortURL.NormalizedURL,
+		"created_by":        shortURL.CreatedBy,
+		"strategy":          shortURL.Strategy,
+		"content_type_hint": shortURL.ContentTypeHint,
 	})
 
 	// Index by hash if present (for hash strategy)
@@ -47,6 +55,58 @@ func (r *RedisStore) Save(ctx context.Context, shortURL *shortener.ShortURL) err
 	return err
 }
 
+// SaveBatch claims each code with HSETNX before writing the rest of its
+// fields, so codes that are already taken are skipped rather than overwritten.
+func (r *RedisStore) SaveBatch(ctx context.Context, shortURLs []*shortener.ShortURL) ([]shortener.Code, error) {
+	claims := make([]*redis.BoolCmd, len(shortURLs))
+
+	claimPipe := r.client.Pipeline()
+	for i, shortURL := range shortURLs {
+		claims[i] = claimPipe.HSetNX(ctx, r.prefix+string(shortURL.Code), "code", string(shortURL.Code))
+	}
+
+	if _, err := claimPipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	inserted := make([]shortener.Code, 0, len(shortURLs))
+	pipe := r.client.Pipeline()
+
+	for i, shortURL := range shortURLs {
+		if !claims[i].Val() {
+			continue
+		}
+
+		inserted = append(inserted, shortURL.Code)
+
+		pipe.HSet(ctx, r.prefix+string(shortURL.Code), map[string]interface{}{
+			"original_url":      shortURL.OriginalURL,
+			"url_hash":          string(shortURL.URLHash),
+			"created_at":        shortURL.CreatedAt.UnixNano(),
+			"fallback_url":      shortURL.FallbackURL,
+			"flagged":           strconv.FormatBool(shortURL.Flagged),
+			"normalized_url":    shortURL.NormalizedURL,
+			"created_by":        shortURL.CreatedBy,
+			"strategy":          shortURL.Strategy,
+			"content_type_hint": shortURL.ContentTypeHint,
+		})
+
+		if shortURL.URLHash != "" {
+			pipe.HSet(ctx, r.hashKey, string(shortURL.URLHash), string(shortURL.Code))
+		}
+	}
+
+	if len(inserted) == 0 {
+		return inserted, nil
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	return inserted, nil
+}
+
 func (r *RedisStore) GetByCode(ctx context.Context, code shortener.Code) (*shortener.ShortURL, error) {
 	result, err := r.client.HGetAll(ctx, r.prefix+string(code)).Result()
 	if err != nil {
@@ -66,10 +126,16 @@ func (r *RedisStore) GetByCode(ctx context.Context, code shortener.Code) (*short
 	}
 
 	return &shortener.ShortURL{
-		Code:        shortener.Code(result["code"]),
-		OriginalURL: result["original_url"],
-		URLHash:     shortener.URLHash(result["url_hash"]),
-		CreatedAt:   createdAt,
+		Code:            shortener.Code(result["code"]),
+		OriginalURL:     result["original_url"],
+		URLHash:         shortener.URLHash(result["url_hash"]),
+		CreatedAt:       createdAt,
+		FallbackURL:     result["fallback_url"],
+		Flagged:         result["flagged"] == "true",
+		NormalizedURL:   result["normalized_url"],
+		CreatedBy:       result["created_by"],
+		Strategy:        result["strategy"],
+		ContentTypeHint: result["content_type_hint"],
 	}, nil
 }
 
@@ -85,3 +151,151 @@ func (r *RedisStore) GetByHash(ctx context.Context, hash shortener.URLHash) (*sh
 
 	return r.GetByCode(ctx, shortener.Code(code))
 }
+
+// GetWithStats returns the short URL stored under code. The Redis store does
+// not count accesses, so the count is always 0.
+func (r *RedisStore) GetWithStats(ctx context.Context, code shortener.Code) (*shortener.ShortURLStats, error) {
+	shortURL, err := r.GetByCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+
+	return &shortener.ShortURLStats{ShortURL: shortURL}, nil
+}
+
+// Exists reports which of codes are stored, checking every entity key in one pipeline.
+func (r *RedisStore) Exists(ctx context.Context, codes []shortener.Code) (map[shortener.Code]bool, error) {
+	pipe := r.client.Pipeline()
+	cmds := make([]*redis.IntCmd, len(codes))
+
+	for i, code := range codes {
+		cmds[i] = pipe.Exists(ctx, r.prefix+string(code))
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	exists := make(map[shortener.Code]bool, len(codes))
+	for i, code := range codes {
+		exists[code] = cmds[i].Val() > 0
+	}
+
+	return exists, nil
+}
+
+// MostRecent returns up to limit short URLs, newest first. Like Count it scans every
+// entity key, so it is best-effort and meant for small datasets.
+func (r *RedisStore) MostRecent(ctx context.Context, limit int) ([]*shortener.ShortURL, error) {
+	shortURLs, err := r.scanAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return mostRecent(shortURLs, limit), nil
+}
+
+// ListByCreator returns up to limit short URLs created by creator, newest first.
+// It scans every entity key like MostRecent.
+func (r *RedisStore) ListByCreator(ctx context.Context, creator string, limit int) ([]*shortener.ShortURL, error) {
+	if creator == "" {
+		return []*shortener.ShortURL{}, nil
+	}
+
+	shortURLs, err := r.scanAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	shortURLs = slices.DeleteFunc(shortURLs, func(shortURL *shortener.ShortURL) bool {
+		return shortURL.CreatedBy != creator
+	})
+
+	return mostRecent(shortURLs, limit), nil
+}
+
+// scanAll loads every stored short URL by scanning entity keys.
+func (r *RedisStore) scanAll(ctx context.Context) ([]*shortener.ShortURL, error) {
+	var shortURLs []*shortener.ShortURL
+
+	iter := r.client.Scan(ctx, 0, r.prefix+"*", 0).Iterator()
+	for iter.Next(ctx) {
+		shortURL, err := r.GetByCode(ctx, shortener.Code(strings.TrimPrefix(iter.Val(), r.prefix)))
+		if err != nil {
+			if errors.Is(err, shortener.ErrNotFound) {
+				continue
+			}
+
+			return nil, err
+		}
+
+		shortURLs = append(shortURLs, shortURL)
+	}
+
+	if err := iter.Err(); err != nil {
+		return nil, err
+	}
+
+	return shortURLs, nil
+}
+
+// UpdateTarget points code at newURL, moving its hash index entry and
+// replacing its normalized URL, if any.
+func (r *RedisStore) UpdateTarget(ctx context.Context, code shortener.Code, newURL, normalizedURL string) error {
+	current, err := r.GetByCode(ctx, code)
+	if err != nil {
+		return err
+	}
+
+	pipe := r.client.TxPipeline()
+	pipe.HSet(ctx, r.prefix+string(code), "original_url", newURL)
+
+	if current.URLHash != "" {
+		urlHash := shortener.HashURL(normalizedURL)
+
+		pipe.HDel(ctx, r.hashKey, string(current.URLHash))
+		pipe.HSet(ctx, r.hashKey, urlHash, string(code))
+		pipe.HSet(ctx, r.prefix+string(code), "url_hash", urlHash)
+	}
+
+	if current.NormalizedURL != "" {
+		pipe.HSet(ctx, r.prefix+string(code), "normalized_url", normalizedURL)
+	}
+
+	_, err = pipe.Exec(ctx)
+
+	return err
+}
+
+// Flag sets or clears the flagged mark of code.
+func (r *RedisStore) Flag(ctx context.Context, code shortener.Code, flagged bool) error {
+	key := r.prefix + string(code)
+
+	// HSET would create a partial entity for an unknown code
+	exists, err := r.client.Exists(ctx, key).Result()
+	if err != nil {
+		return err
+	}
+
+	if exists == 0 {
+		return shortener.ErrNotFound
+	}
+
+	return r.client.HSet(ctx, key, "flagged", strconv.FormatBool(flagged)).Err()
+}
+
+// Count returns a best-effort count of stored URLs by scanning keys with the entity prefix.
+func (r *RedisStore) Count(ctx context.Context) (int64, error) {
+	var count int64
+
+	iter := r.client.Scan(ctx, 0, r.prefix+"*", 0).Iterator()
+	for iter.Next(ctx) {
+		count++
+	}
+
+	if err := iter.Err(); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
@@ -2,11 +2,16 @@ package store
 
 import (
 	"context"
+	"errors"
 	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/redis/go-redis/v9"
+	"github.com/serroba/web-demo-go/internal/clock"
 	"github.com/serroba/web-demo-go/internal/shortener"
+	"go.uber.org/zap"
 )
 
 // RedisCacheRepository wraps a Repository with Redis caching for reads.
@@ -16,19 +21,158 @@ type RedisCacheRepository struct {
 	prefix  string
 	hashKey string
 	ttl     time.Duration
+
+	// strategyTTLs overrides ttl for short URLs created by a given strategy.
+	strategyTTLs map[string]time.Duration
+
+	// errorCooldown is how long cache population is skipped after a cache error;
+	// degradedUntil holds the end of the current cooldown in Unix nanoseconds.
+	errorCooldown time.Duration
+	degradedUntil atomic.Int64
+	clock         clock.Clock
+
+	negativeTTL time.Duration
+
+	logger   *zap.Logger
+	recorder CacheRecorder
+
+	// writeQueue holds short URLs waiting to be cached in write-behind mode;
+	// nil in write-through mode. queueMu guards sends against Shutdown, after
+	// which writerStopped is set and short URLs are cached synchronously.
+	writeQueue    chan *shortener.ShortURL
+	queueMu       sync.RWMutex
+	writerStopped bool
+	stopWriter    chan struct{}
+	writerDone    chan struct{}
+	stopWriting   sync.Once
+}
+
+// CacheRecorder counts cache writes that failed and were rolled back.
+type CacheRecorder interface {
+	RecordCacheWriteError()
+}
+
+// NopCacheRecorder is a CacheRecorder that discards everything.
+type NopCacheRecorder struct{}
+
+// RecordCacheWriteError implements CacheRecorder.
+func (NopCacheRecorder) RecordCacheWriteError() {}
+
+// CacheWriteMode selects when Save and SaveBatch populate the cache.
+type CacheWriteMode string
+
+const (
+	// CacheWriteThrough caches new short URLs before Save returns.
+	CacheWriteThrough CacheWriteMode = "through"
+	// CacheWriteBehind queues new short URLs and caches them in the background.
+	CacheWriteBehind CacheWriteMode = "behind"
+)
+
+// IsValidCacheWriteMode reports whether mode is a supported cache write mode.
+func IsValidCacheWriteMode(mode CacheWriteMode) bool {
+	return mode == CacheWriteThrough || mode == CacheWriteBehind
+}
+
+// missingField marks a cache entry that records a code the store does not know.
+const missingField = "missing"
+
+// errCachedMiss is returned by getFromCache for a negative cache entry.
+var errCachedMiss = errors.New("cached miss")
+
+// RedisCacheOption configures a RedisCacheRepository.
+type RedisCacheOption func(*RedisCacheRepository)
+
+// WithCacheErrorCooldown skips cache population for d after a cache read or
+// write fails, so reads served from the store while Redis is struggling do not
+// also wait on writes that are likely to fail. Zero disables the cooldown.
+func WithCacheErrorCooldown(d time.Duration) RedisCacheOption {
+	return func(r *RedisCacheRepository) {
+		r.errorCooldown = d
+	}
+}
+
+// WithCacheClock sets the clock used to time the error cooldown.
+func WithCacheClock(c clock.Clock) RedisCacheOption {
+	return func(r *RedisCacheRepository) {
+		r.clock = c
+	}
+}
+
+// WithRedisNegativeTTL caches codes the store does not know for ttl, so
+// scanners requesting the same missing code repeatedly do not reach the store.
+// Saving the code replaces the entry. Zero disables negative caching.
+func WithRedisNegativeTTL(ttl time.Duration) RedisCacheOption {
+	return func(r *RedisCacheRepository) {
+		r.negativeTTL = ttl
+	}
+}
+
+// WithStrategyTTL caches short URLs created by strategy (see ShortURL.Strategy)
+// for ttl instead of the repository TTL. Hash-strategy codes always map to the
+// same URL, so they can safely be kept longer than token codes.
+func WithStrategyTTL(strategy string, ttl time.Duration) RedisCacheOption {
+	return func(r *RedisCacheRepository) {
+		if r.strategyTTLs == nil {
+			r.strategyTTLs = make(map[string]time.Duration)
+		}
+
+		r.strategyTTLs[strategy] = ttl
+	}
+}
+
+// WithCacheLogger sets the logger used to report failed cache writes.
+func WithCacheLogger(logger *zap.Logger) RedisCacheOption {
+	return func(r *RedisCacheRepository) {
+		r.logger = logger
+	}
+}
+
+// WithCacheRecorder sets the recorder that counts failed cache writes.
+func WithCacheRecorder(recorder CacheRecorder) RedisCacheOption {
+	return func(r *RedisCacheRepository) {
+		r.recorder = recorder
+	}
+}
+
+// WithWriteBehind makes Save and SaveBatch return once the underlying store has
+// saved, and caches the new short URLs in the background from a queue of up to
+// queueSize entries. Negative entries for the saved codes are still dropped
+// before Save returns, so a new code never resolves as missing. When the queue
+// is full, or after Shutdown, short URLs are cached synchronously instead.
+// Shutdown drains the queue.
+func WithWriteBehind(queueSize int) RedisCacheOption {
+	return func(r *RedisCacheRepository) {
+		r.writeQueue = make(chan *shortener.ShortURL, max(queueSize, 1))
+	}
 }
 
 // NewRedisCacheRepository creates a new Redis-cached repository decorator.
 func NewRedisCacheRepository(
-	store shortener.Repository, client *redis.Client, ttl time.Duration,
+	store shortener.Repository, client *redis.Client, ttl time.Duration, opts ...RedisCacheOption,
 ) *RedisCacheRepository {
-	return &RedisCacheRepository{
-		store:   store,
-		client:  client,
-		prefix:  "url:",
-		hashKey: "url_hashes",
-		ttl:     ttl,
+	r := &RedisCacheRepository{
+		store:    store,
+		client:   client,
+		prefix:   "url:",
+		hashKey:  "url_hashes",
+		ttl:      ttl,
+		clock:    clock.Real{},
+		logger:   zap.NewNop(),
+		recorder: NopCacheRecorder{},
+	}
+
+	for _, opt := range opts {
+		opt(r)
 	}
+
+	if r.writeQueue != nil {
+		r.stopWriter = make(chan struct{})
+		r.writerDone = make(chan struct{})
+
+		go r.writeBehind()
+	}
+
+	return r
 }
 
 // Save stores a short URL in the underlying store and updates the cache.
@@ -37,22 +181,118 @@ func (r *RedisCacheRepository) Save(ctx context.Context, shortURL *shortener.Sho
 		return err
 	}
 
-	// Write-through: update cache after successful save
-	r.cacheURL(ctx, shortURL)
+	r.cacheSaved(ctx, []*shortener.ShortURL{shortURL})
 
 	return nil
 }
 
+// SaveBatch saves the short URLs to the underlying store, then caches the
+// ones it inserted.
+func (r *RedisCacheRepository) SaveBatch(
+	ctx context.Context, shortURLs []*shortener.ShortURL,
+) ([]shortener.Code, error) {
+	inserted, err := r.store.SaveBatch(ctx, shortURLs)
+	if err != nil {
+		return nil, err
+	}
+
+	r.cacheSaved(ctx, insertedURLs(shortURLs, inserted))
+
+	return inserted, nil
+}
+
+// cacheSaved caches newly saved short URLs now in write-through mode. In
+// write-behind mode it drops their negative entries now and queues the cache
+// writes for the background writer.
+func (r *RedisCacheRepository) cacheSaved(ctx context.Context, shortURLs []*shortener.ShortURL) {
+	if r.writeQueue == nil {
+		for _, shortURL := range shortURLs {
+			r.cacheURL(ctx, shortURL)
+		}
+
+		return
+	}
+
+	r.dropMisses(ctx, shortURLs)
+
+	r.queueMu.RLock()
+	defer r.queueMu.RUnlock()
+
+	for _, shortURL := range shortURLs {
+		if r.writerStopped {
+			r.cacheURL(ctx, shortURL)
+
+			continue
+		}
+
+		select {
+		case r.writeQueue <- shortURL:
+		default:
+			r.cacheURL(ctx, shortURL)
+		}
+	}
+}
+
+// dropMisses deletes any negative entries for shortURLs in one round trip.
+func (r *RedisCacheRepository) dropMisses(ctx context.Context, shortURLs []*shortener.ShortURL) {
+	if r.negativeTTL <= 0 || len(shortURLs) == 0 {
+		return
+	}
+
+	keys := make([]string, 0, len(shortURLs))
+	for _, shortURL := range shortURLs {
+		keys = append(keys, r.prefix+string(shortURL.Code))
+	}
+
+	if err := r.client.Del(ctx, keys...).Err(); err != nil {
+		r.markDegraded()
+		r.logger.Warn("failed to drop negative cache entries", zap.Int("codes", len(keys)), zap.Error(err))
+	}
+}
+
+// writeBehind caches queued short URLs until Shutdown, then drains the queue.
+func (r *RedisCacheRepository) writeBehind() {
+	defer close(r.writerDone)
+
+	// Requests may be cancelled long before their entry is written
+	ctx := context.Background()
+
+	for {
+		select {
+		case shortURL := <-r.writeQueue:
+			r.cacheURL(ctx, shortURL)
+		case <-r.stopWriter:
+			for {
+				select {
+				case shortURL := <-r.writeQueue:
+					r.cacheURL(ctx, shortURL)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
 // GetByCode retrieves a short URL by its code, checking cache first.
 func (r *RedisCacheRepository) GetByCode(ctx context.Context, code shortener.Code) (*shortener.ShortURL, error) {
 	// Check cache first
-	if url, err := r.getFromCache(ctx, code); err == nil {
+	url, err := r.getFromCache(ctx, code)
+	if err == nil {
 		return url, nil
 	}
 
+	if errors.Is(err, errCachedMiss) {
+		return nil, shortener.ErrNotFound
+	}
+
 	// Cache miss - fetch from store
-	url, err := r.store.GetByCode(ctx, code)
+	url, err = r.store.GetByCode(ctx, code)
 	if err != nil {
+		if errors.Is(err, shortener.ErrNotFound) {
+			r.cacheMiss(ctx, code)
+		}
+
 		return nil, err
 	}
 
@@ -85,9 +325,89 @@ func (r *RedisCacheRepository) GetByHash(ctx context.Context, hash shortener.URL
 	return url, nil
 }
 
+// GetWithStats returns a short URL with its access count from the underlying
+// store. It is not cached since the count changes with every flush.
+func (r *RedisCacheRepository) GetWithStats(
+	ctx context.Context, code shortener.Code,
+) (*shortener.ShortURLStats, error) {
+	return r.store.GetWithStats(ctx, code)
+}
+
+// Exists reports which of codes are taken using the underlying store.
+func (r *RedisCacheRepository) Exists(ctx context.Context, codes []shortener.Code) (map[shortener.Code]bool, error) {
+	return r.store.Exists(ctx, codes)
+}
+
+// Count returns the number of stored URLs from the underlying store.
+func (r *RedisCacheRepository) Count(ctx context.Context) (int64, error) {
+	return r.store.Count(ctx)
+}
+
+// MostRecent returns the newest short URLs from the underlying store.
+func (r *RedisCacheRepository) MostRecent(ctx context.Context, limit int) ([]*shortener.ShortURL, error) {
+	return r.store.MostRecent(ctx, limit)
+}
+
+// ListByCreator returns a creator's short URLs from the underlying store.
+func (r *RedisCacheRepository) ListByCreator(
+	ctx context.Context,
+	creator string,
+	limit int,
+) ([]*shortener.ShortURL, error) {
+	return r.store.ListByCreator(ctx, creator, limit)
+}
+
+// UpdateTarget repoints a short URL in the underlying store, then drops its cache
+// entry and the hash index entry of the old target.
+func (r *RedisCacheRepository) UpdateTarget(
+	ctx context.Context,
+	code shortener.Code,
+	newURL, normalizedURL string,
+) error {
+	current, err := r.store.GetByCode(ctx, code)
+	if err != nil {
+		return err
+	}
+
+	if err = r.store.UpdateTarget(ctx, code, newURL, normalizedURL); err != nil {
+		return err
+	}
+
+	pipe := r.client.Pipeline()
+	pipe.Del(ctx, r.prefix+string(code))
+
+	if current.URLHash != "" {
+		pipe.HDel(ctx, r.hashKey, string(current.URLHash))
+	}
+
+	// The update itself succeeded; an entry that could not be dropped expires with the TTL
+	if _, err = pipe.Exec(ctx); err != nil {
+		r.markDegraded()
+	}
+
+	return nil
+}
+
+// Flag flags a short URL in the underlying store and drops the cached entry,
+// so redirects see the change before the entry would expire.
+func (r *RedisCacheRepository) Flag(ctx context.Context, code shortener.Code, flagged bool) error {
+	if err := r.store.Flag(ctx, code, flagged); err != nil {
+		return err
+	}
+
+	// The flag itself succeeded; an entry that could not be dropped expires with the TTL
+	if err := r.client.Del(ctx, r.prefix+string(code)).Err(); err != nil {
+		r.markDegraded()
+	}
+
+	return nil
+}
+
 func (r *RedisCacheRepository) getFromCache(ctx context.Context, code shortener.Code) (*shortener.ShortURL, error) {
 	result, err := r.client.HGetAll(ctx, r.prefix+string(code)).Result()
 	if err != nil {
+		r.markDegraded()
+
 		return nil, err
 	}
 
@@ -95,6 +415,10 @@ func (r *RedisCacheRepository) getFromCache(ctx context.Context, code shortener.
 		return nil, shortener.ErrNotFound
 	}
 
+	if result[missingField] != "" {
+		return nil, errCachedMiss
+	}
+
 	var createdAt time.Time
 
 	if ts, ok := result["created_at"]; ok {
@@ -104,26 +428,81 @@ func (r *RedisCacheRepository) getFromCache(ctx context.Context, code shortener.
 	}
 
 	return &shortener.ShortURL{
-		Code:        shortener.Code(result["code"]),
-		OriginalURL: result["original_url"],
-		URLHash:     shortener.URLHash(result["url_hash"]),
-		CreatedAt:   createdAt,
+		Code:            shortener.Code(result["code"]),
+		OriginalURL:     result["original_url"],
+		URLHash:         shortener.URLHash(result["url_hash"]),
+		CreatedAt:       createdAt,
+		FallbackURL:     result["fallback_url"],
+		Flagged:         result["flagged"] == "true",
+		NormalizedURL:   result["normalized_url"],
+		CreatedBy:       result["created_by"],
+		Strategy:        result["strategy"],
+		ContentTypeHint: result["content_type_hint"],
 	}, nil
 }
 
-func (r *RedisCacheRepository) cacheURL(ctx context.Context, url *shortener.ShortURL) {
+// markDegraded starts a population cooldown after a cache error.
+func (r *RedisCacheRepository) markDegraded() {
+	if r.errorCooldown > 0 {
+		r.degradedUntil.Store(r.clock.Now().Add(r.errorCooldown).UnixNano())
+	}
+}
+
+// degraded reports whether a cache error happened within the cooldown.
+func (r *RedisCacheRepository) degraded() bool {
+	return r.clock.Now().UnixNano() < r.degradedUntil.Load()
+}
+
+// cacheMiss records a negative entry for a code the store does not know.
+func (r *RedisCacheRepository) cacheMiss(ctx context.Context, code shortener.Code) {
+	if r.negativeTTL <= 0 || r.degraded() {
+		return
+	}
+
+	key := r.prefix + string(code)
 	pipe := r.client.Pipeline()
+	pipe.HSet(ctx, key, missingField, "1")
+	pipe.Expire(ctx, key, r.negativeTTL)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		r.markDegraded()
+	}
+}
+
+func (r *RedisCacheRepository) cacheURL(ctx context.Context, url *shortener.ShortURL) {
 	key := r.prefix + string(url.Code)
 
+	if r.degraded() {
+		// A negative entry would hide the new code until it expires, so try to drop it anyway
+		if r.negativeTTL > 0 {
+			_ = r.client.Del(ctx, key).Err()
+		}
+
+		return
+	}
+
+	pipe := r.client.Pipeline()
+
+	// Replace a negative entry along with its TTL
+	if r.negativeTTL > 0 {
+		pipe.Del(ctx, key)
+	}
+
 	pipe.HSet(ctx, key, map[string]interface{}{
-		"code":         string(url.Code),
-		"original_url": url.OriginalURL,
-		"url_hash":     string(url.URLHash),
-		"created_at":   url.CreatedAt.UnixNano(),
+		"code":              string(url.Code),
+		"original_url":      url.OriginalURL,
+		"url_hash":          string(url.URLHash),
+		"created_at":        url.CreatedAt.UnixNano(),
+		"fallback_url":      url.FallbackURL,
+		"flagged":           strconv.FormatBool(url.Flagged),
+		"normalized_url":    url.NormalizedURL,
+		"created_by":        url.CreatedBy,
+		"strategy":          url.Strategy,
+		"content_type_hint": url.ContentTypeHint,
 	})
 
-	if r.ttl > 0 {
-		pipe.Expire(ctx, key, r.ttl)
+	if ttl := r.ttlFor(url); ttl > 0 {
+		pipe.Expire(ctx, key, ttl)
 	}
 
 	// Index by hash if present
@@ -131,11 +510,75 @@ func (r *RedisCacheRepository) cacheURL(ctx context.Context, url *shortener.Shor
 		pipe.HSet(ctx, r.hashKey, string(url.URLHash), string(url.Code))
 	}
 
-	_, _ = pipe.Exec(ctx)
+	cmds, err := pipe.Exec(ctx)
+	if err == nil {
+		return
+	}
+
+	r.markDegraded()
+	r.recorder.RecordCacheWriteError()
+
+	failed := 0
+
+	for _, cmd := range cmds {
+		if cmd.Err() != nil {
+			failed++
+		}
+	}
+
+	r.logger.Warn("failed to cache short url, discarding partial entry",
+		zap.String("code", string(url.Code)),
+		zap.Int("failed_commands", failed),
+		zap.Int("commands", len(cmds)),
+		zap.Error(err),
+	)
+
+	r.discard(ctx, url)
 }
 
-// Shutdown is a no-op for RedisCacheRepository (client managed externally).
+// ttlFor returns the cache TTL for url, preferring its strategy's TTL.
+func (r *RedisCacheRepository) ttlFor(url *shortener.ShortURL) time.Duration {
+	if ttl, ok := r.strategyTTLs[url.Strategy]; ok {
+		return ttl
+	}
+
+	return r.ttl
+}
+
+// discard removes whatever part of a failed cacheURL reached Redis, so a code is
+// never cached without its hash index entry or the other way round. Commands
+// that failed may still have been applied, so both parts are always removed.
+// Entries that survive a failed discard expire with the cache TTL.
+func (r *RedisCacheRepository) discard(ctx context.Context, url *shortener.ShortURL) {
+	if err := r.client.Del(ctx, r.prefix+string(url.Code)).Err(); err != nil {
+		r.logger.Warn("failed to discard partial cache entry", zap.String("code", string(url.Code)), zap.Error(err))
+	}
+
+	if url.URLHash == "" {
+		return
+	}
+
+	if err := r.client.HDel(ctx, r.hashKey, string(url.URLHash)).Err(); err != nil {
+		r.logger.Warn("failed to discard partial hash index entry", zap.String("code", string(url.Code)), zap.Error(err))
+	}
+}
+
+// Shutdown writes the short URLs still queued in write-behind mode. The client
+// is managed externally and stays open.
 func (r *RedisCacheRepository) Shutdown() error {
+	if r.writeQueue == nil {
+		return nil
+	}
+
+	r.stopWriting.Do(func() {
+		r.queueMu.Lock()
+		defer r.queueMu.Unlock()
+
+		r.writerStopped = true
+		close(r.stopWriter)
+	})
+	<-r.writerDone
+
 	return nil
 }
 
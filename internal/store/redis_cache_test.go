@@ -0,0 +1,473 @@
+package store_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/serroba/web-demo-go/internal/clock"
+	"github.com/serroba/web-demo-go/internal/shortener"
+	"github.com/serroba/web-demo-go/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// failingWrites answers cache reads with a miss and fails every pipeline, the
+// path used to populate the cache, without talking to a Redis server.
+type failingWrites struct {
+	pipelines int
+}
+
+func (h *failingWrites) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (h *failingWrites) ProcessHook(_ redis.ProcessHook) redis.ProcessHook {
+	return func(_ context.Context, cmd redis.Cmder) error {
+		if c, ok := cmd.(*redis.MapStringStringCmd); ok {
+			c.SetVal(map[string]string{})
+		}
+
+		return nil
+	}
+}
+
+func (h *failingWrites) ProcessPipelineHook(_ redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(_ context.Context, _ []redis.Cmder) error {
+		h.pipelines++
+
+		return errors.New("cache write failed")
+	}
+}
+
+func newFailingWritesClient(t *testing.T) (*redis.Client, *failingWrites) {
+	t.Helper()
+
+	client := redis.NewClient(&redis.Options{Addr: "localhost:0"})
+	t.Cleanup(func() { _ = client.Close() })
+
+	hook := &failingWrites{}
+	client.AddHook(hook)
+
+	return client, hook
+}
+
+func TestRedisCacheRepository_ErrorCooldown(t *testing.T) {
+	url := &shortener.ShortURL{Code: "abc123", OriginalURL: "https://example.com"}
+	backing := &mockStore{
+		getByCodeFunc: func(_ context.Context, _ shortener.Code) (*shortener.ShortURL, error) {
+			return url, nil
+		},
+	}
+
+	t.Run("skips population during the cooldown", func(t *testing.T) {
+		client, hook := newFailingWritesClient(t)
+		repo := store.NewRedisCacheRepository(backing, client, time.Hour, store.WithCacheErrorCooldown(time.Hour))
+
+		for range 3 {
+			got, err := repo.GetByCode(context.Background(), url.Code)
+			require.NoError(t, err)
+			assert.Equal(t, url, got)
+		}
+
+		assert.Equal(t, 1, hook.pipelines, "only the first failed write should reach the cache")
+	})
+
+	t.Run("populates again once the cooldown expires", func(t *testing.T) {
+		client, hook := newFailingWritesClient(t)
+		clk := clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+		repo := store.NewRedisCacheRepository(backing, client, time.Hour,
+			store.WithCacheErrorCooldown(time.Minute), store.WithCacheClock(clk))
+
+		_, err := repo.GetByCode(context.Background(), url.Code)
+		require.NoError(t, err)
+
+		clk.Advance(59 * time.Second)
+
+		_, err = repo.GetByCode(context.Background(), url.Code)
+		require.NoError(t, err)
+		assert.Equal(t, 1, hook.pipelines, "the cooldown has not expired yet")
+
+		clk.Advance(time.Second)
+
+		_, err = repo.GetByCode(context.Background(), url.Code)
+		require.NoError(t, err)
+		assert.Equal(t, 2, hook.pipelines)
+	})
+
+	t.Run("always populates without a cooldown", func(t *testing.T) {
+		client, hook := newFailingWritesClient(t)
+		repo := store.NewRedisCacheRepository(backing, client, time.Hour)
+
+		for range 3 {
+			_, err := repo.GetByCode(context.Background(), url.Code)
+			require.NoError(t, err)
+		}
+
+		assert.Equal(t, 3, hook.pipelines)
+	})
+}
+
+// cachedMiss answers every cache read with a negative entry.
+type cachedMiss struct{}
+
+func (cachedMiss) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (cachedMiss) ProcessHook(_ redis.ProcessHook) redis.ProcessHook {
+	return func(_ context.Context, cmd redis.Cmder) error {
+		if c, ok := cmd.(*redis.MapStringStringCmd); ok {
+			c.SetVal(map[string]string{"missing": "1"})
+		}
+
+		return nil
+	}
+}
+
+func (cachedMiss) ProcessPipelineHook(_ redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(_ context.Context, _ []redis.Cmder) error {
+		return nil
+	}
+}
+
+func TestRedisCacheRepository_NegativeEntry(t *testing.T) {
+	client := redis.NewClient(&redis.Options{Addr: "localhost:0"})
+	t.Cleanup(func() { _ = client.Close() })
+	client.AddHook(cachedMiss{})
+
+	backing := &mockStore{}
+	repo := store.NewRedisCacheRepository(backing, client, time.Hour, store.WithRedisNegativeTTL(time.Minute))
+
+	_, err := repo.GetByCode(context.Background(), "missing")
+
+	require.ErrorIs(t, err, shortener.ErrNotFound)
+	assert.Equal(t, 0, backing.callCount, "a negative entry should not reach the store")
+}
+
+// recordingWrites records the entity keys written by cache pipelines, the
+// TTLs set on them and the keys deleted outside pipelines. When gate is set,
+// pipelines wait for it to be closed.
+type recordingWrites struct {
+	mu      sync.Mutex
+	keys    []string
+	deleted []string
+	ttls    map[string]time.Duration
+	gate    chan struct{}
+}
+
+func (h *recordingWrites) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (h *recordingWrites) ProcessHook(_ redis.ProcessHook) redis.ProcessHook {
+	return func(_ context.Context, cmd redis.Cmder) error {
+		if cmd.Name() != "del" {
+			return nil
+		}
+
+		h.mu.Lock()
+		defer h.mu.Unlock()
+
+		for _, key := range cmd.Args()[1:] {
+			h.deleted = append(h.deleted, key.(string))
+		}
+
+		return nil
+	}
+}
+
+func (h *recordingWrites) ProcessPipelineHook(_ redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(_ context.Context, cmds []redis.Cmder) error {
+		if h.gate != nil {
+			<-h.gate
+		}
+
+		h.mu.Lock()
+		defer h.mu.Unlock()
+
+		for _, cmd := range cmds {
+			args := cmd.Args()
+
+			switch cmd.Name() {
+			case "hset":
+				if strings.HasPrefix(args[1].(string), "url:") {
+					h.keys = append(h.keys, args[1].(string))
+				}
+			case "expire":
+				if h.ttls == nil {
+					h.ttls = make(map[string]time.Duration)
+				}
+
+				h.ttls[args[1].(string)] = time.Duration(args[2].(int64)) * time.Second
+			}
+		}
+
+		return nil
+	}
+}
+
+func (h *recordingWrites) written() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return slices.Clone(h.keys)
+}
+
+func (h *recordingWrites) deletedKeys() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return slices.Clone(h.deleted)
+}
+
+func (h *recordingWrites) ttl(key string) time.Duration {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.ttls[key]
+}
+
+func newRecordingClient(t *testing.T, hook *recordingWrites) *redis.Client {
+	t.Helper()
+
+	client := redis.NewClient(&redis.Options{Addr: "localhost:0"})
+	t.Cleanup(func() { _ = client.Close() })
+	client.AddHook(hook)
+
+	return client
+}
+
+func TestRedisCacheRepository_WriteMode(t *testing.T) {
+	url := &shortener.ShortURL{Code: "abc123", OriginalURL: "https://example.com"}
+
+	t.Run("write-through caches before save returns", func(t *testing.T) {
+		hook := &recordingWrites{}
+		repo := store.NewRedisCacheRepository(&mockStore{}, newRecordingClient(t, hook), time.Hour)
+
+		require.NoError(t, repo.Save(context.Background(), url))
+
+		assert.Equal(t, []string{"url:abc123"}, hook.written())
+	})
+
+	t.Run("write-behind returns before the cache is written and populates it eventually", func(t *testing.T) {
+		hook := &recordingWrites{gate: make(chan struct{})}
+		repo := store.NewRedisCacheRepository(&mockStore{}, newRecordingClient(t, hook), time.Hour,
+			store.WithWriteBehind(10))
+		t.Cleanup(func() { _ = repo.Shutdown() })
+
+		require.NoError(t, repo.Save(context.Background(), url))
+		assert.Empty(t, hook.written(), "the cache write is still blocked")
+
+		close(hook.gate)
+
+		assert.Eventually(t, func() bool {
+			return slices.Equal([]string{"url:abc123"}, hook.written())
+		}, time.Second, 5*time.Millisecond)
+	})
+
+	t.Run("write-behind drops negative entries before save returns", func(t *testing.T) {
+		hook := &recordingWrites{gate: make(chan struct{})}
+		repo := store.NewRedisCacheRepository(&mockStore{}, newRecordingClient(t, hook), time.Hour,
+			store.WithWriteBehind(10), store.WithRedisNegativeTTL(time.Minute))
+		t.Cleanup(func() { _ = repo.Shutdown() })
+		t.Cleanup(func() { close(hook.gate) })
+
+		require.NoError(t, repo.Save(context.Background(), url))
+		_, err := repo.SaveBatch(context.Background(), []*shortener.ShortURL{
+			{Code: "a1", OriginalURL: "https://example.com/a"},
+			{Code: "b2", OriginalURL: "https://example.com/b"},
+		})
+		require.NoError(t, err)
+
+		assert.Empty(t, hook.written(), "the cache writes are still blocked")
+		assert.Equal(t, []string{"url:abc123", "url:a1", "url:b2"}, hook.deletedKeys())
+	})
+
+	t.Run("saves after shutdown are cached synchronously", func(t *testing.T) {
+		hook := &recordingWrites{}
+		repo := store.NewRedisCacheRepository(&mockStore{}, newRecordingClient(t, hook), time.Hour,
+			store.WithWriteBehind(10))
+		require.NoError(t, repo.Shutdown())
+
+		require.NoError(t, repo.Save(context.Background(), url))
+
+		assert.Equal(t, []string{"url:abc123"}, hook.written())
+	})
+
+	t.Run("shutdown drains the write-behind queue", func(t *testing.T) {
+		hook := &recordingWrites{}
+		repo := store.NewRedisCacheRepository(&mockStore{}, newRecordingClient(t, hook), time.Hour,
+			store.WithWriteBehind(10))
+
+		_, err := repo.SaveBatch(context.Background(), []*shortener.ShortURL{
+			{Code: "a1", OriginalURL: "https://example.com/a"},
+			{Code: "b2", OriginalURL: "https://example.com/b"},
+		})
+		require.NoError(t, err)
+		require.NoError(t, repo.Shutdown())
+
+		assert.ElementsMatch(t, []string{"url:a1", "url:b2"}, hook.written())
+	})
+
+	t.Run("store failures are not queued", func(t *testing.T) {
+		hook := &recordingWrites{}
+		backing := &mockStore{saveFunc: func(_ context.Context, _ *shortener.ShortURL) error {
+			return errors.New("db down")
+		}}
+		repo := store.NewRedisCacheRepository(backing, newRecordingClient(t, hook), time.Hour,
+			store.WithWriteBehind(10))
+
+		require.Error(t, repo.Save(context.Background(), url))
+		require.NoError(t, repo.Shutdown())
+
+		assert.Empty(t, hook.written())
+	})
+}
+
+func TestRedisCacheRepository_StrategyTTL(t *testing.T) {
+	hook := &recordingWrites{}
+	repo := store.NewRedisCacheRepository(&mockStore{}, newRecordingClient(t, hook), time.Hour,
+		store.WithStrategyTTL(shortener.StrategyHash, 24*time.Hour),
+		store.WithStrategyTTL(shortener.StrategyToken, 10*time.Minute))
+
+	_, err := repo.SaveBatch(context.Background(), []*shortener.ShortURL{
+		{Code: "hash01", OriginalURL: "https://example.com/a", Strategy: shortener.StrategyHash},
+		{Code: "tok001", OriginalURL: "https://example.com/b", Strategy: shortener.StrategyToken},
+		{Code: "alias1", OriginalURL: "https://example.com/c", Strategy: shortener.StrategyAlias},
+		{Code: "legacy", OriginalURL: "https://example.com/d"},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 24*time.Hour, hook.ttl("url:hash01"))
+	assert.Equal(t, 10*time.Minute, hook.ttl("url:tok001"))
+	assert.Equal(t, time.Hour, hook.ttl("url:alias1"), "strategies without an override use the repository TTL")
+	assert.Equal(t, time.Hour, hook.ttl("url:legacy"), "records without a strategy use the repository TTL")
+}
+
+func TestIsValidCacheWriteMode(t *testing.T) {
+	assert.True(t, store.IsValidCacheWriteMode(store.CacheWriteThrough))
+	assert.True(t, store.IsValidCacheWriteMode(store.CacheWriteBehind))
+	assert.False(t, store.IsValidCacheWriteMode("around"))
+}
+
+// partialWrites keeps hashes in memory and fails the url_hashes HSet of every
+// pipeline, so a cache write lands the entity but not its hash index entry.
+type partialWrites struct {
+	mu     sync.Mutex
+	hashes map[string]map[string]string
+}
+
+func (h *partialWrites) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (h *partialWrites) ProcessHook(_ redis.ProcessHook) redis.ProcessHook {
+	return func(_ context.Context, cmd redis.Cmder) error {
+		h.apply(cmd)
+
+		return nil
+	}
+}
+
+func (h *partialWrites) ProcessPipelineHook(_ redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(_ context.Context, cmds []redis.Cmder) error {
+		var failed error
+
+		for _, cmd := range cmds {
+			if cmd.Name() == "hset" && cmd.Args()[1] == "url_hashes" {
+				failed = errors.New("hash index write failed")
+				cmd.SetErr(failed)
+
+				continue
+			}
+
+			h.apply(cmd)
+		}
+
+		return failed
+	}
+}
+
+func (h *partialWrites) apply(cmd redis.Cmder) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	args := cmd.Args()
+
+	switch cmd.Name() {
+	case "hset":
+		key := args[1].(string)
+		if h.hashes[key] == nil {
+			h.hashes[key] = map[string]string{}
+		}
+
+		for i := 2; i+1 < len(args); i += 2 {
+			h.hashes[key][fmt.Sprint(args[i])] = fmt.Sprint(args[i+1])
+		}
+	case "hdel":
+		for _, field := range args[2:] {
+			delete(h.hashes[args[1].(string)], field.(string))
+		}
+	case "del":
+		for _, key := range args[1:] {
+			delete(h.hashes, key.(string))
+		}
+	case "hgetall":
+		if c, ok := cmd.(*redis.MapStringStringCmd); ok {
+			c.SetVal(map[string]string{})
+		}
+	case "hget":
+		cmd.SetErr(redis.Nil)
+	}
+}
+
+func (h *partialWrites) stored(key string) map[string]string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.hashes[key]
+}
+
+type countingCacheRecorder struct {
+	errors int
+}
+
+func (r *countingCacheRecorder) RecordCacheWriteError() {
+	r.errors++
+}
+
+func TestRedisCacheRepository_PartialWrite(t *testing.T) {
+	client := redis.NewClient(&redis.Options{Addr: "localhost:0"})
+	t.Cleanup(func() { _ = client.Close() })
+
+	hook := &partialWrites{hashes: map[string]map[string]string{}}
+	client.AddHook(hook)
+
+	core, logs := observer.New(zap.WarnLevel)
+	recorder := &countingCacheRecorder{}
+	repo := store.NewRedisCacheRepository(store.NewMemoryStore(), client, time.Hour,
+		store.WithCacheLogger(zap.New(core)),
+		store.WithCacheRecorder(recorder),
+	)
+
+	url := &shortener.ShortURL{Code: "abc123", OriginalURL: "https://example.com", URLHash: "hash123"}
+	require.NoError(t, repo.Save(context.Background(), url))
+
+	assert.Empty(t, hook.stored("url:abc123"), "code must not stay cached without its hash index entry")
+	assert.Empty(t, hook.stored("url_hashes"))
+	assert.Equal(t, 1, recorder.errors)
+	assert.Equal(t, 1, logs.FilterMessageSnippet("failed to cache short url").Len())
+
+	got, err := repo.GetByHash(context.Background(), "hash123")
+	require.NoError(t, err)
+	assert.Equal(t, url.Code, got.Code)
+}
@@ -6,6 +6,7 @@ import (
 	"context"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/redis/go-redis/v9"
 	"github.com/serroba/web-demo-go/internal/shortener"
@@ -52,6 +53,94 @@ func TestRedisStoreIntegration(t *testing.T) {
 		client.Del(ctx, "url:"+string(shortURL.Code))
 	})
 
+	t.Run("round trips the normalized url", func(t *testing.T) {
+		shortURL := &shortener.ShortURL{
+			Code:          "testnormal1",
+			OriginalURL:   "HTTPS://Example.COM/Path/",
+			NormalizedURL: "https://example.com/Path",
+		}
+
+		require.NoError(t, s.Save(ctx, shortURL))
+
+		got, err := s.GetByCode(ctx, shortURL.Code)
+		require.NoError(t, err)
+		assert.Equal(t, shortURL.NormalizedURL, got.NormalizedURL)
+
+		// Cleanup
+		client.Del(ctx, "url:"+string(shortURL.Code))
+	})
+
+	t.Run("flag sets the mark without creating missing codes", func(t *testing.T) {
+		shortURL := &shortener.ShortURL{
+			Code:        "testflag1",
+			OriginalURL: "https://example.com",
+		}
+
+		require.NoError(t, s.Save(ctx, shortURL))
+		require.NoError(t, s.Flag(ctx, shortURL.Code, true))
+
+		got, err := s.GetByCode(ctx, shortURL.Code)
+		require.NoError(t, err)
+		assert.True(t, got.Flagged)
+
+		require.ErrorIs(t, s.Flag(ctx, "testflagmissing", true), shortener.ErrNotFound)
+
+		exists, err := client.Exists(ctx, "url:testflagmissing").Result()
+		require.NoError(t, err)
+		assert.Zero(t, exists)
+
+		// Cleanup
+		client.Del(ctx, "url:"+string(shortURL.Code))
+	})
+
+	t.Run("creates as a key and lists that key's urls", func(t *testing.T) {
+		codes := []string{"testmine1", "testmine2", "testanon1"}
+		next := 0
+		generate := func() string {
+			code := codes[next]
+			next++
+
+			return code
+		}
+
+		strategy := shortener.NewTokenStrategy(s, generate)
+		keyCtx := shortener.WithCreator(ctx, "key:rediscreator")
+
+		_, err := strategy.Shorten(keyCtx, "https://example.com/first")
+		require.NoError(t, err)
+
+		_, err = strategy.Shorten(keyCtx, "https://example.com/second")
+		require.NoError(t, err)
+
+		anonymous, err := strategy.Shorten(ctx, "https://example.com/anonymous")
+		require.NoError(t, err)
+		assert.Empty(t, anonymous.CreatedBy)
+
+		mine, err := s.ListByCreator(ctx, "key:rediscreator", 10)
+		require.NoError(t, err)
+		require.Len(t, mine, 2)
+
+		for _, shortURL := range mine {
+			assert.Equal(t, "key:rediscreator", shortURL.CreatedBy)
+		}
+
+		// Cleanup
+		for _, code := range codes {
+			client.Del(ctx, "url:"+code)
+		}
+	})
+
+	t.Run("exists reports taken and free codes", func(t *testing.T) {
+		require.NoError(t, s.Save(ctx, &shortener.ShortURL{Code: "testexists1", OriginalURL: "https://example.com"}))
+
+		exists, err := s.Exists(ctx, []shortener.Code{"testexists1", "testexists2"})
+		require.NoError(t, err)
+		assert.Equal(t, map[shortener.Code]bool{"testexists1": true, "testexists2": false}, exists)
+
+		// Cleanup
+		client.Del(ctx, "url:testexists1")
+	})
+
 	t.Run("save and get by hash", func(t *testing.T) {
 		shortURL := &shortener.ShortURL{
 			Code:        "hashcode123",
@@ -101,3 +190,50 @@ func TestRedisStoreIntegration(t *testing.T) {
 		assert.ErrorIs(t, err, shortener.ErrNotFound)
 	})
 }
+
+func TestRedisCacheRepositoryNegativeCacheIntegration(t *testing.T) {
+	client := redis.NewClient(&redis.Options{
+		Addr: getRedisAddr(),
+	})
+	defer client.Close()
+
+	ctx := context.Background()
+	if err := client.Ping(ctx).Err(); err != nil {
+		t.Skipf("Redis not available: %v", err)
+	}
+
+	backing := store.NewMemoryStore()
+	repo := store.NewRedisCacheRepository(backing, client, time.Hour, store.WithRedisNegativeTTL(time.Minute))
+
+	t.Run("serves a repeated miss from the cache", func(t *testing.T) {
+		code := shortener.Code("negcache1")
+		defer client.Del(ctx, "url:"+string(code))
+
+		_, err := repo.GetByCode(ctx, code)
+		require.ErrorIs(t, err, shortener.ErrNotFound)
+
+		// Saved behind the cache's back, so only the negative entry can answer 404
+		require.NoError(t, backing.Save(ctx, &shortener.ShortURL{Code: code, OriginalURL: "https://example.com"}))
+
+		_, err = repo.GetByCode(ctx, code)
+		require.ErrorIs(t, err, shortener.ErrNotFound)
+	})
+
+	t.Run("creating the code clears the negative entry", func(t *testing.T) {
+		code := shortener.Code("negcache2")
+		defer client.Del(ctx, "url:"+string(code))
+
+		_, err := repo.GetByCode(ctx, code)
+		require.ErrorIs(t, err, shortener.ErrNotFound)
+
+		require.NoError(t, repo.Save(ctx, &shortener.ShortURL{Code: code, OriginalURL: "https://example.com/new"}))
+
+		got, err := repo.GetByCode(ctx, code)
+		require.NoError(t, err)
+		assert.Equal(t, "https://example.com/new", got.OriginalURL)
+
+		ttl, err := client.TTL(ctx, "url:"+string(code)).Result()
+		require.NoError(t, err)
+		assert.Greater(t, ttl, time.Minute, "the positive entry should use the cache TTL")
+	})
+}
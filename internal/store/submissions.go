@@ -0,0 +1,120 @@
+package store
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/serroba/web-demo-go/internal/shortener"
+)
+
+// submissionKey identifies a client and URL pair without storing either in
+// the clear.
+func submissionKey(client, url string) string {
+	sum := sha256.Sum256([]byte(client + "\x00" + url))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// RedisRecentSubmissions is a Redis implementation of
+// shortener.RecentSubmissions. Each submission is a key that expires with the
+// window, so instances behind a load balancer share it.
+type RedisRecentSubmissions struct {
+	client *redis.Client
+	prefix string
+	window time.Duration
+}
+
+// NewRedisRecentSubmissions creates a Redis-backed submission window.
+func NewRedisRecentSubmissions(client *redis.Client, window time.Duration) *RedisRecentSubmissions {
+	return &RedisRecentSubmissions{
+		client: client,
+		prefix: "submission:",
+		window: window,
+	}
+}
+
+// Recent implements shortener.RecentSubmissions.
+func (s *RedisRecentSubmissions) Recent(ctx context.Context, client, url string) (shortener.Code, error) {
+	code, err := s.client.Get(ctx, s.prefix+submissionKey(client, url)).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", nil
+	}
+
+	return shortener.Code(code), err
+}
+
+// Remember implements shortener.RecentSubmissions.
+func (s *RedisRecentSubmissions) Remember(ctx context.Context, client, url string, code shortener.Code) error {
+	return s.client.Set(ctx, s.prefix+submissionKey(client, url), string(code), s.window).Err()
+}
+
+// MemoryRecentSubmissions is an in-memory implementation of
+// shortener.RecentSubmissions for single-instance deployments and tests.
+type MemoryRecentSubmissions struct {
+	mu      sync.Mutex
+	window  time.Duration
+	entries map[string]submission
+}
+
+type submission struct {
+	code      shortener.Code
+	expiresAt time.Time
+}
+
+// NewMemoryRecentSubmissions creates an in-memory submission window.
+func NewMemoryRecentSubmissions(window time.Duration) *MemoryRecentSubmissions {
+	return &MemoryRecentSubmissions{
+		window:  window,
+		entries: make(map[string]submission),
+	}
+}
+
+// Recent implements shortener.RecentSubmissions.
+func (s *MemoryRecentSubmissions) Recent(_ context.Context, client, url string) (shortener.Code, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := submissionKey(client, url)
+
+	entry, ok := s.entries[key]
+	if !ok {
+		return "", nil
+	}
+
+	if !time.Now().Before(entry.expiresAt) {
+		delete(s.entries, key)
+
+		return "", nil
+	}
+
+	return entry.code, nil
+}
+
+// Remember implements shortener.RecentSubmissions. Expired entries are
+// dropped as new ones are recorded, so the map stays bounded by the window.
+func (s *MemoryRecentSubmissions) Remember(_ context.Context, client, url string, code shortener.Code) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+
+	for key, entry := range s.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(s.entries, key)
+		}
+	}
+
+	s.entries[submissionKey(client, url)] = submission{code: code, expiresAt: now.Add(s.window)}
+
+	return nil
+}
+
+var (
+	_ shortener.RecentSubmissions = (*RedisRecentSubmissions)(nil)
+	_ shortener.RecentSubmissions = (*MemoryRecentSubmissions)(nil)
+)
@@ -0,0 +1,99 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/serroba/web-demo-go/internal/shortener"
+	"github.com/serroba/web-demo-go/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stringKeys serves GET and SET from memory and records the expiry of each SET.
+type stringKeys struct {
+	values  map[string]string
+	expires map[string]any
+}
+
+func (h *stringKeys) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (h *stringKeys) ProcessHook(_ redis.ProcessHook) redis.ProcessHook {
+	return func(_ context.Context, cmd redis.Cmder) error {
+		args := cmd.Args()
+
+		switch cmd.Name() {
+		case "set":
+			h.values[args[1].(string)] = args[2].(string)
+			h.expires[args[1].(string)] = args[4]
+		case "get":
+			value, ok := h.values[args[1].(string)]
+			if !ok {
+				cmd.SetErr(redis.Nil)
+
+				return redis.Nil
+			}
+
+			cmd.(*redis.StringCmd).SetVal(value)
+		}
+
+		return nil
+	}
+}
+
+func (h *stringKeys) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return next
+}
+
+func TestRedisRecentSubmissions(t *testing.T) {
+	hook := &stringKeys{values: map[string]string{}, expires: map[string]any{}}
+	client := redis.NewClient(&redis.Options{Addr: "localhost:0"})
+	t.Cleanup(func() { _ = client.Close() })
+	client.AddHook(hook)
+
+	submissions := store.NewRedisRecentSubmissions(client, 10*time.Second)
+	ctx := context.Background()
+
+	code, err := submissions.Recent(ctx, "10.0.0.1", "https://example.com")
+	require.NoError(t, err)
+	assert.Empty(t, code)
+
+	require.NoError(t, submissions.Remember(ctx, "10.0.0.1", "https://example.com", "abc123"))
+
+	code, err = submissions.Recent(ctx, "10.0.0.1", "https://example.com")
+	require.NoError(t, err)
+	assert.Equal(t, shortener.Code("abc123"), code)
+
+	code, err = submissions.Recent(ctx, "10.0.0.2", "https://example.com")
+	require.NoError(t, err)
+	assert.Empty(t, code, "submissions are per client")
+
+	require.Len(t, hook.expires, 1)
+
+	for key, expiry := range hook.expires {
+		assert.Contains(t, key, "submission:")
+		assert.NotContains(t, key, "10.0.0.1", "client identities are hashed")
+		assert.EqualValues(t, 10, expiry, "entries expire with the window")
+	}
+}
+
+func TestMemoryRecentSubmissions(t *testing.T) {
+	submissions := store.NewMemoryRecentSubmissions(20 * time.Millisecond)
+	ctx := context.Background()
+
+	require.NoError(t, submissions.Remember(ctx, "10.0.0.1", "https://example.com", "abc123"))
+
+	code, err := submissions.Recent(ctx, "10.0.0.1", "https://example.com")
+	require.NoError(t, err)
+	assert.Equal(t, shortener.Code("abc123"), code)
+
+	time.Sleep(25 * time.Millisecond)
+
+	code, err = submissions.Recent(ctx, "10.0.0.1", "https://example.com")
+	require.NoError(t, err)
+	assert.Empty(t, code, "entries expire with the window")
+}
@@ -0,0 +1,36 @@
+// Package tlsconfig builds the TLS configuration used when the server terminates TLS itself.
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+)
+
+// ErrInsecureVersion is returned for TLS versions older than 1.2.
+var ErrInsecureVersion = errors.New("insecure TLS version")
+
+// ParseVersion converts a version such as "1.2" or "1.3" to its crypto/tls constant.
+// TLS 1.0 and 1.1 are rejected with ErrInsecureVersion.
+func ParseVersion(version string) (uint16, error) {
+	switch version {
+	case "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	case "1.0", "1.1":
+		return 0, fmt.Errorf("%w %s: must be 1.2 or 1.3", ErrInsecureVersion, version)
+	default:
+		return 0, fmt.Errorf("invalid TLS version %q: must be 1.2 or 1.3", version)
+	}
+}
+
+// New returns a server TLS configuration that refuses handshakes below minVersion.
+func New(minVersion string) (*tls.Config, error) {
+	version, err := ParseVersion(minVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tls.Config{MinVersion: version}, nil
+}
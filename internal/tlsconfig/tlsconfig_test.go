@@ -0,0 +1,48 @@
+package tlsconfig_test
+
+import (
+	"crypto/tls"
+	"testing"
+
+	"github.com/serroba/web-demo-go/internal/tlsconfig"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		version string
+		want    uint16
+	}{
+		{version: "1.2", want: tls.VersionTLS12},
+		{version: "1.3", want: tls.VersionTLS13},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			cfg, err := tlsconfig.New(tt.version)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, cfg.MinVersion)
+		})
+	}
+
+	t.Run("rejects insecure versions", func(t *testing.T) {
+		for _, version := range []string{"1.0", "1.1"} {
+			cfg, err := tlsconfig.New(version)
+
+			assert.Nil(t, cfg)
+			require.ErrorIs(t, err, tlsconfig.ErrInsecureVersion)
+		}
+	})
+
+	t.Run("rejects unknown versions", func(t *testing.T) {
+		for _, version := range []string{"", "1.4", "TLS1.2"} {
+			cfg, err := tlsconfig.New(version)
+
+			assert.Nil(t, cfg)
+			require.Error(t, err)
+			assert.NotErrorIs(t, err, tlsconfig.ErrInsecureVersion)
+		}
+	})
+}
@@ -0,0 +1,44 @@
+// Package version reports the build the service is running. The variables are
+// set at build time with -ldflags, for example:
+//
+//	go build -ldflags "-X github.com/serroba/web-demo-go/internal/version.Version=v1.2.3 \
+//	  -X github.com/serroba/web-demo-go/internal/version.Commit=$(git rev-parse HEAD) \
+//	  -X github.com/serroba/web-demo-go/internal/version.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)" ./cmd/server
+package version
+
+import (
+	"context"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// Build information injected via -ldflags; the defaults identify local builds.
+var (
+	Version   = "dev"
+	Commit    = "unknown"
+	BuildTime = "unknown"
+)
+
+// Response is the response for the version endpoint.
+type Response struct {
+	Body struct {
+		Version   string `doc:"Release version"           example:"v1.2.3"               json:"version"`
+		Commit    string `doc:"Git commit built from"     example:"1f24a3e"              json:"commit"`
+		BuildTime string `doc:"When the binary was built" example:"2026-10-16T16:00:00Z" json:"buildTime"`
+	}
+}
+
+// Get returns the build information of the running binary.
+func Get(_ context.Context, _ *struct{}) (*Response, error) {
+	resp := &Response{}
+	resp.Body.Version = Version
+	resp.Body.Commit = Commit
+	resp.Body.BuildTime = BuildTime
+
+	return resp, nil
+}
+
+// RegisterRoutes registers the version endpoint.
+func RegisterRoutes(api huma.API) {
+	huma.Get(api, "/version", Get)
+}
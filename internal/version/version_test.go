@@ -0,0 +1,41 @@
+package version_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/adapters/humachi"
+	"github.com/go-chi/chi/v5"
+	"github.com/serroba/web-demo-go/internal/version"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterRoutes(t *testing.T) {
+	v, c, b := version.Version, version.Commit, version.BuildTime
+	t.Cleanup(func() { version.Version, version.Commit, version.BuildTime = v, c, b })
+
+	version.Version, version.Commit, version.BuildTime = "v1.2.3", "1f24a3e", "2026-10-16T16:00:00Z"
+
+	router := chi.NewMux()
+	version.RegisterRoutes(humachi.New(router, huma.DefaultConfig("Test", "1.0.0")))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/version", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var body struct {
+		Version   string `json:"version"`
+		Commit    string `json:"commit"`
+		BuildTime string `json:"buildTime"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+
+	assert.Equal(t, "v1.2.3", body.Version)
+	assert.Equal(t, "1f24a3e", body.Commit)
+	assert.Equal(t, "2026-10-16T16:00:00Z", body.BuildTime)
+}
@@ -0,0 +1,2 @@
+-- Aggregated access count, incremented in batches by the analytics consumer
+ALTER TABLE short_urls ADD COLUMN access_count BIGINT NOT NULL DEFAULT 0;
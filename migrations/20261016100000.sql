@@ -0,0 +1,3 @@
+-- Optional secondary target used while the original URL is flagged as bad
+ALTER TABLE short_urls ADD COLUMN fallback_url TEXT;
+ALTER TABLE short_urls ADD COLUMN flagged BOOLEAN NOT NULL DEFAULT false;
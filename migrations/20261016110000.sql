@@ -0,0 +1,3 @@
+-- Keyed hash of the client IP, stored instead of client_ip when IP hashing is enabled
+ALTER TABLE url_created_events ADD COLUMN client_ip_hash VARCHAR(64);
+ALTER TABLE url_accessed_events ADD COLUMN client_ip_hash VARCHAR(64);
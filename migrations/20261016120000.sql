@@ -0,0 +1,2 @@
+-- Serves the newest-first listing of short URLs
+CREATE INDEX idx_short_urls_created_at ON short_urls (created_at DESC);
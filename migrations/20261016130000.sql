@@ -0,0 +1,11 @@
+-- Fixed-window rate limit counters for deployments without Redis
+CREATE TABLE rate_limit_counters (
+    key          TEXT        NOT NULL,
+    window_ms    BIGINT      NOT NULL,
+    window_start TIMESTAMPTZ NOT NULL,
+    count        BIGINT      NOT NULL DEFAULT 0,
+    expires_at   TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (key, window_ms, window_start)
+);
+
+CREATE INDEX idx_rate_limit_counters_expires_at ON rate_limit_counters (expires_at);
@@ -0,0 +1,2 @@
+-- Normalized form of original_url, stored when STORE_NORMALIZED_URL is enabled
+ALTER TABLE short_urls ADD COLUMN normalized_url TEXT;
@@ -0,0 +1,4 @@
+-- API key or user that created the short URL; NULL for unauthenticated creates
+ALTER TABLE short_urls ADD COLUMN created_by TEXT;
+
+CREATE INDEX idx_short_urls_created_by ON short_urls (created_by, created_at DESC) WHERE created_by IS NOT NULL;
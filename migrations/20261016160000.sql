@@ -0,0 +1,2 @@
+-- Strategy that created the short URL (token, hash or alias); NULL for imported and older rows
+ALTER TABLE short_urls ADD COLUMN strategy TEXT;
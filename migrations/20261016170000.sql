@@ -0,0 +1,2 @@
+-- Optional media type of the target, so clients can preview downloads
+ALTER TABLE short_urls ADD COLUMN content_type_hint TEXT;